@@ -2,18 +2,28 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
 	"github.com/nirarg/vm-deep-inspection-demo/internal/api"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/auth"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/compression"
 	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/metrics"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/ratelimit"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/requestid"
 	"github.com/nirarg/vm-deep-inspection-demo/internal/storage"
 	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
 	"github.com/sirupsen/logrus"
@@ -52,6 +62,9 @@ func main() {
 	log := setupLogger(cfg.Logging)
 	log.Info("Starting VM Deep Inspection Demo service...")
 	log.WithField("config_file", configFile).Debug("Configuration loaded")
+	for _, warning := range cfg.Warnings() {
+		log.Warn(warning)
+	}
 
 	// Set Gin mode based on log level
 	if cfg.Logging.Level == "debug" {
@@ -74,6 +87,11 @@ func main() {
 	// Initialize VMware services
 	vmService := vmware.NewVMService(vmwareClient, log)
 
+	// Remove clones leaked by earlier runs (e.g. after a crash mid-inspection)
+	if cfg.Inspection.StaleCloneAge > 0 {
+		go cleanupStaleClones(vmService, cfg.Inspection.StaleCloneAge, log)
+	}
+
 	// Initialize database connection
 	db, err := initDatabase(cfg.Database, log)
 	if err != nil {
@@ -84,13 +102,29 @@ func main() {
 		"name": cfg.Database.Name,
 	}).Info("Database initialized")
 
+	// Get underlying sql.DB for readiness checks and shutdown
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get underlying database: %v", err)
+	}
+
 	// Initialize inspection database
-	inspectionDB, err := storage.NewInspectionDB(db, log)
+	inspectionDB, err := storage.NewInspectionDB(db, cfg.Storage.InspectionCacheSize, log)
 	if err != nil {
 		log.Fatalf("Failed to initialize inspection database: %v", err)
 	}
 	log.Info("Inspection database schema migrated")
 
+	// Initialize inspection job history
+	jobHistory, err := storage.NewJobHistoryDB(db, log)
+	if err != nil {
+		log.Fatalf("Failed to initialize job history: %v", err)
+	}
+
+	// Initialize the per-run inspection artifact store
+	artifacts := storage.NewArtifactStore(cfg.Storage.BasePath, cfg.Storage.ArtifactRetention, log)
+	go pruneArtifacts(artifacts, log)
+
 	// Initialize persistent inspector with credentials and DB
 	credentials := persistent.Credentials{
 		VCenterURL: cfg.VMware.VCenterURL,
@@ -98,48 +132,128 @@ func main() {
 		Password:   cfg.VMware.Password,
 	}
 	inspector := persistent.NewInspector(
-		"",    // virt-inspector path (uses system PATH)
-		"",    // virt-v2v-inspector path (uses system PATH)
-		30*time.Minute, // timeout
+		cfg.Inspection.VirtInspectorPath,    // empty uses system PATH
+		cfg.Inspection.VirtV2vInspectorPath, // empty uses system PATH
+		cfg.Inspection.Timeout,
 		credentials,
 		log,
 		inspectionDB, // Use file-based DB persistence
 	)
 
+	// Inspections run under this context so they can be aborted on shutdown
+	inspectionCtx, cancelInspections := context.WithCancel(context.Background())
+	defer cancelInspections()
+
 	// Initialize handlers
-	vmHandler := api.NewVMHandler(vmService, vmwareClient, inspector, log)
+	vmHandler := api.NewVMHandler(inspectionCtx, vmService, vmwareClient, inspector, inspectionDB, api.NewJobRegistry(), jobHistory, artifacts, cfg.Inspection, log)
+	inspectionHandler := api.NewInspectionHandler(inspectionDB, artifacts, log)
 
 	// Setup router
 	router := gin.Default()
 
+	// Only trusted proxies may set the client IP through X-Forwarded-For; it keys rate limits
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	// CORS middleware (if enabled)
 	if cfg.Server.EnableCORS {
-		router.Use(corsMiddleware())
+		router.Use(corsMiddleware(cfg.Server.CORS))
 	}
 
+	// Request ID middleware (must run before request logging)
+	router.Use(requestid.Middleware())
+
 	// Request logging middleware
 	router.Use(requestLoggerMiddleware(log))
 
+	// Response compression (metrics are scraped uncompressed)
+	if cfg.Server.EnableCompression {
+		router.Use(compression.Middleware(cfg.Server.CompressionMinSize, []string{"/metrics"}))
+	}
+
+	// Authentication (health and readiness probes are exempt)
+	if cfg.Server.Auth.Enabled {
+		router.Use(auth.Middleware(cfg.Server.Auth, []string{"/health", "/readiness"}, log))
+	}
+
 	// Health check endpoint
 	router.GET("/health", healthCheck(log))
 
+	// Readiness check endpoint (verifies vCenter and database connectivity)
+	router.GET("/readiness", readinessCheck(vmwareClient, sqlDB, log))
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Per-client rate limits; health, readiness, metrics and swagger are not limited
+	apiLimit, inspectionLimit := rateLimiters(cfg.Server.RateLimit)
+
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", apiLimit)
 	{
+		// Inventory routes (values for the datacenter and cluster filters)
+		v1.GET("/datacenters", vmHandler.ListDatacenters)
+		v1.GET("/datacenters/:dc/clusters", vmHandler.ListClusters)
+
 		// VM routes
 		v1.GET("/vms", vmHandler.ListVMs)
+		v1.GET("/vms/summary", vmHandler.ListVMSummaries)
+		v1.GET("/vms/search", vmHandler.SearchVMsByIP)
+		v1.GET("/vms/needs-consolidation", vmHandler.ListVMsNeedingConsolidation)
 		v1.GET("/vms/:name", vmHandler.GetVM)
+		v1.GET("/vms/:name/snapshots", vmHandler.ListSnapshots)
+		v1.GET("/vms/:name/disks", vmHandler.ListDisks)
+		v1.GET("/vms/:name/stats", vmHandler.GetVMStats)
+		v1.PATCH("/vms/:name/config", vmHandler.ReconfigureVM)
+		v1.PUT("/vms/:name/annotation", vmHandler.SetVMAnnotation)
+		v1.GET("/vms/:name/cbt", vmHandler.GetVMChangeTracking)
+		v1.POST("/vms/:name/cbt", vmHandler.SetVMChangeTracking)
+		v1.POST("/vms/:name/consolidate", vmHandler.ConsolidateVM)
 		v1.POST("/vms/snapshot", vmHandler.CreateVMSnapshot)
 
 		// Clone and inspection routes
 		v1.POST("/vms/clone", vmHandler.CreateClone)
 		v1.DELETE("/vms/delete-clone", vmHandler.DeleteClone)
+		v1.POST("/vms/cleanup-clones", vmHandler.CleanupClones)
 
 		// Snapshot inspection route (direct inspection without clone)
-		v1.POST("/vms/inspect-snapshot", vmHandler.InspectSnapshot)
+		v1.POST("/vms/inspect-snapshot", inspectionLimit, vmHandler.InspectSnapshot)
+
+		// Current-state inspection route (powered-off VMs, no snapshot)
+		v1.POST("/vms/inspect", inspectionLimit, vmHandler.InspectVM)
+
+		// Running-VM inspection route (temporary snapshot or suspend/resume)
+		v1.POST("/vms/inspect-live", inspectionLimit, vmHandler.InspectLive)
+
+		// Inspection prerequisites check (no inspection is run)
+		v1.POST("/vms/inspect-snapshot/preflight", vmHandler.PreflightInspection)
+
+		// Inspection of disks at known locations (no VM or snapshot lookup)
+		v1.POST("/inspect-disk", inspectionLimit, vmHandler.InspectDisk)
+
+		// Batch inspection route
+		v1.POST("/vms/inspect-batch", inspectionLimit, vmHandler.InspectBatch)
+
+		// Asynchronous inspection routes
+		v1.POST("/vms/inspect-snapshot/async", inspectionLimit, vmHandler.InspectSnapshotAsync)
+		v1.GET("/jobs", vmHandler.ListJobs)
+		v1.GET("/jobs/:id", vmHandler.GetJob)
 
 		// Validation checks route (generic check runner)
-		v1.POST("/vms/check", vmHandler.RunCheck)
+		v1.POST("/vms/check", inspectionLimit, vmHandler.RunCheck)
+
+		// Stored inspection results routes
+		v1.GET("/vms/inspection/export", inspectionHandler.ExportInspection)
+		v1.GET("/vms/inspection/summary", inspectionHandler.GetInspectionSummary)
+		v1.GET("/vms/inspection/artifacts", inspectionHandler.GetInspectionArtifacts)
+		v1.DELETE("/vms/inspection", inspectionHandler.DeleteInspection)
+		v1.DELETE("/inspections", inspectionHandler.PurgeInspections)
+		v1.GET("/inspections/by-application", inspectionHandler.FindInspectionsByApplication)
+
+		// vCenter session diagnostics and forced reconnect
+		v1.GET("/vsphere/status", vmHandler.GetVSphereStatus)
+		v1.POST("/vsphere/reconnect", vmHandler.ReconnectVSphere)
 	}
 
 	// Swagger documentation endpoint
@@ -177,12 +291,25 @@ func main() {
 		}
 	}()
 
+	// Reload the settings that can change at runtime on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		current := cfg
+		for range reload {
+			current = reloadConfig(configFile, current, log, vmwareClient, sqlDB)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Shutting down server...")
 
+	// Abort running inspections so their nbdkit and inspector processes are killed
+	cancelInspections()
+
 	// Give the server time to finish handling existing requests
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -191,26 +318,132 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Wait for background inspections to abort within the same deadline
+	if err := vmHandler.WaitForInspections(shutdownCtx); err != nil {
+		log.WithError(err).Warn("Timed out waiting for running inspections to abort")
+	} else {
+		log.Info("All running inspections stopped")
+	}
+
 	// Close database connection
-	sqlDB, err := db.DB()
-	if err == nil {
-		if err := sqlDB.Close(); err != nil {
-			log.WithError(err).Warn("Error closing database connection")
-		} else {
-			log.Info("Database connection closed")
-		}
+	if err := sqlDB.Close(); err != nil {
+		log.WithError(err).Warn("Error closing database connection")
+	} else {
+		log.Info("Database connection closed")
 	}
 
-	// Disconnect from vCenter
-	disconnectCtx, disconnectCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer disconnectCancel()
-	if err := vmwareClient.Disconnect(disconnectCtx); err != nil {
+	// Disconnect from vCenter within what is left of the shutdown deadline
+	if err := vmwareClient.Disconnect(shutdownCtx); err != nil {
 		log.WithError(err).Warn("Error disconnecting from vCenter")
 	}
 
 	log.Info("Server exited")
 }
 
+// cleanupStaleClones deletes clones older than olderThan that were left behind by earlier runs
+func cleanupStaleClones(vmService *vmware.VMService, olderThan time.Duration, log *logrus.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	result, err := vmService.CleanupStaleClones(ctx, olderThan, "")
+	if err != nil {
+		log.WithError(err).Warn("Startup cleanup of stale clones failed")
+		return
+	}
+	if len(result.Deleted) > 0 || len(result.Failed) > 0 {
+		log.WithFields(logrus.Fields{
+			"deleted": len(result.Deleted),
+			"failed":  len(result.Failed),
+		}).Info("Startup cleanup of stale clones completed")
+	}
+}
+
+// pruneArtifacts removes inspection artifacts older than the retention period at startup
+// Later runs are pruned after each inspection stores its artifacts
+func pruneArtifacts(artifacts *storage.ArtifactStore, log *logrus.Logger) {
+	if _, err := artifacts.Prune(context.Background()); err != nil {
+		log.WithError(err).Warn("Startup pruning of inspection artifacts failed")
+	}
+}
+
+// reloadConfig re-reads the configuration and applies the settings that are safe to change
+// at runtime: log level and format, vCenter login retries and database pool sizes
+// Other changes need a restart and are logged as ignored. It returns the configuration in
+// effect, which is current when the new configuration cannot be loaded
+func reloadConfig(configFile string, current *config.Config, log *logrus.Logger, vmwareClient *vmware.Client, sqlDB *sql.DB) *config.Config {
+	loaded, err := config.Load(configFile)
+	if err != nil {
+		log.WithError(err).Error("Failed to reload configuration, keeping the current settings")
+		return current
+	}
+
+	next := *current
+	var changed []string
+
+	if loaded.Logging.Level != current.Logging.Level {
+		if level, err := logrus.ParseLevel(loaded.Logging.Level); err == nil {
+			log.SetLevel(level)
+		}
+		next.Logging.Level = loaded.Logging.Level
+		changed = append(changed, "logging.level")
+	}
+	if loaded.Logging.Format != current.Logging.Format {
+		log.SetFormatter(logFormatter(loaded.Logging.Format))
+		next.Logging.Format = loaded.Logging.Format
+		changed = append(changed, "logging.format")
+	}
+
+	if loaded.VMware.RetryAttempts != current.VMware.RetryAttempts || loaded.VMware.RetryDelay != current.VMware.RetryDelay {
+		vmwareClient.SetRetryPolicy(loaded.VMware.RetryAttempts, loaded.VMware.RetryDelay)
+		next.VMware.RetryAttempts = loaded.VMware.RetryAttempts
+		next.VMware.RetryDelay = loaded.VMware.RetryDelay
+		changed = append(changed, "vmware.retry_attempts", "vmware.retry_delay")
+	}
+
+	if loaded.Database.MaxIdleConns != current.Database.MaxIdleConns ||
+		loaded.Database.MaxOpenConns != current.Database.MaxOpenConns ||
+		loaded.Database.ConnMaxLifetime != current.Database.ConnMaxLifetime {
+		setPoolSettings(sqlDB, loaded.Database)
+		next.Database.MaxIdleConns = loaded.Database.MaxIdleConns
+		next.Database.MaxOpenConns = loaded.Database.MaxOpenConns
+		next.Database.ConnMaxLifetime = loaded.Database.ConnMaxLifetime
+		changed = append(changed, "database.max_idle_conns", "database.max_open_conns", "database.conn_max_lifetime")
+	}
+
+	// Whatever still differs once the runtime settings are applied needs a restart
+	var ignored []string
+	sections := map[string][2]interface{}{
+		"vmware":     {next.VMware, loaded.VMware},
+		"server":     {next.Server, loaded.Server},
+		"logging":    {next.Logging, loaded.Logging},
+		"database":   {next.Database, loaded.Database},
+		"storage":    {next.Storage, loaded.Storage},
+		"inspection": {next.Inspection, loaded.Inspection},
+	}
+	for name, values := range sections {
+		if !reflect.DeepEqual(values[0], values[1]) {
+			ignored = append(ignored, name)
+		}
+	}
+	sort.Strings(ignored)
+	if len(ignored) > 0 {
+		log.WithField("sections", ignored).Warn("Configuration changes that require a restart were ignored")
+	}
+
+	log.WithField("changed", changed).Info("Configuration reloaded")
+	return &next
+}
+
+// logFormatter returns the log formatter for a logging format
+func logFormatter(format string) logrus.Formatter {
+	if format == "json" {
+		return &logrus.JSONFormatter{}
+	}
+	return &logrus.TextFormatter{
+		FullTimestamp: true,
+	}
+}
+
 func setupLogger(cfg config.LoggingConfig) *logrus.Logger {
 	log := logrus.New()
 
@@ -222,13 +455,7 @@ func setupLogger(cfg config.LoggingConfig) *logrus.Logger {
 	log.SetLevel(level)
 
 	// Set log format
-	if cfg.Format == "json" {
-		log.SetFormatter(&logrus.JSONFormatter{})
-	} else {
-		log.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp: true,
-		})
-	}
+	log.SetFormatter(logFormatter(cfg.Format))
 
 	// Set output
 	switch cfg.Output {
@@ -248,18 +475,46 @@ func setupLogger(cfg config.LoggingConfig) *logrus.Logger {
 		log.SetOutput(os.Stdout)
 	}
 
+	// Tag entries logged with a request context with the request ID
+	log.AddHook(requestid.Hook{})
+
 	return log
 }
 
-// corsMiddleware returns a CORS middleware
-func corsMiddleware() gin.HandlerFunc {
+// corsMiddleware returns a CORS middleware enforcing the configured policy
+// Allowed origins are echoed back (or "*" when any origin is allowed); requests from other
+// origins get no CORS headers, so browsers block them, and their preflights are refused
+func corsMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// Responses depend on the origin unless every origin gets the same headers
+		if !anyOrigin {
+			c.Header("Vary", "Origin")
+		}
+
+		allowed := origin != "" && (anyOrigin || slices.Contains(cfg.AllowedOrigins, origin))
+		if allowed {
+			if anyOrigin {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+			}
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+		}
+
+		if preflight {
+			if allowed {
+				c.AbortWithStatus(http.StatusNoContent)
+			} else {
+				c.AbortWithStatus(http.StatusForbidden)
+			}
 			return
 		}
 
@@ -267,6 +522,17 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// rateLimiters returns the rate limiting middleware for all API routes and the stricter
+// one for inspection routes; both let every request through when rate limiting is disabled
+func rateLimiters(cfg config.RateLimitConfig) (api gin.HandlerFunc, inspection gin.HandlerFunc) {
+	if !cfg.Enabled {
+		pass := func(c *gin.Context) { c.Next() }
+		return pass, pass
+	}
+	return ratelimit.Middleware(ratelimit.New(cfg.RequestsPerSecond, cfg.Burst)),
+		ratelimit.Middleware(ratelimit.New(cfg.InspectionRequestsPerSecond, cfg.InspectionBurst))
+}
+
 // requestLoggerMiddleware logs HTTP requests
 func requestLoggerMiddleware(log *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -283,11 +549,18 @@ func requestLoggerMiddleware(log *logrus.Logger) gin.HandlerFunc {
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 
+		// Use the matched route template so path parameters don't explode metric cardinality
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(method, route, statusCode, latency)
+
 		if raw != "" {
 			path = path + "?" + raw
 		}
 
-		entry := log.WithFields(logrus.Fields{
+		entry := log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
 			"status":     statusCode,
 			"latency":    latency,
 			"client_ip":  clientIP,
@@ -316,6 +589,40 @@ func healthCheck(log *logrus.Logger) gin.HandlerFunc {
 	}
 }
 
+// readinessCheck returns a readiness handler that verifies vCenter and database connectivity
+func readinessCheck(vmwareClient *vmware.Client, sqlDB *sql.DB, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		failed := gin.H{}
+
+		if err := vmwareClient.HealthCheck(ctx); err != nil {
+			log.WithError(err).Warn("Readiness check failed: vCenter unavailable")
+			failed["vcenter"] = err.Error()
+		}
+
+		if err := sqlDB.PingContext(ctx); err != nil {
+			log.WithError(err).Warn("Readiness check failed: database unavailable")
+			failed["database"] = err.Error()
+		}
+
+		if len(failed) > 0 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":    "not ready",
+				"timestamp": time.Now(),
+				"service":   "vm-deep-inspection-demo",
+				"failed":    failed,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "ready",
+			"timestamp": time.Now(),
+			"service":   "vm-deep-inspection-demo",
+		})
+	}
+}
+
 // initDatabase initializes and returns a GORM database connection
 func initDatabase(cfg config.DatabaseConfig, log *logrus.Logger) (*gorm.DB, error) {
 	var dialector gorm.Dialector
@@ -355,9 +662,14 @@ func initDatabase(cfg config.DatabaseConfig, log *logrus.Logger) (*gorm.DB, erro
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	setPoolSettings(sqlDB, cfg)
 
 	return db, nil
 }
+
+// setPoolSettings applies the connection pool settings to the database
+func setPoolSettings(sqlDB *sql.DB, cfg config.DatabaseConfig) {
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	listed := config.CORSConfig{
+		AllowedOrigins: []string{"https://ui.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+	}
+	wildcard := listed
+	wildcard.AllowedOrigins = []string{"*"}
+
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+		wantVary    bool
+	}{
+		{name: "allowed origin", cfg: listed, method: http.MethodGet, origin: "https://ui.example.com", wantStatus: http.StatusOK, wantOrigin: "https://ui.example.com", wantMethods: "GET, POST", wantVary: true},
+		{name: "denied origin", cfg: listed, method: http.MethodGet, origin: "https://evil.example.com", wantStatus: http.StatusOK, wantVary: true},
+		{name: "no origin", cfg: listed, method: http.MethodGet, wantStatus: http.StatusOK, wantVary: true},
+		{name: "preflight from allowed origin", cfg: listed, method: http.MethodOptions, origin: "https://ui.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://ui.example.com", wantMethods: "GET, POST", wantVary: true},
+		{name: "preflight from denied origin", cfg: listed, method: http.MethodOptions, origin: "https://evil.example.com", wantStatus: http.StatusForbidden, wantVary: true},
+		{name: "any origin", cfg: wildcard, method: http.MethodGet, origin: "https://evil.example.com", wantStatus: http.StatusOK, wantOrigin: "*", wantMethods: "GET, POST"},
+		{name: "preflight with any origin", cfg: wildcard, method: http.MethodOptions, origin: "https://ui.example.com", wantStatus: http.StatusNoContent, wantOrigin: "*", wantMethods: "GET, POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(corsMiddleware(tt.cfg))
+			router.GET("/api/v1/vms", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "/api/v1/vms", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if vary := w.Header().Get("Vary") == "Origin"; vary != tt.wantVary {
+				t.Errorf("Vary: Origin set = %v, want %v", vary, tt.wantVary)
+			}
+		})
+	}
+}
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/vms": {
+        "/api/v1/datacenters": {
             "get": {
-                "description": "Get a list of all virtual machines with optional name filtering",
+                "description": "Get the names of all datacenters, sorted, e.g. for the datacenter filter of other endpoints",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,23 +25,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "vms"
+                    "inventory"
                 ],
-                "summary": "List all virtual machines",
+                "summary": "List datacenters",
+                "responses": {
+                    "200": {
+                        "description": "Datacenters retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.DatacenterListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/datacenters/{dc}/clusters": {
+            "get": {
+                "description": "Get the names of all clusters in a datacenter, sorted, e.g. for the cluster filter of the VM listing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "List the clusters of a datacenter",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"web\"",
-                        "description": "Filter VMs where name contains this string",
-                        "name": "name_contains",
-                        "in": "query"
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter name",
+                        "name": "dc",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of virtual machines",
+                        "description": "Clusters retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/types.VMListResponse"
+                            "$ref": "#/definitions/types.ClusterListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Datacenter not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
                     "500": {
@@ -59,43 +101,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/vms/clone": {
+        "/api/v1/inspect-disk": {
             "post": {
-                "description": "Create a linked clone from a VM snapshot for inspection",
+                "description": "Run virt-inspector or virt-v2v-inspector on disks identified by their VM and snapshot morefs and datastore paths, skipping the VM and snapshot lookup by name. Results are stored under vm_name and snapshot_name, which default to the morefs. With \"Accept: text/event-stream\" the response is a stream of \"progress\" events followed by a \"result\" or \"error\" event.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/event-stream"
                 ],
                 "tags": [
                     "vms"
                 ],
-                "summary": "Create a clone from VM snapshot",
+                "summary": "Inspect disks at known locations",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "\"web-server-01\"",
-                        "description": "VM name",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Clone request",
+                        "description": "Disk locations",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.CloneRequest"
+                            "$ref": "#/definitions/types.InspectDiskRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"mysql\"",
+                        "description": "Only return applications whose name contains this string (case-insensitive)",
+                        "name": "app_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Set to false to omit the application lists",
+                        "name": "include_apps",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Clone created successfully",
+                        "description": "Inspection completed successfully",
                         "schema": {
-                            "$ref": "#/definitions/types.CloneResponse"
+                            "$ref": "#/definitions/types.VMInspectionResponse"
                         }
                     },
                     "400": {
@@ -104,8 +153,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "VM or snapshot not found",
+                    "422": {
+                        "description": "No operating system found on the disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many concurrent inspections",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
@@ -115,13 +170,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/vms/delete-clone": {
+        "/api/v1/inspections": {
             "delete": {
-                "description": "Delete a cloned VM created for inspection",
+                "description": "Permanently delete all stored inspection results created longer ago than the given duration",
                 "consumes": [
                     "application/json"
                 ],
@@ -129,24 +190,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "vms"
+                    "inspections"
                 ],
-                "summary": "Delete a cloned VM",
+                "summary": "Purge old stored inspection results",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"web-server-01-clone-123\"",
-                        "description": "Clone VM name",
-                        "name": "name",
+                        "example": "\"720h\"",
+                        "description": "Go duration, records created before now minus this duration are deleted",
+                        "name": "older_than",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Clone deleted successfully",
+                        "description": "Inspection records purged",
                         "schema": {
-                            "$ref": "#/definitions/types.ErrorResponse"
+                            "$ref": "#/definitions/types.InspectionDeleteResponse"
                         }
                     },
                     "400": {
@@ -155,12 +216,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Clone not found",
-                        "schema": {
-                            "$ref": "#/definitions/types.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -170,34 +225,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/vms/inspect-clone": {
-            "post": {
-                "description": "Run virt-inspector on a cloned VM",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/inspections/by-application": {
+            "get": {
+                "description": "Search the stored virt-inspector results for snapshots that have the given application installed, optionally filtered by version prefix (e.g. which VMs have openssl 1.0?)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vms"
+                    "inspections"
                 ],
-                "summary": "Inspect a cloned VM",
+                "summary": "Find inspected VMs with an application installed",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"web-server-01-clone-123\"",
-                        "description": "Clone VM name",
+                        "example": "\"openssl\"",
+                        "description": "Application name (exact match)",
                         "name": "name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1.0\"",
+                        "description": "Only match application versions starting with this prefix",
+                        "name": "version",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Inspection completed successfully",
+                        "description": "Matching inspections",
                         "schema": {
-                            "$ref": "#/definitions/types.VMInspectionResponse"
+                            "$ref": "#/definitions/types.ApplicationSearchResponse"
                         }
                     },
                     "400": {
@@ -206,12 +265,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Clone not found",
-                        "schema": {
-                            "$ref": "#/definitions/types.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -221,9 +274,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/vms/inspect-snapshot": {
-            "post": {
-                "description": "Run virt-inspector on a VM snapshot using VDDK",
+        "/api/v1/jobs": {
+            "get": {
+                "description": "List recorded inspection attempts, synchronous and asynchronous, newest first. The history is stored in the database and survives restarts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -231,32 +284,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "vms"
+                    "jobs"
                 ],
-                "summary": "Inspect a VM snapshot directly",
+                "summary": "List the inspection job history",
                 "parameters": [
                     {
                         "type": "string",
                         "example": "\"web-server-01\"",
-                        "description": "Original VM name",
+                        "description": "Only list jobs for this VM",
                         "name": "vm",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "\"inspection-snapshot\"",
-                        "description": "Snapshot name",
-                        "name": "snapshot",
-                        "in": "query",
-                        "required": true
+                        "example": "\"failed\"",
+                        "description": "Only list jobs with this status (running, completed, failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of jobs to return (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of jobs to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Inspection completed successfully",
+                        "description": "Job history",
                         "schema": {
-                            "$ref": "#/definitions/types.VMInspectionResponse"
+                            "$ref": "#/definitions/types.JobListResponse"
                         }
                     },
                     "400": {
@@ -265,12 +330,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "VM or snapshot not found",
-                        "schema": {
-                            "$ref": "#/definitions/types.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -280,9 +339,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/vms/snapshot": {
-            "post": {
-                "description": "Create a snapshot for a specific virtual machine",
+        "/api/v1/jobs/{id}": {
+            "get": {
+                "description": "Get the status of an asynchronous inspection job, including the result or error once finished",
                 "consumes": [
                     "application/json"
                 ],
@@ -290,43 +349,94 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "vms"
+                    "jobs"
                 ],
-                "summary": "Create a VM snapshot",
+                "summary": "Get the status of an inspection job",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"web-server-01\"",
-                        "description": "VM name",
-                        "name": "name",
-                        "in": "query",
+                        "example": "\"0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10\"",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Snapshot creation request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.SnapshotCreateRequest"
-                        }
+                        "type": "string",
+                        "example": "\"mysql\"",
+                        "description": "Only return applications whose name contains this string (case-insensitive)",
+                        "name": "app_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Set to false to omit the application lists",
+                        "name": "include_apps",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Snapshot created successfully",
+                        "description": "Job status",
                         "schema": {
-                            "$ref": "#/definitions/types.SnapshotCreateResponse"
+                            "$ref": "#/definitions/types.JobStatusResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "404": {
+                        "description": "Job not found",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms": {
+            "get": {
+                "description": "Get a list of all virtual machines with optional name, datacenter and cluster filtering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "List all virtual machines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web\"",
+                        "description": "Filter VMs where name contains this string",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter to list VMs from (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Cluster1\"",
+                        "description": "Only list VMs in this cluster",
+                        "name": "cluster",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of virtual machines",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMListResponse"
+                        }
                     },
                     "404": {
-                        "description": "VM not found",
+                        "description": "Datacenter or cluster not found",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
@@ -346,9 +456,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/vms/{name}": {
-            "get": {
-                "description": "Get detailed information about a specific virtual machine by name",
+        "/api/v1/vms/check": {
+            "post": {
+                "description": "Run validation checks on a VM snapshot. The checks to run can be listed in the request body or passed as the check parameter. If neither is provided, runs all available checks.",
                 "consumes": [
                     "application/json"
                 ],
@@ -358,22 +468,59 @@ const docTemplate = `{
                 "tags": [
                     "vms"
                 ],
-                "summary": "Get virtual machine details",
+                "summary": "Run validation checks on a VM snapshot",
                 "parameters": [
                     {
                         "type": "string",
                         "example": "\"web-server-01\"",
-                        "description": "VM name",
-                        "name": "name",
-                        "in": "path",
+                        "description": "Original VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name",
+                        "name": "snapshot_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"fstab\"",
+                        "description": "Check type to run (fstab, disk-access, vmware-tools). If omitted, runs all checks.",
+                        "name": "check",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter containing the VM (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Check types to run",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/types.CheckRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Virtual machine details",
+                        "description": "Check completed successfully",
                         "schema": {
-                            "$ref": "#/definitions/types.VMDetailsResponse"
+                            "$ref": "#/definitions/types.CheckResponse"
                         }
                     },
                     "400": {
@@ -383,86 +530,2285 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "VM not found",
+                        "description": "VM or snapshot not found",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "409": {
+                        "description": "Several snapshots have the snapshot name; pass snapshot_id",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "vSphere connection unavailable",
+                    "422": {
+                        "description": "VM is encrypted",
                         "schema": {
                             "$ref": "#/definitions/types.ErrorResponse"
                         }
-                    }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/cleanup-clones": {
+            "post": {
+                "description": "Delete leaked clones created by this service (marked with the vmdi.clone extraConfig key, or named with a generated -inspect-clone-\u003cunix time\u003e or -clone-\u003cYYYYMMDDHHMMSS\u003e suffix) that are powered off and older than the given age",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Delete stale inspection clones",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"1h\"",
+                        "description": "Minimum clone age as a Go duration (defaults to inspection.stale_clone_age)",
+                        "name": "older_than",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01-*\"",
+                        "description": "Additional glob pattern clone names must match",
+                        "name": "pattern",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cleanup completed",
+                        "schema": {
+                            "$ref": "#/definitions/types.CloneCleanupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/clone": {
+            "post": {
+                "description": "Create a linked clone from a VM snapshot for inspection. Without snapshot_name, the VM's current state is cloned through a temporary snapshot that is removed afterwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Create a clone from VM snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Clone request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CloneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Clone created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.CloneResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM or snapshot not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Several snapshots have the snapshot name; pass snapshot_id",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/delete-clone": {
+            "delete": {
+                "description": "Delete a cloned VM created for inspection. A VM whose disks back other linked clones is not deleted unless force is set, as destroying it would break those clones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Delete a cloned VM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01-clone-123\"",
+                        "description": "Clone VM name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Delete the VM even if linked clones depend on its disks",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Clone deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Clone not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Linked clones depend on the VM's disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect": {
+            "post": {
+                "description": "Run virt-inspector or virt-v2v-inspector on the current disks of a powered-off VM using VDDK, without a snapshot. Results are stored under a generated \"current-\u003ctimestamp\u003e\" snapshot name. With \"Accept: text/event-stream\" the response is a stream of \"progress\" events followed by a \"result\" or \"error\" event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Inspect a powered-off VM's current disks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name (or set moref or uuid)",
+                        "name": "vm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"vm-42\"",
+                        "description": "VM managed object reference, to target one of several VMs with the same name",
+                        "name": "moref",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f\"",
+                        "description": "VM BIOS UUID, to target one of several VMs with the same name",
+                        "name": "uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter containing the VM (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"mysql\"",
+                        "description": "Only return applications whose name contains this string (case-insensitive)",
+                        "name": "app_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Set to false to omit the application lists",
+                        "name": "include_apps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Hard disk 2\"",
+                        "description": "Only inspect the disk with this label",
+                        "name": "disk_label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Only inspect the disk at this zero-based index among the VM's disks",
+                        "name": "disk_index",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inspection completed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMInspectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "VM is powered on",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "VM is encrypted or no operating system found on the disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many concurrent inspections",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect-batch": {
+            "post": {
+                "description": "Inspect a list of VM snapshots in one call. Items run concurrently, up to inspection.batch_concurrency at a time, and snapshots with stored results are returned from the cache without running the inspector again. Each item reports its own result or error; the request fails only when the batch itself is invalid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Inspect multiple VM snapshots",
+                "parameters": [
+                    {
+                        "description": "Snapshots to inspect",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.BatchInspectionItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch inspection finished",
+                        "schema": {
+                            "$ref": "#/definitions/types.BatchInspectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect-live": {
+            "post": {
+                "description": "Inspect a VM without a pre-created snapshot. The \"snapshot\" strategy (default) inspects a temporary snapshot without memory, which is removed afterwards. The \"suspend\" strategy suspends a powered-on VM, inspects its current disks and powers it back on; the guest is unavailable for the duration of the inspection. The snapshot is removed, and a suspended VM powered back on, even when the inspection fails. Results are stored under a generated \"live-\u003ctimestamp\u003e-\u003crandom\u003e\" snapshot name. With \"Accept: text/event-stream\" the response is a stream of \"progress\" events followed by a \"result\" or \"error\" event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Inspect a running VM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"snapshot\"",
+                        "description": "Live inspection strategy: 'snapshot' (default) or 'suspend'",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"mysql\"",
+                        "description": "Only return applications whose name contains this string (case-insensitive)",
+                        "name": "app_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Set to false to omit the application lists",
+                        "name": "include_apps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Hard disk 2\"",
+                        "description": "Only inspect the disk with this label",
+                        "name": "disk_label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Only inspect the disk at this zero-based index among the VM's disks",
+                        "name": "disk_index",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inspection completed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMInspectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "VM is encrypted or no operating system found on the disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many concurrent inspections",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect-snapshot": {
+            "post": {
+                "description": "Run virt-inspector or virt-v2v-inspector on a VM snapshot using VDDK. With \"Accept: text/event-stream\" the response is a stream of \"progress\" events followed by a \"result\" or \"error\" event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Inspect a VM snapshot directly",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "Original VM name (or set moref or uuid)",
+                        "name": "vm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"vm-42\"",
+                        "description": "VM managed object reference, to target one of several VMs with the same name",
+                        "name": "moref",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f\"",
+                        "description": "VM BIOS UUID, to target one of several VMs with the same name",
+                        "name": "uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name",
+                        "name": "snapshot_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter containing the VM (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"mysql\"",
+                        "description": "Only return applications whose name contains this string (case-insensitive)",
+                        "name": "app_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Set to false to omit the application lists",
+                        "name": "include_apps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Hard disk 2\"",
+                        "description": "Only inspect the disk with this label",
+                        "name": "disk_label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Only inspect the disk at this zero-based index among the VM's disks",
+                        "name": "disk_index",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "description": "Run the inspector even when a stored result exists, replacing it",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inspection completed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMInspectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM or snapshot not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "VM is powered on, or several snapshots have the snapshot name",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "VM is encrypted or no operating system found on the disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many concurrent inspections",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect-snapshot/async": {
+            "post": {
+                "description": "Start virt-inspector or virt-v2v-inspector on a VM snapshot in the background and return a job ID to poll",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Start an asynchronous VM snapshot inspection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "Original VM name (or set moref or uuid)",
+                        "name": "vm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"vm-42\"",
+                        "description": "VM managed object reference, to target one of several VMs with the same name",
+                        "name": "moref",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f\"",
+                        "description": "VM BIOS UUID, to target one of several VMs with the same name",
+                        "name": "uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name",
+                        "name": "snapshot_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter containing the VM (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Inspection job accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.JobCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspect-snapshot/preflight": {
+            "post": {
+                "description": "Verify that an inspection of the snapshot can run, without inspecting it: the nbdkit and inspector binaries are installed, VDDK is present, vCenter is reachable with the configured credentials, the snapshot disks can be resolved and the vCenter certificate thumbprint can be read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Check inspection prerequisites for a VM snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "Original VM name (or set moref or uuid)",
+                        "name": "vm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"vm-42\"",
+                        "description": "VM managed object reference, to target one of several VMs with the same name",
+                        "name": "moref",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f\"",
+                        "description": "VM BIOS UUID, to target one of several VMs with the same name",
+                        "name": "uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name",
+                        "name": "snapshot_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter containing the VM (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checklist of prerequisites; ready is false when any check failed",
+                        "schema": {
+                            "$ref": "#/definitions/types.PreflightResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspection": {
+            "delete": {
+                "description": "Permanently delete the stored virt-inspector and virt-v2v-inspector results for a VM snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inspections"
+                ],
+                "summary": "Delete stored inspection results for a VM snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inspection records deleted",
+                        "schema": {
+                            "$ref": "#/definitions/types.InspectionDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspection/artifacts": {
+            "get": {
+                "description": "List the artifacts stored for each inspection run of a VM snapshot (job metadata and the inspection result or error), newest first. Pass run and file to download a single artifact",
+                "produces": [
+                    "application/json",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "inspections"
+                ],
+                "summary": "List or download inspection artifacts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"20240115T143000.123456789Z\"",
+                        "description": "Run to download a file from, as returned in the list",
+                        "name": "run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"error.json\"",
+                        "description": "File to download from the run",
+                        "name": "file",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stored inspection runs, or the requested file",
+                        "schema": {
+                            "$ref": "#/definitions/types.InspectionArtifactListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Artifact not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspection/export": {
+            "get": {
+                "description": "Download the stored inspection results for a VM snapshot as a JSON document or as a CSV list of installed applications",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "inspections"
+                ],
+                "summary": "Export stored inspection results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"csv\"",
+                        "description": "Export format: 'json' (default) or 'csv'",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported inspection results",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No stored inspection results",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/inspection/summary": {
+            "get": {
+                "description": "Get a compact summary of the stored inspection results for a VM snapshot: OS family, distro, application count and boot filesystem type",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inspections"
+                ],
+                "summary": "Get a summary of stored inspection results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "vm",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"inspection-snapshot\"",
+                        "description": "Snapshot name",
+                        "name": "snapshot",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"virt-inspector\"",
+                        "description": "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'",
+                        "name": "inspector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inspection summary",
+                        "schema": {
+                            "$ref": "#/definitions/types.InspectionSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No stored inspection results",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/needs-consolidation": {
+            "get": {
+                "description": "List the virtual machines whose disks need consolidation. Leftover delta disks from failed snapshot removals are a common cause of inspection failures.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "List virtual machines needing disk consolidation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter to scan (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Virtual machines needing consolidation",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Datacenter not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/search": {
+            "get": {
+                "description": "Find the virtual machines whose guest reports an IP address (primary or any NIC address). Requires VMware Tools in the guest. All matches are returned, since several VMs can report the same address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Find virtual machines by IP address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"192.168.1.100\"",
+                        "description": "IPv4 or IPv6 address",
+                        "name": "ip",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter to search (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching virtual machines",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMIPSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Datacenter not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/snapshot": {
+            "post": {
+                "description": "Create a snapshot for a specific virtual machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Create a VM snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Snapshot creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SnapshotCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Snapshot created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.SnapshotCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/summary": {
+            "get": {
+                "description": "Get a page of virtual machines with their CPU count, memory, guest OS, datacenter and cluster. The summaries are retrieved for all VMs at once, so this is a richer list view than /vms without the cost of fetching each VM's details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "List virtual machine summaries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web\"",
+                        "description": "Filter VMs where name contains this string",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Datacenter1\"",
+                        "description": "Datacenter to list VMs from (defaults to the default datacenter)",
+                        "name": "datacenter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"Cluster1\"",
+                        "description": "Only list VMs in this cluster",
+                        "name": "cluster",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 50,
+                        "description": "Maximum number of VMs to return (1-500, all when unset)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of VMs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VM summaries, sorted by name",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMSummaryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Datacenter or cluster not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}": {
+            "get": {
+                "description": "Get detailed information about a specific virtual machine by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Get virtual machine details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"ipv4\"",
+                        "description": "Only return guest IP addresses of this family (ipv4 or ipv6)",
+                        "name": "ip_family",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include link-local guest IP addresses (fe80::/10, 169.254.0.0/16)",
+                        "name": "include_link_local",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Detail sections to retrieve: basic, hardware, network, storage or all (repeatable or comma-separated, default all)",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Virtual machine details",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/annotation": {
+            "put": {
+                "description": "Replace the annotation (notes) of a virtual machine, e.g. to record inspection results or migration status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Update virtual machine annotation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.VMAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VM annotation updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMAnnotationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or annotation too long",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/cbt": {
+            "get": {
+                "description": "Get whether change block tracking (CBT), needed for incremental disk copies during migration, is enabled on a virtual machine, and whether a snapshot create/delete cycle is still needed to activate the setting on its disks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Get virtual machine change block tracking state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Change block tracking state",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMChangeTrackingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Enable or disable change block tracking (CBT) on a virtual machine. The setting takes effect at the next power-on of a powered-off VM; a powered-on VM needs a snapshot create/delete cycle, reported by requires_snapshot_cycle",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Enable or disable virtual machine change block tracking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "description": "Whether change block tracking should be enabled",
+                        "name": "enabled",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Change block tracking updated",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMChangeTrackingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/config": {
+            "patch": {
+                "description": "Change the number of CPUs, cores per socket and memory of a virtual machine. Running VMs can only grow CPU or memory when hot-add is enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Change virtual machine CPU and memory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01-clone\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reconfigure request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.VMReconfigureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VM reconfigured successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMReconfigureResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Change not allowed in the current power state",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/consolidate": {
+            "post": {
+                "description": "Consolidate the disks of a virtual machine that needs consolidation and wait for the task to complete",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Consolidate virtual machine disks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VM disks consolidated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMConsolidateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "VM disks do not need consolidation",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/disks": {
+            "get": {
+                "description": "Get the virtual disks of a specific virtual machine, with their controller bus (ide, scsi, sata or nvme), controller type (e.g. pvscsi, lsilogic-sas, ahci) and bus/unit numbers, without fetching the full VM details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "List virtual machine disks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of disks",
+                        "schema": {
+                            "$ref": "#/definitions/types.DiskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/snapshots": {
+            "get": {
+                "description": "Get the snapshots of a specific virtual machine without fetching the full VM details, as a flat list (depth-first) or as a tree of nested children",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "List virtual machine snapshots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "flat",
+                        "description": "Snapshot layout: flat or tree",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order by create time: asc or desc (flat lists default to tree order, tree siblings to asc)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of snapshots",
+                        "schema": {
+                            "$ref": "#/definitions/types.SnapshotListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vms/{name}/stats": {
+            "get": {
+                "description": "Get real-time CPU, memory, disk and network statistics for a virtual machine. Powered-off VMs return zero values with available set to false.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vms"
+                ],
+                "summary": "Get virtual machine performance statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"web-server-01\"",
+                        "description": "VM name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Virtual machine statistics",
+                        "schema": {
+                            "$ref": "#/definitions/types.VMStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "VM not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "vSphere connection unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vsphere/reconnect": {
+            "post": {
+                "description": "Drop the vCenter session and log in again, e.g. after the session was terminated in vCenter or went stale. The login uses the credentials loaded at startup. Running requests that use the old session may fail.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vsphere"
+                ],
+                "summary": "Force a vCenter reconnect",
+                "responses": {
+                    "200": {
+                        "description": "Reconnected to vCenter",
+                        "schema": {
+                            "$ref": "#/definitions/types.VSphereReconnectResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Reconnect failed",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vsphere/status": {
+            "get": {
+                "description": "Get diagnostics about the vCenter session: when it was established, how often it was re-established and the outcome of the last health check. No request is sent to vCenter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vsphere"
+                ],
+                "summary": "Get the vCenter session status",
+                "responses": {
+                    "200": {
+                        "description": "vCenter session status",
+                        "schema": {
+                            "$ref": "#/definitions/types.VSphereStatusResponse"
+                        }
+                    }
                 }
             }
         }
     },
     "definitions": {
-        "types.Application": {
+        "types.ApplicationMatch": {
             "type": "object",
             "properties": {
                 "arch": {
                     "type": "string",
-                    "example": "amd64"
+                    "example": "x86_64"
                 },
-                "description": {
+                "inspected_at": {
                     "type": "string",
-                    "example": "nginx is a web server with a strong focus on high concurrency"
-                },
-                "epoch": {
-                    "type": "integer"
+                    "example": "2024-01-15T14:45:00Z"
                 },
                 "name": {
                     "type": "string",
-                    "example": "nginx"
+                    "example": "openssl"
                 },
-                "release": {
-                    "type": "string"
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
                 },
-                "summary": {
+                "version": {
                     "type": "string",
-                    "example": "High performance web server"
+                    "example": "1.0.2k"
                 },
-                "url": {
+                "vm_name": {
                     "type": "string",
-                    "example": "https://nginx.org"
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.ApplicationSearchResponse": {
+            "type": "object",
+            "properties": {
+                "application": {
+                    "type": "string",
+                    "example": "openssl"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ApplicationMatch"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
                 },
                 "version": {
                     "type": "string",
-                    "example": "1.18.0"
+                    "example": "1.0"
+                }
+            }
+        },
+        "types.BatchInspectionItem": {
+            "type": "object",
+            "properties": {
+                "datacenter": {
+                    "type": "string",
+                    "example": "Datacenter1"
+                },
+                "inspector": {
+                    "type": "string",
+                    "example": "virt-inspector"
+                },
+                "snapshot": {
+                    "type": "string",
+                    "example": "inspection-snapshot"
+                },
+                "snapshot_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "vm": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.BatchInspectionResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BatchInspectionResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "types.BatchInspectionResult": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "error": {
+                    "$ref": "#/definitions/types.ErrorResponse"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "inspector_type": {
+                    "type": "string",
+                    "example": "virt-inspector"
+                },
+                "result": {
+                    "$ref": "#/definitions/types.VMInspectionResponse"
+                },
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "inspection-snapshot"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.CheckRequest": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fstab",
+                        "disk-access"
+                    ]
+                }
+            }
+        },
+        "types.CheckResponse": {
+            "type": "object",
+            "properties": {
+                "all_valid": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.CheckResult"
+                    }
+                },
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.CheckResult": {
+            "type": "object",
+            "properties": {
+                "check_type": {
+                    "type": "string",
+                    "example": "fstab"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Failed to run inspection: connection timeout"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Fstab is migrateable - no /dev/disk/by-path/ entries found"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "types.CloneCleanupFailure": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "VM deletion failed: permission denied"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01-inspect-clone-1705329000"
+                }
+            }
+        },
+        "types.CloneCleanupResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.CloneCleanupFailure"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Deleted 3 stale clone(s)"
+                },
+                "older_than": {
+                    "type": "string",
+                    "example": "1h0m0s"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "web-server-01-*"
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
                 }
             }
         },
         "types.CloneRequest": {
             "type": "object",
-            "required": [
-                "snapshot_name"
-            ],
             "properties": {
                 "clone_name": {
                     "type": "string",
                     "example": "my-clone"
                 },
+                "snapshot_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                },
                 "snapshot_name": {
                     "type": "string",
                     "example": "backup-snapshot"
+                },
+                "target_datastore": {
+                    "type": "string",
+                    "example": "scratch-datastore"
+                },
+                "target_folder": {
+                    "type": "string",
+                    "example": "inspection-clones"
                 }
             }
         },
         "types.CloneResponse": {
             "type": "object",
             "properties": {
+                "clone_moref": {
+                    "type": "string",
+                    "example": "vm-1234"
+                },
                 "clone_name": {
                     "type": "string",
                     "example": "vm-clone-123"
                 },
+                "clone_uuid": {
+                    "type": "string",
+                    "example": "4201a2b3-c4d5-e6f7-8901-234567890abc"
+                },
                 "message": {
                     "type": "string",
                     "example": "Clone created successfully"
@@ -481,19 +2827,68 @@ const docTemplate = `{
                 }
             }
         },
-        "types.Drive": {
+        "types.ClusterListResponse": {
             "type": "object",
             "properties": {
-                "name": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Cluster1",
+                        "Cluster2"
+                    ]
+                },
+                "datacenter": {
                     "type": "string",
-                    "example": "/dev/sda"
+                    "example": "Datacenter1"
                 },
-                "size": {
-                    "type": "integer"
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "types.DatacenterListResponse": {
+            "type": "object",
+            "properties": {
+                "datacenters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Datacenter1",
+                        "Datacenter2"
+                    ]
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "types.DiskListResponse": {
+            "type": "object",
+            "properties": {
+                "disks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMDisk"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "vm_id": {
+                    "type": "string",
+                    "example": "vm-123"
                 },
-                "type": {
+                "vm_name": {
                     "type": "string",
-                    "example": "disk"
+                    "example": "web-server-01"
                 }
             }
         },
@@ -514,116 +2909,396 @@ const docTemplate = `{
                 }
             }
         },
-        "types.Filesystem": {
+        "types.InspectDiskRequest": {
             "type": "object",
+            "required": [
+                "base_disk_path",
+                "compute_resource_path",
+                "snapshot_moref",
+                "vm_moref"
+            ],
             "properties": {
-                "device": {
+                "base_disk_path": {
                     "type": "string",
-                    "example": "/dev/sda1"
+                    "example": "[datastore1] web-server-01/web-server-01.vmdk"
                 },
-                "size": {
-                    "type": "integer"
+                "compute_resource_path": {
+                    "type": "string",
+                    "example": "/Datacenter1/host/Cluster1/esxi-01.example.com"
+                },
+                "datacenter": {
+                    "type": "string",
+                    "example": "Datacenter1"
+                },
+                "disk_path": {
+                    "type": "string",
+                    "example": "[datastore1] web-server-01/web-server-01-000001.vmdk"
                 },
-                "type": {
+                "inspector": {
                     "type": "string",
-                    "example": "ext4"
+                    "example": "virt-inspector"
                 },
-                "used": {
-                    "type": "integer"
+                "snapshot_moref": {
+                    "type": "string",
+                    "example": "snapshot-5678"
                 },
-                "uuid": {
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "vm_moref": {
+                    "type": "string",
+                    "example": "vm-1234"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.InspectionArtifactFile": {
+            "type": "object",
+            "properties": {
+                "name": {
                     "type": "string",
-                    "example": "ac818163-71e7-4b23-a1b7-e94196b9dada"
+                    "example": "result.json"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 48213
                 }
             }
         },
-        "types.InspectionData": {
+        "types.InspectionArtifactListResponse": {
             "type": "object",
             "properties": {
-                "applications": {
+                "runs": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Application"
+                        "$ref": "#/definitions/types.InspectionArtifactRun"
                     }
                 },
-                "drives": {
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.InspectionArtifactRun": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:00Z"
+                },
+                "files": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Drive"
+                        "$ref": "#/definitions/types.InspectionArtifactFile"
                     }
                 },
-                "filesystems": {
+                "run": {
+                    "type": "string",
+                    "example": "20240115T143000.123456789Z"
+                }
+            }
+        },
+        "types.InspectionDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Inspection records deleted successfully"
+                }
+            }
+        },
+        "types.InspectionOperatingSystem": {
+            "type": "object",
+            "properties": {
+                "distro": {
+                    "type": "string",
+                    "example": "windows"
+                },
+                "os_family": {
+                    "type": "string",
+                    "example": "windows"
+                },
+                "os_name": {
+                    "type": "string",
+                    "example": "windows"
+                },
+                "package_format": {
+                    "type": "string",
+                    "example": "rpm"
+                },
+                "product_name": {
+                    "type": "string",
+                    "example": "Windows 10 Pro"
+                },
+                "root": {
+                    "type": "string",
+                    "example": "/dev/sda1"
+                }
+            }
+        },
+        "types.InspectionSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "additional_operating_systems": {
+                    "description": "AdditionalOperatingSystems lists the operating systems found after the one\nsummarized above, e.g. on multi-boot disks",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Filesystem"
+                        "$ref": "#/definitions/types.InspectionOperatingSystem"
                     }
                 },
-                "mountpoints": {
+                "application_count": {
+                    "type": "integer",
+                    "example": 512
+                },
+                "boot_filesystem_type": {
+                    "type": "string",
+                    "example": "xfs"
+                },
+                "distro": {
+                    "type": "string",
+                    "example": "rhel"
+                },
+                "inspector_type": {
+                    "type": "string",
+                    "example": "virt-inspector"
+                },
+                "os_family": {
+                    "type": "string",
+                    "example": "linux"
+                },
+                "os_name": {
+                    "type": "string",
+                    "example": "linux"
+                },
+                "package_format": {
+                    "type": "string",
+                    "example": "rpm"
+                },
+                "product_name": {
+                    "type": "string",
+                    "example": "Red Hat Enterprise Linux 9.2 (Plow)"
+                },
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.JobCreateResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string",
+                    "example": "0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Inspection job accepted"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "types.JobHistoryEntry": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:45:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:00Z"
+                },
+                "datacenter": {
+                    "type": "string",
+                    "example": "Datacenter1"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "INSPECTION_FAILED"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "inspection failed after 3 attempt(s): connection reset by peer"
+                },
+                "inspector_type": {
+                    "type": "string",
+                    "example": "virt-inspector"
+                },
+                "job_id": {
+                    "type": "string",
+                    "example": "0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "4f6c2d1e-8a9b-4c3d-9e2f-1a2b3c4d5e6f"
+                },
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:01Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "failed"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                }
+            }
+        },
+        "types.JobListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Mountpoint"
+                        "$ref": "#/definitions/types.JobHistoryEntry"
                     }
                 },
-                "operating_system": {
-                    "$ref": "#/definitions/types.OSInfo"
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "types.JobStatusResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:45:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:00Z"
+                },
+                "error": {
+                    "$ref": "#/definitions/types.ErrorResponse"
+                },
+                "inspector_type": {
+                    "type": "string",
+                    "example": "virt-inspector"
+                },
+                "job_id": {
+                    "type": "string",
+                    "example": "0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"
+                },
+                "result": {
+                    "$ref": "#/definitions/types.VMInspectionResponse"
+                },
+                "snapshot_name": {
+                    "type": "string",
+                    "example": "backup-snapshot"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:01Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
                 }
             }
         },
-        "types.Mountpoint": {
+        "types.MigrationPackage": {
             "type": "object",
             "properties": {
-                "device": {
+                "category": {
                     "type": "string",
-                    "example": "/dev/sda1"
+                    "example": "vmware-tools"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "open-vm-tools"
                 },
-                "mount_point": {
+                "version": {
                     "type": "string",
-                    "example": "/boot"
+                    "example": "12.1.5"
                 }
             }
         },
-        "types.OSInfo": {
+        "types.PreflightCheck": {
             "type": "object",
             "properties": {
-                "architecture": {
-                    "type": "string",
-                    "example": "x86_64"
-                },
-                "distro": {
-                    "type": "string",
-                    "example": "ubuntu"
-                },
-                "hostname": {
+                "message": {
                     "type": "string",
-                    "example": "web-server-01"
+                    "example": "Found at /usr/bin/nbdkit"
                 },
                 "name": {
                     "type": "string",
-                    "example": "linux"
-                },
-                "osinfo": {
-                    "type": "string",
-                    "example": "centos9"
+                    "example": "nbdkit"
                 },
-                "package_format": {
-                    "type": "string",
-                    "example": "rpm"
+                "passed": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "types.PreflightResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PreflightCheck"
+                    }
                 },
-                "package_management": {
+                "inspector_type": {
                     "type": "string",
-                    "example": "dnf"
+                    "example": "virt-inspector"
                 },
-                "product": {
-                    "type": "string",
-                    "example": "Ubuntu 22.04 LTS"
+                "ready": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "root": {
+                "snapshot_name": {
                     "type": "string",
-                    "example": "/dev/sda1"
+                    "example": "backup-snapshot"
                 },
-                "version": {
+                "vm_name": {
                     "type": "string",
-                    "example": "22.04"
+                    "example": "web-server-01"
                 }
             }
         },
@@ -678,6 +3353,54 @@ const docTemplate = `{
                     "type": "string",
                     "example": "vm-456"
                 },
+                "vm_name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Quiesce was requested but the snapshot is not quiesced; it is only crash-consistent"
+                    ]
+                }
+            }
+        },
+        "types.SnapshotListResponse": {
+            "type": "object",
+            "properties": {
+                "current_snapshot": {
+                    "type": "string",
+                    "example": "snapshot-1"
+                },
+                "current_snapshot_description": {
+                    "type": "string",
+                    "example": "Snapshot before OS upgrade"
+                },
+                "current_snapshot_name": {
+                    "type": "string",
+                    "example": "before-upgrade"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "flat"
+                },
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMSnapshot"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "vm_id": {
+                    "type": "string",
+                    "example": "vm-123"
+                },
                 "vm_name": {
                     "type": "string",
                     "example": "web-server-01"
@@ -687,6 +3410,18 @@ const docTemplate = `{
         "types.VM": {
             "type": "object",
             "properties": {
+                "connection_state": {
+                    "type": "string",
+                    "example": "connected"
+                },
+                "degraded": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "moref": {
+                    "type": "string",
+                    "example": "vm-42"
+                },
                 "name": {
                     "type": "string",
                     "example": "web-server-01"
@@ -722,6 +3457,98 @@ const docTemplate = `{
                 }
             }
         },
+        "types.VMAnnotationRequest": {
+            "type": "object",
+            "required": [
+                "annotation"
+            ],
+            "properties": {
+                "annotation": {
+                    "type": "string",
+                    "example": "migration: inspected 2024-01-15, ready"
+                }
+            }
+        },
+        "types.VMAnnotationResponse": {
+            "type": "object",
+            "properties": {
+                "annotation": {
+                    "type": "string",
+                    "example": "migration: inspected 2024-01-15, ready"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "VM annotation updated successfully"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                }
+            }
+        },
+        "types.VMCPUStats": {
+            "type": "object",
+            "properties": {
+                "ready_time_ms": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "usage_mhz": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "usage_percent": {
+                    "type": "number",
+                    "example": 45.2
+                }
+            }
+        },
+        "types.VMChangeTrackingResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Change block tracking enabled; create and delete a snapshot to activate it on the running VM"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "power_state": {
+                    "type": "string",
+                    "example": "poweredOn"
+                },
+                "requires_snapshot_cycle": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "types.VMConsolidateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "VM disks consolidated successfully"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                }
+            }
+        },
         "types.VMDetailsResponse": {
             "type": "object",
             "properties": {
@@ -732,12 +3559,32 @@ const docTemplate = `{
                     "type": "string",
                     "example": "snapshot-1"
                 },
+                "current_snapshot_description": {
+                    "type": "string",
+                    "example": "Snapshot before OS upgrade"
+                },
+                "current_snapshot_name": {
+                    "type": "string",
+                    "example": "before-upgrade"
+                },
                 "disks": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/types.VMDisk"
                     }
                 },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "basic",
+                        "hardware",
+                        "network",
+                        "storage"
+                    ]
+                },
                 "files": {
                     "$ref": "#/definitions/types.VMFileInfo"
                 },
@@ -785,6 +3632,10 @@ const docTemplate = `{
         "types.VMDisk": {
             "type": "object",
             "properties": {
+                "bus_number": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "capacity_gb": {
                     "type": "integer",
                     "example": 50
@@ -793,6 +3644,14 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 52428800
                 },
+                "controller_bus": {
+                    "type": "string",
+                    "example": "scsi"
+                },
+                "controller_type": {
+                    "type": "string",
+                    "example": "pvscsi"
+                },
                 "datastore": {
                     "type": "string",
                     "example": "datastore1"
@@ -812,6 +3671,35 @@ const docTemplate = `{
                 "thin_provisioned": {
                     "type": "boolean",
                     "example": true
+                },
+                "unit_number": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.VMDiskStats": {
+            "type": "object",
+            "properties": {
+                "latency_ms": {
+                    "type": "number",
+                    "example": 2.1
+                },
+                "read_iops": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "read_mbps": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "write_iops": {
+                    "type": "integer",
+                    "example": 75
+                },
+                "write_mbps": {
+                    "type": "number",
+                    "example": 8.3
                 }
             }
         },
@@ -846,6 +3734,12 @@ const docTemplate = `{
         "types.VMGuestInfo": {
             "type": "object",
             "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMIPAddress"
+                    }
+                },
                 "guest_heartbeat_status": {
                     "type": "string",
                     "example": "green"
@@ -874,45 +3768,125 @@ const docTemplate = `{
                 }
             }
         },
-        "types.VMHardwareInfo": {
+        "types.VMHardwareInfo": {
+            "type": "object",
+            "properties": {
+                "firmware_type": {
+                    "type": "string",
+                    "example": "bios"
+                },
+                "guest_full_name": {
+                    "type": "string",
+                    "example": "Ubuntu Linux (64-bit)"
+                },
+                "memory_mb": {
+                    "type": "integer",
+                    "example": 4096
+                },
+                "num_cores_per_socket": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "num_cpu": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "version": {
+                    "type": "string",
+                    "example": "vmx-19"
+                }
+            }
+        },
+        "types.VMIPAddress": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "192.168.1.100"
+                },
+                "cidr": {
+                    "type": "string",
+                    "example": "192.168.1.100/24"
+                },
+                "family": {
+                    "type": "string",
+                    "example": "ipv4"
+                },
+                "link_local": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "prefix_length": {
+                    "type": "integer",
+                    "example": 24
+                }
+            }
+        },
+        "types.VMIPSearchMatch": {
+            "type": "object",
+            "properties": {
+                "matched_ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMIPAddress"
+                    }
+                },
+                "vm": {
+                    "$ref": "#/definitions/types.VM"
+                }
+            }
+        },
+        "types.VMIPSearchResponse": {
             "type": "object",
             "properties": {
-                "firmware_type": {
+                "datacenter": {
                     "type": "string",
-                    "example": "bios"
+                    "example": "Datacenter1"
                 },
-                "guest_full_name": {
+                "ip": {
                     "type": "string",
-                    "example": "Ubuntu Linux (64-bit)"
+                    "example": "192.168.1.100"
                 },
-                "memory_mb": {
-                    "type": "integer",
-                    "example": 4096
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMIPSearchMatch"
+                    }
                 },
-                "num_cores_per_socket": {
+                "total": {
                     "type": "integer",
                     "example": 1
-                },
-                "num_cpu": {
-                    "type": "integer",
-                    "example": 2
-                },
-                "version": {
-                    "type": "string",
-                    "example": "vmx-19"
                 }
             }
         },
         "types.VMInspectionResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/types.InspectionData"
+                "cached": {
+                    "description": "Cached is true when the result was loaded from the stored results instead of running the inspector",
+                    "type": "boolean",
+                    "example": false
+                },
+                "inspected_at": {
+                    "description": "InspectedAt is when the inspector produced the result",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "inspector_type": {
+                    "type": "string",
+                    "example": "virt-inspector"
                 },
                 "message": {
                     "type": "string",
                     "example": "Inspection completed successfully"
                 },
+                "migration_relevant_packages": {
+                    "description": "MigrationRelevantPackages lists installed packages and drivers that matter for migration",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.MigrationPackage"
+                    }
+                },
                 "snapshot_name": {
                     "type": "string",
                     "example": "backup-snapshot"
@@ -921,6 +3895,8 @@ const docTemplate = `{
                     "type": "string",
                     "example": "completed"
                 },
+                "virt_inspector": {},
+                "virt_v2v": {},
                 "vm_name": {
                     "type": "string",
                     "example": "web-server-01"
@@ -959,6 +3935,31 @@ const docTemplate = `{
                 }
             }
         },
+        "types.VMMemoryStats": {
+            "type": "object",
+            "properties": {
+                "active_mb": {
+                    "type": "integer",
+                    "example": 2456
+                },
+                "ballooned_mb": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "swapped_mb": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "usage_mb": {
+                    "type": "integer",
+                    "example": 2780
+                },
+                "usage_percent": {
+                    "type": "number",
+                    "example": 67.8
+                }
+            }
+        },
         "types.VMMetadata": {
             "type": "object",
             "properties": {
@@ -987,6 +3988,12 @@ const docTemplate = `{
                     "type": "string",
                     "example": "VMXNET3"
                 },
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMIPAddress"
+                    }
+                },
                 "connected": {
                     "type": "boolean",
                     "example": true
@@ -1011,6 +4018,92 @@ const docTemplate = `{
                 "network_name": {
                     "type": "string",
                     "example": "VM Network"
+                },
+                "portgroup_key": {
+                    "type": "string",
+                    "example": "dvportgroup-1021"
+                },
+                "switch_uuid": {
+                    "type": "string",
+                    "example": "50 2e 7c 6e b5 c3 4d 0e-9a 5a 8b 9c 1d 2e 3f 40"
+                },
+                "tools_available": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "types.VMNetworkStats": {
+            "type": "object",
+            "properties": {
+                "receive_mbps": {
+                    "type": "number",
+                    "example": 5.2
+                },
+                "receive_pps": {
+                    "type": "integer",
+                    "example": 450
+                },
+                "transmit_mbps": {
+                    "type": "number",
+                    "example": 3.1
+                },
+                "transmit_pps": {
+                    "type": "integer",
+                    "example": 280
+                }
+            }
+        },
+        "types.VMReconfigureRequest": {
+            "type": "object",
+            "properties": {
+                "memory_mb": {
+                    "type": "integer",
+                    "minimum": 4,
+                    "example": 8192
+                },
+                "num_cores_per_socket": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                },
+                "num_cpu": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4
+                }
+            }
+        },
+        "types.VMReconfigureResponse": {
+            "type": "object",
+            "properties": {
+                "memory_mb": {
+                    "type": "integer",
+                    "example": 8192
+                },
+                "message": {
+                    "type": "string",
+                    "example": "VM reconfigured successfully"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01-clone"
+                },
+                "num_cores_per_socket": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "num_cpu": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "power_state": {
+                    "type": "string",
+                    "example": "poweredOff"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
                 }
             }
         },
@@ -1091,10 +4184,21 @@ const docTemplate = `{
         "types.VMSnapshot": {
             "type": "object",
             "properties": {
+                "children": {
+                    "description": "Children is only set when snapshots are listed as a tree",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMSnapshot"
+                    }
+                },
                 "create_time": {
                     "type": "string",
                     "example": "2024-01-15T14:30:00Z"
                 },
+                "depth": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "description": {
                     "type": "string",
                     "example": "Before upgrade"
@@ -1103,10 +4207,18 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "is_current": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "name": {
                     "type": "string",
                     "example": "snapshot-1"
                 },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "quiesced": {
                     "type": "boolean",
                     "example": true
@@ -1117,6 +4229,51 @@ const docTemplate = `{
                 }
             }
         },
+        "types.VMStatsResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "cpu_usage": {
+                    "$ref": "#/definitions/types.VMCPUStats"
+                },
+                "disk_usage": {
+                    "$ref": "#/definitions/types.VMDiskStats"
+                },
+                "memory_usage": {
+                    "$ref": "#/definitions/types.VMMemoryStats"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "network_usage": {
+                    "$ref": "#/definitions/types.VMNetworkStats"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "real-time statistics are only available for powered-on VMs"
+                },
+                "power_state": {
+                    "type": "string",
+                    "example": "poweredOn"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:00Z"
+                },
+                "uptime_seconds": {
+                    "type": "integer",
+                    "example": 86400
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "502e7c6e-b5c3-4d0e-9a5a-8b9c1d2e3f4g"
+                }
+            }
+        },
         "types.VMStorageSummary": {
             "type": "object",
             "properties": {
@@ -1138,6 +4295,11 @@ const docTemplate = `{
                         "datastore2"
                     ]
                 },
+                "storage_source": {
+                    "description": "StorageSource is 'summary' (vSphere storage summary), 'layout' (computed from the VM\nfiles when the summary is unavailable) or 'unavailable'",
+                    "type": "string",
+                    "example": "summary"
+                },
                 "uncommitted_bytes": {
                     "type": "integer",
                     "example": 47244640256
@@ -1148,6 +4310,70 @@ const docTemplate = `{
                 }
             }
         },
+        "types.VMSummary": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "type": "string",
+                    "example": "Cluster1"
+                },
+                "cpu_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "datacenter": {
+                    "type": "string",
+                    "example": "Datacenter1"
+                },
+                "guest_os": {
+                    "type": "string",
+                    "example": "Ubuntu Linux (64-bit)"
+                },
+                "memory_mb": {
+                    "type": "integer",
+                    "example": 4096
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web-server-01"
+                },
+                "power_state": {
+                    "type": "string",
+                    "example": "poweredOn"
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "502e7c6e-b5c3-4d0e-9a5a-8b9c1d2e3f4g"
+                }
+            }
+        },
+        "types.VMSummaryListResponse": {
+            "type": "object",
+            "properties": {
+                "datacenter": {
+                    "type": "string",
+                    "example": "Datacenter1"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "vms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VMSummary"
+                    }
+                }
+            }
+        },
         "types.VMToolsInfo": {
             "type": "object",
             "properties": {
@@ -1168,6 +4394,68 @@ const docTemplate = `{
                     "example": "guestToolsCurrent"
                 }
             }
+        },
+        "types.VSphereReconnectResponse": {
+            "type": "object",
+            "properties": {
+                "connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "login_time": {
+                    "type": "string",
+                    "example": "2024-01-15T16:50:00Z"
+                },
+                "reconnect_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "session_key": {
+                    "type": "string",
+                    "example": "52b2ffd5-6f2d-ec3d-bb6b-9d3e4b1fd2a4"
+                },
+                "user_name": {
+                    "type": "string",
+                    "example": "VSPHERE.LOCAL\\inspector"
+                },
+                "vcenter_url": {
+                    "type": "string",
+                    "example": "https://vcenter.example.com/sdk"
+                }
+            }
+        },
+        "types.VSphereStatusResponse": {
+            "type": "object",
+            "properties": {
+                "connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "connected_for": {
+                    "type": "string",
+                    "example": "2h15m0s"
+                },
+                "last_connect_time": {
+                    "type": "string",
+                    "example": "2024-01-15T14:30:00Z"
+                },
+                "last_health_check": {
+                    "type": "string",
+                    "example": "2024-01-15T16:45:00Z"
+                },
+                "last_health_check_error": {
+                    "type": "string",
+                    "example": "connection not available: connection refused"
+                },
+                "reconnect_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "vcenter_url": {
+                    "type": "string",
+                    "example": "https://vcenter.example.com/sdk"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// applicationFilter trims the application lists of returned inspection results, which can
+// hold thousands of entries; stored results are never modified
+type applicationFilter struct {
+	nameContains string // lower-cased name substring; empty keeps every application
+	omit         bool   // drop the application lists entirely
+}
+
+// parseApplicationFilter reads the app_filter and include_apps query parameters
+// It writes a 400 response and returns false when a parameter is invalid
+func parseApplicationFilter(c *gin.Context) (applicationFilter, bool) {
+	filter := applicationFilter{nameContains: strings.ToLower(c.Query("app_filter"))}
+
+	if value := c.Query("include_apps"); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid include_apps value",
+				Code:    "INVALID_INCLUDE_APPS",
+				Details: fmt.Sprintf("include_apps must be 'true' or 'false', got: %s", value),
+			})
+			return applicationFilter{}, false
+		}
+		filter.omit = !include
+	}
+
+	return filter, true
+}
+
+// apply returns response with its application lists filtered
+// The response is copied when filtering applies, so shared results are left untouched
+func (f applicationFilter) apply(response *types.VMInspectionResponse) *types.VMInspectionResponse {
+	if response == nil || (f.nameContains == "" && !f.omit) {
+		return response
+	}
+
+	filtered := *response
+	for _, field := range []*interface{}{&filtered.VirtInspector, &filtered.VirtV2V} {
+		if *field == nil {
+			continue
+		}
+		data, err := decodeInspection(*field)
+		if err != nil {
+			continue
+		}
+		*field = f.filterData(data)
+	}
+	return &filtered
+}
+
+// filterData filters every "applications" list in decoded inspection data, matching keys
+// case-insensitively like extractApplications
+func (f applicationFilter) filterData(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if !strings.EqualFold(key, "applications") {
+				v[key] = f.filterData(value)
+				continue
+			}
+			if f.omit {
+				delete(v, key)
+				continue
+			}
+			v[key] = f.filterApplications(value)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = f.filterData(item)
+		}
+	}
+	return data
+}
+
+// filterApplications keeps the applications whose name contains the filter
+// The value is either a list of applications or an object wrapping that list
+func (f applicationFilter) filterApplications(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		kept := []interface{}{}
+		for _, item := range v {
+			app, ok := item.(map[string]interface{})
+			if ok && strings.Contains(strings.ToLower(lookupString(app, "name")), f.nameContains) {
+				kept = append(kept, item)
+			}
+		}
+		return kept
+	case map[string]interface{}:
+		for key, inner := range v {
+			v[key] = f.filterApplications(inner)
+		}
+	}
+	return value
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/checks"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// InspectionData is the input passed to validation checks
+type InspectionData struct {
+	// Params gives checks that read the snapshot disks directly everything they need
+	Params checks.InspectionParams
+
+	// loadInspection runs (or loads the cached) virt-inspector inspection of the snapshot
+	loadInspection func(ctx context.Context) (interface{}, error)
+	inspection     interface{}
+	inspectionErr  error
+	inspected      bool
+}
+
+// Inspection returns the decoded virt-inspector result for the snapshot
+// The inspection runs at most once per request and is shared between checks
+func (d *InspectionData) Inspection(ctx context.Context) (interface{}, error) {
+	if !d.inspected {
+		d.inspected = true
+		if d.loadInspection == nil {
+			d.inspectionErr = fmt.Errorf("inspection data is not available")
+		} else {
+			d.inspection, d.inspectionErr = d.loadInspection(ctx)
+		}
+	}
+	return d.inspection, d.inspectionErr
+}
+
+// decodeInspection converts an inspector result into generic JSON data, matching the stored format
+func decodeInspection(result interface{}) (interface{}, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode inspection result: %w", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode inspection result: %w", err)
+	}
+	return data, nil
+}
+
+// Check is a validation check run against a VM snapshot
+type Check interface {
+	Name() string
+	Run(ctx context.Context, data *InspectionData) types.CheckResult
+}
+
+// checkRegistry maps each check type to its implementation
+// Adding a new check only requires registering it here
+var checkRegistry = map[string]Check{
+	"fstab":        newDetectiveCheck("fstab", checks.NewFstabCheck()),
+	"disk-access":  newDetectiveCheck("disk-access", checks.NewDiskAccessCheck()),
+	"vmware-tools": vmwareToolsCheck{},
+}
+
+// SupportedCheckTypes returns the registered check types in sorted order
+func SupportedCheckTypes() []string {
+	supported := make([]string, 0, len(checkRegistry))
+	for name := range checkRegistry {
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// detectiveCheck adapts a vm-migration-detective check, which reads the snapshot disks itself
+type detectiveCheck struct {
+	name  string
+	check checks.Check
+}
+
+// newDetectiveCheck wraps a vm-migration-detective check under the given check type
+func newDetectiveCheck(name string, check checks.Check) detectiveCheck {
+	return detectiveCheck{name: name, check: check}
+}
+
+// Name returns the check type
+func (c detectiveCheck) Name() string {
+	return c.name
+}
+
+// Run runs the wrapped check with the request's disk access parameters
+func (c detectiveCheck) Run(ctx context.Context, data *InspectionData) types.CheckResult {
+	params := data.Params
+	params.Ctx = ctx
+
+	result := c.check.Run(params)
+	return types.CheckResult{
+		CheckType: c.name,
+		Valid:     result.Valid,
+		Message:   result.Message,
+		Error:     result.Error,
+	}
+}
+
+// vmwareToolsPackages are application names that indicate VMware Tools is installed
+var vmwareToolsPackages = []string{
+	"open-vm-tools",
+	"vmware-tools",
+	"vmware tools",
+}
+
+// vmwareToolsCheck reports whether VMware Tools is installed in the guest
+// VMware Tools must be removed from a migrated guest, so the check fails when it is found
+type vmwareToolsCheck struct{}
+
+// Name returns the check type
+func (vmwareToolsCheck) Name() string {
+	return "vmware-tools"
+}
+
+// Run looks for VMware Tools in the inspected applications
+func (c vmwareToolsCheck) Run(ctx context.Context, data *InspectionData) types.CheckResult {
+	inspection, err := data.Inspection(ctx)
+	if err != nil {
+		message := fmt.Sprintf("Failed to run inspection: %v", err)
+		return types.CheckResult{
+			CheckType: c.Name(),
+			Valid:     false,
+			Message:   "Could not determine whether VMware Tools is installed",
+			Error:     &message,
+		}
+	}
+
+	var found []string
+	for _, app := range extractApplications(inspection) {
+		name := strings.ToLower(app[0])
+		for _, pkg := range vmwareToolsPackages {
+			if strings.HasPrefix(name, pkg) {
+				found = append(found, strings.TrimSpace(app[0]+" "+app[1]))
+				break
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return types.CheckResult{
+			CheckType: c.Name(),
+			Valid:     true,
+			Message:   "VMware Tools is not installed",
+		}
+	}
+	return types.CheckResult{
+		CheckType: c.Name(),
+		Valid:     false,
+		Message:   fmt.Sprintf("VMware Tools is installed (%s) and should be removed after migration", strings.Join(found, ", ")),
+	}
+}
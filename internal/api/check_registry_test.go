@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// inspectionWithApps returns decoded inspection data holding the given application names
+func inspectionWithApps(names ...string) map[string]interface{} {
+	apps := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		apps = append(apps, map[string]interface{}{"name": name, "version": "1.0", "arch": "x86_64"})
+	}
+	return map[string]interface{}{
+		"operatingsystems": []interface{}{
+			map[string]interface{}{"name": "linux", "applications": apps},
+		},
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	want := []string{"disk-access", "fstab", "vmware-tools"}
+	if got := SupportedCheckTypes(); !slices.Equal(got, want) {
+		t.Errorf("SupportedCheckTypes() = %v, want %v", got, want)
+	}
+	for name, check := range checkRegistry {
+		if check.Name() != name {
+			t.Errorf("checkRegistry[%q].Name() = %q, want %q", name, check.Name(), name)
+		}
+	}
+}
+
+func TestVMwareToolsCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		inspection  interface{}
+		err         error
+		wantValid   bool
+		wantError   bool
+		wantMessage string
+	}{
+		{
+			name:        "no applications",
+			inspection:  inspectionWithApps(),
+			wantValid:   true,
+			wantMessage: "VMware Tools is not installed",
+		},
+		{
+			name:        "unrelated applications",
+			inspection:  inspectionWithApps("bash", "openssh-server", "vmware-unrelated"),
+			wantValid:   true,
+			wantMessage: "VMware Tools is not installed",
+		},
+		{
+			name:        "open-vm-tools",
+			inspection:  inspectionWithApps("bash", "open-vm-tools"),
+			wantValid:   false,
+			wantMessage: "open-vm-tools 1.0",
+		},
+		{
+			name:        "legacy vmware-tools package",
+			inspection:  inspectionWithApps("vmware-tools-core"),
+			wantValid:   false,
+			wantMessage: "vmware-tools-core 1.0",
+		},
+		{
+			name:        "Windows VMware Tools",
+			inspection:  inspectionWithApps("VMware Tools"),
+			wantValid:   false,
+			wantMessage: "VMware Tools 1.0",
+		},
+		{
+			name:        "inspection failure",
+			err:         errors.New("virt-inspector failed"),
+			wantValid:   false,
+			wantError:   true,
+			wantMessage: "Could not determine whether VMware Tools is installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &InspectionData{
+				loadInspection: func(ctx context.Context) (interface{}, error) {
+					return tt.inspection, tt.err
+				},
+			}
+
+			result := checkRegistry["vmware-tools"].Run(context.Background(), data)
+			if result.CheckType != "vmware-tools" {
+				t.Errorf("Run() CheckType = %q, want %q", result.CheckType, "vmware-tools")
+			}
+			if result.Valid != tt.wantValid {
+				t.Errorf("Run() Valid = %v, want %v", result.Valid, tt.wantValid)
+			}
+			if (result.Error != nil) != tt.wantError {
+				t.Errorf("Run() Error = %v, want error %v", result.Error, tt.wantError)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("Run() Message = %q, want it to contain %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestInspectionDataRunsInspectionOnce(t *testing.T) {
+	loads := 0
+	data := &InspectionData{
+		loadInspection: func(ctx context.Context) (interface{}, error) {
+			loads++
+			return inspectionWithApps("open-vm-tools"), nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if result := checkRegistry["vmware-tools"].Run(context.Background(), data); result.Valid {
+			t.Errorf("Run() Valid = true, want false")
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Inspection() loaded the inspection %d times, want 1", loads)
+	}
+
+	var missing InspectionData
+	if _, err := missing.Inspection(context.Background()); err == nil {
+		t.Error("Inspection() without a loader error = nil, want an error")
+	}
+}
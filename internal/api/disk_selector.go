@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// parseDiskSelector reads the disk_label and disk_index query parameters, which restrict an
+// inspection to a single disk of the VM
+// It writes a 400 response and returns false when a parameter is invalid
+func parseDiskSelector(c *gin.Context) (vmware.DiskSelector, bool) {
+	label := c.Query("disk_label")
+	indexParam := c.Query("disk_index")
+
+	if label != "" && indexParam != "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid disk selector",
+			Code:    "INVALID_DISK_SELECTOR",
+			Details: "disk_label and disk_index cannot be used together",
+		})
+		return vmware.DiskSelector{}, false
+	}
+
+	if label != "" {
+		// The label ends up in the stored snapshot name passed to the inspectors
+		if err := validateName("disk label", label); err != nil {
+			c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+			return vmware.DiskSelector{}, false
+		}
+		return vmware.DiskSelector{Label: label}, true
+	}
+
+	if indexParam != "" {
+		index, err := strconv.Atoi(indexParam)
+		if err != nil || index < 0 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid disk selector",
+				Code:    "INVALID_DISK_SELECTOR",
+				Details: fmt.Sprintf("disk_index must be a non-negative integer, got: %s", indexParam),
+			})
+			return vmware.DiskSelector{}, false
+		}
+		return vmware.DiskSelector{Index: &index}, true
+	}
+
+	return vmware.DiskSelector{}, true
+}
+
+// storedName is the snapshot name an inspection result is locked, cached and stored under
+// Single-disk inspections are kept apart from the inspection of all disks of the snapshot
+func (r inspectionRequest) storedName() string {
+	if r.disk.IsZero() {
+		return r.snapshotName
+	}
+	return fmt.Sprintf("%s [%s]", r.snapshotName, r.disk)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseDiskSelector(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		want   string
+		wantOK bool
+	}{
+		{name: "no selector", query: "", want: "", wantOK: true},
+		{name: "label", query: "disk_label=Hard+disk+2", want: "Hard disk 2", wantOK: true},
+		{name: "index", query: "disk_index=1", want: "disk 1", wantOK: true},
+		{name: "zero index", query: "disk_index=0", want: "disk 0", wantOK: true},
+		{name: "label and index", query: "disk_label=Hard+disk+1&disk_index=0", wantOK: false},
+		{name: "negative index", query: "disk_index=-1", wantOK: false},
+		{name: "non-numeric index", query: "disk_index=first", wantOK: false},
+		{name: "label with a control character", query: "disk_label=Hard%0Adisk", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, recorder := newTestContext()
+			c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+			selector, ok := parseDiskSelector(c)
+			if ok != tt.wantOK {
+				t.Fatalf("parseDiskSelector(%q) ok = %v, want %v", tt.query, ok, tt.wantOK)
+			}
+			if !ok {
+				if recorder.Code != http.StatusBadRequest {
+					t.Errorf("parseDiskSelector(%q) status = %d, want %d", tt.query, recorder.Code, http.StatusBadRequest)
+				}
+				return
+			}
+			if got := selector.String(); got != tt.want {
+				t.Errorf("parseDiskSelector(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
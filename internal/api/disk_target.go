@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+var (
+	// vmMorefPattern and snapshotMorefPattern match vCenter managed object IDs
+	vmMorefPattern       = regexp.MustCompile(`^vm-\d+$`)
+	snapshotMorefPattern = regexp.MustCompile(`^snapshot-\d+$`)
+
+	// datastorePathPattern matches a datastore disk path such as "[datastore1] vm/vm.vmdk"
+	datastorePathPattern = regexp.MustCompile(`^\[[^\[\]]+\] \S.*\.vmdk$`)
+)
+
+// newDiskTarget validates the disk locations of an inspect-disk request and builds the
+// disk info the inspectors need, along with the datacenter name
+// The datacenter defaults to the first element of the compute resource inventory path
+func newDiskTarget(req types.InspectDiskRequest) (*validationtypes.SnapshotDiskInfo, string, error) {
+	if !vmMorefPattern.MatchString(req.VMMoref) {
+		return nil, "", fmt.Errorf("vm_moref must look like 'vm-123', got: %s", req.VMMoref)
+	}
+	if !snapshotMorefPattern.MatchString(req.SnapshotMoref) {
+		return nil, "", fmt.Errorf("snapshot_moref must look like 'snapshot-123', got: %s", req.SnapshotMoref)
+	}
+
+	diskPath := req.DiskPath
+	if diskPath == "" {
+		diskPath = req.BaseDiskPath
+	}
+	if !datastorePathPattern.MatchString(req.BaseDiskPath) {
+		return nil, "", fmt.Errorf("base_disk_path must look like '[datastore] folder/disk.vmdk', got: %s", req.BaseDiskPath)
+	}
+	if !datastorePathPattern.MatchString(diskPath) {
+		return nil, "", fmt.Errorf("disk_path must look like '[datastore] folder/disk.vmdk', got: %s", diskPath)
+	}
+
+	// Inventory paths look like /<datacenter>/host/<cluster or host>[/<host>]
+	parts := strings.Split(strings.TrimPrefix(req.ComputeResourcePath, "/"), "/")
+	if !strings.HasPrefix(req.ComputeResourcePath, "/") || len(parts) < 3 || parts[0] == "" || parts[1] != "host" || parts[2] == "" {
+		return nil, "", fmt.Errorf("compute_resource_path must look like '/<datacenter>/host/<cluster>/<host>', got: %s", req.ComputeResourcePath)
+	}
+
+	datacenter := req.Datacenter
+	if datacenter == "" {
+		datacenter = parts[0]
+	}
+
+	return &validationtypes.SnapshotDiskInfo{
+		VMMoref:             req.VMMoref,
+		SnapshotMoref:       req.SnapshotMoref,
+		DiskPaths:           []string{diskPath},
+		BaseDiskPaths:       []string{req.BaseDiskPath},
+		ComputeResourcePath: req.ComputeResourcePath,
+	}, datacenter, nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/storage"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestInspectionDB returns an inspection database in a temporary SQLite file
+func newTestInspectionDB(tb testing.TB, cacheSize int) *storage.InspectionDB {
+	tb.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(tb.TempDir(), "inspections.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	inspectionDB, err := storage.NewInspectionDB(db, cacheSize, logger)
+	if err != nil {
+		tb.Fatalf("NewInspectionDB() error = %v", err)
+	}
+	return inspectionDB
+}
+
+// countingInspector replaces the virt-inspector runner for the duration of the test and
+// returns the number of times it ran
+func countingInspector(t *testing.T) *int {
+	t.Helper()
+
+	runs := 0
+	original := inspectorRegistry[InspectorTypeVirtInspector]
+	inspectorRegistry[InspectorTypeVirtInspector] = func(ctx context.Context, inspector *persistent.Inspector, target inspectionTarget, message string) (types.VMInspectionResponse, error) {
+		runs++
+		return types.NewVirtInspectorResponse(target.vmName, target.snapshotName, message, &validationtypes.VirtInspectorXML{}), nil
+	}
+	t.Cleanup(func() {
+		inspectorRegistry[InspectorTypeVirtInspector] = original
+	})
+	return &runs
+}
+
+func TestExecuteInspectionCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		stored     bool
+		force      bool
+		wantRuns   int
+		wantCached bool
+		wantStored bool
+	}{
+		{name: "miss runs the inspector", stored: false, wantRuns: 1, wantCached: false},
+		{name: "hit returns the stored result", stored: true, wantRuns: 0, wantCached: true, wantStored: true},
+		{name: "force replaces the stored result", stored: true, force: true, wantRuns: 1, wantCached: false},
+		{name: "force without a stored result", stored: false, force: true, wantRuns: 1, wantCached: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newSimulatorHandler(t)
+			handler.inspectionDB = newTestInspectionDB(t, 0)
+			handler.inspectionConfig.VerifyDiskPaths = false
+			runs := countingInspector(t)
+			ctx := context.Background()
+
+			req := inspectionRequest{
+				vmName:        "DC0_H0_VM0",
+				snapshotName:  "before-upgrade",
+				inspectorType: InspectorTypeVirtInspector,
+				force:         tt.force,
+				// Known disk locations skip the vSphere disk lookup
+				datacenter: "DC0",
+				diskInfo:   &validationtypes.SnapshotDiskInfo{DiskPaths: []string{"[LocalDS_0] DC0_H0_VM0/disk1.vmdk"}},
+			}
+			key := persistent.CacheKey{VMName: req.vmName, SnapshotName: req.storedName()}
+
+			var storedAt time.Time
+			if tt.stored {
+				if err := handler.inspectionDB.SetVirtInspectorXML(ctx, key, &validationtypes.VirtInspectorXML{}); err != nil {
+					t.Fatalf("SetVirtInspectorXML() error = %v", err)
+				}
+				record, err := handler.inspectionDB.GetVirtInspectorRecord(ctx, key)
+				if err != nil {
+					t.Fatalf("GetVirtInspectorRecord() error = %v", err)
+				}
+				storedAt = record.UpdatedAt
+			}
+
+			start := time.Now()
+			response, failure := handler.executeInspection(ctx, req)
+			if failure != nil {
+				t.Fatalf("executeInspection() failure = %d %+v", failure.status, failure.response)
+			}
+
+			if *runs != tt.wantRuns {
+				t.Errorf("executeInspection() ran the inspector %d times, want %d", *runs, tt.wantRuns)
+			}
+			if response.Cached != tt.wantCached {
+				t.Errorf("executeInspection() Cached = %v, want %v", response.Cached, tt.wantCached)
+			}
+			if tt.wantCached {
+				if !response.InspectedAt.Equal(storedAt) {
+					t.Errorf("executeInspection() InspectedAt = %v, want the stored time %v", response.InspectedAt, storedAt)
+				}
+			} else if response.InspectedAt.Before(start) {
+				t.Errorf("executeInspection() InspectedAt = %v, want a time after %v", response.InspectedAt, start)
+			}
+
+			// The fake inspector stores nothing, so a forced run leaves no stored result behind
+			record, err := handler.inspectionDB.GetVirtInspectorRecord(ctx, key)
+			if err != nil {
+				t.Fatalf("GetVirtInspectorRecord() error = %v", err)
+			}
+			if stored := record != nil; stored != tt.wantStored {
+				t.Errorf("stored result present = %v, want %v", stored, tt.wantStored)
+			}
+		})
+	}
+}
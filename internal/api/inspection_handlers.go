@@ -0,0 +1,648 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/storage"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// InspectionHandler handles requests for stored inspection results
+type InspectionHandler struct {
+	inspectionDB *storage.InspectionDB
+	artifacts    *storage.ArtifactStore
+	logger       *logrus.Logger
+}
+
+// NewInspectionHandler creates a new inspection handler instance
+func NewInspectionHandler(inspectionDB *storage.InspectionDB, artifacts *storage.ArtifactStore, logger *logrus.Logger) *InspectionHandler {
+	return &InspectionHandler{
+		inspectionDB: inspectionDB,
+		artifacts:    artifacts,
+		logger:       logger,
+	}
+}
+
+// log returns a logger entry that carries the request ID of the request
+func (h *InspectionHandler) log(c *gin.Context) *logrus.Entry {
+	return h.logger.WithContext(c.Request.Context())
+}
+
+// ExportInspection godoc
+// @Summary Export stored inspection results
+// @Description Download the stored inspection results for a VM snapshot as a JSON document or as a CSV list of installed applications
+// @Tags inspections
+// @Produce json
+// @Produce text/csv
+// @Param vm query string true "VM name" example("web-server-01")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Param format query string false "Export format: 'json' (default) or 'csv'" example("csv")
+// @Success 200 {file} file "Exported inspection results"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "No stored inspection results"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/vms/inspection/export [get]
+func (h *InspectionHandler) ExportInspection(c *gin.Context) {
+	vmName := c.Query("vm")
+	snapshotName := c.Query("snapshot")
+	inspectorParam := c.DefaultQuery("inspector", string(DefaultInspectorType))
+	format := c.DefaultQuery("format", "json")
+
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?vm=xxx",
+		})
+		return
+	}
+
+	if snapshotName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Snapshot name is required",
+			Code:    "MISSING_SNAPSHOT_NAME",
+			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
+		})
+		return
+	}
+
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return
+	}
+
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid export format",
+			Code:    "INVALID_EXPORT_FORMAT",
+			Details: fmt.Sprintf("format must be 'json' or 'csv', got: %s", format),
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        vmName,
+		"snapshot_name":  snapshotName,
+		"inspector_type": inspectorType,
+		"format":         format,
+	}).Info("Exporting stored inspection results")
+
+	key := persistent.CacheKey{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+	}
+
+	dataJSON, found, err := h.loadInspectionJSON(c.Request.Context(), key, inspectorType)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to load stored inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to load inspection results",
+			Code:    "INSPECTION_EXPORT_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "Inspection results not found",
+			Code:    "INSPECTION_NOT_FOUND",
+			Details: fmt.Sprintf("No stored %s results for VM '%s' snapshot '%s'", inspectorType, vmName, snapshotName),
+		})
+		return
+	}
+
+	filename := exportFilename(vmName, snapshotName, string(inspectorType), format)
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(dataJSON))
+		return
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		h.log(c).WithError(err).Error("Failed to decode stored inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to decode inspection results",
+			Code:    "INSPECTION_EXPORT_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"name", "version", "arch"})
+	for _, app := range extractApplications(data) {
+		_ = writer.Write(app)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.log(c).WithError(err).Error("Failed to write CSV export")
+	}
+}
+
+// GetInspectionArtifacts godoc
+// @Summary List or download inspection artifacts
+// @Description List the artifacts stored for each inspection run of a VM snapshot (job metadata and the inspection result or error), newest first. Pass run and file to download a single artifact
+// @Tags inspections
+// @Produce json
+// @Produce application/octet-stream
+// @Param vm query string true "VM name" example("web-server-01")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param run query string false "Run to download a file from, as returned in the list" example("20240115T143000.123456789Z")
+// @Param file query string false "File to download from the run" example("error.json")
+// @Success 200 {object} types.InspectionArtifactListResponse "Stored inspection runs, or the requested file"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "Artifact not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/vms/inspection/artifacts [get]
+func (h *InspectionHandler) GetInspectionArtifacts(c *gin.Context) {
+	vmName := c.Query("vm")
+	snapshotName := c.Query("snapshot")
+	run := c.Query("run")
+	file := c.Query("file")
+
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?vm=xxx",
+		})
+		return
+	}
+
+	if snapshotName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Snapshot name is required",
+			Code:    "MISSING_SNAPSHOT_NAME",
+			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
+		})
+		return
+	}
+
+	if (run == "") != (file == "") {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Run and file must be given together",
+			Code:    "INVALID_ARTIFACT_REQUEST",
+			Details: "Provide both run and file to download an artifact, or neither to list the stored runs",
+		})
+		return
+	}
+
+	if file != "" {
+		path, err := h.artifacts.Path(vmName, snapshotName, run, file)
+		if err != nil {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Inspection artifact not found",
+				Code:    "ARTIFACT_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.FileAttachment(path, file)
+		return
+	}
+
+	runs, err := h.artifacts.List(vmName, snapshotName)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list inspection artifacts")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to list inspection artifacts",
+			Code:    "ARTIFACT_LIST_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := types.InspectionArtifactListResponse{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+		Runs:         make([]types.InspectionArtifactRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		files := make([]types.InspectionArtifactFile, 0, len(run.Files))
+		for _, file := range run.Files {
+			files = append(files, types.InspectionArtifactFile{Name: file.Name, Size: file.Size})
+		}
+		response.Runs = append(response.Runs, types.InspectionArtifactRun{
+			Run:       run.Run,
+			CreatedAt: run.CreatedAt,
+			Files:     files,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// FindInspectionsByApplication godoc
+// @Summary Find inspected VMs with an application installed
+// @Description Search the stored virt-inspector results for snapshots that have the given application installed, optionally filtered by version prefix (e.g. which VMs have openssl 1.0?)
+// @Tags inspections
+// @Produce json
+// @Param name query string true "Application name (exact match)" example("openssl")
+// @Param version query string false "Only match application versions starting with this prefix" example("1.0")
+// @Success 200 {object} types.ApplicationSearchResponse "Matching inspections"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/inspections/by-application [get]
+func (h *InspectionHandler) FindInspectionsByApplication(c *gin.Context) {
+	appName := c.Query("name")
+	versionPrefix := c.Query("version")
+
+	if appName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Application name is required",
+			Code:    "MISSING_APPLICATION_NAME",
+			Details: "Please provide application name as query parameter: ?name=xxx",
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"application": appName,
+		"version":     versionPrefix,
+	}).Info("Searching inspections by application")
+
+	records, err := h.inspectionDB.FindByApplication(c.Request.Context(), appName)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to search stored inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to search inspection results",
+			Code:    "INSPECTION_SEARCH_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// The database match is on any string value; keep only real application entries
+	matches := []types.ApplicationMatch{}
+	for _, record := range records {
+		var data interface{}
+		if err := json.Unmarshal([]byte(record.DataJSON), &data); err != nil {
+			h.log(c).WithError(err).WithField("vm_name", record.VMName).Warn("Skipping undecodable inspection results")
+			continue
+		}
+		for _, app := range extractApplications(data) {
+			if app[0] != appName || !strings.HasPrefix(app[1], versionPrefix) {
+				continue
+			}
+			matches = append(matches, types.ApplicationMatch{
+				VMName:       record.VMName,
+				SnapshotName: record.SnapshotName,
+				InspectedAt:  record.UpdatedAt,
+				Name:         app[0],
+				Version:      app[1],
+				Arch:         app[2],
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, types.ApplicationSearchResponse{
+		Application: appName,
+		Version:     versionPrefix,
+		Matches:     matches,
+		Total:       len(matches),
+	})
+}
+
+// GetInspectionSummary godoc
+// @Summary Get a summary of stored inspection results
+// @Description Get a compact summary of the stored inspection results for a VM snapshot: OS family, distro, application count and boot filesystem type
+// @Tags inspections
+// @Produce json
+// @Param vm query string true "VM name" example("web-server-01")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Success 200 {object} types.InspectionSummaryResponse "Inspection summary"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "No stored inspection results"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/vms/inspection/summary [get]
+func (h *InspectionHandler) GetInspectionSummary(c *gin.Context) {
+	vmName := c.Query("vm")
+	snapshotName := c.Query("snapshot")
+	inspectorParam := c.DefaultQuery("inspector", string(DefaultInspectorType))
+
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?vm=xxx",
+		})
+		return
+	}
+
+	if snapshotName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Snapshot name is required",
+			Code:    "MISSING_SNAPSHOT_NAME",
+			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
+		})
+		return
+	}
+
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        vmName,
+		"snapshot_name":  snapshotName,
+		"inspector_type": inspectorType,
+	}).Info("Getting inspection summary")
+
+	key := persistent.CacheKey{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+	}
+
+	dataJSON, found, err := h.loadInspectionJSON(c.Request.Context(), key, inspectorType)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to load stored inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to load inspection results",
+			Code:    "INSPECTION_SUMMARY_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "Inspection results not found",
+			Code:    "INSPECTION_NOT_FOUND",
+			Details: fmt.Sprintf("No stored %s results for VM '%s' snapshot '%s'", inspectorType, vmName, snapshotName),
+		})
+		return
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		h.log(c).WithError(err).Error("Failed to decode stored inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to decode inspection results",
+			Code:    "INSPECTION_SUMMARY_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	summary := summarizeInspection(data)
+	h.log(c).WithField("os_layout", summary.osLayout).Debug("Matched operating system layout")
+
+	c.JSON(http.StatusOK, types.InspectionSummaryResponse{
+		VMName:             vmName,
+		SnapshotName:       snapshotName,
+		InspectorType:      string(inspectorType),
+		OSFamily:           summary.osFamily,
+		OSName:             summary.osName,
+		Distro:             summary.distro,
+		ProductName:        summary.productName,
+		PackageFormat:      summary.packageFormat,
+		ApplicationCount:   summary.applicationCount,
+		BootFilesystemType: summary.bootFilesystemType,
+
+		AdditionalOperatingSystems: summary.additionalSystems,
+	})
+}
+
+// DeleteInspection godoc
+// @Summary Delete stored inspection results for a VM snapshot
+// @Description Permanently delete the stored virt-inspector and virt-v2v-inspector results for a VM snapshot
+// @Tags inspections
+// @Accept json
+// @Produce json
+// @Param vm query string true "VM name" example("web-server-01")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Success 200 {object} types.InspectionDeleteResponse "Inspection records deleted"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/vms/inspection [delete]
+func (h *InspectionHandler) DeleteInspection(c *gin.Context) {
+	vmName := c.Query("vm")
+	snapshotName := c.Query("snapshot")
+
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?vm=xxx",
+		})
+		return
+	}
+
+	if snapshotName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Snapshot name is required",
+			Code:    "MISSING_SNAPSHOT_NAME",
+			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":       vmName,
+		"snapshot_name": snapshotName,
+	}).Info("Deleting stored inspection results")
+
+	key := persistent.CacheKey{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+	}
+
+	virtDeleted, err := h.inspectionDB.DeleteVirtInspectorXML(c.Request.Context(), key)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to delete virt-inspector results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to delete inspection results",
+			Code:    "INSPECTION_DELETE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	v2vDeleted, err := h.inspectionDB.DeleteVirtV2VInspectorXML(c.Request.Context(), key)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to delete virt-v2v-inspector results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to delete inspection results",
+			Code:    "INSPECTION_DELETE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	deleted := virtDeleted + v2vDeleted
+
+	h.log(c).WithField("deleted", deleted).Info("Stored inspection results deleted")
+	c.JSON(http.StatusOK, types.InspectionDeleteResponse{
+		Deleted: deleted,
+		Message: "Inspection records deleted successfully",
+	})
+}
+
+// PurgeInspections godoc
+// @Summary Purge old stored inspection results
+// @Description Permanently delete all stored inspection results created longer ago than the given duration
+// @Tags inspections
+// @Accept json
+// @Produce json
+// @Param older_than query string true "Go duration, records created before now minus this duration are deleted" example("720h")
+// @Success 200 {object} types.InspectionDeleteResponse "Inspection records purged"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /api/v1/inspections [delete]
+func (h *InspectionHandler) PurgeInspections(c *gin.Context) {
+	olderThanParam := c.Query("older_than")
+	if olderThanParam == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "older_than is required",
+			Code:    "MISSING_OLDER_THAN",
+			Details: "Please provide a duration as query parameter: ?older_than=720h",
+		})
+		return
+	}
+
+	olderThan, err := time.ParseDuration(olderThanParam)
+	if err != nil || olderThan <= 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid older_than duration",
+			Code:    "INVALID_OLDER_THAN",
+			Details: fmt.Sprintf("older_than must be a positive duration such as '720h', got: %s", olderThanParam),
+		})
+		return
+	}
+
+	before := time.Now().Add(-olderThan)
+	h.log(c).WithField("before", before).Info("Purging stored inspection results")
+
+	deleted, err := h.inspectionDB.DeleteOlderThan(c.Request.Context(), before)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to purge inspection results")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to purge inspection results",
+			Code:    "INSPECTION_PURGE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.log(c).WithField("deleted", deleted).Info("Stored inspection results purged")
+	c.JSON(http.StatusOK, types.InspectionDeleteResponse{
+		Deleted: deleted,
+		Message: "Inspection records purged successfully",
+	})
+}
+
+// loadInspectionJSON returns the stored JSON inspection data for the given inspector type
+func (h *InspectionHandler) loadInspectionJSON(ctx context.Context, key persistent.CacheKey, inspectorType InspectorType) (string, bool, error) {
+	if inspectorType == InspectorTypeVirtV2VInspector {
+		record, err := h.inspectionDB.GetVirtV2VInspectorRecord(ctx, key)
+		if err != nil || record == nil {
+			return "", false, err
+		}
+		return string(record.DataJSON), true, nil
+	}
+
+	record, err := h.inspectionDB.GetVirtInspectorRecord(ctx, key)
+	if err != nil || record == nil {
+		return "", false, err
+	}
+	return string(record.DataJSON), true, nil
+}
+
+// exportFilename builds a download filename for exported inspection results
+func exportFilename(vmName, snapshotName, inspectorType, format string) string {
+	sanitize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+				return r
+			}
+			return '_'
+		}, s)
+	}
+	return fmt.Sprintf("%s_%s_%s.%s", sanitize(vmName), sanitize(snapshotName), inspectorType, format)
+}
+
+// extractApplications walks decoded inspection data and collects (name, version, arch)
+// rows from every "applications" list, matching keys case-insensitively since the
+// stored JSON mirrors the inspector XML structure
+func extractApplications(data interface{}) [][]string {
+	var rows [][]string
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		// Visit keys in sorted order so exports are deterministic
+		for _, key := range sortedKeys(v) {
+			if strings.EqualFold(key, "applications") {
+				rows = append(rows, applicationRows(v[key])...)
+				continue
+			}
+			rows = append(rows, extractApplications(v[key])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rows = append(rows, extractApplications(item)...)
+		}
+	}
+
+	return rows
+}
+
+// applicationRows converts an applications value into CSV rows
+// The value is either a list of applications or an object wrapping that list
+func applicationRows(value interface{}) [][]string {
+	var rows [][]string
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			app, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rows = append(rows, []string{
+				lookupString(app, "name"),
+				lookupString(app, "version"),
+				lookupString(app, "arch"),
+			})
+		}
+	case map[string]interface{}:
+		for _, inner := range v {
+			rows = append(rows, applicationRows(inner)...)
+		}
+	}
+
+	return rows
+}
+
+// lookupString returns the string value of a key, matched case-insensitively
+func lookupString(m map[string]interface{}, key string) string {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			if s, ok := v.(string); ok {
+				return s
+			}
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
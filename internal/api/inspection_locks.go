@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// inspectionLocks serializes inspections of the same key (inspector, VM and snapshot),
+// so concurrent requests for one snapshot don't open two VDDK sessions on its disks
+type inspectionLocks struct {
+	mutex sync.Mutex
+	locks map[string]*inspectionLock
+}
+
+// inspectionLock is held by one inspection at a time; refs counts holders and waiters
+type inspectionLock struct {
+	held chan struct{}
+	refs int
+}
+
+// newInspectionLocks creates an empty set of inspection locks
+func newInspectionLocks() *inspectionLocks {
+	return &inspectionLocks{locks: make(map[string]*inspectionLock)}
+}
+
+// acquire blocks until the lock for key is free or ctx is done
+// waited reports whether another inspection held the lock; release must be called
+// once the inspection finishes, unless an error is returned
+func (l *inspectionLocks) acquire(ctx context.Context, key string) (release func(), waited bool, err error) {
+	l.mutex.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &inspectionLock{held: make(chan struct{}, 1)}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mutex.Unlock()
+
+	select {
+	case lock.held <- struct{}{}:
+	default:
+		waited = true
+		select {
+		case lock.held <- struct{}{}:
+		case <-ctx.Done():
+			l.unref(key, lock)
+			return nil, true, ctx.Err()
+		}
+	}
+
+	return func() {
+		<-lock.held
+		l.unref(key, lock)
+	}, waited, nil
+}
+
+// unref drops a reference to the lock for key, removing it when unused
+func (l *inspectionLocks) unref(key string, lock *inspectionLock) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+// inspectionLockKey identifies the inspections that must not run concurrently; it
+// matches the key inspection results are stored under
+func inspectionLockKey(req inspectionRequest) string {
+	return string(req.inspectorType) + "\x00" + req.vmName + "\x00" + req.storedName()
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInspectionLocksSameKey(t *testing.T) {
+	locks := newInspectionLocks()
+	const key = "virt-inspector\x00web01\x00snap1"
+
+	// Each caller runs the inspection unless it waited for one, as runInspection does
+	var runs atomic.Int32
+	var waitedCount atomic.Int32
+	firstRunning := make(chan struct{})
+	finishFirst := make(chan struct{})
+
+	inspect := func(first bool) error {
+		release, waited, err := locks.acquire(context.Background(), key)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		if waited {
+			waitedCount.Add(1)
+			return nil
+		}
+		runs.Add(1)
+		if first {
+			close(firstRunning)
+			<-finishFirst
+		}
+		return nil
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- inspect(true) }()
+	<-firstRunning
+	go func() { errs <- inspect(false) }()
+
+	// The second caller must be waiting for the lock, not running
+	waitForLockRefs(t, locks, key, 2)
+	close(finishFirst)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("two identical inspections ran %d times, want 1", got)
+	}
+	if got := waitedCount.Load(); got != 1 {
+		t.Errorf("acquire() reported waited to %d callers, want 1", got)
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("inspectionLocks kept %d locks after release, want 0", len(locks.locks))
+	}
+}
+
+func TestInspectionLocksDifferentKeys(t *testing.T) {
+	locks := newInspectionLocks()
+
+	release1, waited, err := locks.acquire(context.Background(), "virt-inspector\x00web01\x00snap1")
+	if err != nil || waited {
+		t.Fatalf("acquire() = waited %v, error %v, want neither", waited, err)
+	}
+	defer release1()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release2, waited, err := locks.acquire(ctx, "virt-inspector\x00web01\x00snap2")
+	if err != nil || waited {
+		t.Fatalf("acquire() for another snapshot = waited %v, error %v, want neither", waited, err)
+	}
+	release2()
+}
+
+func TestInspectionLocksCancelledWait(t *testing.T) {
+	locks := newInspectionLocks()
+	const key = "virt-inspector\x00web01\x00snap1"
+
+	release, _, err := locks.acquire(context.Background(), key)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, waited, err := locks.acquire(ctx, key); !errors.Is(err, context.DeadlineExceeded) || !waited {
+		t.Errorf("acquire() while held = waited %v, error %v, want waited and %v", waited, err, context.DeadlineExceeded)
+	}
+
+	release()
+	if len(locks.locks) != 0 {
+		t.Errorf("inspectionLocks kept %d locks after the waiter gave up and the holder released, want 0", len(locks.locks))
+	}
+}
+
+// waitForLockRefs waits until the lock for key has refs holders and waiters
+func waitForLockRefs(t *testing.T, locks *inspectionLocks, key string, refs int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		locks.mutex.Lock()
+		lock, ok := locks.locks[key]
+		current := 0
+		if ok {
+			current = lock.refs
+		}
+		locks.mutex.Unlock()
+		if current == refs {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("lock for %q never reached %d references", key, refs)
+}
+
+func TestInspectionLockKey(t *testing.T) {
+	base := inspectionRequest{inspectorType: InspectorTypeVirtInspector, vmName: "web01", snapshotName: "snap1"}
+
+	same := base
+	otherSnapshot := base
+	otherSnapshot.snapshotName = "snap2"
+	otherInspector := base
+	otherInspector.inspectorType = InspectorTypeVirtV2VInspector
+
+	if inspectionLockKey(base) != inspectionLockKey(same) {
+		t.Errorf("inspectionLockKey() differs for identical requests")
+	}
+	if inspectionLockKey(base) == inspectionLockKey(otherSnapshot) {
+		t.Errorf("inspectionLockKey() is the same for different snapshots")
+	}
+	if inspectionLockKey(base) == inspectionLockKey(otherInspector) {
+		t.Errorf("inspectionLockKey() is the same for different inspectors")
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// InspectionPhase is a step of an inspection reported to streaming clients
+type InspectionPhase string
+
+// Inspection phases, in the order they are reported
+const (
+	PhaseStarting         InspectionPhase = "starting"
+	PhaseResolvingDisks   InspectionPhase = "resolving disks"
+	PhaseRunningInspector InspectionPhase = "running inspector"
+	PhaseRetrying         InspectionPhase = "retrying"
+	PhaseParsing          InspectionPhase = "parsing"
+	PhaseDone             InspectionPhase = "done"
+)
+
+// eventStreamKeepAlive is how often a comment is written to an idle event stream,
+// so proxies between the client and the service don't time the connection out
+const eventStreamKeepAlive = 15 * time.Second
+
+type progressKey struct{}
+
+// withProgress returns a copy of ctx that reports inspection phases to report
+func withProgress(ctx context.Context, report func(InspectionPhase)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress reports an inspection phase to the reporter carried by ctx, if any
+func reportProgress(ctx context.Context, phase InspectionPhase) {
+	if report, ok := ctx.Value(progressKey{}).(func(InspectionPhase)); ok {
+		report(phase)
+	}
+}
+
+// wantsEventStream reports whether the client asked for a Server-Sent Events response
+func wantsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// streamInspection runs an inspection and streams its progress as Server-Sent Events:
+// "progress" events while it runs, then a "result" event with the inspection response
+// or an "error" event with the error body. The HTTP status is always 200 once the
+// stream has started, so clients must check the final event type
+func (h *VMHandler) streamInspection(c *gin.Context, ctx context.Context, req inspectionRequest) {
+	phases := make(chan InspectionPhase, 16)
+	ctx = withProgress(ctx, func(phase InspectionPhase) {
+		// Never block the inspection on a slow client; phases are informational
+		select {
+		case phases <- phase:
+		default:
+		}
+	})
+
+	type outcome struct {
+		response *types.VMInspectionResponse
+		failure  *inspectionFailure
+	}
+	finished := make(chan outcome, 1)
+	go func() {
+		response, failure := h.runInspection(ctx, req)
+		finished <- outcome{response: response, failure: failure}
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	sendPhase := func(phase InspectionPhase) {
+		c.SSEvent("progress", types.InspectionProgressEvent{Phase: string(phase), Timestamp: time.Now().UTC()})
+		c.Writer.Flush()
+	}
+	sendPhase(PhaseStarting)
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case phase := <-phases:
+			sendPhase(phase)
+		case <-keepAlive.C:
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		case result := <-finished:
+			// Phases reported just before the inspection finished are still buffered
+			for len(phases) > 0 {
+				sendPhase(<-phases)
+			}
+
+			if result.failure != nil {
+				h.log(c).WithField("code", result.failure.response.Code).Warn("Streamed inspection failed")
+				c.SSEvent("error", result.failure.response)
+			} else {
+				sendPhase(PhaseDone)
+				h.log(c).WithField("inspector_type", req.inspectorType).Info("Streamed inspection completed successfully")
+				c.SSEvent("result", req.apps.apply(result.response))
+			}
+			c.Writer.Flush()
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nirarg/vm-deep-inspection-demo/internal/metrics"
+)
+
+// errInspectionQueueFull is returned when no inspection slot frees up within the queue timeout
+var errInspectionQueueFull = errors.New("all inspection slots are busy")
+
+// inspectionSlots bounds the number of inspector subprocesses (nbdkit, virt-inspector and
+// libguestfs) running at the same time; further inspections queue for a free slot
+type inspectionSlots struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+// newInspectionSlots creates max inspection slots
+// queueTimeout bounds how long an inspection waits for a slot; zero rejects it right away
+func newInspectionSlots(max int, queueTimeout time.Duration) *inspectionSlots {
+	return &inspectionSlots{
+		slots:        make(chan struct{}, max),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire takes a slot, waiting up to the queue timeout for one to free up
+// It returns errInspectionQueueFull when the wait times out, or ctx's error when ctx is
+// done first; otherwise release must be called once the inspection finishes
+func (s *inspectionSlots) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case s.slots <- struct{}{}:
+		return s.acquired(), nil
+	default:
+	}
+
+	if s.queueTimeout <= 0 {
+		return nil, errInspectionQueueFull
+	}
+
+	dequeued := metrics.InspectionQueued()
+	defer dequeued()
+
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return s.acquired(), nil
+	case <-timer.C:
+		return nil, errInspectionQueueFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// acquired records a taken slot and returns the function that frees it
+func (s *inspectionSlots) acquired() func() {
+	freed := metrics.InspectionSlotAcquired()
+	return func() {
+		freed()
+		<-s.slots
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fillInspectionSlots takes every slot and returns a function that frees them all
+func fillInspectionSlots(t *testing.T, slots *inspectionSlots, max int) func() {
+	t.Helper()
+	var releases []func()
+	for i := 0; i < max; i++ {
+		release, err := slots.acquire(context.Background())
+		if err != nil {
+			t.Fatalf("acquire() slot %d of %d error = %v", i+1, max, err)
+		}
+		releases = append(releases, release)
+	}
+	return func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+}
+
+func TestInspectionSlotsRejectWithoutQueue(t *testing.T) {
+	const max = 2
+	slots := newInspectionSlots(max, 0)
+	releaseAll := fillInspectionSlots(t, slots, max)
+	defer releaseAll()
+
+	if _, err := slots.acquire(context.Background()); !errors.Is(err, errInspectionQueueFull) {
+		t.Errorf("acquire() with %d busy slots error = %v, want %v", max, err, errInspectionQueueFull)
+	}
+}
+
+func TestInspectionSlotsQueueTimeout(t *testing.T) {
+	const max = 2
+	const queueTimeout = 20 * time.Millisecond
+	slots := newInspectionSlots(max, queueTimeout)
+	releaseAll := fillInspectionSlots(t, slots, max)
+	defer releaseAll()
+
+	started := time.Now()
+	_, err := slots.acquire(context.Background())
+	if !errors.Is(err, errInspectionQueueFull) {
+		t.Fatalf("acquire() with %d busy slots error = %v, want %v", max, err, errInspectionQueueFull)
+	}
+	if waited := time.Since(started); waited < queueTimeout {
+		t.Errorf("acquire() gave up after %s, want at least the queue timeout %s", waited, queueTimeout)
+	}
+}
+
+func TestInspectionSlotsQueuedCallerGetsFreedSlot(t *testing.T) {
+	const max = 1
+	slots := newInspectionSlots(max, 5*time.Second)
+	release, err := slots.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		releaseQueued, err := slots.acquire(context.Background())
+		if err == nil {
+			releaseQueued()
+		}
+		acquired <- err
+	}()
+
+	// The queued caller blocks while the slot is taken
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquire() returned %v while all slots were busy, want it to block", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("acquire() after a slot was freed error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire() still blocked after a slot was freed")
+	}
+}
+
+func TestInspectionSlotsCancelledWait(t *testing.T) {
+	const max = 1
+	slots := newInspectionSlots(max, 5*time.Second)
+	releaseAll := fillInspectionSlots(t, slots, max)
+	defer releaseAll()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slots.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() with a cancelled context error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// OS families reported in inspection summaries
+const (
+	osFamilyLinux   = "linux"
+	osFamilyWindows = "windows"
+	osFamilyUnknown = "unknown"
+)
+
+// linuxPackageFormats are package formats that imply a Linux guest
+var linuxPackageFormats = map[string]bool{
+	"rpm":     true,
+	"deb":     true,
+	"apk":     true,
+	"pacman":  true,
+	"ebuild":  true,
+	"pisi":    true,
+	"pkgsrc":  true,
+	"xbps":    true,
+	"flatpak": true,
+}
+
+// osLayout is a known shape of an operating system entry in inspector output
+// The fields reported differ between libguestfs versions and between Linux and Windows
+// guests, so an entry is recognized by having any of the layout's marker fields
+type osLayout struct {
+	name    string
+	markers []string
+}
+
+// osLayouts are the operating system layouts, tried in order
+var osLayouts = []osLayout{
+	// Package-managed guests, reported by every libguestfs version
+	{name: "package-manager", markers: []string{"distro", "package_format"}},
+	// Newer libguestfs versions add the libosinfo short ID
+	{name: "osinfo", markers: []string{"osinfo"}},
+	// Windows guests without a distro, identified by their registry fields
+	{name: "windows", markers: []string{"windows_systemroot", "windows_current_control_set"}},
+	// Minimal entries that only carry product details
+	{name: "product", markers: []string{"product_name", "product_variant"}},
+}
+
+// inspectionSummary is a compact summary derived from stored inspection data
+type inspectionSummary struct {
+	// osLayout names the layout the operating system entry matched, if any
+	osLayout string
+
+	osFamily           string
+	osName             string
+	distro             string
+	productName        string
+	packageFormat      string
+	applicationCount   int
+	bootFilesystemType string
+
+	// additionalSystems are the operating systems found after the first one,
+	// e.g. on multi-boot disks or VMs with one system per disk
+	additionalSystems []types.InspectionOperatingSystem
+}
+
+// summarizeInspection derives a compact summary from decoded inspection data
+// The stored JSON mirrors the inspector XML structure, so fields are located by
+// walking the document and matching keys case- and separator-insensitively
+func summarizeInspection(data interface{}) inspectionSummary {
+	summary := inspectionSummary{
+		applicationCount: len(extractApplications(data)),
+		osFamily:         osFamilyUnknown,
+	}
+
+	if systems := findOperatingSystems(data); len(systems) > 0 {
+		os := systems[0].entry
+		summary.osLayout = systems[0].layout
+		summary.osName = lookupField(os, "name")
+		summary.distro = lookupField(os, "distro")
+		summary.productName = lookupField(os, "product_name")
+		summary.packageFormat = lookupField(os, "package_format")
+		summary.osFamily = inferOSFamily(summary.osName, summary.distro, summary.packageFormat)
+		summary.bootFilesystemType = findBootFilesystemType(os)
+
+		for _, system := range systems[1:] {
+			additional := types.InspectionOperatingSystem{
+				Root:          lookupField(system.entry, "root"),
+				OSName:        lookupField(system.entry, "name"),
+				Distro:        lookupField(system.entry, "distro"),
+				ProductName:   lookupField(system.entry, "product_name"),
+				PackageFormat: lookupField(system.entry, "package_format"),
+			}
+			additional.OSFamily = inferOSFamily(additional.OSName, additional.Distro, additional.PackageFormat)
+			summary.additionalSystems = append(summary.additionalSystems, additional)
+		}
+	}
+
+	return summary
+}
+
+// inferOSFamily determines the OS family from the OS name, distro and package format
+func inferOSFamily(name, distro, packageFormat string) string {
+	name = strings.ToLower(name)
+	distro = strings.ToLower(distro)
+
+	switch {
+	case name == osFamilyWindows || distro == osFamilyWindows:
+		return osFamilyWindows
+	case name == osFamilyLinux || linuxPackageFormats[strings.ToLower(packageFormat)]:
+		return osFamilyLinux
+	case distro != "" && distro != "unknown":
+		// virt-inspector only reports distros for Linux guests
+		return osFamilyLinux
+	default:
+		return osFamilyUnknown
+	}
+}
+
+// operatingSystem is an operating system entry found in inspection data
+type operatingSystem struct {
+	entry  map[string]interface{}
+	layout string
+}
+
+// findOperatingSystems returns every object that describes an operating system, in
+// document order, e.g. both systems of a dual-boot disk
+func findOperatingSystems(data interface{}) []operatingSystem {
+	var systems []operatingSystem
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if layout := matchOSLayout(v); layout != "" {
+			return []operatingSystem{{entry: v, layout: layout}}
+		}
+		for _, key := range sortedKeys(v) {
+			systems = append(systems, findOperatingSystems(v[key])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			systems = append(systems, findOperatingSystems(item)...)
+		}
+	}
+	return systems
+}
+
+// matchOSLayout returns the name of the first layout m matches, or an empty string
+func matchOSLayout(m map[string]interface{}) string {
+	for _, layout := range osLayouts {
+		for _, marker := range layout.markers {
+			if hasField(m, marker) {
+				return layout.name
+			}
+		}
+	}
+	return ""
+}
+
+// findBootFilesystemType returns the filesystem type of the device mounted on /boot,
+// falling back to the root filesystem when there is no separate /boot
+func findBootFilesystemType(os map[string]interface{}) string {
+	mounts := make(map[string]string) // mount path -> device
+	collectObjects(os, "mountpoint", func(m map[string]interface{}) {
+		device := lookupField(m, "dev")
+		for _, key := range sortedKeys(m) {
+			if value, ok := m[key].(string); ok && strings.HasPrefix(value, "/") && value != device {
+				mounts[value] = device
+			}
+		}
+	})
+
+	device, ok := mounts["/boot"]
+	if !ok {
+		device, ok = mounts["/"]
+	}
+	if !ok {
+		return ""
+	}
+
+	var fsType string
+	collectObjects(os, "filesystem", func(m map[string]interface{}) {
+		if fsType == "" && lookupField(m, "dev") == device {
+			fsType = lookupField(m, "type")
+		}
+	})
+	return fsType
+}
+
+// collectObjects calls fn for every object found under a key matching name or its plural
+func collectObjects(data interface{}, name string, fn func(map[string]interface{})) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			normalized := normalizeKey(key)
+			if normalized == name || normalized == name+"s" {
+				forEachObject(v[key], fn)
+				continue
+			}
+			collectObjects(v[key], name, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectObjects(item, name, fn)
+		}
+	}
+}
+
+// forEachObject calls fn for every object in value, unwrapping lists and wrapper objects
+func forEachObject(value interface{}, fn func(map[string]interface{})) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			forEachObject(item, fn)
+		}
+	case map[string]interface{}:
+		if hasField(v, "dev") {
+			fn(v)
+			return
+		}
+		for _, key := range sortedKeys(v) {
+			forEachObject(v[key], fn)
+		}
+	}
+}
+
+// lookupField returns the string value of a field, matching keys case- and separator-insensitively
+func lookupField(m map[string]interface{}, name string) string {
+	for _, key := range sortedKeys(m) {
+		if normalizeKey(key) == normalizeKey(name) {
+			if s, ok := m[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// hasField reports whether m has a field matching name case- and separator-insensitively
+func hasField(m map[string]interface{}, name string) bool {
+	for key := range m {
+		if normalizeKey(key) == normalizeKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey lowercases a key and strips separators so "package_format" matches "PackageFormat"
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// sortedKeys returns the keys of m in sorted order so results are deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// decodeInspectorXML decodes inspector XML into the generic form of stored inspection
+// data: elements become objects keyed by child name, repeated children become lists and
+// text-only elements become strings. Attributes are kept as fields of their element.
+func decodeInspectorXML(t *testing.T, path string) interface{} {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", path, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				t.Fatalf("failed to decode %s: %v", path, err)
+			}
+			return map[string]interface{}{start.Name.Local: value}
+		}
+	}
+}
+
+// decodeXMLElement decodes the content of the element opened by start
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	fields := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		fields[attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, tok)
+			if err != nil {
+				return nil, err
+			}
+			name := tok.Name.Local
+			switch existing := fields[name].(type) {
+			case nil:
+				fields[name] = child
+			case []interface{}:
+				fields[name] = append(existing, child)
+			default:
+				fields[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(fields) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				fields["text"] = content
+			}
+			return fields, nil
+		}
+	}
+}
+
+func TestSummarizeInspectionWindows(t *testing.T) {
+	data := decodeInspectorXML(t, "testdata/windows-inspector.xml")
+
+	summary := summarizeInspection(data)
+
+	tests := []struct {
+		field string
+		got   interface{}
+		want  interface{}
+	}{
+		{field: "osFamily", got: summary.osFamily, want: osFamilyWindows},
+		{field: "osName", got: summary.osName, want: "windows"},
+		{field: "distro", got: summary.distro, want: "windows"},
+		{field: "productName", got: summary.productName, want: "Windows Server 2019 Standard"},
+		{field: "packageFormat", got: summary.packageFormat, want: ""},
+		{field: "applicationCount", got: summary.applicationCount, want: 2},
+		{field: "bootFilesystemType", got: summary.bootFilesystemType, want: "ntfs"},
+		{field: "osLayout", got: summary.osLayout, want: "package-manager"},
+		{field: "additionalSystems", got: len(summary.additionalSystems), want: 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("summarizeInspection().%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeInspectionDualBoot(t *testing.T) {
+	data := decodeInspectorXML(t, "testdata/dual-boot-inspector.xml")
+
+	summary := summarizeInspection(data)
+
+	tests := []struct {
+		field string
+		got   interface{}
+		want  interface{}
+	}{
+		{field: "osFamily", got: summary.osFamily, want: osFamilyLinux},
+		{field: "osName", got: summary.osName, want: "linux"},
+		{field: "distro", got: summary.distro, want: "rhel"},
+		{field: "productName", got: summary.productName, want: "Red Hat Enterprise Linux 8.9 (Ootpa)"},
+		{field: "packageFormat", got: summary.packageFormat, want: "rpm"},
+		{field: "applicationCount", got: summary.applicationCount, want: 4},
+		{field: "bootFilesystemType", got: summary.bootFilesystemType, want: "xfs"},
+		{field: "additionalSystems", got: len(summary.additionalSystems), want: 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("summarizeInspection().%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+
+	if len(summary.additionalSystems) != 1 {
+		return
+	}
+	want := types.InspectionOperatingSystem{
+		Root:        "/dev/sdb2",
+		OSFamily:    osFamilyWindows,
+		OSName:      "windows",
+		Distro:      "windows",
+		ProductName: "Windows Server 2019 Standard",
+	}
+	if got := summary.additionalSystems[0]; got != want {
+		t.Errorf("summarizeInspection().additionalSystems[0] = %+v, want %+v", got, want)
+	}
+}
+
+func TestInferOSFamily(t *testing.T) {
+	tests := []struct {
+		name          string
+		osName        string
+		distro        string
+		packageFormat string
+		want          string
+	}{
+		{name: "windows by name", osName: "windows", want: osFamilyWindows},
+		{name: "windows by distro", distro: "Windows", want: osFamilyWindows},
+		{name: "linux by name", osName: "linux", want: osFamilyLinux},
+		{name: "linux by package format", packageFormat: "RPM", want: osFamilyLinux},
+		{name: "linux by distro", distro: "ubuntu", want: osFamilyLinux},
+		{name: "unknown distro", distro: "unknown", want: osFamilyUnknown},
+		{name: "nothing reported", want: osFamilyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferOSFamily(tt.osName, tt.distro, tt.packageFormat); got != tt.want {
+				t.Errorf("inferOSFamily(%q, %q, %q) = %q, want %q", tt.osName, tt.distro, tt.packageFormat, got, tt.want)
+			}
+		})
+	}
+}
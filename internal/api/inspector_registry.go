@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/storage"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// InspectorType identifies an inspection tool
+type InspectorType string
+
+// Supported inspector types
+const (
+	InspectorTypeVirtInspector    InspectorType = "virt-inspector"
+	InspectorTypeVirtV2VInspector InspectorType = "virt-v2v-inspector"
+)
+
+// DefaultInspectorType is used when no inspector is requested
+const DefaultInspectorType = InspectorTypeVirtInspector
+
+// ErrNoOperatingSystem is returned when the inspector finds no operating system on the
+// inspected disks, e.g. because they only hold data
+var ErrNoOperatingSystem = errors.New("no operating system detected")
+
+// inspectionTarget describes the snapshot disks an inspector runs against
+type inspectionTarget struct {
+	vmName       string
+	snapshotName string
+	datacenter   string
+	diskInfo     *validationtypes.SnapshotDiskInfo
+	sslVerify    string
+}
+
+// inspectorRunner runs an inspector against a target and builds the API response
+type inspectorRunner func(ctx context.Context, inspector *persistent.Inspector, target inspectionTarget, message string) (types.VMInspectionResponse, error)
+
+// inspectorRegistry maps each supported inspector type to its runner
+// Adding a new inspector only requires registering it here
+var inspectorRegistry = map[InspectorType]inspectorRunner{
+	InspectorTypeVirtInspector:    runVirtInspector,
+	InspectorTypeVirtV2VInspector: runVirtV2VInspector,
+}
+
+// ParseInspectorType validates an inspector type against the registry
+func ParseInspectorType(value string) (InspectorType, bool) {
+	inspectorType := InspectorType(value)
+	_, ok := inspectorRegistry[inspectorType]
+	return inspectorType, ok
+}
+
+// SupportedInspectorTypes returns the registered inspector types in sorted order
+func SupportedInspectorTypes() []string {
+	supported := make([]string, 0, len(inspectorRegistry))
+	for inspectorType := range inspectorRegistry {
+		supported = append(supported, string(inspectorType))
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// invalidInspectorTypeResponse builds the 400 error body for an unsupported inspector type
+func invalidInspectorTypeResponse(value string) types.ErrorResponse {
+	return types.ErrorResponse{
+		Error:   "Invalid inspector type",
+		Code:    "INVALID_INSPECTOR_TYPE",
+		Details: fmt.Sprintf("inspector must be one of [%s], got: %s", strings.Join(SupportedInspectorTypes(), ", "), value),
+	}
+}
+
+// runVirtInspector runs virt-inspector on the snapshot disks
+func runVirtInspector(ctx context.Context, inspector *persistent.Inspector, target inspectionTarget, message string) (types.VMInspectionResponse, error) {
+	data, err := inspector.InspectWithVirt(
+		ctx,
+		target.vmName,
+		target.snapshotName,
+		target.datacenter,
+		target.diskInfo,
+	)
+	if err != nil {
+		return types.VMInspectionResponse{}, classifyInspectorError(err)
+	}
+	reportProgress(ctx, PhaseParsing)
+	response := types.NewVirtInspectorResponse(target.vmName, target.snapshotName, message, data)
+	response.MigrationRelevantPackages = migrationRelevantPackages(data)
+	return response, nil
+}
+
+// runVirtV2VInspector runs virt-v2v-inspector on the snapshot disks
+func runVirtV2VInspector(ctx context.Context, inspector *persistent.Inspector, target inspectionTarget, message string) (types.VMInspectionResponse, error) {
+	data, err := inspector.InspectWithVirtV2v(
+		ctx,
+		target.vmName,
+		target.snapshotName,
+		target.datacenter,
+		target.diskInfo,
+		target.sslVerify,
+	)
+	if err != nil {
+		return types.VMInspectionResponse{}, classifyInspectorError(err)
+	}
+	reportProgress(ctx, PhaseParsing)
+	return types.NewVirtV2VInspectorResponse(target.vmName, target.snapshotName, message, data), nil
+}
+
+// classifyInspectorError wraps inspector errors that callers need to tell apart
+// The inspector reports an empty result and nbdkit startup failures as plain errors, so
+// they are recognized by their message
+func classifyInspectorError(err error) error {
+	if contains(err.Error(), "no operating systems found") {
+		return fmt.Errorf("%w: %w", ErrNoOperatingSystem, err)
+	}
+	return classifyNbdkitError(err)
+}
+
+// loadCachedInspection returns the stored inspection result for a snapshot, or nil when
+// the snapshot has not been inspected with the given inspector yet
+// The result is marked as cached and dated with the time it was stored
+func loadCachedInspection(ctx context.Context, db *storage.InspectionDB, inspectorType InspectorType, vmName, snapshotName string) (*types.VMInspectionResponse, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	key := persistent.CacheKey{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+	}
+	message := fmt.Sprintf("Snapshot inspection loaded from stored %s results", inspectorType)
+
+	var response types.VMInspectionResponse
+	var storedAt time.Time
+	switch inspectorType {
+	case InspectorTypeVirtInspector:
+		record, err := db.GetVirtInspectorRecord(ctx, key)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		var data validationtypes.VirtInspectorXML
+		if err := json.Unmarshal([]byte(record.DataJSON), &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal inspection data: %w", err)
+		}
+		response = types.NewVirtInspectorResponse(vmName, snapshotName, message, &data)
+		response.MigrationRelevantPackages = migrationRelevantPackages(&data)
+		storedAt = record.UpdatedAt
+	case InspectorTypeVirtV2VInspector:
+		record, err := db.GetVirtV2VInspectorRecord(ctx, key)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		var data validationtypes.VirtV2VInspectorXML
+		if err := json.Unmarshal([]byte(record.DataJSON), &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal inspection data: %w", err)
+		}
+		response = types.NewVirtV2VInspectorResponse(vmName, snapshotName, message, &data)
+		storedAt = record.UpdatedAt
+	default:
+		return nil, nil
+	}
+
+	response.Cached = true
+	response.InspectedAt = storedAt
+	return &response, nil
+}
+
+// deleteCachedInspection removes the stored result of a snapshot for the given inspector,
+// so the next inspection runs the inspector instead of reusing it
+func deleteCachedInspection(ctx context.Context, db *storage.InspectionDB, inspectorType InspectorType, vmName, snapshotName string) error {
+	if db == nil {
+		return nil
+	}
+
+	key := persistent.CacheKey{
+		VMName:       vmName,
+		SnapshotName: snapshotName,
+	}
+
+	var err error
+	switch inspectorType {
+	case InspectorTypeVirtInspector:
+		_, err = db.DeleteVirtInspectorXML(ctx, key)
+	case InspectorTypeVirtV2VInspector:
+		_, err = db.DeleteVirtV2VInspectorXML(ctx, key)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// defaultJobListLimit is the page size used when listing the job history without a limit
+const defaultJobListLimit = 50
+
+// jobRetention is how long finished jobs are kept in memory before being pruned
+const jobRetention = 24 * time.Hour
+
+// InspectionJob tracks the state of an asynchronous inspection
+type InspectionJob struct {
+	mutex         sync.RWMutex
+	id            string
+	vmName        string
+	snapshotName  string
+	inspectorType string
+	status        string
+	createdAt     time.Time
+	startedAt     *time.Time
+	completedAt   *time.Time
+	result        *types.VMInspectionResponse
+	err           *types.ErrorResponse
+}
+
+// JobRegistry is an in-memory registry of asynchronous inspection jobs
+type JobRegistry struct {
+	jobs sync.Map // job ID -> *InspectionJob
+}
+
+// NewJobRegistry creates a new job registry
+func NewJobRegistry() *JobRegistry {
+	return &JobRegistry{}
+}
+
+// Create registers a new pending job and returns it
+func (r *JobRegistry) Create(vmName, snapshotName, inspectorType string) *InspectionJob {
+	r.prune()
+
+	job := &InspectionJob{
+		id:            uuid.New().String(),
+		vmName:        vmName,
+		snapshotName:  snapshotName,
+		inspectorType: inspectorType,
+		status:        JobStatusPending,
+		createdAt:     time.Now(),
+	}
+	r.jobs.Store(job.id, job)
+	return job
+}
+
+// Get returns the job with the given ID
+func (r *JobRegistry) Get(id string) (*InspectionJob, bool) {
+	value, ok := r.jobs.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return value.(*InspectionJob), true
+}
+
+// prune removes finished jobs older than the retention period
+func (r *JobRegistry) prune() {
+	cutoff := time.Now().Add(-jobRetention)
+	r.jobs.Range(func(key, value interface{}) bool {
+		job := value.(*InspectionJob)
+		job.mutex.RLock()
+		expired := job.completedAt != nil && job.completedAt.Before(cutoff)
+		job.mutex.RUnlock()
+		if expired {
+			r.jobs.Delete(key)
+		}
+		return true
+	})
+}
+
+// ID returns the job ID
+func (j *InspectionJob) ID() string {
+	return j.id
+}
+
+// MarkRunning marks the job as running
+func (j *InspectionJob) MarkRunning() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	now := time.Now()
+	j.status = JobStatusRunning
+	j.startedAt = &now
+}
+
+// Complete marks the job as completed with the given result
+func (j *InspectionJob) Complete(result *types.VMInspectionResponse) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	now := time.Now()
+	j.status = JobStatusCompleted
+	j.completedAt = &now
+	j.result = result
+}
+
+// Fail marks the job as failed with the given error
+func (j *InspectionJob) Fail(errResponse types.ErrorResponse) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	now := time.Now()
+	j.status = JobStatusFailed
+	j.completedAt = &now
+	j.err = &errResponse
+}
+
+// Status returns a snapshot of the job state as an API response
+func (j *InspectionJob) Status() types.JobStatusResponse {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	return types.JobStatusResponse{
+		JobID:         j.id,
+		Status:        j.status,
+		VMName:        j.vmName,
+		SnapshotName:  j.snapshotName,
+		InspectorType: j.inspectorType,
+		CreatedAt:     j.createdAt,
+		StartedAt:     j.startedAt,
+		CompletedAt:   j.completedAt,
+		Result:        j.result,
+		Error:         j.err,
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// Live inspection strategies
+const (
+	// LiveStrategySnapshot inspects a temporary snapshot without memory, removed afterwards
+	LiveStrategySnapshot = "snapshot"
+	// LiveStrategySuspend suspends the VM, inspects its current disks and resumes it
+	LiveStrategySuspend = "suspend"
+)
+
+// InspectLive godoc
+// @Summary Inspect a running VM
+// @Description Inspect a VM without a pre-created snapshot. The "snapshot" strategy (default) inspects a temporary snapshot without memory, which is removed afterwards. The "suspend" strategy suspends a powered-on VM, inspects its current disks and powers it back on; the guest is unavailable for the duration of the inspection. The snapshot is removed, and a suspended VM powered back on, even when the inspection fails. Results are stored under a generated "live-<timestamp>-<random>" snapshot name. With "Accept: text/event-stream" the response is a stream of "progress" events followed by a "result" or "error" event.
+// @Tags vms
+// @Accept json
+// @Produce json,text/event-stream
+// @Param vm query string true "VM name" example("web-server-01")
+// @Param strategy query string false "Live inspection strategy: 'snapshot' (default) or 'suspend'" example("snapshot")
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Param app_filter query string false "Only return applications whose name contains this string (case-insensitive)" example("mysql")
+// @Param include_apps query bool false "Set to false to omit the application lists" example(false)
+// @Param disk_label query string false "Only inspect the disk with this label" example("Hard disk 2")
+// @Param disk_index query int false "Only inspect the disk at this zero-based index among the VM's disks" example(1)
+// @Success 200 {object} types.VMInspectionResponse "Inspection completed successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 422 {object} types.ErrorResponse "VM is encrypted or no operating system found on the disks"
+// @Failure 429 {object} types.ErrorResponse "Too many concurrent inspections"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/vms/inspect-live [post]
+func (h *VMHandler) InspectLive(c *gin.Context) {
+	vmName := c.Query("vm")
+	strategy := c.DefaultQuery("strategy", LiveStrategySnapshot)
+	inspectorParam := c.DefaultQuery("inspector", string(DefaultInspectorType))
+
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?vm=xxx",
+		})
+		return
+	}
+
+	if strategy != LiveStrategySnapshot && strategy != LiveStrategySuspend {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid strategy",
+			Code:    "INVALID_STRATEGY",
+			Details: fmt.Sprintf("strategy must be '%s' or '%s', got: %s", LiveStrategySnapshot, LiveStrategySuspend, strategy),
+		})
+		return
+	}
+
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return
+	}
+
+	// Each run is stored under its own name so results of earlier runs are never served from cache
+	// The random suffix keeps runs started within the same second apart
+	req := inspectionRequest{
+		vmName:        vmName,
+		snapshotName:  "live-" + time.Now().UTC().Format("20060102T150405Z") + "-" + uuid.New().String()[:8],
+		inspectorType: inspectorType,
+	}
+	if err := validateInspectionNames(req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+		return
+	}
+	if req.apps, ok = parseApplicationFilter(c); !ok {
+		return
+	}
+	if req.disk, ok = parseDiskSelector(c); !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"strategy":       strategy,
+		"inspector_type": req.inspectorType,
+	}).Info("Inspecting running VM")
+
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	h.extendWriteDeadline(c, h.inspectionConfig.Timeout)
+
+	// Fail before touching the VM when the inspection could not run anyway
+	if err := h.vmClient.RequireVCenter(ctx); err != nil {
+		h.log(c).WithError(err).Error("inspection is not available on this host")
+		if errors.Is(err, vmware.ErrVCenterRequired) {
+			c.JSON(http.StatusBadRequest, vcenterRequiredResponse(err))
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "vSphere connection unavailable",
+			Code:    "VSPHERE_UNAVAILABLE",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// The cleanup restores the VM even when the inspection failed or the request was cancelled
+	var cleanup func()
+	var failure *inspectionFailure
+	if strategy == LiveStrategySuspend {
+		cleanup, failure = h.suspendForInspection(ctx, &req)
+	} else {
+		cleanup, failure = h.snapshotForInspection(ctx, &req)
+	}
+	if failure != nil {
+		c.JSON(failure.status, failure.response)
+		return
+	}
+	defer cleanup()
+
+	if wantsEventStream(c) {
+		h.streamInspection(c, ctx, req)
+		return
+	}
+
+	response, failure := h.runInspection(ctx, req)
+	if failure != nil {
+		c.JSON(failure.status, failure.response)
+		return
+	}
+
+	h.log(c).WithField("inspector_type", req.inspectorType).Info("Live VM inspection completed successfully")
+	c.JSON(http.StatusOK, req.apps.apply(response))
+}
+
+// snapshotForInspection creates a temporary snapshot without memory for req to inspect
+// req is pinned to the created snapshot by its ID, so another snapshot of the same name is
+// never inspected instead. The returned cleanup removes the snapshot
+func (h *VMHandler) snapshotForInspection(ctx context.Context, req *inspectionRequest) (cleanup func(), failure *inspectionFailure) {
+	logger := h.logger.WithContext(ctx).WithField("snapshot_name", req.snapshotName)
+
+	snapshot, err := h.vmService.CreateSnapshot(ctx, req.vmName, req.snapshotName, "Temporary snapshot for live inspection", false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create live inspection snapshot")
+		return nil, liveInspectionFailure(err, "Failed to create snapshot", "SNAPSHOT_CREATE_FAILED")
+	}
+	logger = logger.WithField("snapshot_moref", snapshot.SnapshotMoref)
+
+	cleanup = func() {
+		if err := h.vmService.RemoveSnapshot(context.WithoutCancel(ctx), req.vmName, snapshot.SnapshotMoref, req.snapshotName); err != nil {
+			logger.WithError(err).Error("Failed to remove live inspection snapshot; remove it manually")
+		}
+	}
+
+	if snapshot.SnapshotMoref == "" {
+		err = errors.New("snapshot task returned no snapshot reference")
+	} else {
+		req.snapshotID, err = h.vmService.FindSnapshotIDByMoref(ctx, req.vmName, snapshot.SnapshotMoref)
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to resolve live inspection snapshot")
+		cleanup()
+		return nil, liveInspectionFailure(err, "Failed to resolve the created snapshot", "SNAPSHOT_CREATE_FAILED")
+	}
+
+	return cleanup, nil
+}
+
+// suspendForInspection suspends a powered-on VM so req can inspect its current disks
+// The returned cleanup powers the VM back on; VMs that were not running are left as they are
+func (h *VMHandler) suspendForInspection(ctx context.Context, req *inspectionRequest) (cleanup func(), failure *inspectionFailure) {
+	req.currentState = true
+	req.allowSuspended = true
+
+	logger := h.logger.WithContext(ctx).WithField("vm_name", req.vmName)
+
+	state, err := h.vmService.GetPowerState(ctx, req.vmName)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get VM power state")
+		return nil, liveInspectionFailure(err, "Failed to get VM power state", "VM_POWER_STATE_FAILED")
+	}
+	if state != vimtypes.VirtualMachinePowerStatePoweredOn {
+		logger.WithField("power_state", state).Info("VM is not running, inspecting without suspending it")
+		return func() {}, nil
+	}
+
+	if err := h.vmService.SuspendVM(ctx, req.vmName); err != nil {
+		logger.WithError(err).Error("Failed to suspend VM")
+		// The suspend task may have completed even though waiting for it failed
+		h.resumeVM(ctx, req.vmName)
+		return nil, liveInspectionFailure(err, "Failed to suspend VM", "VM_SUSPEND_FAILED")
+	}
+
+	return func() {
+		h.resumeVM(ctx, req.vmName)
+	}, nil
+}
+
+// resumeVM powers a VM suspended for a live inspection back on
+// Only a VM confirmed to be suspended is powered on: a VM someone powered off in the
+// meantime stays off, and an unknown state is left for an operator to check
+func (h *VMHandler) resumeVM(ctx context.Context, vmName string) {
+	ctx = context.WithoutCancel(ctx)
+	logger := h.logger.WithContext(ctx).WithField("vm_name", vmName)
+
+	state, err := h.vmService.GetPowerState(ctx, vmName)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get VM power state after live inspection; check the VM and power it on manually if it is still suspended")
+		return
+	}
+	if state != vimtypes.VirtualMachinePowerStateSuspended {
+		if state != vimtypes.VirtualMachinePowerStatePoweredOn {
+			logger.WithField("power_state", state).Warn("VM is no longer suspended after live inspection; leaving it as it is")
+		}
+		return
+	}
+	if err := h.vmService.PowerOnVM(ctx, vmName); err != nil {
+		logger.WithError(err).Error("Failed to power the VM back on after live inspection; power it on manually")
+	}
+}
+
+// liveInspectionFailure builds the failure for a VM that could not be prepared for inspection
+func liveInspectionFailure(err error, message, code string) *inspectionFailure {
+	if isConnectionError(err) {
+		return &inspectionFailure{
+			status: http.StatusServiceUnavailable,
+			response: types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			},
+		}
+	}
+	if isNotFoundError(err) {
+		return &inspectionFailure{
+			status: http.StatusNotFound,
+			response: types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			},
+		}
+	}
+	return &inspectionFailure{
+		status: http.StatusInternalServerError,
+		response: types.ErrorResponse{
+			Error:   message,
+			Code:    code,
+			Details: err.Error(),
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// newSimulatorHandler returns a VMHandler whose VM service and client are connected to a vcsim
+// inventory, and the vSphere client to set VMs up with; both stop when the test ends
+func newSimulatorHandler(t *testing.T) (*VMHandler, *vmware.Client) {
+	t.Helper()
+
+	model := simulator.VPX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("failed to create simulator inventory: %v", err)
+	}
+	server := model.Service.NewServer()
+	t.Cleanup(func() {
+		server.Close()
+		model.Remove()
+	})
+
+	cfg := config.DefaultConfig().VMware
+	u := *server.URL
+	cfg.Username = u.User.Username()
+	cfg.Password, _ = u.User.Password()
+	u.User = nil
+	cfg.VCenterURL = u.String()
+	cfg.InsecureSkipVerify = true
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	client := vmware.NewClient(cfg, logger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect to the simulator: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Disconnect(context.Background())
+	})
+
+	handler := newTestHandler(config.DefaultConfig().Inspection)
+	handler.vmService = vmware.NewVMService(client, logger)
+	handler.vmClient = client
+	return handler, client
+}
+
+// setPowerState puts a simulator VM in the given power state
+func setPowerState(t *testing.T, client *vmware.Client, vmName string, state vimtypes.VirtualMachinePowerState) {
+	t.Helper()
+	ctx := context.Background()
+
+	gc, err := client.GetClient(ctx)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	finder := find.NewFinder(gc.Client)
+	datacenter, err := finder.DefaultDatacenter(ctx)
+	if err != nil {
+		t.Fatalf("DefaultDatacenter() error = %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+	vm, err := finder.VirtualMachine(ctx, vmName)
+	if err != nil {
+		t.Fatalf("VirtualMachine(%q) error = %v", vmName, err)
+	}
+
+	switch state {
+	case vimtypes.VirtualMachinePowerStateSuspended:
+		task, err := vm.Suspend(ctx)
+		if err == nil {
+			err = task.Wait(ctx)
+		}
+		if err != nil {
+			t.Fatalf("Suspend(%q) error = %v", vmName, err)
+		}
+	case vimtypes.VirtualMachinePowerStatePoweredOff:
+		task, err := vm.PowerOff(ctx)
+		if err == nil {
+			err = task.Wait(ctx)
+		}
+		if err != nil {
+			t.Fatalf("PowerOff(%q) error = %v", vmName, err)
+		}
+	}
+}
+
+func TestResumeVM(t *testing.T) {
+	tests := []struct {
+		name  string
+		state vimtypes.VirtualMachinePowerState
+		want  vimtypes.VirtualMachinePowerState
+	}{
+		{name: "suspended VM is powered back on", state: vimtypes.VirtualMachinePowerStateSuspended, want: vimtypes.VirtualMachinePowerStatePoweredOn},
+		{name: "running VM is left running", state: vimtypes.VirtualMachinePowerStatePoweredOn, want: vimtypes.VirtualMachinePowerStatePoweredOn},
+		{name: "VM powered off meanwhile stays off", state: vimtypes.VirtualMachinePowerStatePoweredOff, want: vimtypes.VirtualMachinePowerStatePoweredOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, client := newSimulatorHandler(t)
+			ctx := context.Background()
+
+			const vmName = "DC0_H0_VM0"
+			setPowerState(t, client, vmName, tt.state)
+			handler.resumeVM(ctx, vmName)
+
+			got, err := handler.vmService.GetPowerState(ctx, vmName)
+			if err != nil {
+				t.Fatalf("GetPowerState() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resumeVM() left the VM %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeVMUnknownPowerState(t *testing.T) {
+	handler, _ := newSimulatorHandler(t)
+	logger, hook := test.NewNullLogger()
+	handler.logger = logger
+
+	// The power state of a missing VM can't be read, so resumeVM must leave it to an operator
+	handler.resumeVM(context.Background(), "missing")
+
+	entries := hook.AllEntries()
+	if len(entries) != 1 || entries[0].Level != logrus.ErrorLevel {
+		t.Fatalf("resumeVM() logged %d entries, want one error", len(entries))
+	}
+	if entries[0].Message == "Failed to power the VM back on after live inspection; power it on manually" {
+		t.Errorf("resumeVM() tried to power on a VM in an unknown state")
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// Categories of migration-relevant packages
+const (
+	MigrationCategoryVMwareTools = "vmware-tools"
+	MigrationCategoryVirtIO      = "virtio"
+	MigrationCategoryCloudInit   = "cloud-init"
+)
+
+// migrationPackageRule flags applications whose lower-cased name contains one of the keywords
+type migrationPackageRule struct {
+	category string
+	keywords []string
+}
+
+// migrationPackageRules lists the packages and drivers that matter when migrating a guest:
+// VMware Tools must be removed, VirtIO drivers are needed to boot on KVM and cloud-init
+// (cloudbase-init on Windows) reconfigures the guest on first boot
+// Names cover both Linux packages and Windows registry entries
+var migrationPackageRules = []migrationPackageRule{
+	{category: MigrationCategoryVMwareTools, keywords: vmwareToolsPackages},
+	{category: MigrationCategoryVirtIO, keywords: []string{"virtio", "viostor", "vioscsi", "netkvm", "qemu-guest-agent", "qemu guest agent"}},
+	{category: MigrationCategoryCloudInit, keywords: []string{"cloud-init", "cloudbase-init"}},
+}
+
+// migrationRelevantPackages returns the installed applications of an inspection result
+// that are relevant for migration planning, in the order they were inspected
+func migrationRelevantPackages(result interface{}) []types.MigrationPackage {
+	data, err := decodeInspection(result)
+	if err != nil {
+		return nil
+	}
+
+	var packages []types.MigrationPackage
+	for _, app := range extractApplications(data) {
+		name := strings.ToLower(app[0])
+		for _, rule := range migrationPackageRules {
+			if containsAny(name, rule.keywords) {
+				packages = append(packages, types.MigrationPackage{
+					Name:     app[0],
+					Version:  app[1],
+					Category: rule.category,
+				})
+				break
+			}
+		}
+	}
+	return packages
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+func TestMigrationRelevantPackages(t *testing.T) {
+	tests := []struct {
+		name string
+		apps []string
+		want []types.MigrationPackage
+	}{
+		{
+			name: "Linux guest",
+			apps: []string{"bash", "open-vm-tools", "qemu-guest-agent", "cloud-init", "openssh-server"},
+			want: []types.MigrationPackage{
+				{Name: "open-vm-tools", Version: "1.0", Category: MigrationCategoryVMwareTools},
+				{Name: "qemu-guest-agent", Version: "1.0", Category: MigrationCategoryVirtIO},
+				{Name: "cloud-init", Version: "1.0", Category: MigrationCategoryCloudInit},
+			},
+		},
+		{
+			name: "Windows guest",
+			apps: []string{"Mozilla Firefox", "VMware Tools", "Red Hat VirtIO SCSI pass-through controller", "QEMU Guest Agent", "Cloudbase-Init 1.1.4"},
+			want: []types.MigrationPackage{
+				{Name: "VMware Tools", Version: "1.0", Category: MigrationCategoryVMwareTools},
+				{Name: "Red Hat VirtIO SCSI pass-through controller", Version: "1.0", Category: MigrationCategoryVirtIO},
+				{Name: "QEMU Guest Agent", Version: "1.0", Category: MigrationCategoryVirtIO},
+				{Name: "Cloudbase-Init 1.1.4", Version: "1.0", Category: MigrationCategoryCloudInit},
+			},
+		},
+		{
+			name: "Windows VirtIO drivers",
+			apps: []string{"viostor", "vioscsi", "netkvm"},
+			want: []types.MigrationPackage{
+				{Name: "viostor", Version: "1.0", Category: MigrationCategoryVirtIO},
+				{Name: "vioscsi", Version: "1.0", Category: MigrationCategoryVirtIO},
+				{Name: "netkvm", Version: "1.0", Category: MigrationCategoryVirtIO},
+			},
+		},
+		{
+			name: "no relevant packages",
+			apps: []string{"bash", "coreutils"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := migrationRelevantPackages(inspectionWithApps(tt.apps...))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("migrationRelevantPackages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
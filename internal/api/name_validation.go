@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// unsafeNameCharacters are rejected in names passed to the inspectors: quotes, escapes,
+// command substitution, command separators and redirections
+// Spaces, parentheses and other characters common in vSphere names are allowed
+const unsafeNameCharacters = "'\"`\\$;&|<>"
+
+// maxNameLength is the longest name vSphere accepts for VMs and snapshots, in characters
+const maxNameLength = 80
+
+// validateName checks that a VM, snapshot or datacenter name is safe to pass to the
+// inspectors, which embed it in vpx:// URLs and inspector command lines
+// kind names the field in the returned error
+func validateName(kind, name string) error {
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("%s must be valid UTF-8, got: %q", kind, name)
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return fmt.Errorf("%s must be at most %d characters long", kind, maxNameLength)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%s must not contain control characters, got: %q", kind, name)
+		}
+		if strings.ContainsRune(unsafeNameCharacters, r) {
+			return fmt.Errorf("%s must not contain any of %s, got: %q", kind, unsafeNameCharacters, name)
+		}
+	}
+	return nil
+}
+
+// validateInspectionNames checks the names of an inspection request
+func validateInspectionNames(req inspectionRequest) error {
+	if err := validateName("vm", req.vmName); err != nil {
+		return err
+	}
+	if err := validateName("snapshot", req.snapshotName); err != nil {
+		return err
+	}
+	return validateName("datacenter", req.datacenter)
+}
+
+// invalidNameResponse builds the error response for a name rejected by validateName
+func invalidNameResponse(err error) types.ErrorResponse {
+	return types.ErrorResponse{
+		Error:   "Invalid name",
+		Code:    "INVALID_NAME",
+		Details: err.Error(),
+	}
+}
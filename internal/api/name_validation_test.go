@@ -0,0 +1,76 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "plain", value: "web-server-01"},
+		{name: "spaces and parentheses", value: "Web Server (prod) #2"},
+		{name: "empty", value: ""},
+		{name: "80 ASCII characters", value: strings.Repeat("a", 80)},
+		{name: "81 ASCII characters", value: strings.Repeat("a", 81), wantErr: true},
+		{name: "80 multi-byte characters", value: strings.Repeat("ü", 80)},
+		{name: "80 four-byte characters", value: strings.Repeat("😀", 80)},
+		{name: "81 multi-byte characters", value: strings.Repeat("ü", 81), wantErr: true},
+		{name: "CJK name", value: "生产数据库服务器"},
+		{name: "single quote", value: "web'; rm -rf /", wantErr: true},
+		{name: "double quote", value: `web"01`, wantErr: true},
+		{name: "command substitution", value: "web$(id)", wantErr: true},
+		{name: "backticks", value: "web`id`", wantErr: true},
+		{name: "backslash", value: `web\01`, wantErr: true},
+		{name: "pipe", value: "web|cat", wantErr: true},
+		{name: "redirection", value: "web>out", wantErr: true},
+		{name: "ampersand", value: "web&", wantErr: true},
+		{name: "newline", value: "web\n01", wantErr: true},
+		{name: "NUL byte", value: "web\x0001", wantErr: true},
+		{name: "escape sequence", value: "web\x1b[2J", wantErr: true},
+		{name: "C1 control character", value: "web\u008501", wantErr: true},
+		{name: "invalid UTF-8", value: "web\xff01", wantErr: true},
+		{name: "truncated UTF-8 sequence", value: "web\xc3", wantErr: true},
+		{name: "overlong encoding of a slash", value: "web\xc0\xaf01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateName("vm", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateName(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInspectionNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     inspectionRequest
+		wantErr string
+	}{
+		{name: "valid", req: inspectionRequest{vmName: "web01", snapshotName: "before upgrade", datacenter: "DC1"}},
+		{name: "unsafe VM name", req: inspectionRequest{vmName: "web$(id)", snapshotName: "snap"}, wantErr: "vm must not contain"},
+		{name: "unsafe snapshot name", req: inspectionRequest{vmName: "web01", snapshotName: "snap;reboot"}, wantErr: "snapshot must not contain"},
+		{name: "unsafe datacenter", req: inspectionRequest{vmName: "web01", snapshotName: "snap", datacenter: "DC1\n"}, wantErr: "datacenter must not contain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInspectionNames(tt.req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateInspectionNames() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateInspectionNames() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNbdkitStartup is returned when nbdkit cannot start or cannot open the disks through VDDK
+var ErrNbdkitStartup = errors.New("nbdkit failed to start")
+
+// NbdkitStartupError is an nbdkit startup failure with a hint on how to fix its likely cause
+// The inspector only reports nbdkit's stderr, which rarely names the actual problem
+type NbdkitStartupError struct {
+	Cause string
+	Hint  string
+	Err   error
+}
+
+// Error describes the likely cause along with the inspector error
+func (e *NbdkitStartupError) Error() string {
+	return fmt.Sprintf("%v: %s: %v", ErrNbdkitStartup, e.Cause, e.Err)
+}
+
+// Unwrap allows errors.Is to match ErrNbdkitStartup and the inspector error
+func (e *NbdkitStartupError) Unwrap() []error {
+	return []error{ErrNbdkitStartup, e.Err}
+}
+
+// nbdkitCauseDiskLocked is the cause of the only startup failure that may clear up on retry
+const nbdkitCauseDiskLocked = "disk files are locked"
+
+// nbdkitFailure maps nbdkit and VDDK stderr messages to a likely cause and a remediation hint
+type nbdkitFailure struct {
+	patterns []string
+	cause    string
+	hint     string
+}
+
+// nbdkitFailures is ordered from the most to the least specific messages
+// Patterns avoid bare option names (libdir, thumbprint), which also appear in the nbdkit
+// command line the inspector may include in its error
+var nbdkitFailures = []nbdkitFailure{
+	{
+		patterns: []string{"libvixdisklib.so", "vddk library", "vix_e_library"},
+		cause:    "VDDK library not found",
+		hint:     "set inspection.vddk_libdir to the directory VDDK was extracted to; it must contain lib64/libvixDiskLib.so (see VDDK Setup)",
+	},
+	{
+		patterns: []string{"cannot open plugin", "cannot load plugin", "unknown plugin"},
+		cause:    "nbdkit VDDK plugin not found",
+		hint:     "install the nbdkit VDDK plugin (nbdkit-vddk-plugin) on the service host",
+	},
+	{
+		patterns: []string{"nbdkit: not found", "nbdkit: command not found", "\"nbdkit\": executable file not found"},
+		cause:    "nbdkit not installed",
+		hint:     "install nbdkit on the service host or in the container image",
+	},
+	{
+		patterns: []string{"thumbprint mismatch", "thumbprint does not match", "certificate verify failed", "ssl exception"},
+		cause:    "vCenter certificate verification failed",
+		hint:     "the vCenter or ESXi certificate changed or is not trusted; check vmware.ca_cert_file and vmware.insecure_skip_verify, and compare the thumbprint reported by the preflight endpoint",
+	},
+	{
+		patterns: []string{"incorrect user name or password", "cannot complete login", "vix_e_not_authorized", "noauthorized"},
+		cause:    "vCenter login rejected",
+		hint:     "check vmware.username and vmware.password; VDDK logs in separately from the API session",
+	},
+	{
+		patterns: []string{"nopermission", "vix_e_host_user_permissions", "access rights"},
+		cause:    "missing vSphere privileges",
+		hint:     "grant the service account the privileges listed under vSphere Permissions (Required for VDDK Inspection)",
+	},
+	{
+		patterns: []string{"permission denied"},
+		cause:    "local permission denied",
+		hint:     "the service user must be able to read inspection.vddk_libdir and write to its temporary directory (check SELinux labels for container volumes)",
+	},
+	{
+		patterns: []string{"file is locked", "failed to lock"},
+		cause:    nbdkitCauseDiskLocked,
+		hint:     "another process holds the disks (e.g. a backup job or a running consolidation); retry once it has finished",
+	},
+	{
+		patterns: []string{"exited immediately", "process exited", "failed to start nbdkit", "nbdkit did not start"},
+		cause:    "nbdkit exited during startup",
+		hint:     "run the preflight endpoint to check the nbdkit, VDDK and vCenter prerequisites; the inspector error holds nbdkit's stderr",
+	},
+}
+
+// classifyNbdkitError wraps nbdkit startup failures in an NbdkitStartupError
+// Errors that don't come from nbdkit or VDDK are returned unchanged
+func classifyNbdkitError(err error) error {
+	message := strings.ToLower(err.Error())
+	if !strings.Contains(message, "nbdkit") && !strings.Contains(message, "vddk") && !strings.Contains(message, "vixdisklib") {
+		return err
+	}
+
+	for _, failure := range nbdkitFailures {
+		for _, pattern := range failure.patterns {
+			if strings.Contains(message, pattern) {
+				return &NbdkitStartupError{Cause: failure.cause, Hint: failure.hint, Err: err}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClassifyNbdkitError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       string
+		wantCause string // empty when the error must be returned unchanged
+	}{
+		{
+			name:      "missing VDDK library",
+			err:       "virt-inspector: nbdkit: vddk[1]: error: libvixDiskLib.so.8: cannot open shared object file: No such file or directory",
+			wantCause: "VDDK library not found",
+		},
+		{
+			name:      "missing VDDK plugin",
+			err:       "nbdkit: error: cannot open plugin \"vddk\": /usr/lib64/nbdkit/plugins/nbdkit-vddk-plugin.so: cannot open shared object file",
+			wantCause: "nbdkit VDDK plugin not found",
+		},
+		{
+			name:      "nbdkit not installed",
+			err:       "exec: \"nbdkit\": executable file not found in $PATH",
+			wantCause: "nbdkit not installed",
+		},
+		{
+			name:      "thumbprint mismatch",
+			err:       "nbdkit: vddk[1]: error: VixDiskLib: Thumbprint mismatch for host vcenter.example.com",
+			wantCause: "vCenter certificate verification failed",
+		},
+		{
+			name:      "login rejected",
+			err:       "nbdkit: vddk[1]: error: VixDiskLib_Connect: Cannot complete login due to an incorrect user name or password",
+			wantCause: "vCenter login rejected",
+		},
+		{
+			name:      "missing privileges",
+			err:       "nbdkit: vddk[1]: error: VixDiskLib_Open: VIX_E_HOST_USER_PERMISSIONS",
+			wantCause: "missing vSphere privileges",
+		},
+		{
+			name:      "local permission denied",
+			err:       "nbdkit: error: /opt/vmware-vix-disklib-distrib/lib64: Permission denied",
+			wantCause: "local permission denied",
+		},
+		{
+			name:      "locked disk",
+			err:       "nbdkit: vddk[1]: error: VixDiskLib_Open: [LocalDS_0] web01/web01.vmdk: Failed to lock the file",
+			wantCause: nbdkitCauseDiskLocked,
+		},
+		{
+			name:      "startup exit",
+			err:       "nbdkit exited immediately with status 1",
+			wantCause: "nbdkit exited during startup",
+		},
+		{
+			// The command line names the libdir option, which must not be taken for a library error
+			name:      "command line with option names",
+			err:       "nbdkit --exit-with-parent vddk libdir=/opt/vddk thumbprint=AA:BB exited immediately",
+			wantCause: "nbdkit exited during startup",
+		},
+		{name: "unrecognised nbdkit error", err: "nbdkit: vddk[1]: error: unexpected failure"},
+		{name: "not an nbdkit error", err: "virt-inspector: no operating systems found; permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errors.New(tt.err)
+			got := classifyNbdkitError(err)
+
+			var startupErr *NbdkitStartupError
+			if tt.wantCause == "" {
+				if got != err {
+					t.Errorf("classifyNbdkitError(%q) = %v, want the error unchanged", tt.err, got)
+				}
+				return
+			}
+			if !errors.As(got, &startupErr) {
+				t.Fatalf("classifyNbdkitError(%q) = %v, want an *NbdkitStartupError", tt.err, got)
+			}
+			if startupErr.Cause != tt.wantCause {
+				t.Errorf("classifyNbdkitError(%q) cause = %q, want %q", tt.err, startupErr.Cause, tt.wantCause)
+			}
+			if startupErr.Hint == "" {
+				t.Errorf("classifyNbdkitError(%q) has no hint", tt.err)
+			}
+			if !errors.Is(got, ErrNbdkitStartup) || !errors.Is(got, err) {
+				t.Errorf("classifyNbdkitError(%q) does not wrap ErrNbdkitStartup and the original error", tt.err)
+			}
+			if !strings.Contains(got.Error(), tt.err) {
+				t.Errorf("classifyNbdkitError(%q).Error() = %q, want the original message", tt.err, got.Error())
+			}
+		})
+	}
+}
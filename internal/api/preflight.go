@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// PreflightInspection godoc
+// @Summary Check inspection prerequisites for a VM snapshot
+// @Description Verify that an inspection of the snapshot can run, without inspecting it: the nbdkit and inspector binaries are installed, VDDK is present, vCenter is reachable with the configured credentials, the snapshot disks can be resolved and the vCenter certificate thumbprint can be read
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param vm query string false "Original VM name (or set moref or uuid)" example("web-server-01")
+// @Param moref query string false "VM managed object reference, to target one of several VMs with the same name" example("vm-42")
+// @Param uuid query string false "VM BIOS UUID, to target one of several VMs with the same name" example("42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param snapshot_id query int false "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name" example(3)
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Param datacenter query string false "Datacenter containing the VM (defaults to the default datacenter)" example("Datacenter1")
+// @Success 200 {object} types.PreflightResponse "Checklist of prerequisites; ready is false when any check failed"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Router /api/v1/vms/inspect-snapshot/preflight [post]
+func (h *VMHandler) PreflightInspection(c *gin.Context) {
+	req, ok := h.parseInspectionParams(c)
+	if !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"inspector_type": req.inspectorType,
+		"datacenter":     req.datacenter,
+	}).Info("Checking inspection prerequisites")
+
+	ctx := c.Request.Context()
+	inspectorPath := h.inspectionConfig.VirtInspectorPath
+	if req.inspectorType == InspectorTypeVirtV2VInspector {
+		inspectorPath = h.inspectionConfig.VirtV2vInspectorPath
+	}
+
+	checks := []types.PreflightCheck{
+		binaryCheck("nbdkit", ""),
+		binaryCheck(string(req.inspectorType), inspectorPath),
+		vddkCheck(h.inspectionConfig.VDDKLibDir),
+	}
+
+	vcenter := h.vcenterCheck(ctx)
+	checks = append(checks, vcenter)
+	if vcenter.Passed {
+		checks = append(checks, h.snapshotCheck(ctx, req), h.thumbprintCheck(ctx))
+	} else {
+		checks = append(checks,
+			types.PreflightCheck{Name: "snapshot", Message: "Skipped: vCenter is not available"},
+			types.PreflightCheck{Name: "thumbprint", Message: "Skipped: vCenter is not available"},
+		)
+	}
+
+	ready := true
+	for _, check := range checks {
+		if !check.Passed {
+			ready = false
+		}
+	}
+
+	h.log(c).WithField("ready", ready).Info("Inspection prerequisites checked")
+
+	c.JSON(http.StatusOK, types.PreflightResponse{
+		VMName:        req.vmName,
+		SnapshotName:  req.snapshotName,
+		InspectorType: string(req.inspectorType),
+		Checks:        checks,
+		Ready:         ready,
+	})
+}
+
+// binaryCheck verifies that an executable is available at path, or on the system PATH when path is empty
+func binaryCheck(name, path string) types.PreflightCheck {
+	if path == "" {
+		path = name
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return types.PreflightCheck{Name: name, Message: err.Error()}
+	}
+	return types.PreflightCheck{Name: name, Passed: true, Message: "Found at " + resolved}
+}
+
+// vddkCheck verifies that the VDDK disk library is installed in libDir
+func vddkCheck(libDir string) types.PreflightCheck {
+	if libDir == "" {
+		return types.PreflightCheck{Name: "vddk", Message: "inspection.vddk_libdir is not configured"}
+	}
+	libs, _ := filepath.Glob(filepath.Join(libDir, "lib64", "libvixDiskLib.so*"))
+	if len(libs) == 0 {
+		return types.PreflightCheck{Name: "vddk", Message: fmt.Sprintf("libvixDiskLib.so not found in %s", filepath.Join(libDir, "lib64"))}
+	}
+	return types.PreflightCheck{Name: "vddk", Passed: true, Message: "Found " + libs[0]}
+}
+
+// vcenterCheck verifies that the service can log in to vCenter with the configured credentials
+func (h *VMHandler) vcenterCheck(ctx context.Context) types.PreflightCheck {
+	if err := h.vmClient.RequireVCenter(ctx); err != nil {
+		if errors.Is(err, vmware.ErrVCenterRequired) {
+			return types.PreflightCheck{Name: "vcenter", Message: "Connected to a standalone ESXi host; inspection requires vCenter"}
+		}
+		return types.PreflightCheck{Name: "vcenter", Message: err.Error()}
+	}
+	return types.PreflightCheck{Name: "vcenter", Passed: true, Message: "Logged in to " + h.vmClient.GetVCenterURL()}
+}
+
+// snapshotCheck verifies that the snapshot disks can be resolved, as an inspection would
+func (h *VMHandler) snapshotCheck(ctx context.Context, req inspectionRequest) types.PreflightCheck {
+	resolved, err := h.vmService.ResolveVM(ctx, vmware.VMRef{Name: req.vmName, Moref: req.vmMoref}, req.datacenter)
+	if err != nil {
+		return types.PreflightCheck{Name: "snapshot", Message: err.Error()}
+	}
+	diskInfo, err := h.vmService.GetSnapshotDiskInfo(ctx, vmware.VMRef{Moref: resolved.Moref}, req.snapshotName, req.snapshotID, resolved.Datacenter)
+	if err != nil {
+		return types.PreflightCheck{Name: "snapshot", Message: err.Error()}
+	}
+	return types.PreflightCheck{
+		Name:    "snapshot",
+		Passed:  true,
+		Message: fmt.Sprintf("Found %d disk(s) in datacenter '%s'", len(diskInfo.DiskPaths), resolved.Datacenter),
+	}
+}
+
+// thumbprintCheck verifies that the vCenter certificate thumbprint VDDK needs can be read
+func (h *VMHandler) thumbprintCheck(ctx context.Context) types.PreflightCheck {
+	thumbprint, err := h.vmClient.FetchThumbprint(ctx)
+	if err != nil {
+		return types.PreflightCheck{Name: "thumbprint", Message: err.Error()}
+	}
+	return types.PreflightCheck{Name: "thumbprint", Passed: true, Message: thumbprint}
+}
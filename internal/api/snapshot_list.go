@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// Snapshot list formats accepted by the format query parameter
+const (
+	snapshotFormatFlat = "flat"
+	snapshotFormatTree = "tree"
+)
+
+// Snapshot create time orders accepted by the order query parameter
+const (
+	snapshotOrderAsc  = "asc"
+	snapshotOrderDesc = "desc"
+)
+
+// snapshotListOptions controls how GET /vms/:name/snapshots lays out the snapshots
+type snapshotListOptions struct {
+	format string // flat or tree
+	order  string // asc or desc by create time; empty keeps the flat list in tree order
+}
+
+// parseSnapshotListOptions reads the format and order query parameters
+// It writes a 400 response and returns false when a parameter is invalid
+func parseSnapshotListOptions(c *gin.Context) (snapshotListOptions, bool) {
+	options := snapshotListOptions{
+		format: strings.ToLower(c.DefaultQuery("format", snapshotFormatFlat)),
+		order:  strings.ToLower(c.Query("order")),
+	}
+
+	if options.format != snapshotFormatFlat && options.format != snapshotFormatTree {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid format parameter",
+			Code:    "INVALID_SNAPSHOT_FORMAT",
+			Details: fmt.Sprintf("format must be one of [%s, %s], got: %s", snapshotFormatFlat, snapshotFormatTree, c.Query("format")),
+		})
+		return snapshotListOptions{}, false
+	}
+
+	if options.order != "" && options.order != snapshotOrderAsc && options.order != snapshotOrderDesc {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid order parameter",
+			Code:    "INVALID_SNAPSHOT_ORDER",
+			Details: fmt.Sprintf("order must be one of [%s, %s], got: %s", snapshotOrderAsc, snapshotOrderDesc, c.Query("order")),
+		})
+		return snapshotListOptions{}, false
+	}
+
+	return options, true
+}
+
+// snapshotList returns the snapshots of a listing in the requested format and order
+// Flat lists keep the depth-first tree order unless an order is given; trees always order
+// siblings by create time, oldest first by default
+func (o snapshotListOptions) snapshotList(result *vmware.VMSnapshotListResult) []types.VMSnapshot {
+	snapshots := convertSnapshots(result.Snapshots)
+	if o.format == snapshotFormatTree {
+		snapshots = convertSnapshots(result.Tree)
+	}
+
+	if o.order != "" {
+		sortSnapshots(snapshots, o.order == snapshotOrderDesc)
+	}
+	return snapshots
+}
+
+// convertSnapshots converts service snapshots, with their children, to API snapshots
+func convertSnapshots(snapshots []vmware.VMSnapshotInfo) []types.VMSnapshot {
+	result := make([]types.VMSnapshot, 0, len(snapshots))
+	for _, snap := range snapshots {
+		converted := types.VMSnapshot{
+			Name:        snap.Name,
+			Description: snap.Description,
+			CreateTime:  snap.CreateTime,
+			State:       snap.State,
+			Quiesced:    snap.Quiesced,
+			ID:          snap.ID,
+			ParentID:    snap.ParentID,
+			Depth:       snap.Depth,
+			IsCurrent:   snap.IsCurrent,
+		}
+		if len(snap.Children) > 0 {
+			converted.Children = convertSnapshots(snap.Children)
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+// sortSnapshots orders snapshots by create time, and their children at every level of a tree
+func sortSnapshots(snapshots []types.VMSnapshot, newestFirst bool) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if newestFirst {
+			return snapshots[i].CreateTime.After(snapshots[j].CreateTime)
+		}
+		return snapshots[i].CreateTime.Before(snapshots[j].CreateTime)
+	})
+	for i := range snapshots {
+		sortSnapshots(snapshots[i].Children, newestFirst)
+	}
+}
@@ -1,13 +1,26 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/kubev2v/vm-migration-detective/pkg/checks"
 	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/metrics"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/requestid"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/storage"
 	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
 	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
 	"github.com/sirupsen/logrus"
@@ -15,46 +28,232 @@ import (
 
 // VMHandler handles VM-related API requests
 type VMHandler struct {
-	vmService *vmware.VMService
-	vmClient  *vmware.Client
-	inspector *persistent.Inspector
-	logger    *logrus.Logger
+	vmService        *vmware.VMService
+	vmClient         *vmware.Client
+	inspector        *persistent.Inspector
+	inspectionDB     *storage.InspectionDB
+	jobs             *JobRegistry
+	history          *storage.JobHistoryDB
+	artifacts        *storage.ArtifactStore
+	inspectionConfig config.InspectionConfig
+	logger           *logrus.Logger
+
+	// inspectionLocks keeps concurrent requests from inspecting the same snapshot twice
+	inspectionLocks *inspectionLocks
+
+	// inspectionSlots bounds the inspections running at the same time
+	inspectionSlots *inspectionSlots
+
+	// baseCtx is cancelled on shutdown to abort running inspections
+	baseCtx     context.Context
+	inspections sync.WaitGroup
+
+	// shutdownMu orders inspections.Add against the inspections.Wait of WaitForInspections;
+	// once shuttingDown is set, no inspection is registered anymore
+	shutdownMu   sync.Mutex
+	shuttingDown bool
 }
 
 // NewVMHandler creates a new VM handler instance
-func NewVMHandler(vmService *vmware.VMService, vmClient *vmware.Client, inspector *persistent.Inspector, logger *logrus.Logger) *VMHandler {
+// Running inspections are cancelled when ctx is done
+func NewVMHandler(ctx context.Context, vmService *vmware.VMService, vmClient *vmware.Client, inspector *persistent.Inspector, inspectionDB *storage.InspectionDB, jobs *JobRegistry, history *storage.JobHistoryDB, artifacts *storage.ArtifactStore, inspectionConfig config.InspectionConfig, logger *logrus.Logger) *VMHandler {
 	return &VMHandler{
-		vmService: vmService,
-		vmClient:  vmClient,
-		inspector: inspector,
-		logger:    logger,
+		baseCtx:          ctx,
+		vmService:        vmService,
+		vmClient:         vmClient,
+		inspector:        inspector,
+		inspectionDB:     inspectionDB,
+		jobs:             jobs,
+		history:          history,
+		artifacts:        artifacts,
+		inspectionConfig: inspectionConfig,
+		logger:           logger,
+		inspectionLocks:  newInspectionLocks(),
+		inspectionSlots:  newInspectionSlots(inspectionConfig.MaxConcurrentInspections, inspectionConfig.QueueTimeout),
 	}
 }
 
+// log returns a logger entry that carries the request ID of the request
+func (h *VMHandler) log(c *gin.Context) *logrus.Entry {
+	return h.logger.WithContext(c.Request.Context())
+}
+
 // ListVMs godoc
 // @Summary List all virtual machines
-// @Description Get a list of all virtual machines with optional name filtering
+// @Description Get a list of all virtual machines with optional name, datacenter and cluster filtering
 // @Tags vms
 // @Accept json
 // @Produce json
 // @Param name_contains query string false "Filter VMs where name contains this string" example("web")
+// @Param datacenter query string false "Datacenter to list VMs from (defaults to the default datacenter)" example("Datacenter1")
+// @Param cluster query string false "Only list VMs in this cluster" example("Cluster1")
 // @Success 200 {object} types.VMListResponse "List of virtual machines"
+// @Failure 404 {object} types.ErrorResponse "Datacenter or cluster not found"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
 // @Router /api/v1/vms [get]
 func (h *VMHandler) ListVMs(c *gin.Context) {
 	nameContains := c.Query("name_contains")
+	datacenter := c.Query("datacenter")
+	cluster := c.Query("cluster")
 
-	h.logger.WithField("name_contains", nameContains).Info("Listing VMs")
+	h.log(c).WithFields(logrus.Fields{
+		"name_contains": nameContains,
+		"datacenter":    datacenter,
+		"cluster":       cluster,
+	}).Info("Listing VMs")
 
 	// Build filter from query parameters
 	filter := vmware.VMFilter{
-		Name: nameContains,
+		Name:       nameContains,
+		Datacenter: datacenter,
+		Cluster:    cluster,
 	}
 
 	result, err := h.vmService.ListVMs(c.Request.Context(), filter)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to list VMs")
+		h.log(c).WithError(err).Error("Failed to list VMs")
+		writeVMListError(c, err)
+		return
+	}
+
+	// Convert VMInfos to VMs
+	var vms []types.VM
+	for _, vmInfo := range result.VMs {
+		vms = append(vms, h.convertVMInfoToVM(vmInfo))
+	}
+
+	response := types.VMListResponse{
+		Datacenter: result.Datacenter,
+		VMs:        vms,
+		Total:      result.Total,
+	}
+
+	h.log(c).WithField("total_vms", result.Total).Info("Successfully retrieved VMs")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListVMSummaries godoc
+// @Summary List virtual machine summaries
+// @Description Get a page of virtual machines with their CPU count, memory, guest OS, datacenter and cluster. The summaries are retrieved for all VMs at once, so this is a richer list view than /vms without the cost of fetching each VM's details
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name_contains query string false "Filter VMs where name contains this string" example("web")
+// @Param datacenter query string false "Datacenter to list VMs from (defaults to the default datacenter)" example("Datacenter1")
+// @Param cluster query string false "Only list VMs in this cluster" example("Cluster1")
+// @Param limit query int false "Maximum number of VMs to return (1-500, all when unset)" example(50)
+// @Param offset query int false "Number of VMs to skip" default(0)
+// @Success 200 {object} types.VMSummaryListResponse "VM summaries, sorted by name"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "Datacenter or cluster not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/summary [get]
+func (h *VMHandler) ListVMSummaries(c *gin.Context) {
+	var req types.VMSummaryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"name_contains": req.NameContains,
+		"datacenter":    req.Datacenter,
+		"cluster":       req.Cluster,
+		"limit":         req.Limit,
+		"offset":        req.Offset,
+	}).Info("Listing VM summaries")
+
+	result, err := h.vmService.ListVMSummaries(c.Request.Context(), vmware.VMFilter{
+		Name:       req.NameContains,
+		Datacenter: req.Datacenter,
+		Cluster:    req.Cluster,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list VM summaries")
+		writeVMListError(c, err)
+		return
+	}
+
+	summaries := make([]types.VMSummary, 0, len(result.VMs))
+	for _, vm := range result.VMs {
+		summaries = append(summaries, types.VMSummary{
+			UUID:       vm.UUID,
+			Name:       vm.Name,
+			PowerState: vm.PowerState,
+			CPUCount:   vm.CPUCount,
+			MemoryMB:   vm.MemoryMB,
+			GuestOS:    vm.GuestOS,
+			Datacenter: vm.Datacenter,
+			Cluster:    vm.Cluster,
+		})
+	}
+
+	h.log(c).WithField("total_vms", result.Total).Info("Successfully retrieved VM summaries")
+
+	c.JSON(http.StatusOK, types.VMSummaryListResponse{
+		Datacenter: result.Datacenter,
+		VMs:        summaries,
+		Total:      result.Total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+}
+
+// SearchVMsByIP godoc
+// @Summary Find virtual machines by IP address
+// @Description Find the virtual machines whose guest reports an IP address (primary or any NIC address). Requires VMware Tools in the guest. All matches are returned, since several VMs can report the same address.
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param ip query string true "IPv4 or IPv6 address" example("192.168.1.100")
+// @Param datacenter query string false "Datacenter to search (defaults to the default datacenter)" example("Datacenter1")
+// @Success 200 {object} types.VMIPSearchResponse "Matching virtual machines"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "Datacenter not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/search [get]
+func (h *VMHandler) SearchVMsByIP(c *gin.Context) {
+	ip := c.Query("ip")
+	if ip == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "IP address is required",
+			Code:    "MISSING_IP",
+			Details: "Please provide the IP address as query parameter: ?ip=xxx",
+		})
+		return
+	}
+
+	result, err := h.vmService.FindVMsByIP(c.Request.Context(), ip, c.Query("datacenter"))
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to search VMs by IP address")
+
+		if errors.Is(err, vmware.ErrInvalidIPAddress) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid IP address",
+				Code:    "INVALID_IP",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, vmware.ErrDatacenterNotFound) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Datacenter not found",
+				Code:    "DATACENTER_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
 
 		if isConnectionError(err) {
 			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
@@ -65,38 +264,119 @@ func (h *VMHandler) ListVMs(c *gin.Context) {
 			return
 		}
 
-		if isAuthenticationError(err) {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to search VMs",
+			Code:    "VM_SEARCH_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	matches := make([]types.VMIPSearchMatch, 0, len(result.Matches))
+	for _, match := range result.Matches {
+		_, addresses := convertGuestIPs(match.MatchedIPs)
+		matches = append(matches, types.VMIPSearchMatch{
+			VM:         h.convertVMInfoToVM(match.VM),
+			MatchedIPs: addresses,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.VMIPSearchResponse{
+		IP:         result.IP,
+		Datacenter: result.Datacenter,
+		Matches:    matches,
+		Total:      result.Total,
+	})
+}
+
+// ListDatacenters godoc
+// @Summary List datacenters
+// @Description Get the names of all datacenters, sorted, e.g. for the datacenter filter of other endpoints
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.DatacenterListResponse "Datacenters retrieved successfully"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/datacenters [get]
+func (h *VMHandler) ListDatacenters(c *gin.Context) {
+	datacenters, err := h.vmService.ListDatacenters(c.Request.Context())
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list datacenters")
+
+		if isConnectionError(err) {
 			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
-				Error:   "vSphere authentication failed",
-				Code:    "VSPHERE_AUTH_FAILED",
-				Details: "Authentication to vSphere failed. Please check configuration.",
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
 			})
 			return
 		}
 
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   "Failed to retrieve VMs",
-			Code:    "VM_LIST_FAILED",
-			Details: "An error occurred while retrieving virtual machines from vSphere",
+			Error:   "Failed to list datacenters",
+			Code:    "DATACENTER_LIST_FAILED",
+			Details: err.Error(),
 		})
 		return
 	}
 
-	// Convert VMInfos to VMs
-	var vms []types.VM
-	for _, vmInfo := range result.VMs {
-		vms = append(vms, h.convertVMInfoToVM(vmInfo))
-	}
+	c.JSON(http.StatusOK, types.DatacenterListResponse{
+		Datacenters: datacenters,
+		Total:       len(datacenters),
+	})
+}
 
-	response := types.VMListResponse{
-		Datacenter: result.Datacenter,
-		VMs:        vms,
-		Total:      result.Total,
-	}
+// ListClusters godoc
+// @Summary List the clusters of a datacenter
+// @Description Get the names of all clusters in a datacenter, sorted, e.g. for the cluster filter of the VM listing
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param dc path string true "Datacenter name" example("Datacenter1")
+// @Success 200 {object} types.ClusterListResponse "Clusters retrieved successfully"
+// @Failure 404 {object} types.ErrorResponse "Datacenter not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/datacenters/{dc}/clusters [get]
+func (h *VMHandler) ListClusters(c *gin.Context) {
+	datacenter := c.Param("dc")
 
-	h.logger.WithField("total_vms", result.Total).Info("Successfully retrieved VMs")
+	clusters, err := h.vmService.ListClusters(c.Request.Context(), datacenter)
+	if err != nil {
+		h.log(c).WithError(err).WithField("datacenter", datacenter).Error("Failed to list clusters")
 
-	c.JSON(http.StatusOK, response)
+		if errors.Is(err, vmware.ErrDatacenterNotFound) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Datacenter not found",
+				Code:    "DATACENTER_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to list clusters",
+			Code:    "CLUSTER_LIST_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.ClusterListResponse{
+		Datacenter: datacenter,
+		Clusters:   clusters,
+		Total:      len(clusters),
+	})
 }
 
 // GetVM godoc
@@ -106,6 +386,9 @@ func (h *VMHandler) ListVMs(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param name path string true "VM name" example("web-server-01")
+// @Param ip_family query string false "Only return guest IP addresses of this family (ipv4 or ipv6)" example("ipv4")
+// @Param include_link_local query bool false "Include link-local guest IP addresses (fe80::/10, 169.254.0.0/16)" default(false)
+// @Param fields query []string false "Detail sections to retrieve: basic, hardware, network, storage or all (repeatable or comma-separated, default all)" collectionFormat(multi)
 // @Success 200 {object} types.VMDetailsResponse "Virtual machine details"
 // @Failure 400 {object} types.ErrorResponse "Invalid request"
 // @Failure 404 {object} types.ErrorResponse "VM not found"
@@ -123,11 +406,29 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 		return
 	}
 
-	h.logger.WithField("vm_name", name).Info("Getting VM details")
+	ipFilter, ok := parseGuestIPFilter(c)
+	if !ok {
+		return
+	}
+
+	fields, err := vmware.ParseVMFields(c.QueryArray("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid fields parameter",
+			Code:    "INVALID_FIELDS",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name": name,
+		"fields":  fields.Names(),
+	}).Info("Getting VM details")
 
-	result, err := h.vmService.GetVMByName(c.Request.Context(), name)
+	result, err := h.vmService.GetVMByName(c.Request.Context(), name, fields)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get VM")
+		h.log(c).WithError(err).Error("Failed to get VM")
 
 		if isConnectionError(err) {
 			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
@@ -158,34 +459,33 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 	// Convert detailed VM info to API response
 	vm := types.VM{
 		UUID:       result.VM.UUID,
+		Moref:      result.VM.Moref,
 		Name:       result.VM.Name,
 		PowerState: result.VM.PowerState,
+		Degraded:   result.VM.Degraded,
 	}
 
 	// Convert disks
 	var disks []types.VMDisk
 	for _, disk := range result.VM.Disks {
-		disks = append(disks, types.VMDisk{
-			Label:           disk.Label,
-			CapacityKB:      disk.CapacityKB,
-			CapacityGB:      disk.CapacityKB / 1024 / 1024,
-			DiskPath:        disk.DiskPath,
-			Datastore:       disk.Datastore,
-			ThinProvisioned: disk.ThinProvisioned,
-			DiskMode:        disk.DiskMode,
-		})
+		disks = append(disks, convertDisk(disk))
 	}
 
 	// Convert network adapters
 	var networkAdapters []types.VMNetworkAdapter
 	for _, adapter := range result.VM.NetworkAdapters {
+		adapterIPs, adapterAddresses := convertGuestIPs(ipFilter.Apply(adapter.IPAddresses))
 		networkAdapters = append(networkAdapters, types.VMNetworkAdapter{
-			Label:       adapter.Label,
-			NetworkName: adapter.NetworkName,
-			MacAddress:  adapter.MacAddress,
-			IPAddresses: adapter.IPAddresses,
-			Connected:   adapter.Connected,
-			AdapterType: adapter.AdapterType,
+			Label:          adapter.Label,
+			NetworkName:    adapter.NetworkName,
+			MacAddress:     adapter.MacAddress,
+			IPAddresses:    adapterIPs,
+			Addresses:      adapterAddresses,
+			Connected:      adapter.Connected,
+			AdapterType:    adapter.AdapterType,
+			ToolsAvailable: adapter.ToolsAvailable,
+			PortgroupKey:   adapter.PortgroupKey,
+			SwitchUUID:     adapter.SwitchUUID,
 		})
 	}
 
@@ -199,12 +499,18 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 			State:       snap.State,
 			Quiesced:    snap.Quiesced,
 			ID:          snap.ID,
+			ParentID:    snap.ParentID,
+			Depth:       snap.Depth,
+			IsCurrent:   snap.IsCurrent,
 		})
 	}
 
+	guestIPs, guestAddresses := convertGuestIPs(ipFilter.Apply(result.VM.IPAddresses))
+
 	// Build detailed response with all available information
 	response := types.VMDetailsResponse{
-		VM: vm,
+		VM:     vm,
+		Fields: fields.Names(),
 		Hardware: types.VMHardwareInfo{
 			NumCPU:            result.VM.NumCPU,
 			NumCoresPerSocket: result.VM.NumCoresPerSocket,
@@ -217,10 +523,12 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 			Status:        result.VM.ToolsStatus,
 			Version:       result.VM.ToolsVersion,
 			RunningStatus: result.VM.ToolsRunningStatus,
+			VersionStatus: result.VM.ToolsVersionStatus,
 		},
 		GuestInfo: types.VMGuestInfo{
 			Hostname:             result.VM.Hostname,
-			IPAddresses:          result.VM.IPAddresses,
+			IPAddresses:          guestIPs,
+			Addresses:            guestAddresses,
 			GuestID:              result.VM.GuestID,
 			GuestState:           result.VM.GuestState,
 			GuestHeartbeatStatus: result.VM.GuestHeartbeatStatus,
@@ -245,6 +553,10 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 		NetworkAdapters: networkAdapters,
 		Snapshots:       snapshots,
 		CurrentSnapshot: result.VM.CurrentSnapshot,
+
+		CurrentSnapshotName:        result.VM.CurrentSnapshotName,
+		CurrentSnapshotDescription: result.VM.CurrentSnapshotDescription,
+
 		Resources: types.VMResourceInfo{
 			CPUReservationMHz:   result.VM.ResourceAllocation.CPUReservation,
 			CPULimitMHz:         result.VM.ResourceAllocation.CPULimit,
@@ -261,6 +573,7 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 			UncommittedBytes: result.VM.UncommittedStorage,
 			UncommittedGB:    result.VM.UncommittedStorage / 1024 / 1024 / 1024,
 			Datastores:       result.VM.Datastores,
+			StorageSource:    result.VM.StorageSource,
 		},
 		Files: types.VMFileInfo{
 			VMPathName:  result.VM.VMPathName,
@@ -279,7 +592,7 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 		},
 	}
 
-	h.logger.WithFields(logrus.Fields{
+	h.log(c).WithFields(logrus.Fields{
 		"vm_name": vm.Name,
 		"vm_uuid": vm.UUID,
 	}).Info("Successfully retrieved VM details")
@@ -287,241 +600,2327 @@ func (h *VMHandler) GetVM(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// CreateClone godoc
-// @Summary Create a clone from VM snapshot
-// @Description Create a linked clone from a VM snapshot for inspection
+// parseGuestIPFilter reads the ip_family and include_link_local query parameters
+// It writes a 400 response and returns false when a parameter is invalid
+func parseGuestIPFilter(c *gin.Context) (vmware.GuestIPFilter, bool) {
+	filter := vmware.GuestIPFilter{
+		Family: strings.ToLower(c.Query("ip_family")),
+	}
+	if filter.Family != "" && filter.Family != vmware.IPFamilyIPv4 && filter.Family != vmware.IPFamilyIPv6 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid ip_family parameter",
+			Code:    "INVALID_IP_FAMILY",
+			Details: fmt.Sprintf("ip_family must be one of [%s, %s], got: %s", vmware.IPFamilyIPv4, vmware.IPFamilyIPv6, c.Query("ip_family")),
+		})
+		return vmware.GuestIPFilter{}, false
+	}
+
+	if value := c.Query("include_link_local"); value != "" {
+		includeLinkLocal, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid include_link_local parameter",
+				Code:    "INVALID_INCLUDE_LINK_LOCAL",
+				Details: fmt.Sprintf("include_link_local must be true or false, got: %s", value),
+			})
+			return vmware.GuestIPFilter{}, false
+		}
+		filter.IncludeLinkLocal = includeLinkLocal
+	}
+
+	return filter, true
+}
+
+// parseSnapshotID reads the optional snapshot_id query parameter (0 when absent)
+// Snapshot IDs are listed by GET /vms/:name/snapshots and select one of several snapshots
+// sharing a name. It writes a 400 response and returns false when the parameter is invalid
+func parseSnapshotID(c *gin.Context) (int32, bool) {
+	value := c.Query("snapshot_id")
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid snapshot_id parameter",
+			Code:    "INVALID_SNAPSHOT_ID",
+			Details: fmt.Sprintf("snapshot_id must be a positive integer, got: %s", value),
+		})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// parseForce reads the optional force query parameter (false when absent)
+// It writes a 400 response and returns false when the parameter is invalid
+func parseForce(c *gin.Context) (bool, bool) {
+	value := c.Query("force")
+	if value == "" {
+		return false, true
+	}
+	force, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid force parameter",
+			Code:    "INVALID_FORCE",
+			Details: fmt.Sprintf("force must be true or false, got: %s", value),
+		})
+		return false, false
+	}
+	return force, true
+}
+
+// convertGuestIPs converts classified guest addresses to the plain address list and detailed API entries
+func convertGuestIPs(ips []vmware.GuestIPAddress) ([]string, []types.VMIPAddress) {
+	if len(ips) == 0 {
+		return nil, nil
+	}
+
+	plain := make([]string, 0, len(ips))
+	detailed := make([]types.VMIPAddress, 0, len(ips))
+	for _, ip := range ips {
+		plain = append(plain, ip.Address)
+		detailed = append(detailed, types.VMIPAddress{
+			Address:      ip.Address,
+			PrefixLength: ip.PrefixLength,
+			CIDR:         ip.CIDR(),
+			Family:       ip.Family,
+			LinkLocal:    ip.LinkLocal,
+		})
+	}
+	return plain, detailed
+}
+
+// ListSnapshots godoc
+// @Summary List virtual machine snapshots
+// @Description Get the snapshots of a specific virtual machine without fetching the full VM details, as a flat list (depth-first) or as a tree of nested children
 // @Tags vms
 // @Accept json
 // @Produce json
-// @Param name query string true "VM name" example("web-server-01")
-// @Param request body types.CloneRequest true "Clone request"
-// @Success 200 {object} types.CloneResponse "Clone created successfully"
+// @Param name path string true "VM name" example("web-server-01")
+// @Param format query string false "Snapshot layout: flat or tree" default(flat)
+// @Param order query string false "Order by create time: asc or desc (flat lists default to tree order, tree siblings to asc)"
+// @Success 200 {object} types.SnapshotListResponse "List of snapshots"
 // @Failure 400 {object} types.ErrorResponse "Invalid request"
-// @Failure 404 {object} types.ErrorResponse "VM or snapshot not found"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /api/v1/vms/clone [post]
-func (h *VMHandler) CreateClone(c *gin.Context) {
-	vmName := c.Query("name")
-	if vmName == "" {
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/snapshots [get]
+func (h *VMHandler) ListSnapshots(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "VM name is required",
 			Code:    "MISSING_VM_NAME",
-			Details: "Please provide VM name as query parameter: ?name=xxx",
+			Details: "VM name must be provided in the URL path",
 		})
 		return
 	}
 
-	var req types.CloneRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Error("Failed to bind clone request")
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   "Invalid request body",
-			Code:    "INVALID_REQUEST",
-			Details: err.Error(),
-		})
+	options, ok := parseSnapshotListOptions(c)
+	if !ok {
 		return
 	}
 
-	// Generate clone name if not provided
-	cloneName := req.CloneName
-	if cloneName == "" {
-		cloneName = vmName + "-clone-" + time.Now().Format("20060102150405")
-	}
-
-	h.logger.WithFields(logrus.Fields{
-		"vm_name":       vmName,
-		"snapshot_name": req.SnapshotName,
-		"clone_name":    cloneName,
-	}).Info("Creating clone from snapshot")
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name": name,
+		"format":  options.format,
+		"order":   options.order,
+	}).Info("Listing VM snapshots")
 
-	// Find snapshot
-	snapshotRef, err := h.vmService.FindSnapshotByName(c.Request.Context(), vmName, req.SnapshotName)
+	result, err := h.vmService.ListSnapshots(c.Request.Context(), name)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to find snapshot")
+		h.log(c).WithError(err).Error("Failed to list snapshots")
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
 		if isNotFoundError(err) {
 			c.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error:   "Snapshot not found",
-				Code:    "SNAPSHOT_NOT_FOUND",
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
 				Details: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   "Failed to find snapshot",
-			Code:    "SNAPSHOT_FIND_FAILED",
-			Details: err.Error(),
-		})
-		return
-	}
 
-	// Create clone
-	err = h.vmService.CreateLinkedClone(c.Request.Context(), vmName, snapshotRef, cloneName)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to create clone")
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   "Failed to create clone",
-			Code:    "CLONE_CREATE_FAILED",
-			Details: err.Error(),
+			Error:   "Failed to retrieve snapshots",
+			Code:    "SNAPSHOT_LIST_FAILED",
+			Details: "An error occurred while retrieving the virtual machine snapshots",
 		})
 		return
 	}
 
-	response := types.CloneResponse{
-		CloneName:    cloneName,
-		VMName:       vmName,
-		SnapshotName: req.SnapshotName,
-		Status:       "completed",
-		Message:      "Clone created successfully",
+	snapshots := options.snapshotList(result)
+
+	response := types.SnapshotListResponse{
+		VMID:            result.VMMoref,
+		VMName:          name,
+		Format:          options.format,
+		Snapshots:       snapshots,
+		CurrentSnapshot: result.CurrentSnapshot,
+		Total:           len(result.Snapshots),
+
+		CurrentSnapshotName:        result.CurrentSnapshotName,
+		CurrentSnapshotDescription: result.CurrentSnapshotDescription,
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"clone_name": cloneName,
-	}).Info("Clone created successfully")
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        name,
+		"snapshot_count": len(result.Snapshots),
+	}).Info("Successfully retrieved VM snapshots")
 
 	c.JSON(http.StatusOK, response)
 }
 
-// InspectSnapshot godoc
-// @Summary Inspect a VM snapshot directly
-// @Description Run virt-inspector or virt-v2v-inspector on a VM snapshot using VDDK
+// ListDisks godoc
+// @Summary List virtual machine disks
+// @Description Get the virtual disks of a specific virtual machine, with their controller bus (ide, scsi, sata or nvme), controller type (e.g. pvscsi, lsilogic-sas, ahci) and bus/unit numbers, without fetching the full VM details
 // @Tags vms
 // @Accept json
 // @Produce json
-// @Param vm query string true "Original VM name" example("web-server-01")
-// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param name path string true "VM name" example("web-server-01")
+// @Success 200 {object} types.DiskListResponse "List of disks"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/disks [get]
+func (h *VMHandler) ListDisks(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	h.log(c).WithField("vm_name", name).Info("Listing VM disks")
+
+	result, err := h.vmService.ListDisks(c.Request.Context(), name)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list disks")
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to retrieve disks",
+			Code:    "DISK_LIST_FAILED",
+			Details: "An error occurred while retrieving the virtual machine disks",
+		})
+		return
+	}
+
+	disks := []types.VMDisk{}
+	for _, disk := range result.Disks {
+		disks = append(disks, convertDisk(disk))
+	}
+
+	response := types.DiskListResponse{
+		VMID:   result.VMMoref,
+		VMName: name,
+		Disks:  disks,
+		Total:  len(disks),
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":    name,
+		"disk_count": len(disks),
+	}).Info("Successfully retrieved VM disks")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// convertDisk converts a disk of the VM service into its API representation
+func convertDisk(disk vmware.VMDiskInfo) types.VMDisk {
+	return types.VMDisk{
+		Label:           disk.Label,
+		CapacityKB:      disk.CapacityKB,
+		CapacityGB:      disk.CapacityKB / 1024 / 1024,
+		DiskPath:        disk.DiskPath,
+		Datastore:       disk.Datastore,
+		ThinProvisioned: disk.ThinProvisioned,
+		DiskMode:        disk.DiskMode,
+		ControllerBus:   disk.ControllerBus,
+		ControllerType:  disk.ControllerType,
+		BusNumber:       disk.BusNumber,
+		UnitNumber:      disk.UnitNumber,
+	}
+}
+
+// GetVMStats godoc
+// @Summary Get virtual machine performance statistics
+// @Description Get real-time CPU, memory, disk and network statistics for a virtual machine. Powered-off VMs return zero values with available set to false.
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01")
+// @Success 200 {object} types.VMStatsResponse "Virtual machine statistics"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/stats [get]
+func (h *VMHandler) GetVMStats(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	h.log(c).WithField("vm_name", name).Info("Getting VM statistics")
+
+	stats, err := h.vmService.GetVMStats(c.Request.Context(), name)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to get VM statistics")
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to retrieve VM statistics",
+			Code:    "VM_STATS_FAILED",
+			Details: "An error occurred while retrieving performance statistics from vSphere",
+		})
+		return
+	}
+
+	response := types.VMStatsResponse{
+		UUID:      stats.UUID,
+		Name:      stats.Name,
+		Timestamp: stats.Timestamp,
+		CPUUsage: types.VMCPUStats{
+			UsagePercent: stats.CPUUsagePercent,
+			UsageMHz:     stats.CPUUsageMHz,
+			ReadyTime:    stats.CPUReadyTimeMS,
+		},
+		MemoryUsage: types.VMMemoryStats{
+			UsagePercent: stats.MemoryUsagePercent,
+			UsageMB:      stats.MemoryUsageMB,
+			ActiveMB:     stats.MemoryActiveMB,
+			BalloonedMB:  stats.MemoryBalloonedMB,
+			SwappedMB:    stats.MemorySwappedMB,
+		},
+		DiskUsage: types.VMDiskStats{
+			ReadIOPS:  stats.DiskReadIOPS,
+			WriteIOPS: stats.DiskWriteIOPS,
+			ReadMBps:  stats.DiskReadMBps,
+			WriteMBps: stats.DiskWriteMBps,
+			LatencyMS: stats.DiskLatencyMS,
+		},
+		NetworkUsage: types.VMNetworkStats{
+			ReceiveMBps:  stats.NetworkReceiveMBps,
+			TransmitMBps: stats.NetworkTransmitMBps,
+			ReceivePPS:   stats.NetworkReceivePPS,
+			TransmitPPS:  stats.NetworkTransmitPPS,
+		},
+		Uptime:     stats.UptimeSeconds,
+		PowerState: stats.PowerState,
+		Available:  stats.Available,
+		Note:       stats.Note,
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":   stats.Name,
+		"available": stats.Available,
+	}).Info("Successfully retrieved VM statistics")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReconfigureVM godoc
+// @Summary Change virtual machine CPU and memory
+// @Description Change the number of CPUs, cores per socket and memory of a virtual machine. Running VMs can only grow CPU or memory when hot-add is enabled.
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01-clone")
+// @Param request body types.VMReconfigureRequest true "Reconfigure request"
+// @Success 200 {object} types.VMReconfigureResponse "VM reconfigured successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 409 {object} types.ErrorResponse "Change not allowed in the current power state"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/config [patch]
+func (h *VMHandler) ReconfigureVM(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	var req types.VMReconfigureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).WithError(err).Error("Failed to bind reconfigure request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.log(c).WithField("vm_name", name).Info("Reconfiguring VM")
+
+	config, err := h.vmService.Reconfigure(c.Request.Context(), name, vmware.VMReconfigureSpec{
+		NumCPU:            req.NumCPU,
+		NumCoresPerSocket: req.NumCoresPerSocket,
+		MemoryMB:          req.MemoryMB,
+	})
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to reconfigure VM")
+
+		if errors.Is(err, vmware.ErrInvalidReconfigureSpec) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid reconfigure request",
+				Code:    "INVALID_RECONFIGURE_REQUEST",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, vmware.ErrReconfigureNotAllowed) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "Reconfiguration not allowed",
+				Code:    "RECONFIGURE_NOT_ALLOWED",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to reconfigure VM",
+			Code:    "RECONFIGURE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.VMReconfigureResponse{
+		Name:              config.Name,
+		PowerState:        config.PowerState,
+		NumCPU:            config.NumCPU,
+		NumCoresPerSocket: config.NumCoresPerSocket,
+		MemoryMB:          config.MemoryMB,
+		Status:            "completed",
+		Message:           "VM reconfigured successfully",
+	})
+}
+
+// SetVMAnnotation godoc
+// @Summary Update virtual machine annotation
+// @Description Replace the annotation (notes) of a virtual machine, e.g. to record inspection results or migration status
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01")
+// @Param request body types.VMAnnotationRequest true "Annotation request"
+// @Success 200 {object} types.VMAnnotationResponse "VM annotation updated successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request or annotation too long"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/annotation [put]
+func (h *VMHandler) SetVMAnnotation(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	var req types.VMAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).WithError(err).Error("Failed to bind annotation request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.log(c).WithField("vm_name", name).Info("Updating VM annotation")
+
+	annotation, err := h.vmService.SetAnnotation(c.Request.Context(), name, req.Annotation)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to update VM annotation")
+
+		if errors.Is(err, vmware.ErrInvalidAnnotation) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid annotation",
+				Code:    "INVALID_ANNOTATION",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to update VM annotation",
+			Code:    "ANNOTATION_UPDATE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.VMAnnotationResponse{
+		Name:       annotation.Name,
+		Annotation: annotation.Annotation,
+		Status:     "completed",
+		Message:    "VM annotation updated successfully",
+	})
+}
+
+// GetVMChangeTracking godoc
+// @Summary Get virtual machine change block tracking state
+// @Description Get whether change block tracking (CBT), needed for incremental disk copies during migration, is enabled on a virtual machine, and whether a snapshot create/delete cycle is still needed to activate the setting on its disks
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01")
+// @Success 200 {object} types.VMChangeTrackingResponse "Change block tracking state"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/cbt [get]
+func (h *VMHandler) GetVMChangeTracking(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	tracking, err := h.vmService.GetChangeTracking(c.Request.Context(), name)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to get VM change block tracking")
+		writeChangeTrackingError(c, err, "Failed to get change block tracking", "CBT_GET_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, changeTrackingResponse(tracking))
+}
+
+// SetVMChangeTracking godoc
+// @Summary Enable or disable virtual machine change block tracking
+// @Description Enable or disable change block tracking (CBT) on a virtual machine. The setting takes effect at the next power-on of a powered-off VM; a powered-on VM needs a snapshot create/delete cycle, reported by requires_snapshot_cycle
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01")
+// @Param enabled query bool true "Whether change block tracking should be enabled" example(true)
+// @Success 200 {object} types.VMChangeTrackingResponse "Change block tracking updated"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/cbt [post]
+func (h *VMHandler) SetVMChangeTracking(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	value := c.Query("enabled")
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid enabled parameter",
+			Code:    "INVALID_ENABLED",
+			Details: fmt.Sprintf("enabled must be true or false, got: %q", value),
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name": name,
+		"enabled": enabled,
+	}).Info("Updating VM change block tracking")
+
+	tracking, err := h.vmService.SetChangeTracking(c.Request.Context(), name, enabled)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to update VM change block tracking")
+		writeChangeTrackingError(c, err, "Failed to update change block tracking", "CBT_UPDATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, changeTrackingResponse(tracking))
+}
+
+// changeTrackingResponse converts a change block tracking state to the API response
+func changeTrackingResponse(tracking *vmware.VMChangeTracking) types.VMChangeTrackingResponse {
+	state := "disabled"
+	if tracking.Enabled {
+		state = "enabled"
+	}
+
+	message := fmt.Sprintf("Change block tracking is %s", state)
+	switch {
+	case tracking.RequiresSnapshotCycle:
+		message += "; create and delete a snapshot to apply it to the running VM's disks"
+	case tracking.PowerState != string(types.PowerStatePoweredOn):
+		message += "; it applies to the disks at the next power-on"
+	}
+
+	return types.VMChangeTrackingResponse{
+		Name:                  tracking.Name,
+		Enabled:               tracking.Enabled,
+		PowerState:            tracking.PowerState,
+		RequiresSnapshotCycle: tracking.RequiresSnapshotCycle,
+		Message:               message,
+	}
+}
+
+// writeChangeTrackingError writes the error response for a failed change block tracking request
+func writeChangeTrackingError(c *gin.Context, err error, message string, code string) {
+	if isConnectionError(err) {
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "vSphere connection unavailable",
+			Code:    "VSPHERE_UNAVAILABLE",
+			Details: "Unable to connect to vSphere. Please try again later.",
+		})
+		return
+	}
+
+	if isNotFoundError(err) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "VM not found",
+			Code:    "VM_NOT_FOUND",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Details: err.Error(),
+	})
+}
+
+// ListVMsNeedingConsolidation godoc
+// @Summary List virtual machines needing disk consolidation
+// @Description List the virtual machines whose disks need consolidation. Leftover delta disks from failed snapshot removals are a common cause of inspection failures.
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param datacenter query string false "Datacenter to scan (defaults to the default datacenter)" example("Datacenter1")
+// @Success 200 {object} types.VMListResponse "Virtual machines needing consolidation"
+// @Failure 404 {object} types.ErrorResponse "Datacenter not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/needs-consolidation [get]
+func (h *VMHandler) ListVMsNeedingConsolidation(c *gin.Context) {
+	result, err := h.vmService.ListVMsNeedingConsolidation(c.Request.Context(), c.Query("datacenter"))
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list VMs needing consolidation")
+
+		if errors.Is(err, vmware.ErrDatacenterNotFound) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Datacenter not found",
+				Code:    "DATACENTER_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to retrieve VMs",
+			Code:    "VM_LIST_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	vms := make([]types.VM, 0, len(result.VMs))
+	for _, vmInfo := range result.VMs {
+		vms = append(vms, h.convertVMInfoToVM(vmInfo))
+	}
+
+	c.JSON(http.StatusOK, types.VMListResponse{
+		Datacenter: result.Datacenter,
+		VMs:        vms,
+		Total:      result.Total,
+	})
+}
+
+// ConsolidateVM godoc
+// @Summary Consolidate virtual machine disks
+// @Description Consolidate the disks of a virtual machine that needs consolidation and wait for the task to complete
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name path string true "VM name" example("web-server-01")
+// @Success 200 {object} types.VMConsolidateResponse "VM disks consolidated successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 409 {object} types.ErrorResponse "VM disks do not need consolidation"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/{name}/consolidate [post]
+func (h *VMHandler) ConsolidateVM(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "VM name must be provided in the URL path",
+		})
+		return
+	}
+
+	h.log(c).WithField("vm_name", name).Info("Consolidating VM disks")
+
+	if err := h.vmService.ConsolidateDisks(c.Request.Context(), name); err != nil {
+		h.log(c).WithError(err).Error("Failed to consolidate VM disks")
+
+		if errors.Is(err, vmware.ErrConsolidationNotNeeded) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "Consolidation not needed",
+				Code:    "CONSOLIDATION_NOT_NEEDED",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to consolidate VM disks",
+			Code:    "CONSOLIDATE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.VMConsolidateResponse{
+		Name:    name,
+		Status:  "completed",
+		Message: "VM disks consolidated successfully",
+	})
+}
+
+// CreateClone godoc
+// @Summary Create a clone from VM snapshot
+// @Description Create a linked clone from a VM snapshot for inspection. Without snapshot_name, the VM's current state is cloned through a temporary snapshot that is removed afterwards
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param name query string true "VM name" example("web-server-01")
+// @Param request body types.CloneRequest true "Clone request"
+// @Success 200 {object} types.CloneResponse "Clone created successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM or snapshot not found"
+// @Failure 409 {object} types.ErrorResponse "Several snapshots have the snapshot name; pass snapshot_id"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/clone [post]
+func (h *VMHandler) CreateClone(c *gin.Context) {
+	vmName := c.Query("name")
+	if vmName == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide VM name as query parameter: ?name=xxx",
+		})
+		return
+	}
+
+	var req types.CloneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).WithError(err).Error("Failed to bind clone request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// Generate clone name if not provided
+	cloneName := req.CloneName
+	if cloneName == "" {
+		cloneName = vmName + "-clone-" + time.Now().Format("20060102150405")
+	}
+
+	if req.SnapshotName == "" && req.SnapshotID != 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Snapshot name is required with a snapshot ID",
+			Code:    "INVALID_REQUEST",
+			Details: "snapshot_id selects one of several snapshots named snapshot_name; omit both to clone the current state",
+		})
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":          vmName,
+		"snapshot_name":    req.SnapshotName,
+		"clone_name":       cloneName,
+		"target_folder":    req.TargetFolder,
+		"target_datastore": req.TargetDatastore,
+	}).Info("Creating clone from snapshot")
+
+	target := vmware.CloneTarget{
+		Folder:    req.TargetFolder,
+		Datastore: req.TargetDatastore,
+	}
+
+	var clone *vmware.CloneInfo
+	var err error
+	if req.SnapshotName == "" {
+		// Clone the current state through a temporary snapshot
+		clone, err = h.vmService.CreateLinkedCloneFromCurrent(c.Request.Context(), vmName, cloneName, target)
+	} else {
+		// Find snapshot
+		snapshotRef, findErr := h.vmService.FindSnapshotByName(c.Request.Context(), vmName, req.SnapshotName, req.SnapshotID)
+		if findErr != nil {
+			h.log(c).WithError(findErr).Error("Failed to find snapshot")
+			if errors.Is(findErr, vmware.ErrSnapshotAmbiguous) {
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "Snapshot name is ambiguous",
+					Code:    "SNAPSHOT_AMBIGUOUS",
+					Details: findErr.Error(),
+				})
+				return
+			}
+			if isNotFoundError(findErr) {
+				c.JSON(http.StatusNotFound, types.ErrorResponse{
+					Error:   "Snapshot not found",
+					Code:    "SNAPSHOT_NOT_FOUND",
+					Details: findErr.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "Failed to find snapshot",
+				Code:    "SNAPSHOT_FIND_FAILED",
+				Details: findErr.Error(),
+			})
+			return
+		}
+
+		// Create clone
+		clone, err = h.vmService.CreateLinkedClone(c.Request.Context(), vmName, snapshotRef, cloneName, target)
+	}
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to create clone")
+
+		if errors.Is(err, vmware.ErrVCenterRequired) {
+			c.JSON(http.StatusBadRequest, vcenterRequiredResponse(err))
+			return
+		}
+
+		if errors.Is(err, vmware.ErrFolderNotFound) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Target folder not found",
+				Code:    "FOLDER_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, vmware.ErrDatastoreNotFound) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Target datastore not found",
+				Code:    "DATASTORE_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to create clone",
+			Code:    "CLONE_CREATE_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := types.CloneResponse{
+		CloneName:    cloneName,
+		CloneUUID:    clone.UUID,
+		CloneMoref:   clone.Moref,
+		VMName:       vmName,
+		SnapshotName: req.SnapshotName,
+		Status:       "completed",
+		Message:      "Clone created successfully",
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"clone_name":  cloneName,
+		"clone_moref": clone.Moref,
+		"clone_uuid":  clone.UUID,
+	}).Info("Clone created successfully")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// InspectSnapshot godoc
+// @Summary Inspect a VM snapshot directly
+// @Description Run virt-inspector or virt-v2v-inspector on a VM snapshot using VDDK. With "Accept: text/event-stream" the response is a stream of "progress" events followed by a "result" or "error" event.
+// @Tags vms
+// @Accept json
+// @Produce json,text/event-stream
+// @Param vm query string false "Original VM name (or set moref or uuid)" example("web-server-01")
+// @Param moref query string false "VM managed object reference, to target one of several VMs with the same name" example("vm-42")
+// @Param uuid query string false "VM BIOS UUID, to target one of several VMs with the same name" example("42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param snapshot_id query int false "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name" example(3)
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Param datacenter query string false "Datacenter containing the VM (defaults to the default datacenter)" example("Datacenter1")
+// @Param app_filter query string false "Only return applications whose name contains this string (case-insensitive)" example("mysql")
+// @Param include_apps query bool false "Set to false to omit the application lists" example(false)
+// @Param disk_label query string false "Only inspect the disk with this label" example("Hard disk 2")
+// @Param disk_index query int false "Only inspect the disk at this zero-based index among the VM's disks" example(1)
+// @Param force query bool false "Run the inspector even when a stored result exists, replacing it" example(true)
+// @Success 200 {object} types.VMInspectionResponse "Inspection completed successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM or snapshot not found"
+// @Failure 409 {object} types.ErrorResponse "VM is powered on, or several snapshots have the snapshot name"
+// @Failure 422 {object} types.ErrorResponse "VM is encrypted or no operating system found on the disks"
+// @Failure 429 {object} types.ErrorResponse "Too many concurrent inspections"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/vms/inspect-snapshot [post]
+func (h *VMHandler) InspectSnapshot(c *gin.Context) {
+	req, ok := h.parseInspectionParams(c)
+	if !ok {
+		return
+	}
+	if req.apps, ok = parseApplicationFilter(c); !ok {
+		return
+	}
+	if req.disk, ok = parseDiskSelector(c); !ok {
+		return
+	}
+	if req.force, ok = parseForce(c); !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"inspector_type": req.inspectorType,
+		"datacenter":     req.datacenter,
+		"force":          req.force,
+	}).Info("Inspecting VM snapshot with VDDK")
+
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	h.extendWriteDeadline(c, h.inspectionConfig.Timeout)
+
+	if wantsEventStream(c) {
+		h.streamInspection(c, ctx, req)
+		return
+	}
+
+	response, failure := h.runInspection(ctx, req)
+	if failure != nil {
+		c.JSON(failure.status, failure.response)
+		return
+	}
+
+	h.log(c).WithField("inspector_type", req.inspectorType).Info("Snapshot inspection completed successfully")
+	c.JSON(http.StatusOK, req.apps.apply(response))
+}
+
+// InspectVM godoc
+// @Summary Inspect a powered-off VM's current disks
+// @Description Run virt-inspector or virt-v2v-inspector on the current disks of a powered-off VM using VDDK, without a snapshot. Results are stored under a generated "current-<timestamp>" snapshot name. With "Accept: text/event-stream" the response is a stream of "progress" events followed by a "result" or "error" event.
+// @Tags vms
+// @Accept json
+// @Produce json,text/event-stream
+// @Param vm query string false "VM name (or set moref or uuid)" example("web-server-01")
+// @Param moref query string false "VM managed object reference, to target one of several VMs with the same name" example("vm-42")
+// @Param uuid query string false "VM BIOS UUID, to target one of several VMs with the same name" example("42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f")
+// @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
+// @Param datacenter query string false "Datacenter containing the VM (defaults to the default datacenter)" example("Datacenter1")
+// @Param app_filter query string false "Only return applications whose name contains this string (case-insensitive)" example("mysql")
+// @Param include_apps query bool false "Set to false to omit the application lists" example(false)
+// @Param disk_label query string false "Only inspect the disk with this label" example("Hard disk 2")
+// @Param disk_index query int false "Only inspect the disk at this zero-based index among the VM's disks" example(1)
+// @Success 200 {object} types.VMInspectionResponse "Inspection completed successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "VM not found"
+// @Failure 409 {object} types.ErrorResponse "VM is powered on"
+// @Failure 422 {object} types.ErrorResponse "VM is encrypted or no operating system found on the disks"
+// @Failure 429 {object} types.ErrorResponse "Too many concurrent inspections"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/vms/inspect [post]
+func (h *VMHandler) InspectVM(c *gin.Context) {
+	inspectorParam := c.DefaultQuery("inspector", string(DefaultInspectorType))
+
+	vmRef, ok := parseVMSelector(c)
+	if !ok {
+		return
+	}
+
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return
+	}
+
+	// Each run is stored under its own name so results of earlier runs are never served from cache
+	req := inspectionRequest{
+		snapshotName:  "current-" + time.Now().UTC().Format("20060102T150405Z"),
+		inspectorType: inspectorType,
+		datacenter:    c.Query("datacenter"),
+		currentState:  true,
+	}
+	if !h.resolveVMSelector(c, vmRef, &req) {
+		return
+	}
+	if err := validateInspectionNames(req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+		return
+	}
+	if req.apps, ok = parseApplicationFilter(c); !ok {
+		return
+	}
+	if req.disk, ok = parseDiskSelector(c); !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"inspector_type": req.inspectorType,
+		"datacenter":     req.datacenter,
+	}).Info("Inspecting current VM disks with VDDK")
+
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	h.extendWriteDeadline(c, h.inspectionConfig.Timeout)
+
+	if wantsEventStream(c) {
+		h.streamInspection(c, ctx, req)
+		return
+	}
+
+	response, failure := h.runInspection(ctx, req)
+	if failure != nil {
+		c.JSON(failure.status, failure.response)
+		return
+	}
+
+	h.log(c).WithField("inspector_type", req.inspectorType).Info("VM inspection completed successfully")
+	c.JSON(http.StatusOK, req.apps.apply(response))
+}
+
+// InspectDisk godoc
+// @Summary Inspect disks at known locations
+// @Description Run virt-inspector or virt-v2v-inspector on disks identified by their VM and snapshot morefs and datastore paths, skipping the VM and snapshot lookup by name. Results are stored under vm_name and snapshot_name, which default to the morefs. With "Accept: text/event-stream" the response is a stream of "progress" events followed by a "result" or "error" event.
+// @Tags vms
+// @Accept json
+// @Produce json,text/event-stream
+// @Param request body types.InspectDiskRequest true "Disk locations"
+// @Param app_filter query string false "Only return applications whose name contains this string (case-insensitive)" example("mysql")
+// @Param include_apps query bool false "Set to false to omit the application lists" example(false)
+// @Success 200 {object} types.VMInspectionResponse "Inspection completed successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 422 {object} types.ErrorResponse "No operating system found on the disks"
+// @Failure 429 {object} types.ErrorResponse "Too many concurrent inspections"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/inspect-disk [post]
+func (h *VMHandler) InspectDisk(c *gin.Context) {
+	var body types.InspectDiskRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).WithError(err).Error("Failed to bind inspect-disk request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	diskInfo, datacenter, err := newDiskTarget(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid disk target",
+			Code:    "INVALID_DISK_TARGET",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	inspectorParam := body.Inspector
+	if inspectorParam == "" {
+		inspectorParam = string(DefaultInspectorType)
+	}
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return
+	}
+
+	req := inspectionRequest{
+		vmName:        body.VMName,
+		snapshotName:  body.SnapshotName,
+		inspectorType: inspectorType,
+		datacenter:    datacenter,
+		diskInfo:      diskInfo,
+	}
+	if req.vmName == "" {
+		req.vmName = body.VMMoref
+	}
+	if req.snapshotName == "" {
+		req.snapshotName = body.SnapshotMoref
+	}
+	if err := validateInspectionNames(req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+		return
+	}
+	if req.apps, ok = parseApplicationFilter(c); !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"vm_moref":       body.VMMoref,
+		"snapshot_moref": body.SnapshotMoref,
+		"base_disk_path": body.BaseDiskPath,
+		"inspector_type": req.inspectorType,
+		"datacenter":     req.datacenter,
+	}).Info("Inspecting disk at known location with VDDK")
+
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	h.extendWriteDeadline(c, h.inspectionConfig.Timeout)
+
+	if wantsEventStream(c) {
+		h.streamInspection(c, ctx, req)
+		return
+	}
+
+	response, failure := h.runInspection(ctx, req)
+	if failure != nil {
+		c.JSON(failure.status, failure.response)
+		return
+	}
+
+	h.log(c).WithField("inspector_type", req.inspectorType).Info("Disk inspection completed successfully")
+	c.JSON(http.StatusOK, req.apps.apply(response))
+}
+
+// InspectSnapshotAsync godoc
+// @Summary Start an asynchronous VM snapshot inspection
+// @Description Start virt-inspector or virt-v2v-inspector on a VM snapshot in the background and return a job ID to poll
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param vm query string false "Original VM name (or set moref or uuid)" example("web-server-01")
+// @Param moref query string false "VM managed object reference, to target one of several VMs with the same name" example("vm-42")
+// @Param uuid query string false "VM BIOS UUID, to target one of several VMs with the same name" example("42008b4e-5b2c-4c7a-9d6f-1a2b3c4d5e6f")
+// @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
+// @Param snapshot_id query int false "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name" example(3)
 // @Param inspector query string false "Inspector type: 'virt-inspector' (default) or 'virt-v2v-inspector'" example("virt-inspector")
-// @Success 200 {object} types.VMInspectionResponse "Inspection completed successfully"
+// @Param datacenter query string false "Datacenter containing the VM (defaults to the default datacenter)" example("Datacenter1")
+// @Success 202 {object} types.JobCreateResponse "Inspection job accepted"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/vms/inspect-snapshot/async [post]
+func (h *VMHandler) InspectSnapshotAsync(c *gin.Context) {
+	req, ok := h.parseInspectionParams(c)
+	if !ok {
+		return
+	}
+
+	// Run detached from the request context so the job outlives the HTTP request,
+	// keeping the request ID so the job's logs can be traced back to it
+	// Results are persisted to the inspection database by the inspector itself
+	jobCtx := requestid.NewContext(context.Background(), requestid.FromContext(c.Request.Context()))
+	timeoutCtx, cancel := context.WithTimeout(jobCtx, h.inspectionConfig.Timeout)
+	ctx, done, ok := h.beginInspection(c, timeoutCtx)
+	if !ok {
+		cancel()
+		return
+	}
+
+	job := h.jobs.Create(req.vmName, req.snapshotName, string(req.inspectorType))
+	req.jobID = job.ID()
+
+	logger := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"job_id":         job.ID(),
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"inspector_type": req.inspectorType,
+		"datacenter":     req.datacenter,
+	})
+	logger.Info("Starting asynchronous VM snapshot inspection")
+
+	go func() {
+		defer cancel()
+		defer done()
+
+		job.MarkRunning()
+		response, failure := h.runInspection(ctx, req)
+		if failure != nil {
+			logger.WithField("code", failure.response.Code).Error("Asynchronous inspection failed")
+			job.Fail(failure.response)
+			return
+		}
+
+		logger.Info("Asynchronous inspection completed successfully")
+		job.Complete(response)
+	}()
+
+	c.JSON(http.StatusAccepted, types.JobCreateResponse{
+		JobID:   job.ID(),
+		Status:  JobStatusPending,
+		Message: "Inspection job accepted",
+	})
+}
+
+// InspectBatch godoc
+// @Summary Inspect multiple VM snapshots
+// @Description Inspect a list of VM snapshots in one call. Items run concurrently, up to inspection.batch_concurrency at a time, and snapshots with stored results are returned from the cache without running the inspector again. Each item reports its own result or error; the request fails only when the batch itself is invalid.
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param request body []types.BatchInspectionItem true "Snapshots to inspect"
+// @Success 200 {object} types.BatchInspectionResponse "Batch inspection finished"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
+// @Router /api/v1/vms/inspect-batch [post]
+func (h *VMHandler) InspectBatch(c *gin.Context) {
+	var items []types.BatchInspectionItem
+	if err := c.ShouldBindJSON(&items); err != nil {
+		h.log(c).WithError(err).Error("Failed to bind batch inspection request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if len(items) == 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Batch is empty",
+			Code:    "EMPTY_BATCH",
+			Details: "Please provide at least one {vm, snapshot} item",
+		})
+		return
+	}
+
+	if len(items) > h.inspectionConfig.MaxBatchSize {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Batch is too large",
+			Code:    "BATCH_TOO_LARGE",
+			Details: fmt.Sprintf("batch has %d items, the maximum is %d", len(items), h.inspectionConfig.MaxBatchSize),
+		})
+		return
+	}
+
+	// Validate every item before starting any inspection
+	requests := make([]inspectionRequest, len(items))
+	for i, item := range items {
+		if item.VM == "" || item.Snapshot == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid batch item",
+				Code:    "INVALID_BATCH_ITEM",
+				Details: fmt.Sprintf("item %d: vm and snapshot are required", i),
+			})
+			return
+		}
+		if item.SnapshotID < 0 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid batch item",
+				Code:    "INVALID_BATCH_ITEM",
+				Details: fmt.Sprintf("item %d: snapshot_id must be a positive integer", i),
+			})
+			return
+		}
+
+		inspectorParam := item.Inspector
+		if inspectorParam == "" {
+			inspectorParam = string(DefaultInspectorType)
+		}
+		inspectorType, ok := ParseInspectorType(inspectorParam)
+		if !ok {
+			response := invalidInspectorTypeResponse(inspectorParam)
+			response.Details = fmt.Sprintf("item %d: %s", i, response.Details)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
+		requests[i] = inspectionRequest{
+			vmName:        item.VM,
+			snapshotName:  item.Snapshot,
+			snapshotID:    item.SnapshotID,
+			inspectorType: inspectorType,
+			datacenter:    item.Datacenter,
+		}
+		if err := validateInspectionNames(requests[i]); err != nil {
+			response := invalidNameResponse(err)
+			response.Details = fmt.Sprintf("item %d: %s", i, response.Details)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"items":       len(requests),
+		"concurrency": h.inspectionConfig.BatchConcurrency,
+	}).Info("Starting batch inspection")
+
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	// Items run in rounds of batch_concurrency inspections
+	rounds := (len(requests) + h.inspectionConfig.BatchConcurrency - 1) / h.inspectionConfig.BatchConcurrency
+	h.extendWriteDeadline(c, time.Duration(rounds)*h.inspectionConfig.Timeout)
+
+	// Inspect items concurrently with a bounded number of running inspections
+	results := make([]types.BatchInspectionResult, len(requests))
+	sem := make(chan struct{}, h.inspectionConfig.BatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req inspectionRequest) {
+			defer wg.Done()
+			results[i] = h.inspectBatchItem(ctx, sem, i, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	response := types.BatchInspectionResponse{
+		Results: results,
+		Total:   len(results),
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"succeeded": response.Succeeded,
+		"failed":    response.Failed,
+	}).Info("Batch inspection completed")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// inspectBatchItem inspects one item of a batch, holding a slot of sem while the inspector runs
+// Snapshots with stored results are returned from the cache without waiting for a slot
+func (h *VMHandler) inspectBatchItem(ctx context.Context, sem chan struct{}, index int, req inspectionRequest) types.BatchInspectionResult {
+	result := types.BatchInspectionResult{
+		Index:         index,
+		VMName:        req.vmName,
+		SnapshotName:  req.snapshotName,
+		InspectorType: string(req.inspectorType),
+		Status:        JobStatusFailed,
+	}
+	logger := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"index":          index,
+		"vm_name":        req.vmName,
+		"snapshot_name":  req.snapshotName,
+		"inspector_type": req.inspectorType,
+	})
+
+	cached, err := loadCachedInspection(ctx, h.inspectionDB, req.inspectorType, req.vmName, req.snapshotName)
+	if err != nil {
+		// Fall back to running the inspection
+		logger.WithError(err).Warn("Failed to load cached inspection results")
+	} else if cached != nil {
+		logger.Debug("Batch item served from cached inspection results")
+		result.Status = JobStatusCompleted
+		result.Cached = true
+		result.Result = cached
+		return result
+	}
+
+	select {
+	case sem <- struct{}{}:
+		defer func() { <-sem }()
+	case <-ctx.Done():
+		result.Error = &types.ErrorResponse{
+			Error:   "Inspection cancelled",
+			Code:    "INSPECTION_CANCELLED",
+			Details: fmt.Sprintf("batch item was not started: %v", ctx.Err()),
+		}
+		return result
+	}
+
+	response, failure := h.runInspection(ctx, req)
+	if failure != nil {
+		logger.WithField("code", failure.response.Code).Error("Batch item inspection failed")
+		result.Error = &failure.response
+		return result
+	}
+
+	result.Status = JobStatusCompleted
+	result.Result = response
+	return result
+}
+
+// ListJobs godoc
+// @Summary List the inspection job history
+// @Description List recorded inspection attempts, synchronous and asynchronous, newest first. The history is stored in the database and survives restarts.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param vm query string false "Only list jobs for this VM" example("web-server-01")
+// @Param status query string false "Only list jobs with this status (running, completed, failed)" example("failed")
+// @Param limit query int false "Maximum number of jobs to return (1-500)" default(50)
+// @Param offset query int false "Number of jobs to skip" default(0)
+// @Success 200 {object} types.JobListResponse "Job history"
 // @Failure 400 {object} types.ErrorResponse "Invalid request"
-// @Failure 404 {object} types.ErrorResponse "VM or snapshot not found"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /api/v1/vms/inspect-snapshot [post]
-func (h *VMHandler) InspectSnapshot(c *gin.Context) {
-	vmName := c.Query("vm")
+// @Router /api/v1/jobs [get]
+func (h *VMHandler) ListJobs(c *gin.Context) {
+	var req types.JobListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultJobListLimit
+	}
+
+	records, total, err := h.history.ListJobs(c.Request.Context(), storage.JobHistoryFilter{
+		VMName: req.VM,
+		Status: req.Status,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to list inspection jobs")
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to list jobs",
+			Code:    "JOB_LIST_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	jobs := make([]types.JobHistoryEntry, 0, len(records))
+	for _, record := range records {
+		jobs = append(jobs, types.JobHistoryEntry{
+			JobID:         record.JobID,
+			VMName:        record.VMName,
+			SnapshotName:  record.SnapshotName,
+			InspectorType: record.InspectorType,
+			Datacenter:    record.Datacenter,
+			Async:         record.Async,
+			RequestID:     record.RequestID,
+			Status:        record.Status,
+			CreatedAt:     record.CreatedAt,
+			StartedAt:     record.StartedAt,
+			CompletedAt:   record.CompletedAt,
+			ErrorCode:     record.ErrorCode,
+			ErrorMessage:  record.ErrorMessage,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.JobListResponse{
+		Jobs:   jobs,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// GetJob godoc
+// @Summary Get the status of an inspection job
+// @Description Get the status of an asynchronous inspection job, including the result or error once finished
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID" example("0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10")
+// @Param app_filter query string false "Only return applications whose name contains this string (case-insensitive)" example("mysql")
+// @Param include_apps query bool false "Set to false to omit the application lists" example(false)
+// @Success 200 {object} types.JobStatusResponse "Job status"
+// @Failure 404 {object} types.ErrorResponse "Job not found"
+// @Router /api/v1/jobs/{id} [get]
+func (h *VMHandler) GetJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, ok := h.jobs.Get(jobID)
+	if !ok {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "Job not found",
+			Code:    "JOB_NOT_FOUND",
+			Details: fmt.Sprintf("no inspection job with ID '%s'", jobID),
+		})
+		return
+	}
+
+	apps, ok := parseApplicationFilter(c)
+	if !ok {
+		return
+	}
+
+	status := job.Status()
+	status.Result = apps.apply(status.Result)
+	c.JSON(http.StatusOK, status)
+}
+
+// GetVSphereStatus godoc
+// @Summary Get the vCenter session status
+// @Description Get diagnostics about the vCenter session: when it was established, how often it was re-established and the outcome of the last health check. No request is sent to vCenter.
+// @Tags vsphere
+// @Produce json
+// @Success 200 {object} types.VSphereStatusResponse "vCenter session status"
+// @Router /api/v1/vsphere/status [get]
+func (h *VMHandler) GetVSphereStatus(c *gin.Context) {
+	stats := h.vmClient.Stats()
+
+	response := types.VSphereStatusResponse{
+		Connected:            stats.Connected,
+		VCenterURL:           stats.VCenterURL,
+		ReconnectCount:       stats.ReconnectCount,
+		LastHealthCheckError: stats.LastHealthCheckError,
+	}
+	if !stats.LastConnectTime.IsZero() {
+		response.LastConnectTime = &stats.LastConnectTime
+		if stats.Connected {
+			response.ConnectedFor = time.Since(stats.LastConnectTime).Round(time.Second).String()
+		}
+	}
+	if !stats.LastHealthCheck.IsZero() {
+		response.LastHealthCheck = &stats.LastHealthCheck
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReconnectVSphere godoc
+// @Summary Force a vCenter reconnect
+// @Description Drop the vCenter session and log in again, e.g. after the session was terminated in vCenter or went stale. The login uses the credentials loaded at startup. Running requests that use the old session may fail.
+// @Tags vsphere
+// @Produce json
+// @Success 200 {object} types.VSphereReconnectResponse "Reconnected to vCenter"
+// @Failure 503 {object} types.ErrorResponse "Reconnect failed"
+// @Router /api/v1/vsphere/reconnect [post]
+func (h *VMHandler) ReconnectVSphere(c *gin.Context) {
+	// Record who forced the reconnect, as it disrupts requests using the current session
+	h.log(c).WithFields(logrus.Fields{
+		"client_ip":  c.ClientIP(),
+		"user_agent": c.Request.UserAgent(),
+	}).Warn("vCenter reconnect requested")
+
+	if err := h.vmClient.Reconnect(c.Request.Context()); err != nil {
+		h.log(c).WithError(err).Error("Failed to reconnect to vCenter")
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "Failed to reconnect to vCenter",
+			Code:    "VSPHERE_RECONNECT_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	stats := h.vmClient.Stats()
+	response := types.VSphereReconnectResponse{
+		Connected:      stats.Connected,
+		VCenterURL:     stats.VCenterURL,
+		ReconnectCount: stats.ReconnectCount,
+	}
+
+	// The reconnect already succeeded, so a failed session lookup only leaves out its details
+	session, err := h.vmClient.CurrentSession(c.Request.Context())
+	if err != nil {
+		h.log(c).WithError(err).Warn("Failed to get the new vCenter session")
+	} else {
+		response.SessionKey = session.Key
+		response.UserName = session.UserName
+		response.LoginTime = &session.LoginTime
+	}
+
+	h.log(c).WithField("reconnect_count", stats.ReconnectCount).Info("Reconnected to vCenter")
+	c.JSON(http.StatusOK, response)
+}
+
+// inspectionFailure describes a failed inspection with the HTTP status and error body to return
+type inspectionFailure struct {
+	status   int
+	response types.ErrorResponse
+}
+
+// beginInspection registers a running inspection and returns a context derived from parent
+// that is also cancelled on shutdown. The returned done function must be called when the
+// inspection finishes. If the service is shutting down, a 503 is written and ok is false.
+func (h *VMHandler) beginInspection(c *gin.Context, parent context.Context) (ctx context.Context, done func(), ok bool) {
+	h.shutdownMu.Lock()
+	if h.shuttingDown || h.baseCtx.Err() != nil {
+		h.shutdownMu.Unlock()
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "Service is shutting down",
+			Code:    "SERVICE_SHUTTING_DOWN",
+			Details: "New inspections are not accepted while the service is shutting down",
+		})
+		return nil, nil, false
+	}
+
+	h.inspections.Add(1)
+	h.shutdownMu.Unlock()
+
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(h.baseCtx, cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+		h.inspections.Done()
+	}, true
+}
+
+// writeDeadlineMargin is added to the expected duration when extending a response write deadline
+const writeDeadlineMargin = time.Minute
+
+// extendWriteDeadline lets a synchronous inspection response be written up to d from now,
+// even when server.write_timeout is shorter; otherwise the connection would be closed
+// mid-inspection and the client would get no response
+func (h *VMHandler) extendWriteDeadline(c *gin.Context, d time.Duration) {
+	deadline := time.Now().Add(d + writeDeadlineMargin)
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+		h.log(c).WithError(err).Warn("Failed to extend response write deadline; long inspections may be cut off by server.write_timeout")
+	}
+}
+
+// WaitForInspections blocks until all running inspections have finished or ctx is done
+// Cancel the context passed to NewVMHandler first so running inspections abort
+// Inspections requested after the call are rejected with 503
+func (h *VMHandler) WaitForInspections(ctx context.Context) error {
+	h.shutdownMu.Lock()
+	h.shuttingDown = true
+	h.shutdownMu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		h.inspections.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inspectionRequest holds the validated parameters of an inspection request
+type inspectionRequest struct {
+	vmName         string
+	vmMoref        string // set when the VM was selected by moref or UUID; pins the lookup to that VM
+	snapshotName   string
+	snapshotID     int32 // selects one of several snapshots sharing snapshotName; 0 matches by name only
+	inspectorType  InspectorType
+	datacenter     string                            // empty uses the default datacenter
+	currentState   bool                              // inspect the VM's current disks instead of a snapshot
+	allowSuspended bool                              // with currentState, also accept a suspended VM
+	diskInfo       *validationtypes.SnapshotDiskInfo // disk locations given by the caller; nil looks them up by name
+	jobID          string                            // asynchronous job ID; empty for synchronous inspections
+	apps           applicationFilter                 // trims the application lists of the returned result
+	disk           vmware.DiskSelector               // restricts the inspection to one disk; the zero value inspects all disks
+	force          bool                              // re-run the inspector even when a stored result exists
+}
+
+// parseInspectionParams reads and validates the common inspection query parameters
+// If validation fails, the error response is written and ok is false
+func (h *VMHandler) parseInspectionParams(c *gin.Context) (inspectionRequest, bool) {
 	snapshotName := c.Query("snapshot")
-	inspectorType := c.DefaultQuery("inspector", "virt-inspector") // Default to virt-inspector
+	inspectorParam := c.DefaultQuery("inspector", string(DefaultInspectorType))
 
-	if vmName == "" {
+	vmRef, ok := parseVMSelector(c)
+	if !ok {
+		return inspectionRequest{}, false
+	}
+
+	if snapshotName == "" {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   "VM name is required",
-			Code:    "MISSING_VM_NAME",
-			Details: "Please provide VM name as query parameter: ?vm=xxx",
+			Error:   "Snapshot name is required",
+			Code:    "MISSING_SNAPSHOT_NAME",
+			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
 		})
+		return inspectionRequest{}, false
+	}
+
+	snapshotID, ok := parseSnapshotID(c)
+	if !ok {
+		return inspectionRequest{}, false
+	}
+
+	// Validate inspector type against the registry
+	inspectorType, ok := ParseInspectorType(inspectorParam)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidInspectorTypeResponse(inspectorParam))
+		return inspectionRequest{}, false
+	}
+
+	req := inspectionRequest{
+		snapshotName:  snapshotName,
+		snapshotID:    snapshotID,
+		inspectorType: inspectorType,
+		datacenter:    c.Query("datacenter"),
+	}
+	if !h.resolveVMSelector(c, vmRef, &req) {
+		return inspectionRequest{}, false
+	}
+	if err := validateInspectionNames(req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+		return inspectionRequest{}, false
+	}
+	return req, true
+}
+
+// runInspection runs an inspection and records the attempt in the job history
+func (h *VMHandler) runInspection(ctx context.Context, req inspectionRequest) (*types.VMInspectionResponse, *inspectionFailure) {
+	jobID := req.jobID
+	if jobID == "" {
+		jobID = uuid.New().String()
+	}
+
+	startedAt := time.Now()
+	record := &storage.InspectionJobRecord{
+		JobID:         jobID,
+		VMName:        req.vmName,
+		SnapshotName:  req.snapshotName,
+		InspectorType: string(req.inspectorType),
+		Datacenter:    req.datacenter,
+		Async:         req.jobID != "",
+		RequestID:     requestid.FromContext(ctx),
+		Status:        JobStatusRunning,
+		StartedAt:     &startedAt,
+	}
+	recorded := true
+	if err := h.history.CreateJob(ctx, record); err != nil {
+		// History is an audit trail; failing to write it must not block the inspection
+		h.logger.WithContext(ctx).WithError(err).WithField("job_id", jobID).Warn("Failed to record inspection job")
+		recorded = false
+	}
+
+	response, failure := h.executeInspection(ctx, req)
+
+	status, errorCode, errorMessage := JobStatusCompleted, "", ""
+	if failure != nil {
+		status, errorCode, errorMessage = JobStatusFailed, failure.response.Code, failure.response.Details
+	}
+	if recorded {
+		// The inspection context may already be cancelled (timeout or shutdown)
+		if err := h.history.FinishJob(context.WithoutCancel(ctx), jobID, status, errorCode, errorMessage); err != nil {
+			h.logger.WithContext(ctx).WithError(err).WithField("job_id", jobID).Warn("Failed to record inspection job result")
+		}
+	}
+
+	completedAt := time.Now()
+	record.Status, record.CompletedAt = status, &completedAt
+	record.ErrorCode, record.ErrorMessage = errorCode, errorMessage
+	h.saveArtifacts(context.WithoutCancel(ctx), record, response, failure)
+
+	return response, failure
+}
+
+// saveArtifacts stores the job metadata and the inspection result (or error) of a run,
+// then prunes runs older than the artifact retention
+// Like the job history, artifacts are best effort and never fail the inspection
+func (h *VMHandler) saveArtifacts(ctx context.Context, record *storage.InspectionJobRecord, response *types.VMInspectionResponse, failure *inspectionFailure) {
+	if h.artifacts == nil {
+		return
+	}
+	logger := h.logger.WithContext(ctx).WithField("job_id", record.JobID)
+
+	files := map[string][]byte{}
+	add := func(name string, value interface{}) {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			logger.WithError(err).WithField("artifact", name).Warn("Failed to encode inspection artifact")
+			return
+		}
+		files[name] = data
+	}
+
+	add("job.json", types.JobHistoryEntry{
+		JobID:         record.JobID,
+		VMName:        record.VMName,
+		SnapshotName:  record.SnapshotName,
+		InspectorType: record.InspectorType,
+		Datacenter:    record.Datacenter,
+		Async:         record.Async,
+		RequestID:     record.RequestID,
+		Status:        record.Status,
+		CreatedAt:     *record.StartedAt,
+		StartedAt:     record.StartedAt,
+		CompletedAt:   record.CompletedAt,
+		ErrorCode:     record.ErrorCode,
+		ErrorMessage:  record.ErrorMessage,
+	})
+	if failure != nil {
+		add("error.json", failure.response)
+	} else if response != nil {
+		add("result.json", response)
+	}
+
+	if _, err := h.artifacts.Save(ctx, record.VMName, record.SnapshotName, files); err != nil {
+		logger.WithError(err).Warn("Failed to store inspection artifacts")
 		return
 	}
+	if _, err := h.artifacts.Prune(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to prune inspection artifacts")
+	}
+}
+
+// executeInspection resolves the snapshot disk info and runs the selected inspector on it
+func (h *VMHandler) executeInspection(ctx context.Context, req inspectionRequest) (*types.VMInspectionResponse, *inspectionFailure) {
+	vmName, inspectorType := req.vmName, req.inspectorType
+
+	// Inspectors read the disks through vpx:// URLs, which only vCenter serves
+	if err := h.vmClient.RequireVCenter(ctx); err != nil {
+		h.logger.WithContext(ctx).WithError(err).Error("inspection is not available on this host")
+		if errors.Is(err, vmware.ErrVCenterRequired) {
+			return nil, &inspectionFailure{
+				status:   http.StatusBadRequest,
+				response: vcenterRequiredResponse(err),
+			}
+		}
+		return nil, &inspectionFailure{
+			status: http.StatusServiceUnavailable,
+			response: types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: err.Error(),
+			},
+		}
+	}
+
+	// Only one inspection of a snapshot runs at a time; a request that had to wait
+	// reuses the result stored by the inspection it waited for
+	release, waited, err := h.inspectionLocks.acquire(ctx, inspectionLockKey(req))
+	if err != nil {
+		return nil, &inspectionFailure{
+			status: http.StatusServiceUnavailable,
+			response: types.ErrorResponse{
+				Error:   "Inspection cancelled",
+				Code:    "INSPECTION_CANCELLED",
+				Details: fmt.Sprintf("gave up waiting for a concurrent inspection of the same snapshot: %v", err),
+			},
+		}
+	}
+	defer release()
+
+	// Stored results are returned unless the caller forces a fresh inspection; a forced
+	// request that waited still reuses the result of the inspection it waited for
+	if waited || !req.force {
+		cached, err := loadCachedInspection(ctx, h.inspectionDB, inspectorType, vmName, req.storedName())
+		if err != nil {
+			h.logger.WithContext(ctx).WithError(err).Warn("Failed to load cached inspection results")
+		} else if cached != nil {
+			if waited {
+				h.logger.WithContext(ctx).Info("Reusing result of a concurrent inspection of the same snapshot")
+			} else {
+				h.logger.WithContext(ctx).Info("Returning stored inspection results")
+			}
+			return cached, nil
+		}
+	} else if err := deleteCachedInspection(ctx, h.inspectionDB, inspectorType, vmName, req.storedName()); err != nil {
+		// The inspector would otherwise serve the stale result from its own cache
+		h.logger.WithContext(ctx).WithError(err).Error("failed to delete stored inspection results")
+		return nil, &inspectionFailure{
+			status: http.StatusInternalServerError,
+			response: types.ErrorResponse{
+				Error:   "Inspection failed",
+				Code:    "INSPECTION_FAILED",
+				Details: fmt.Sprintf("failed to delete stored results before a forced inspection: %v", err),
+			},
+		}
+	}
+
+	// SSL verification option for vpx:// URL, derived from the vCenter TLS settings
+	vmwareConfig := h.vmClient.GetConfig()
+	sslVerify := vmwareConfig.VpxSSLVerify()
+
+	// Disk locations given by the caller are used as-is, without a vSphere lookup
+	datacenter, diskInfo := req.datacenter, req.diskInfo
+	if diskInfo == nil {
+		var failure *inspectionFailure
+		datacenter, diskInfo, failure = h.resolveDiskInfo(ctx, req)
+		if failure != nil {
+			return nil, failure
+		}
+	}
+
+	if h.inspectionConfig.VerifyDiskPaths {
+		if failure := h.verifyDiskFiles(ctx, datacenter, diskInfo); failure != nil {
+			return nil, failure
+		}
+	}
+
+	// Use the selected inspector to inspect snapshot
+	var response types.VMInspectionResponse
+	message := fmt.Sprintf("Snapshot inspection completed successfully using %s", inspectorType)
+
+	run := inspectorRegistry[inspectorType]
+	target := inspectionTarget{
+		vmName:       vmName,
+		snapshotName: req.storedName(),
+		datacenter:   datacenter,
+		diskInfo:     diskInfo,
+		sslVerify:    sslVerify,
+	}
+
+	// Wait for a free slot before launching the inspector subprocesses
+	releaseSlot, err := h.inspectionSlots.acquire(ctx)
+	if err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("No inspection slot available")
+		if errors.Is(err, errInspectionQueueFull) {
+			return nil, &inspectionFailure{
+				status: http.StatusTooManyRequests,
+				response: types.ErrorResponse{
+					Error:   "Too many concurrent inspections",
+					Code:    "INSPECTION_QUEUE_FULL",
+					Details: fmt.Sprintf("%v: no slot freed up within %s (max_concurrent_inspections=%d)", err, h.inspectionConfig.QueueTimeout, h.inspectionConfig.MaxConcurrentInspections),
+				},
+			}
+		}
+		return nil, &inspectionFailure{
+			status: http.StatusServiceUnavailable,
+			response: types.ErrorResponse{
+				Error:   "Inspection cancelled",
+				Code:    "INSPECTION_CANCELLED",
+				Details: fmt.Sprintf("gave up waiting for an inspection slot: %v", err),
+			},
+		}
+	}
+	defer releaseSlot()
 
-	if snapshotName == "" {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   "Snapshot name is required",
-			Code:    "MISSING_SNAPSHOT_NAME",
-			Details: "Please provide snapshot name as query parameter: &snapshot=xxx",
-		})
-		return
+	h.logger.WithContext(ctx).WithField("inspector_type", inspectorType).Info("Running inspector with VDDK on snapshot")
+	inspectionDone := metrics.InspectionStarted(string(inspectorType))
+	reportProgress(ctx, PhaseRunningInspector)
+
+	err = h.inspectWithRetry(ctx, string(inspectorType), func() error {
+		var err error
+		response, err = run(ctx, h.inspector, target, message)
+		return err
+	})
+	inspectionDone(err == nil)
+	if errors.Is(err, ErrNoOperatingSystem) {
+		h.logger.WithContext(ctx).WithError(err).WithField("inspector_type", inspectorType).Warn("no operating system found on the inspected disks")
+		return nil, &inspectionFailure{
+			status: http.StatusUnprocessableEntity,
+			response: types.ErrorResponse{
+				Error:   "No operating system detected",
+				Code:    "NO_OS_DETECTED",
+				Details: fmt.Sprintf("the inspected disks may only hold data (e.g. a data-only disk): %v", err),
+			},
+		}
+	}
+	var startupErr *NbdkitStartupError
+	if errors.As(err, &startupErr) {
+		h.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"inspector_type": inspectorType,
+			"cause":          startupErr.Cause,
+		}).Error("nbdkit failed to start")
+		return nil, &inspectionFailure{
+			status: http.StatusInternalServerError,
+			response: types.ErrorResponse{
+				Error:   "Inspection failed: nbdkit could not start",
+				Code:    "NBDKIT_STARTUP_FAILED",
+				Details: fmt.Sprintf("%s: %s (%v)", startupErr.Cause, startupErr.Hint, err),
+			},
+		}
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).WithError(err).WithField("inspector_type", inspectorType).Error("inspection execution failed")
+		return nil, &inspectionFailure{
+			status: http.StatusInternalServerError,
+			response: types.ErrorResponse{
+				Error:   "Inspection failed",
+				Code:    "INSPECTION_FAILED",
+				Details: err.Error(),
+			},
+		}
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"vm_name":        vmName,
-		"snapshot_name":  snapshotName,
-		"inspector_type": inspectorType,
-	}).Info("Inspecting VM snapshot with VDDK")
+	response.InspectedAt = time.Now()
+	return &response, nil
+}
 
-	// Validate inspector type
-	if inspectorType != "virt-inspector" && inspectorType != "virt-v2v-inspector" {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   "Invalid inspector type",
-			Code:    "INVALID_INSPECTOR_TYPE",
-			Details: fmt.Sprintf("inspector must be 'virt-inspector' or 'virt-v2v-inspector', got: %s", inspectorType),
-		})
-		return
+// verifyDiskFiles checks that the disk files the inspector reads exist on their datastores
+func (h *VMHandler) verifyDiskFiles(ctx context.Context, datacenter string, diskInfo *validationtypes.SnapshotDiskInfo) *inspectionFailure {
+	diskPaths := diskInfo.BaseDiskPaths
+	if len(diskPaths) == 0 {
+		diskPaths = diskInfo.DiskPaths
 	}
 
-	// SSL verification option for vpx:// URL
-	// Using no_verify=1 for now to simplify (can be enhanced later with certificate support)
-	sslVerify := "no_verify=1"
+	err := h.vmService.VerifyDiskFiles(ctx, datacenter, diskPaths)
+	if err == nil {
+		return nil
+	}
 
-	datacenter, err := h.vmService.GetDatacenterName(c.Request.Context(), vmName)
-	if err != nil {
-		h.logger.WithError(err).Error("failed to get datacenter name")
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   "Inspection failed",
-			Code:    "INSPECTION_FAILED",
+	h.logger.WithContext(ctx).WithError(err).Error("disk files are not accessible")
+	if errors.Is(err, vmware.ErrDiskNotAccessible) {
+		return &inspectionFailure{
+			status: http.StatusNotFound,
+			response: types.ErrorResponse{
+				Error:   "Disk not accessible",
+				Code:    "DISK_NOT_ACCESSIBLE",
+				Details: err.Error(),
+			},
+		}
+	}
+	return &inspectionFailure{
+		status: http.StatusInternalServerError,
+		response: types.ErrorResponse{
+			Error:   "Failed to verify disk files",
+			Code:    "DISK_VERIFY_FAILED",
 			Details: err.Error(),
-		})
-		return
+		},
 	}
+}
 
-	// Get snapshot disk info (morefs and disk path) from vm_service
-	h.logger.Debug("Getting snapshot disk info from vm_service")
-	diskInfo, err := h.vmService.GetSnapshotDiskInfo(c.Request.Context(), vmName, snapshotName)
+// resolveDiskInfo looks up the datacenter and the snapshot (or current) disk info of the
+// VM to inspect
+// The VM is resolved once and later lookups use its moref, so they cannot pick a
+// different VM that shares its name
+func (h *VMHandler) resolveDiskInfo(ctx context.Context, req inspectionRequest) (string, *validationtypes.SnapshotDiskInfo, *inspectionFailure) {
+	snapshotName := req.snapshotName
+
+	reportProgress(ctx, PhaseResolvingDisks)
+	vmRef := vmware.VMRef{Name: req.vmName, Moref: req.vmMoref}
+	resolved, err := h.vmService.ResolveVM(ctx, vmRef, req.datacenter)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to get snapshot disk info")
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   "Inspection failed",
-			Code:    "INSPECTION_FAILED",
-			Details: fmt.Sprintf("failed to get snapshot disk info: %v", err),
-		})
-		return
-	}
+		h.logger.WithContext(ctx).WithError(err).Error("failed to resolve VM")
+
+		if errors.Is(err, vmware.ErrDatacenterNotFound) {
+			return "", nil, &inspectionFailure{
+				status: http.StatusNotFound,
+				response: types.ErrorResponse{
+					Error:   "Datacenter not found",
+					Code:    "DATACENTER_NOT_FOUND",
+					Details: err.Error(),
+				},
+			}
+		}
 
-	// Use the selected inspector to inspect snapshot
-	var response types.VMInspectionResponse
-	message := fmt.Sprintf("Snapshot inspection completed successfully using %s", inspectorType)
+		if errors.Is(err, vmware.ErrVMNotInDatacenter) {
+			return "", nil, &inspectionFailure{
+				status: http.StatusNotFound,
+				response: types.ErrorResponse{
+					Error:   "VM not found in datacenter",
+					Code:    "VM_NOT_IN_DATACENTER",
+					Details: err.Error(),
+				},
+			}
+		}
 
-	if inspectorType == "virt-v2v-inspector" {
-		h.logger.Info("Running virt-v2v-inspector with VDDK on snapshot")
-		inspectionData, err := h.inspector.InspectWithVirtV2v(
-			c.Request.Context(),
-			vmName,
-			snapshotName,
-			datacenter,
-			diskInfo,
-			sslVerify,
-		)
-		if err != nil {
-			h.logger.WithError(err).WithField("inspector_type", inspectorType).Error("inspection execution failed")
-			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		return "", nil, &inspectionFailure{
+			status: http.StatusInternalServerError,
+			response: types.ErrorResponse{
 				Error:   "Inspection failed",
 				Code:    "INSPECTION_FAILED",
 				Details: err.Error(),
-			})
-			return
+			},
 		}
-		response = types.NewVirtV2VInspectorResponse(vmName, snapshotName, message, inspectionData)
+	}
+	datacenter := resolved.Datacenter
+	vmRef = vmware.VMRef{Moref: resolved.Moref}
+
+	// Get snapshot disk info (morefs and disk path) from vm_service
+	var diskInfo *validationtypes.SnapshotDiskInfo
+	if req.currentState {
+		h.logger.WithContext(ctx).Debug("Getting current disk info from vm_service")
+		diskInfo, err = h.vmService.GetCurrentDiskInfo(ctx, vmRef, datacenter, req.allowSuspended)
 	} else {
-		// Default: use virt-inspector
-		h.logger.Info("Running virt-inspector with VDDK on snapshot")
-		inspectionData, err := h.inspector.InspectWithVirt(
-			c.Request.Context(),
-			vmName,
-			snapshotName,
-			datacenter,
-			diskInfo,
-		)
-		if err != nil {
-			h.logger.WithError(err).WithField("inspector_type", inspectorType).Error("inspection execution failed")
-			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		h.logger.WithContext(ctx).Debug("Getting snapshot disk info from vm_service")
+		diskInfo, err = h.vmService.GetSnapshotDiskInfo(ctx, vmRef, snapshotName, req.snapshotID, datacenter)
+	}
+	if err != nil {
+		if errors.Is(err, vmware.ErrVMPoweredOn) {
+			h.logger.WithContext(ctx).WithError(err).Warn("refusing to inspect current disks of a running VM")
+			return "", nil, &inspectionFailure{
+				status: http.StatusConflict,
+				response: types.ErrorResponse{
+					Error:   "VM is powered on",
+					Code:    "VM_POWERED_ON",
+					Details: err.Error(),
+				},
+			}
+		}
+
+		if errors.Is(err, vmware.ErrVMEncrypted) {
+			h.logger.WithContext(ctx).WithError(err).Warn("refusing to inspect an encrypted VM")
+			return "", nil, &inspectionFailure{
+				status: http.StatusUnprocessableEntity,
+				response: types.ErrorResponse{
+					Error:   "VM is encrypted",
+					Code:    "VM_ENCRYPTED",
+					Details: err.Error(),
+				},
+			}
+		}
+
+		if errors.Is(err, vmware.ErrSnapshotAmbiguous) {
+			h.logger.WithContext(ctx).WithError(err).Warn("snapshot name matches several snapshots")
+			return "", nil, &inspectionFailure{
+				status: http.StatusConflict,
+				response: types.ErrorResponse{
+					Error:   "Snapshot name is ambiguous",
+					Code:    "SNAPSHOT_AMBIGUOUS",
+					Details: err.Error(),
+				},
+			}
+		}
+
+		h.logger.WithContext(ctx).WithError(err).Error("failed to get snapshot disk info")
+		return "", nil, &inspectionFailure{
+			status: http.StatusInternalServerError,
+			response: types.ErrorResponse{
 				Error:   "Inspection failed",
 				Code:    "INSPECTION_FAILED",
-				Details: err.Error(),
-			})
-			return
+				Details: fmt.Sprintf("failed to get snapshot disk info: %v", err),
+			},
 		}
-		response = types.NewVirtInspectorResponse(vmName, snapshotName, message, inspectionData)
 	}
 
-	h.logger.WithField("inspector_type", inspectorType).Info("Snapshot inspection completed successfully")
-	c.JSON(http.StatusOK, response)
+	if !req.disk.IsZero() {
+		diskInfo, err = h.vmService.SelectDisk(ctx, vmRef, datacenter, diskInfo, req.disk)
+		if err != nil {
+			h.logger.WithContext(ctx).WithError(err).Error("failed to select disk to inspect")
+			if errors.Is(err, vmware.ErrDiskNotFound) {
+				return "", nil, &inspectionFailure{
+					status: http.StatusBadRequest,
+					response: types.ErrorResponse{
+						Error:   "Disk not found",
+						Code:    "DISK_NOT_FOUND",
+						Details: err.Error(),
+					},
+				}
+			}
+			return "", nil, &inspectionFailure{
+				status: http.StatusInternalServerError,
+				response: types.ErrorResponse{
+					Error:   "Inspection failed",
+					Code:    "INSPECTION_FAILED",
+					Details: fmt.Sprintf("failed to select disk: %v", err),
+				},
+			}
+		}
+	}
+
+	return datacenter, diskInfo, nil
+}
+
+// inspectWithRetry runs an inspection, retrying transient failures with exponential backoff
+// Every attempt calls the inspector again, which opens a fresh VDDK disk session
+func (h *VMHandler) inspectWithRetry(ctx context.Context, inspectorType string, inspect func() error) error {
+	var lastErr error
+	delay := h.inspectionConfig.RetryDelay
+	attempts := 0
+
+	for attempt := 0; attempt <= h.inspectionConfig.RetryAttempts; attempt++ {
+		if attempt > 0 {
+			h.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"inspector_type": inspectorType,
+				"attempt":        attempt + 1,
+				"delay":          delay,
+			}).Warn("Retrying inspection after transient failure")
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("inspection cancelled after %d attempt(s): %w", attempts, lastErr)
+			case <-time.After(delay):
+			}
+			delay *= 2
+			reportProgress(ctx, PhaseRetrying)
+		}
+
+		attempts++
+		err := inspect()
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		h.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"inspector_type": inspectorType,
+			"attempt":        attempts,
+			"error":          err,
+		}).Warn("Inspection attempt failed")
+
+		// Only connection-level failures are worth retrying; parse errors and
+		// "no operating systems found" will fail the same way every time
+		if ctx.Err() != nil || !isTransientInspectionError(err) {
+			break
+		}
+	}
+
+	// The attempt count only adds information when the inspection was retried
+	if attempts == 1 {
+		return lastErr
+	}
+	return fmt.Errorf("inspection failed after %d attempt(s): %w", attempts, lastErr)
 }
 
 // DeleteClone godoc
 // @Summary Delete a cloned VM
-// @Description Delete a cloned VM created for inspection
+// @Description Delete a cloned VM created for inspection. A VM whose disks back other linked clones is not deleted unless force is set, as destroying it would break those clones
 // @Tags vms
 // @Accept json
 // @Produce json
 // @Param name query string true "Clone VM name" example("web-server-01-clone-123")
+// @Param force query bool false "Delete the VM even if linked clones depend on its disks" example(false)
 // @Success 200 {object} types.ErrorResponse "Clone deleted successfully"
 // @Failure 400 {object} types.ErrorResponse "Invalid request"
 // @Failure 404 {object} types.ErrorResponse "Clone not found"
+// @Failure 409 {object} types.ErrorResponse "Linked clones depend on the VM's disks"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /api/v1/vms/delete-clone [delete]
 func (h *VMHandler) DeleteClone(c *gin.Context) {
@@ -535,11 +2934,27 @@ func (h *VMHandler) DeleteClone(c *gin.Context) {
 		return
 	}
 
-	h.logger.WithField("clone_name", cloneName).Info("Deleting clone")
+	force, ok := parseForce(c)
+	if !ok {
+		return
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"clone_name": cloneName,
+		"force":      force,
+	}).Info("Deleting clone")
 
-	err := h.vmService.DeleteVM(c.Request.Context(), cloneName)
+	err := h.vmService.DeleteVM(c.Request.Context(), cloneName, force)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to delete clone")
+		h.log(c).WithError(err).Error("Failed to delete clone")
+		if errors.Is(err, vmware.ErrVMHasDependentClones) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "VM has dependent linked clones",
+				Code:    "VM_HAS_DEPENDENT_CLONES",
+				Details: fmt.Sprintf("%v; delete the clones first or pass force=true", err),
+			})
+			return
+		}
 		if isNotFoundError(err) {
 			c.JSON(http.StatusNotFound, types.ErrorResponse{
 				Error:   "Clone not found",
@@ -556,13 +2971,100 @@ func (h *VMHandler) DeleteClone(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Clone deleted successfully")
+	h.log(c).Info("Clone deleted successfully")
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Clone deleted successfully",
 	})
 }
 
+// CleanupClones godoc
+// @Summary Delete stale inspection clones
+// @Description Delete leaked clones created by this service (marked with the vmdi.clone extraConfig key, or named with a generated -inspect-clone-<unix time> or -clone-<YYYYMMDDHHMMSS> suffix) that are powered off and older than the given age
+// @Tags vms
+// @Accept json
+// @Produce json
+// @Param older_than query string false "Minimum clone age as a Go duration (defaults to inspection.stale_clone_age)" example("1h")
+// @Param pattern query string false "Additional glob pattern clone names must match" example("web-server-01-*")
+// @Success 200 {object} types.CloneCleanupResponse "Cleanup completed"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "vSphere connection unavailable"
+// @Router /api/v1/vms/cleanup-clones [post]
+func (h *VMHandler) CleanupClones(c *gin.Context) {
+	olderThan := h.inspectionConfig.StaleCloneAge
+	if value := c.Query("older_than"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid older_than parameter",
+				Code:    "INVALID_OLDER_THAN",
+				Details: fmt.Sprintf("older_than must be a duration such as 30m or 2h, got: %s", value),
+			})
+			return
+		}
+		olderThan = parsed
+	}
+	if olderThan <= 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid older_than parameter",
+			Code:    "INVALID_OLDER_THAN",
+			Details: "older_than must be a positive duration; it defaults to inspection.stale_clone_age, which is 0 unless configured",
+		})
+		return
+	}
+
+	pattern := c.Query("pattern")
+
+	result, err := h.vmService.CleanupStaleClones(c.Request.Context(), olderThan, pattern)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to clean up stale clones")
+
+		if errors.Is(err, vmware.ErrInvalidClonePattern) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid pattern parameter",
+				Code:    "INVALID_CLONE_PATTERN",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isConnectionError(err) {
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to clean up clones",
+			Code:    "CLONE_CLEANUP_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	failed := make([]types.CloneCleanupFailure, 0, len(result.Failed))
+	for _, failure := range result.Failed {
+		failed = append(failed, types.CloneCleanupFailure{
+			Name:  failure.Name,
+			Error: failure.Error,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.CloneCleanupResponse{
+		OlderThan: olderThan.String(),
+		Pattern:   pattern,
+		Deleted:   result.Deleted,
+		Failed:    failed,
+		Skipped:   result.Skipped,
+		Status:    "completed",
+		Message:   fmt.Sprintf("Deleted %d stale clone(s)", len(result.Deleted)),
+	})
+}
+
 // CreateVMSnapshot godoc
 // @Summary Create a VM snapshot
 // @Description Create a snapshot for a specific virtual machine
@@ -592,7 +3094,7 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 	// Parse request body
 	var req types.SnapshotCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Error("Failed to bind snapshot request")
+		h.log(c).WithError(err).Error("Failed to bind snapshot request")
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Invalid request body",
 			Code:    "INVALID_REQUEST",
@@ -601,7 +3103,7 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
+	h.log(c).WithFields(logrus.Fields{
 		"vm_name":       vmName,
 		"snapshot_name": req.Name,
 		"memory":        req.Memory,
@@ -609,7 +3111,7 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 	}).Info("Creating VM snapshot")
 
 	// Create snapshot
-	snapshotID, err := h.vmService.CreateSnapshot(
+	result, err := h.vmService.CreateSnapshot(
 		c.Request.Context(),
 		vmName,
 		req.Name,
@@ -619,7 +3121,7 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 	)
 
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to create snapshot")
+		h.log(c).WithError(err).Error("Failed to create snapshot")
 
 		if isConnectionError(err) {
 			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
@@ -648,7 +3150,7 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 	}
 
 	response := types.SnapshotCreateResponse{
-		SnapshotID: snapshotID,
+		SnapshotID: result.TaskID,
 		Name:       req.Name,
 		VMID:       "",
 		VMName:     vmName,
@@ -656,9 +3158,19 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 		Message:    "Snapshot created successfully",
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"snapshot_id": snapshotID,
+	// vSphere falls back to a crash-consistent snapshot when it cannot quiesce the guest
+	if req.Quiesce {
+		if !result.QuiesceVerified {
+			response.Warnings = append(response.Warnings, "Quiesce was requested but it could not be verified that the snapshot is quiesced")
+		} else if !result.Quiesced {
+			response.Warnings = append(response.Warnings, "Quiesce was requested but the snapshot is not quiesced; it is only crash-consistent (is VMware Tools running?)")
+		}
+	}
+
+	h.log(c).WithFields(logrus.Fields{
+		"snapshot_id": result.TaskID,
 		"vm_name":     vmName,
+		"warnings":    len(response.Warnings),
 	}).Info("Snapshot created successfully")
 
 	c.JSON(http.StatusOK, response)
@@ -667,9 +3179,66 @@ func (h *VMHandler) CreateVMSnapshot(c *gin.Context) {
 // convertVMInfoToVM converts internal VMInfo to API VM type
 func (h *VMHandler) convertVMInfoToVM(vmInfo vmware.VMInfo) types.VM {
 	return types.VM{
-		UUID:       vmInfo.UUID,
-		Name:       vmInfo.Name,
-		PowerState: vmInfo.PowerState,
+		UUID:            vmInfo.UUID,
+		Moref:           vmInfo.Moref,
+		Name:            vmInfo.Name,
+		PowerState:      vmInfo.PowerState,
+		ConnectionState: vmInfo.ConnectionState,
+		Degraded:        vmInfo.Degraded,
+	}
+}
+
+// writeVMListError writes the error response for a failed VM listing
+func writeVMListError(c *gin.Context, err error) {
+	if errors.Is(err, vmware.ErrDatacenterNotFound) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "Datacenter not found",
+			Code:    "DATACENTER_NOT_FOUND",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, vmware.ErrClusterNotFound) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "Cluster not found",
+			Code:    "CLUSTER_NOT_FOUND",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if isConnectionError(err) {
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "vSphere connection unavailable",
+			Code:    "VSPHERE_UNAVAILABLE",
+			Details: "Unable to connect to vSphere. Please try again later.",
+		})
+		return
+	}
+
+	if isAuthenticationError(err) {
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "vSphere authentication failed",
+			Code:    "VSPHERE_AUTH_FAILED",
+			Details: "Authentication to vSphere failed. Please check configuration.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		Error:   "Failed to retrieve VMs",
+		Code:    "VM_LIST_FAILED",
+		Details: "An error occurred while retrieving virtual machines from vSphere",
+	})
+}
+
+// vcenterRequiredResponse describes a vCenter-only feature requested against a standalone ESXi host
+func vcenterRequiredResponse(err error) types.ErrorResponse {
+	return types.ErrorResponse{
+		Error:   "vCenter required",
+		Code:    "VCENTER_REQUIRED",
+		Details: fmt.Sprintf("%v. Linked clones, inspections and checks need a vCenter connection (vpx:// disk access)", err),
 	}
 }
 
@@ -683,6 +3252,26 @@ func isConnectionError(err error) bool {
 		contains(errStr, "dial")
 }
 
+func isTransientInspectionError(err error) bool {
+	// Missing VDDK, rejected credentials and similar startup failures don't go away on retry,
+	// except for disks locked by another process
+	var startupErr *NbdkitStartupError
+	if errors.As(err, &startupErr) {
+		return startupErr.Cause == nbdkitCauseDiskLocked
+	}
+
+	errStr := err.Error()
+	if contains(errStr, "parse") ||
+		contains(errStr, "xml") ||
+		contains(errStr, "no operating system") {
+		return false
+	}
+	return isConnectionError(err) ||
+		contains(errStr, "reset by peer") ||
+		contains(errStr, "broken pipe") ||
+		contains(errStr, "locked")
+}
+
 func isAuthenticationError(err error) bool {
 	// Check for authentication-related errors
 	errStr := err.Error()
@@ -732,22 +3321,27 @@ func toLower(b byte) byte {
 
 // RunCheck godoc
 // @Summary Run validation checks on a VM snapshot
-// @Description Run validation checks on a VM snapshot. If check parameter is provided, runs that specific check. If omitted, runs all available checks.
+// @Description Run validation checks on a VM snapshot. The checks to run can be listed in the request body or passed as the check parameter. If neither is provided, runs all available checks.
 // @Tags vms
 // @Accept json
 // @Produce json
 // @Param vm query string true "Original VM name" example("web-server-01")
 // @Param snapshot query string true "Snapshot name" example("inspection-snapshot")
-// @Param check query string false "Check type to run (fstab, disk-access). If omitted, runs all checks." example("fstab")
+// @Param snapshot_id query int false "Snapshot ID from the snapshot listing, to select one of several snapshots with the same name" example(3)
+// @Param check query string false "Check type to run (fstab, disk-access, vmware-tools). If omitted, runs all checks." example("fstab")
+// @Param datacenter query string false "Datacenter containing the VM (defaults to the default datacenter)" example("Datacenter1")
+// @Param request body types.CheckRequest false "Check types to run"
 // @Success 200 {object} types.CheckResponse "Check completed successfully"
 // @Failure 400 {object} types.ErrorResponse "Invalid request"
 // @Failure 404 {object} types.ErrorResponse "VM or snapshot not found"
+// @Failure 409 {object} types.ErrorResponse "Several snapshots have the snapshot name; pass snapshot_id"
+// @Failure 422 {object} types.ErrorResponse "VM is encrypted"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 503 {object} types.ErrorResponse "Service is shutting down"
 // @Router /api/v1/vms/check [post]
 func (h *VMHandler) RunCheck(c *gin.Context) {
 	vmName := c.Query("vm")
 	snapshotName := c.Query("snapshot")
-	checkType := c.Query("check")
 
 	if vmName == "" {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
@@ -767,21 +3361,97 @@ func (h *VMHandler) RunCheck(c *gin.Context) {
 		return
 	}
 
-	logFields := logrus.Fields{
+	snapshotID, ok := parseSnapshotID(c)
+	if !ok {
+		return
+	}
+
+	if err := validateInspectionNames(inspectionRequest{vmName: vmName, snapshotName: snapshotName, datacenter: c.Query("datacenter")}); err != nil {
+		c.JSON(http.StatusBadRequest, invalidNameResponse(err))
+		return
+	}
+
+	// The request body is optional; an empty body runs all checks
+	var req types.CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log(c).WithError(err).Error("Failed to bind check request")
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+	checkTypes := req.Checks
+	if checkType := c.Query("check"); checkType != "" {
+		checkTypes = append(checkTypes, checkType)
+	}
+
+	// Determine which checks to run, validating them before any vSphere work
+	checksToRun := SupportedCheckTypes()
+	if len(checkTypes) > 0 {
+		checksToRun = nil
+		seen := make(map[string]bool)
+		for _, checkType := range checkTypes {
+			if _, exists := checkRegistry[checkType]; !exists {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error:   "Unknown check type",
+					Code:    "UNKNOWN_CHECK_TYPE",
+					Details: fmt.Sprintf("check type '%s' is not supported. Supported types: %s", checkType, strings.Join(SupportedCheckTypes(), ", ")),
+				})
+				return
+			}
+			if !seen[checkType] {
+				seen[checkType] = true
+				checksToRun = append(checksToRun, checkType)
+			}
+		}
+	}
+
+	h.log(c).WithFields(logrus.Fields{
 		"vm_name":       vmName,
 		"snapshot_name": snapshotName,
-	}
-	if checkType != "" {
-		logFields["check_type"] = checkType
-		h.logger.WithFields(logFields).Info("Running specific validation check on VM snapshot")
-	} else {
-		h.logger.WithFields(logFields).Info("Running all validation checks on VM snapshot")
+		"checks":        checksToRun,
+	}).Info("Running validation checks on VM snapshot")
+
+	// Checks read the snapshot disks through vpx:// URLs, which only vCenter serves
+	if err := h.vmClient.RequireVCenter(c.Request.Context()); err != nil {
+		h.log(c).WithError(err).Error("checks are not available on this host")
+		if errors.Is(err, vmware.ErrVCenterRequired) {
+			c.JSON(http.StatusBadRequest, vcenterRequiredResponse(err))
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "vSphere connection unavailable",
+			Code:    "VSPHERE_UNAVAILABLE",
+			Details: err.Error(),
+		})
+		return
 	}
 
 	// Get datacenter name
-	datacenter, err := h.vmService.GetDatacenterName(c.Request.Context(), vmName)
+	datacenter, err := h.vmService.GetDatacenterName(c.Request.Context(), vmName, c.Query("datacenter"))
 	if err != nil {
-		h.logger.WithError(err).Error("failed to get datacenter name")
+		h.log(c).WithError(err).Error("failed to get datacenter name")
+
+		if errors.Is(err, vmware.ErrDatacenterNotFound) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Datacenter not found",
+				Code:    "DATACENTER_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, vmware.ErrVMNotInDatacenter) || isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Check failed",
 			Code:    "CHECK_FAILED",
@@ -791,10 +3461,38 @@ func (h *VMHandler) RunCheck(c *gin.Context) {
 	}
 
 	// Get snapshot disk info
-	h.logger.Debug("Getting snapshot disk info from vm_service")
-	diskInfo, err := h.vmService.GetSnapshotDiskInfo(c.Request.Context(), vmName, snapshotName)
+	h.log(c).Debug("Getting snapshot disk info from vm_service")
+	diskInfo, err := h.vmService.GetSnapshotDiskInfo(c.Request.Context(), vmware.VMRef{Name: vmName}, snapshotName, snapshotID, datacenter)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to get snapshot disk info")
+		h.log(c).WithError(err).Error("failed to get snapshot disk info")
+
+		if errors.Is(err, vmware.ErrVMEncrypted) {
+			c.JSON(http.StatusUnprocessableEntity, types.ErrorResponse{
+				Error:   "VM is encrypted",
+				Code:    "VM_ENCRYPTED",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, vmware.ErrSnapshotAmbiguous) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "Snapshot name is ambiguous",
+				Code:    "SNAPSHOT_AMBIGUOUS",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if isNotFoundError(err) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Snapshot not found",
+				Code:    "SNAPSHOT_NOT_FOUND",
+				Details: err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Check failed",
 			Code:    "CHECK_FAILED",
@@ -807,65 +3505,52 @@ func (h *VMHandler) RunCheck(c *gin.Context) {
 	vcenterURL := h.vmClient.GetVCenterURL()
 	username, password := h.vmClient.GetCredentials()
 
-	// Create inspection params
-	params := checks.InspectionParams{
-		Ctx:          c.Request.Context(),
-		VMName:       vmName,
-		SnapshotName: snapshotName,
-		Datacenter:   datacenter,
-		VCenterURL:   vcenterURL,
-		Username:     username,
-		Password:     password,
-		DiskInfo:     diskInfo,
-		DB:           h.inspector.GetDB(),
-		Logger:       h.logger,
-	}
-
-	// Define all available checks
-	allChecks := map[string]checks.Check{
-		"fstab":       checks.NewFstabCheck(),
-		"disk-access": checks.NewDiskAccessCheck(),
-	}
-
-	// Determine which checks to run
-	var checksToRun map[string]checks.Check
-	if checkType != "" {
-		// Run specific check
-		check, exists := allChecks[checkType]
-		if !exists {
-			c.JSON(http.StatusBadRequest, types.ErrorResponse{
-				Error:   "Unknown check type",
-				Code:    "UNKNOWN_CHECK_TYPE",
-				Details: fmt.Sprintf("check type '%s' is not supported. Supported types: fstab, disk-access", checkType),
-			})
-			return
-		}
-		checksToRun = map[string]checks.Check{checkType: check}
-	} else {
-		// Run all checks
-		checksToRun = allChecks
+	ctx, done, ok := h.beginInspection(c, c.Request.Context())
+	if !ok {
+		return
+	}
+	defer done()
+
+	h.extendWriteDeadline(c, time.Duration(len(checksToRun))*h.inspectionConfig.Timeout)
+
+	// Disk access parameters for checks that read the snapshot disks directly,
+	// plus a lazily-run virt-inspector inspection for checks that use its results
+	data := &InspectionData{
+		Params: checks.InspectionParams{
+			Ctx:          ctx,
+			VMName:       vmName,
+			SnapshotName: snapshotName,
+			Datacenter:   datacenter,
+			VCenterURL:   vcenterURL,
+			Username:     username,
+			Password:     password,
+			DiskInfo:     diskInfo,
+			DB:           h.inspector.GetDB(),
+			Logger:       h.logger,
+		},
+		loadInspection: func(ctx context.Context) (interface{}, error) {
+			result, err := h.inspector.InspectWithVirt(ctx, vmName, snapshotName, datacenter, diskInfo)
+			if err != nil {
+				return nil, err
+			}
+			return decodeInspection(result)
+		},
 	}
 
-	// Execute all selected checks
-	var results []types.CheckResult
+	// Execute all selected checks in a deterministic order
+	results := make([]types.CheckResult, 0, len(checksToRun))
 	allValid := true
 
-	for name, check := range checksToRun {
-		h.logger.WithField("check_type", name).Info("Executing validation check")
-		result := check.Run(params)
-
-		results = append(results, types.CheckResult{
-			CheckType: name,
-			Valid:     result.Valid,
-			Message:   result.Message,
-			Error:     result.Error,
-		})
+	for _, name := range checksToRun {
+		h.log(c).WithField("check_type", name).Info("Executing validation check")
+		result := checkRegistry[name].Run(ctx, data)
+		results = append(results, result)
 
 		if !result.Valid {
 			allValid = false
 		}
 
-		h.logger.WithFields(logrus.Fields{
+		h.log(c).WithFields(logrus.Fields{
 			"check_type": name,
 			"valid":      result.Valid,
 		}).Info("Validation check completed")
@@ -878,7 +3563,7 @@ func (h *VMHandler) RunCheck(c *gin.Context) {
 		AllValid:     allValid,
 	}
 
-	h.logger.WithFields(logrus.Fields{
+	h.log(c).WithFields(logrus.Fields{
 		"checks_run": len(results),
 		"all_valid":  allValid,
 	}).Info("All validation checks completed")
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestHandler returns a VMHandler without vSphere, inspector or database, for the
+// inspection flow helpers
+func newTestHandler(inspectionConfig config.InspectionConfig) *VMHandler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewVMHandler(context.Background(), nil, nil, nil, nil, nil, nil, nil, inspectionConfig, logger)
+}
+
+func TestIsTransientInspectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection refused", err: errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), want: true},
+		{name: "timeout", err: errors.New("nbdkit: vddk: VixDiskLib_Open: timeout waiting for host"), want: true},
+		{name: "connection reset", err: errors.New("read tcp: reset by peer"), want: true},
+		{name: "broken pipe", err: errors.New("write unix @->/tmp/nbd.sock: broken pipe"), want: true},
+		{name: "locked disk", err: errors.New("disk file is locked by another process"), want: true},
+		{name: "XML parse failure", err: errors.New("failed to parse virt-inspector XML output"), want: false},
+		{name: "no operating system", err: errors.New("virt-inspector: no operating systems found"), want: false},
+		{name: "parse failure mentioning a connection", err: errors.New("failed to parse output after connection closed"), want: false},
+		{name: "unrelated failure", err: errors.New("snapshot not found"), want: false},
+		{name: "missing VDDK", err: &NbdkitStartupError{Cause: "VDDK library not found", Err: errors.New("connection timeout")}, want: false},
+		{name: "locked disks at nbdkit startup", err: &NbdkitStartupError{Cause: nbdkitCauseDiskLocked, Err: errors.New("file is locked")}, want: true},
+		{name: "wrapped startup failure", err: fmt.Errorf("inspection: %w", &NbdkitStartupError{Cause: "vCenter login rejected", Err: errors.New("timeout")}), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientInspectionError(tt.err); got != tt.want {
+				t.Errorf("isTransientInspectionError(%q) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectWithRetry(t *testing.T) {
+	transient := errors.New("connection reset by peer")
+	permanent := errors.New("no operating systems found")
+
+	tests := []struct {
+		name         string
+		errs         []error // returned by successive attempts; nil means success
+		wantAttempts int
+		wantErr      error
+		wantWrapped  bool
+	}{
+		{name: "first attempt succeeds", errs: []error{nil}, wantAttempts: 1},
+		{name: "non-transient failure is returned unchanged", errs: []error{permanent}, wantAttempts: 1, wantErr: permanent},
+		{name: "transient failure then success", errs: []error{transient, nil}, wantAttempts: 2},
+		{name: "transient failures exhaust the retries", errs: []error{transient, transient, transient}, wantAttempts: 3, wantErr: transient, wantWrapped: true},
+		{name: "transient then non-transient failure", errs: []error{transient, permanent}, wantAttempts: 2, wantErr: permanent, wantWrapped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(config.InspectionConfig{RetryAttempts: 2, RetryDelay: time.Millisecond})
+
+			attempts := 0
+			err := h.inspectWithRetry(context.Background(), "virt-inspector", func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("inspectWithRetry() made %d attempt(s), want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("inspectWithRetry() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("inspectWithRetry() error = %v, want %v", err, tt.wantErr)
+			}
+			if wrapped := err != tt.wantErr; wrapped != tt.wantWrapped {
+				t.Errorf("inspectWithRetry() error = %q, wrapped = %v, want %v", err, wrapped, tt.wantWrapped)
+			}
+			if tt.wantWrapped && !strings.Contains(err.Error(), fmt.Sprintf("after %d attempt(s)", tt.wantAttempts)) {
+				t.Errorf("inspectWithRetry() error = %q, want the attempt count", err)
+			}
+		})
+	}
+}
+
+// newTestContext returns a gin context for a GET request and the recorder of its response
+func newTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	return c, recorder
+}
+
+func TestWaitForInspections(t *testing.T) {
+	baseCtx, cancelInspections := context.WithCancel(context.Background())
+	h := newTestHandler(config.InspectionConfig{})
+	h.baseCtx = baseCtx
+
+	c, _ := newTestContext()
+	ctx, done, ok := h.beginInspection(c, context.Background())
+	if !ok {
+		t.Fatal("beginInspection() ok = false before shutdown, want true")
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- h.WaitForInspections(context.Background())
+	}()
+
+	// Shutdown cancels the running inspection, but WaitForInspections returns only once it
+	// has finished
+	cancelInspections()
+	<-ctx.Done()
+	select {
+	case err := <-waited:
+		t.Fatalf("WaitForInspections() = %v before the running inspection finished", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	done()
+	if err := <-waited; err != nil {
+		t.Errorf("WaitForInspections() = %v, want nil", err)
+	}
+}
+
+func TestBeginInspectionAfterShutdown(t *testing.T) {
+	// The handler context is still live: WaitForInspections alone must stop new inspections
+	h := newTestHandler(config.InspectionConfig{})
+	if err := h.WaitForInspections(context.Background()); err != nil {
+		t.Fatalf("WaitForInspections() = %v, want nil", err)
+	}
+
+	c, recorder := newTestContext()
+	if _, _, ok := h.beginInspection(c, context.Background()); ok {
+		t.Fatal("beginInspection() ok = true after WaitForInspections, want false")
+	}
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("beginInspection() status = %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestBeginInspectionConcurrentWithShutdown(t *testing.T) {
+	// Run with -race: registering inspections must not race with WaitForInspections
+	h := newTestHandler(config.InspectionConfig{})
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		close(started)
+		for i := 0; i < 100; i++ {
+			c, _ := newTestContext()
+			if _, done, ok := h.beginInspection(c, context.Background()); ok {
+				done()
+			}
+		}
+	}()
+
+	<-started
+	if err := h.WaitForInspections(context.Background()); err != nil {
+		t.Errorf("WaitForInspections() = %v, want nil", err)
+	}
+	<-finished
+}
+
+func TestParseForce(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantForce bool
+		wantOK    bool
+	}{
+		{query: "", wantForce: false, wantOK: true},
+		{query: "force=true", wantForce: true, wantOK: true},
+		{query: "force=false", wantForce: false, wantOK: true},
+		{query: "force=1", wantForce: true, wantOK: true},
+		{query: "force=yes", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		c, recorder := newTestContext()
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+		force, ok := parseForce(c)
+		if force != tt.wantForce || ok != tt.wantOK {
+			t.Errorf("parseForce(%q) = %v, %v, want %v, %v", tt.query, force, ok, tt.wantForce, tt.wantOK)
+		}
+		if !ok && recorder.Code != http.StatusBadRequest {
+			t.Errorf("parseForce(%q) status = %d, want %d", tt.query, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestExecuteInspectionNoOperatingSystem(t *testing.T) {
+	handler, _ := newSimulatorHandler(t)
+	handler.inspectionConfig.VerifyDiskPaths = false
+
+	// virt-inspector exits with this error when its result holds no <operatingsystem>
+	runs := 0
+	original := inspectorRegistry[InspectorTypeVirtInspector]
+	inspectorRegistry[InspectorTypeVirtInspector] = func(ctx context.Context, inspector *persistent.Inspector, target inspectionTarget, message string) (types.VMInspectionResponse, error) {
+		runs++
+		return types.VMInspectionResponse{}, classifyInspectorError(errors.New("virt-inspector: no operating systems found"))
+	}
+	t.Cleanup(func() {
+		inspectorRegistry[InspectorTypeVirtInspector] = original
+	})
+
+	_, failure := handler.executeInspection(context.Background(), inspectionRequest{
+		vmName:        "DC0_H0_VM0",
+		snapshotName:  "data-only",
+		inspectorType: InspectorTypeVirtInspector,
+		datacenter:    "DC0",
+		diskInfo:      &validationtypes.SnapshotDiskInfo{DiskPaths: []string{"[LocalDS_0] DC0_H0_VM0/disk1.vmdk"}},
+	})
+	if failure == nil {
+		t.Fatal("executeInspection() failure = nil, want NO_OS_DETECTED")
+	}
+	if failure.status != http.StatusUnprocessableEntity || failure.response.Code != "NO_OS_DETECTED" {
+		t.Errorf("executeInspection() failure = %d %s, want %d NO_OS_DETECTED", failure.status, failure.response.Code, http.StatusUnprocessableEntity)
+	}
+	if runs != 1 {
+		t.Errorf("executeInspection() ran the inspector %d times, want 1", runs)
+	}
+}
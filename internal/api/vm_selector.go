@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/vmware"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// parseVMSelector reads the VM to target from the vm, moref or uuid query parameter
+// VM names are only unique within a folder, so moref or uuid target a VM unambiguously
+// Exactly one of them must be set; it writes a 400 response and returns false otherwise
+func parseVMSelector(c *gin.Context) (vmware.VMRef, bool) {
+	ref := vmware.VMRef{
+		Name:  c.Query("vm"),
+		Moref: c.Query("moref"),
+		UUID:  c.Query("uuid"),
+	}
+
+	set := 0
+	for _, value := range []string{ref.Name, ref.Moref, ref.UUID} {
+		if value != "" {
+			set++
+		}
+	}
+
+	switch set {
+	case 0:
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "VM name is required",
+			Code:    "MISSING_VM_NAME",
+			Details: "Please provide the VM as query parameter: ?vm=xxx, ?moref=xxx or ?uuid=xxx",
+		})
+		return vmware.VMRef{}, false
+	case 1:
+		return ref, true
+	}
+
+	c.JSON(http.StatusBadRequest, types.ErrorResponse{
+		Error:   "Invalid VM selector",
+		Code:    "INVALID_VM_SELECTOR",
+		Details: "only one of vm, moref and uuid can be set",
+	})
+	return vmware.VMRef{}, false
+}
+
+// resolveVMSelector fills in the name, moref and datacenter of a VM selected by moref or UUID
+// VMs selected by name are resolved later, when the inspection looks up their disks
+// It writes an error response and returns false when the VM cannot be resolved
+func (h *VMHandler) resolveVMSelector(c *gin.Context, ref vmware.VMRef, req *inspectionRequest) bool {
+	if ref.Moref == "" && ref.UUID == "" {
+		req.vmName = ref.Name
+		return true
+	}
+
+	resolved, err := h.vmService.ResolveVM(c.Request.Context(), ref, req.datacenter)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to resolve VM")
+
+		switch {
+		case errors.Is(err, vmware.ErrDatacenterNotFound):
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "Datacenter not found",
+				Code:    "DATACENTER_NOT_FOUND",
+				Details: err.Error(),
+			})
+		case errors.Is(err, vmware.ErrVMNotInDatacenter):
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found in datacenter",
+				Code:    "VM_NOT_IN_DATACENTER",
+				Details: err.Error(),
+			})
+		case isConnectionError(err):
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "vSphere connection unavailable",
+				Code:    "VSPHERE_UNAVAILABLE",
+				Details: "Unable to connect to vSphere. Please try again later.",
+			})
+		case isNotFoundError(err):
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "VM not found",
+				Code:    "VM_NOT_FOUND",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "Failed to retrieve VM",
+				Code:    "VM_GET_FAILED",
+				Details: err.Error(),
+			})
+		}
+		return false
+	}
+
+	req.vmName = resolved.Name
+	req.vmMoref = resolved.Moref
+	req.datacenter = resolved.Datacenter
+	return true
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyHeader is the HTTP header carrying the API key
+const APIKeyHeader = "X-API-Key"
+
+// Middleware rejects requests without valid credentials with 401 Unauthorized
+// A request is accepted when it carries the configured API key in the X-API-Key header
+// or, when a username is configured, matching HTTP basic auth credentials
+// Requests to exempt paths and CORS preflight requests are always accepted
+func Middleware(cfg config.AuthConfig, exempt []string, logger *logrus.Logger) gin.HandlerFunc {
+	exemptPaths := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		exemptPaths[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exemptPaths[c.Request.URL.Path] || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		if authenticated(c.Request, cfg) {
+			c.Next()
+			return
+		}
+
+		logger.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"client_ip": c.ClientIP(),
+			"path":      c.Request.URL.Path,
+		}).Warn("Rejected unauthenticated request")
+
+		if cfg.Username != "" {
+			c.Header("WWW-Authenticate", `Basic realm="vm-deep-inspection", charset="UTF-8"`)
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, types.ErrorResponse{
+			Error:   "Unauthorized",
+			Code:    "UNAUTHORIZED",
+			Details: "Provide a valid API key in the " + APIKeyHeader + " header or valid basic auth credentials",
+		})
+	}
+}
+
+// authenticated reports whether the request carries credentials matching cfg
+func authenticated(r *http.Request, cfg config.AuthConfig) bool {
+	if cfg.APIKey != "" {
+		if key := r.Header.Get(APIKeyHeader); key != "" && equal(key, cfg.APIKey) {
+			return true
+		}
+	}
+	if cfg.Username != "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			// Compare both values so the response time doesn't reveal which one was wrong
+			usernameOK := equal(username, cfg.Username)
+			passwordOK := equal(password, cfg.Password)
+			return usernameOK && passwordOK
+		}
+	}
+	return false
+}
+
+// equal compares two secrets in constant time; hashing first hides their lengths
+func equal(given, expected string) bool {
+	givenHash := sha256.Sum256([]byte(given))
+	expectedHash := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(givenHash[:], expectedHash[:]) == 1
+}
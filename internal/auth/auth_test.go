@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	apiKeyOnly := config.AuthConfig{Enabled: true, APIKey: "secret-key"}
+	withBasic := config.AuthConfig{Enabled: true, APIKey: "secret-key", Username: "admin", Password: "secret-password"}
+
+	tests := []struct {
+		name          string
+		cfg           config.AuthConfig
+		method        string
+		path          string
+		apiKey        string
+		username      string
+		password      string
+		wantStatus    int
+		wantChallenge bool
+	}{
+		{name: "valid API key", cfg: apiKeyOnly, apiKey: "secret-key", wantStatus: http.StatusOK},
+		{name: "invalid API key", cfg: apiKeyOnly, apiKey: "wrong-key", wantStatus: http.StatusUnauthorized},
+		{name: "API key with a different length", cfg: apiKeyOnly, apiKey: "secret-key-and-more", wantStatus: http.StatusUnauthorized},
+		{name: "no credentials", cfg: apiKeyOnly, wantStatus: http.StatusUnauthorized},
+		{name: "basic auth without a configured username", cfg: apiKeyOnly, username: "admin", password: "secret-key", wantStatus: http.StatusUnauthorized},
+		{name: "valid basic auth", cfg: withBasic, username: "admin", password: "secret-password", wantStatus: http.StatusOK},
+		{name: "wrong password", cfg: withBasic, username: "admin", password: "wrong", wantStatus: http.StatusUnauthorized, wantChallenge: true},
+		{name: "wrong username", cfg: withBasic, username: "root", password: "secret-password", wantStatus: http.StatusUnauthorized, wantChallenge: true},
+		{name: "valid API key with basic auth configured", cfg: withBasic, apiKey: "secret-key", wantStatus: http.StatusOK},
+		{name: "no credentials with basic auth configured", cfg: withBasic, wantStatus: http.StatusUnauthorized, wantChallenge: true},
+		{name: "exempt path", cfg: apiKeyOnly, path: "/health", wantStatus: http.StatusOK},
+		{name: "CORS preflight", cfg: apiKeyOnly, method: http.MethodOptions, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Middleware(tt.cfg, []string{"/health"}, logger))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/health", ok)
+			router.GET("/api/v1/vms", ok)
+			router.OPTIONS("/api/v1/vms", ok)
+
+			method, path := tt.method, tt.path
+			if method == "" {
+				method = http.MethodGet
+			}
+			if path == "" {
+				path = "/api/v1/vms"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if challenge := w.Header().Get("WWW-Authenticate") != ""; challenge != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate set = %v, want %v", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package compression
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware gzip-compresses responses of at least minSize bytes for clients that accept gzip
+// Smaller responses, event streams, already encoded responses and requests to exempt paths
+// are sent uncompressed
+func Middleware(minSize int, exempt []string) gin.HandlerFunc {
+	exemptPaths := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		exemptPaths[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exemptPaths[c.Request.URL.Path] || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		// Caches must not serve a compressed response to clients that don't accept it
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+		_ = writer.finish()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		// A quality of 0 explicitly refuses the coding
+		name, value, _ := strings.Cut(params, "=")
+		if strings.TrimSpace(name) == "q" {
+			if quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && quality == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter buffers the start of a response until it reaches minSize bytes, then compresses
+// it when the response allows it; responses that end or are flushed before that are written
+// unchanged
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buffer  []byte
+	gz      *gzip.Writer
+	started bool
+}
+
+// Write buffers or compresses data depending on how much of the response has been written
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	if w.started {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// WriteString writes s like Write
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether any part of the response has been written
+func (w *gzipWriter) Written() bool {
+	return len(w.buffer) > 0 || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow sends the buffered response uncompressed, since its headers are final
+func (w *gzipWriter) WriteHeaderNow() {
+	if !w.started {
+		_ = w.start(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends the buffered response; streamed responses are not compressed
+func (w *gzipWriter) Flush() {
+	if !w.started {
+		_ = w.start(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start writes the headers and the buffered data, compressing the rest of the response
+// when compress is set and the response isn't a stream or already encoded
+func (w *gzipWriter) start(compress bool) error {
+	w.started = true
+	buffer := w.buffer
+	w.buffer = nil
+
+	header := w.Header()
+	if compress && header.Get("Content-Encoding") == "" && header.Get("Content-Range") == "" &&
+		!strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gz.Write(buffer)
+		return err
+	}
+
+	if len(buffer) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+// finish writes what is left of the response once the handlers have returned
+func (w *gzipWriter) finish() error {
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	if !w.started && len(w.buffer) > 0 {
+		return w.start(false)
+	}
+	return nil
+}
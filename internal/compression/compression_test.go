@@ -0,0 +1,168 @@
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           bool
+	}{
+		{acceptEncoding: "", want: false},
+		{acceptEncoding: "gzip", want: true},
+		{acceptEncoding: "deflate, gzip;q=0.8", want: true},
+		{acceptEncoding: "GZIP", want: true},
+		{acceptEncoding: "*", want: true},
+		{acceptEncoding: "br", want: false},
+		{acceptEncoding: "gzip;q=0", want: false},
+		{acceptEncoding: "gzip; q=0.0, br", want: false},
+		{acceptEncoding: "identity", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.acceptEncoding); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const minSize = 64
+	large := strings.Repeat("inspection result ", 20)
+	small := "ok"
+
+	tests := []struct {
+		name           string
+		path           string
+		method         string
+		acceptEncoding string
+		handler        gin.HandlerFunc
+		wantBody       string
+		wantGzip       bool
+	}{
+		{
+			name:           "large response",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.String(http.StatusOK, large) },
+			wantBody:       large,
+			wantGzip:       true,
+		},
+		{
+			name:           "small response",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.String(http.StatusOK, small) },
+			wantBody:       small,
+		},
+		{
+			name:     "client without gzip",
+			handler:  func(c *gin.Context) { c.String(http.StatusOK, large) },
+			wantBody: large,
+		},
+		{
+			name:           "exempt path",
+			path:           "/metrics",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.String(http.StatusOK, large) },
+			wantBody:       large,
+		},
+		{
+			name:           "already encoded",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Encoding", "br")
+				c.String(http.StatusOK, large)
+			},
+			wantBody: large,
+		},
+		{
+			name:           "event stream",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/event-stream")
+				c.String(http.StatusOK, large)
+			},
+			wantBody: large,
+		},
+		{
+			name:           "flushed before the minimum size",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, small)
+				c.Writer.Flush()
+				c.String(http.StatusOK, large)
+			},
+			wantBody: small + large,
+		},
+		{
+			name:           "large response in small writes",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusOK)
+				for _, word := range strings.SplitAfter(large, " ") {
+					_, _ = c.Writer.WriteString(word)
+				}
+			},
+			wantBody: large,
+			wantGzip: true,
+		},
+		{
+			name:           "empty response",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Status(http.StatusNoContent) },
+			wantBody:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Middleware(minSize, []string{"/metrics"}))
+			path := tt.path
+			if path == "" {
+				path = "/api/v1/inspections"
+			}
+			router.GET(path, tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			gzipped := w.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip %v", w.Header().Get("Content-Encoding"), tt.wantGzip)
+			}
+
+			body := w.Body.String()
+			if gzipped {
+				reader, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				decoded, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("failed to decompress the response: %v", err)
+				}
+				body = string(decoded)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+
+			if vary := w.Header().Get("Vary"); tt.path == "" && vary != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want %q", vary, "Accept-Encoding")
+			}
+		})
+	}
+}
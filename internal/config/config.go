@@ -2,11 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/go-sql-driver/mysql"
 	"github.com/spf13/viper"
 )
 
@@ -17,6 +22,8 @@ type Config struct {
 	Logging  LoggingConfig  `mapstructure:"logging" validate:"required"`
 	Database DatabaseConfig `mapstructure:"database" validate:"required"`
 	Storage  StorageConfig  `mapstructure:"storage" validate:"required"`
+
+	Inspection InspectionConfig `mapstructure:"inspection" validate:"required"`
 }
 
 // VMwareConfig contains vSphere connection configuration
@@ -25,21 +32,71 @@ type VMwareConfig struct {
 	Username           string        `mapstructure:"username" validate:"required" example:"service-account"`
 	Password           string        `mapstructure:"password" validate:"required" example:"secret"`
 	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify" example:"false"`
+	CACertFile         string        `mapstructure:"ca_cert_file" example:"/etc/pki/tls/certs/vcenter-ca.pem"`
 	ConnectionTimeout  time.Duration `mapstructure:"connection_timeout" validate:"required" example:"30s"`
 	RequestTimeout     time.Duration `mapstructure:"request_timeout" validate:"required" example:"60s"`
+	DisconnectTimeout  time.Duration `mapstructure:"disconnect_timeout" validate:"required" example:"10s"`
 	RetryAttempts      int           `mapstructure:"retry_attempts" validate:"min=0,max=10" example:"3"`
 	RetryDelay         time.Duration `mapstructure:"retry_delay" validate:"required" example:"5s"`
+
+	// Bounds the session validation of health and readiness checks, separately from the caller's deadline
+	SessionCheckTimeout time.Duration `mapstructure:"session_check_timeout" validate:"required" example:"5s"`
+
+	// Property retrieval batching for large inventories (0 disables batching)
+	PropertyBatchSize    int `mapstructure:"property_batch_size" validate:"min=0" example:"500"`
+	PropertyBatchWorkers int `mapstructure:"property_batch_workers" validate:"min=1,max=64" example:"4"`
 }
 
 // ServerConfig contains HTTP server configuration
 type ServerConfig struct {
-	Port         int           `mapstructure:"port" validate:"min=1,max=65535" example:"8080"`
-	Host         string        `mapstructure:"host" example:"0.0.0.0"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout" validate:"required" example:"10s"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout" validate:"required" example:"10s"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout" validate:"required" example:"60s"`
-	EnableCORS   bool          `mapstructure:"enable_cors" example:"true"`
-	TLSConfig    TLSConfig     `mapstructure:"tls"`
+	Port         int             `mapstructure:"port" validate:"min=1,max=65535" example:"8080"`
+	Host         string          `mapstructure:"host" example:"0.0.0.0"`
+	ReadTimeout  time.Duration   `mapstructure:"read_timeout" validate:"required" example:"10s"`
+	WriteTimeout time.Duration   `mapstructure:"write_timeout" validate:"required" example:"10s"`
+	IdleTimeout  time.Duration   `mapstructure:"idle_timeout" validate:"required" example:"60s"`
+	EnableCORS   bool            `mapstructure:"enable_cors" example:"true"`
+	CORS         CORSConfig      `mapstructure:"cors"`
+	TLSConfig    TLSConfig       `mapstructure:"tls"`
+	RateLimit    RateLimitConfig `mapstructure:"rate_limit"`
+	Auth         AuthConfig      `mapstructure:"auth"`
+
+	// Reverse proxies (IPs or CIDRs) whose X-Forwarded-For header identifies the client;
+	// with none, clients are identified by the address of the connection
+	TrustedProxies []string `mapstructure:"trusted_proxies" example:"10.0.0.0/8"`
+
+	// Gzip-compress responses of at least CompressionMinSize bytes for clients that accept it
+	EnableCompression  bool `mapstructure:"enable_compression" example:"true"`
+	CompressionMinSize int  `mapstructure:"compression_min_size" validate:"min=0" example:"1024"`
+}
+
+// CORSConfig contains the CORS policy applied when EnableCORS is set
+// The request origin is echoed only when it is in AllowedOrigins; an entry of "*" allows any origin
+type CORSConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins" example:"https://ui.example.com"`
+	AllowedMethods []string `mapstructure:"allowed_methods" example:"GET,POST"`
+	AllowedHeaders []string `mapstructure:"allowed_headers" example:"Content-Type,X-API-Key"`
+}
+
+// AuthConfig contains API authentication configuration
+// Requests must carry APIKey in the X-API-Key header or, when Username is set,
+// matching HTTP basic auth credentials
+type AuthConfig struct {
+	Enabled  bool   `mapstructure:"enabled" example:"true"`
+	APIKey   string `mapstructure:"api_key" example:"change-me"`
+	Username string `mapstructure:"username" example:"admin"`
+	Password string `mapstructure:"password" example:"secret"`
+}
+
+// RateLimitConfig contains per-client-IP rate limits for the /api/v1 routes
+// Inspection routes are limited by both the API and the inspection limits
+// Client IPs only come from X-Forwarded-For when the request came through a trusted proxy
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled" example:"true"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" validate:"min=0" example:"10"`
+	Burst             int     `mapstructure:"burst" validate:"min=0" example:"20"`
+
+	InspectionRequestsPerSecond float64 `mapstructure:"inspection_requests_per_second" validate:"min=0" example:"0.1"`
+	InspectionBurst             int     `mapstructure:"inspection_burst" validate:"min=0" example:"5"`
 }
 
 // TLSConfig contains TLS configuration
@@ -66,11 +123,46 @@ type DatabaseConfig struct {
 	User     string `mapstructure:"user" example:"postgres"`
 	Password string `mapstructure:"password" example:"secret"`
 	SSLMode  string `mapstructure:"ssl_mode" example:"disable"`
+
+	// Connection pool settings; 0 means unlimited for MaxOpenConns and ConnMaxLifetime
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" validate:"min=0" example:"10"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns" validate:"min=0" example:"100"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" example:"1h"`
 }
 
 // StorageConfig contains inspection data storage configuration
 type StorageConfig struct {
 	BasePath string `mapstructure:"base_path" validate:"required" example:"./data/inspections"`
+
+	// ArtifactRetention is how long per-run inspection artifacts are kept under BasePath; 0 keeps them forever
+	ArtifactRetention time.Duration `mapstructure:"artifact_retention" example:"168h"`
+
+	// InspectionCacheSize is the number of stored inspection results kept in memory in front of the database; 0 disables the cache
+	InspectionCacheSize int `mapstructure:"inspection_cache_size" validate:"min=0" example:"128"`
+}
+
+// InspectionConfig contains disk inspection configuration
+// Synchronous inspection endpoints extend their write deadline beyond Timeout, so
+// Server.WriteTimeout only needs to exceed it when other middleware enforces deadlines
+type InspectionConfig struct {
+	Timeout              time.Duration `mapstructure:"timeout" validate:"required" example:"30m"`
+	VirtInspectorPath    string        `mapstructure:"virt_inspector_path" example:"/usr/bin/virt-inspector"`
+	VirtV2vInspectorPath string        `mapstructure:"virt_v2v_inspector_path" example:"/usr/bin/virt-v2v-inspector"`
+	VDDKLibDir           string        `mapstructure:"vddk_libdir" example:"/opt/vmware-vix-disklib"`
+	RetryAttempts        int           `mapstructure:"retry_attempts" validate:"min=0,max=10" example:"2"`
+	RetryDelay           time.Duration `mapstructure:"retry_delay" example:"10s"`
+	StaleCloneAge        time.Duration `mapstructure:"stale_clone_age" example:"1h"`
+	BatchConcurrency     int           `mapstructure:"batch_concurrency" validate:"min=1,max=32" example:"2"`
+	MaxBatchSize         int           `mapstructure:"max_batch_size" validate:"min=1,max=500" example:"20"`
+
+	// MaxConcurrentInspections bounds the inspector subprocesses running at the same time;
+	// further inspections wait up to QueueTimeout for a free slot (0 rejects them right away)
+	MaxConcurrentInspections int           `mapstructure:"max_concurrent_inspections" validate:"min=1,max=64" example:"4"`
+	QueueTimeout             time.Duration `mapstructure:"queue_timeout" example:"5m"`
+
+	// VerifyDiskPaths checks that the disk files exist on their datastores before running the
+	// inspector; it costs a datastore browser round-trip per disk
+	VerifyDiskPaths bool `mapstructure:"verify_disk_paths" example:"true"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -82,9 +174,15 @@ func DefaultConfig() *Config {
 			Password:           "", // Will be set via config file or env vars
 			ConnectionTimeout:  30 * time.Second,
 			RequestTimeout:     60 * time.Second,
+			DisconnectTimeout:  10 * time.Second,
 			RetryAttempts:      3,
 			RetryDelay:         5 * time.Second,
 			InsecureSkipVerify: false,
+
+			SessionCheckTimeout: 5 * time.Second,
+
+			PropertyBatchSize:    0,
+			PropertyBatchWorkers: 4,
 		},
 		Server: ServerConfig{
 			Port:         8080,
@@ -93,9 +191,22 @@ func DefaultConfig() *Config {
 			WriteTimeout: 35 * time.Minute, // Increased to accommodate long-running inspections (30 min timeout + buffer)
 			IdleTimeout:  120 * time.Second,
 			EnableCORS:   true,
+			CORS: CORSConfig{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"},
+			},
 			TLSConfig: TLSConfig{
 				Enabled: false,
 			},
+			RateLimit: RateLimitConfig{
+				Enabled:                     false, // Opt-in; set trusted_proxies first when behind a reverse proxy
+				RequestsPerSecond:           10,
+				Burst:                       20,
+				InspectionRequestsPerSecond: 0.1, // one every 10 seconds
+				InspectionBurst:             5,
+			},
+			CompressionMinSize: 1024,
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -103,12 +214,32 @@ func DefaultConfig() *Config {
 			Output: "stdout",
 		},
 		Database: DatabaseConfig{
-			Type:    "sqlite",
-			Name:    "./data/vm_inspections.db",
-			SSLMode: "disable",
+			Type:            "sqlite",
+			Name:            "./data/vm_inspections.db",
+			SSLMode:         "disable",
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
 		},
 		Storage: StorageConfig{
-			BasePath: "./data/inspections",
+			BasePath:          "./data/inspections",
+			ArtifactRetention: 7 * 24 * time.Hour,
+
+			InspectionCacheSize: 128,
+		},
+		Inspection: InspectionConfig{
+			Timeout:              30 * time.Minute,
+			VirtInspectorPath:    "", // Uses system PATH
+			VirtV2vInspectorPath: "", // Uses system PATH
+			VDDKLibDir:           "/opt/vmware-vix-disklib",
+			RetryAttempts:        2,
+			RetryDelay:           10 * time.Second,
+			StaleCloneAge:        0, // Startup cleanup is opt-in
+			BatchConcurrency:     2,
+			MaxBatchSize:         20,
+
+			MaxConcurrentInspections: 4,
+			QueueTimeout:             5 * time.Minute,
 		},
 	}
 }
@@ -138,11 +269,17 @@ func Load(configFile string) (*Config, error) {
 		v.AddConfigPath("$HOME/.vm-deep-inspection/")
 	}
 
-	// Enable environment variable support
+	// Enable environment variable support, e.g. VMDI_VMWARE_PASSWORD for vmware.password
 	v.AutomaticEnv()
 	v.SetEnvPrefix("VMDI")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 
+	// AutomaticEnv only applies to keys viper already knows, so nested keys missing
+	// from the config file would never be read from the environment
+	if err := bindEnvs(v, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, fmt.Errorf("failed to bind environment variables: %w", err)
+	}
+
 	// Read configuration file (optional)
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -196,9 +333,34 @@ func ValidateConfig(config *Config) error {
 		return fmt.Errorf("storage config validation failed: %w", err)
 	}
 
+	if err := validateInspectionConfig(&config.Inspection); err != nil {
+		return fmt.Errorf("inspection config validation failed: %w", err)
+	}
+
 	return nil
 }
 
+// Warnings returns non-fatal configuration issues that should be logged at startup
+func (c *Config) Warnings() []string {
+	var warnings []string
+
+	if c.Server.WriteTimeout <= c.Inspection.Timeout {
+		warnings = append(warnings, fmt.Sprintf(
+			"server write_timeout (%s) does not exceed inspection timeout (%s); synchronous inspection endpoints extend their own write deadline, but proxies in front of the service may still cut off responses",
+			c.Server.WriteTimeout, c.Inspection.Timeout))
+	}
+
+	if c.Server.EnableCORS && c.Server.Auth.Enabled && slices.Contains(c.Server.CORS.AllowedOrigins, "*") {
+		warnings = append(warnings, "server cors allows any origin while auth is enabled; list the trusted origins in cors.allowed_origins")
+	}
+
+	if c.VMware.InsecureSkipVerify && c.VMware.CACertFile != "" {
+		warnings = append(warnings, "vmware ca_cert_file is ignored because insecure_skip_verify is enabled")
+	}
+
+	return warnings
+}
+
 // validateVMwareConfig performs additional validation for VMware configuration
 func validateVMwareConfig(config *VMwareConfig) error {
 	if config.VCenterURL == "" {
@@ -221,11 +383,56 @@ func validateVMwareConfig(config *VMwareConfig) error {
 		return fmt.Errorf("request_timeout must be positive")
 	}
 
+	if config.DisconnectTimeout <= 0 {
+		return fmt.Errorf("disconnect_timeout must be positive")
+	}
+
+	if config.SessionCheckTimeout <= 0 {
+		return fmt.Errorf("session_check_timeout must be positive")
+	}
+
+	// The CA bundle is only used when certificate verification is enabled
+	if !config.InsecureSkipVerify && config.CACertFile != "" {
+		if _, err := os.Stat(config.CACertFile); os.IsNotExist(err) {
+			return fmt.Errorf("ca_cert_file does not exist: %s", config.CACertFile)
+		}
+	}
+
 	return nil
 }
 
 // validateServerConfig performs additional validation for server configuration
 func validateServerConfig(config *ServerConfig) error {
+	if config.RateLimit.Enabled {
+		if config.RateLimit.RequestsPerSecond <= 0 || config.RateLimit.InspectionRequestsPerSecond <= 0 {
+			return fmt.Errorf("rate_limit requests_per_second and inspection_requests_per_second must be positive when rate limiting is enabled")
+		}
+		if config.RateLimit.Burst < 1 || config.RateLimit.InspectionBurst < 1 {
+			return fmt.Errorf("rate_limit burst and inspection_burst must be at least 1 when rate limiting is enabled")
+		}
+	}
+
+	for _, proxy := range config.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("trusted_proxies entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if config.EnableCORS && len(config.CORS.AllowedOrigins) == 0 {
+		return fmt.Errorf("cors allowed_origins must not be empty when CORS is enabled")
+	}
+
+	if config.Auth.Enabled {
+		if config.Auth.APIKey == "" && config.Auth.Username == "" {
+			return fmt.Errorf("auth requires an api_key or a username when enabled")
+		}
+		if config.Auth.Username != "" && config.Auth.Password == "" {
+			return fmt.Errorf("auth password is required when a username is set")
+		}
+	}
+
 	if config.TLSConfig.Enabled {
 		if config.TLSConfig.CertFile == "" {
 			return fmt.Errorf("cert_file is required when TLS is enabled")
@@ -278,6 +485,22 @@ func validateDatabaseConfig(config *DatabaseConfig) error {
 		}
 	}
 
+	if config.MaxIdleConns < 0 {
+		return fmt.Errorf("max_idle_conns must not be negative")
+	}
+
+	if config.MaxOpenConns < 0 {
+		return fmt.Errorf("max_open_conns must not be negative")
+	}
+
+	if config.MaxOpenConns > 0 && config.MaxIdleConns > config.MaxOpenConns {
+		return fmt.Errorf("max_idle_conns (%d) must not exceed max_open_conns (%d)", config.MaxIdleConns, config.MaxOpenConns)
+	}
+
+	if config.ConnMaxLifetime < 0 {
+		return fmt.Errorf("conn_max_lifetime must not be negative")
+	}
+
 	return nil
 }
 
@@ -287,6 +510,87 @@ func validateStorageConfig(config *StorageConfig) error {
 		return fmt.Errorf("base_path is required")
 	}
 
+	if config.ArtifactRetention < 0 {
+		return fmt.Errorf("artifact_retention must not be negative")
+	}
+
+	if config.InspectionCacheSize < 0 {
+		return fmt.Errorf("inspection_cache_size must not be negative")
+	}
+
+	return nil
+}
+
+// validateInspectionConfig performs additional validation for inspection configuration
+func validateInspectionConfig(config *InspectionConfig) error {
+	if config.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	if config.RetryAttempts > 0 && config.RetryDelay <= 0 {
+		return fmt.Errorf("retry_delay must be positive when retry_attempts is set")
+	}
+
+	if config.StaleCloneAge < 0 {
+		return fmt.Errorf("stale_clone_age must not be negative")
+	}
+
+	if config.BatchConcurrency < 1 {
+		return fmt.Errorf("batch_concurrency must be at least 1")
+	}
+
+	if config.MaxBatchSize < 1 {
+		return fmt.Errorf("max_batch_size must be at least 1")
+	}
+
+	if config.MaxConcurrentInspections < 1 {
+		return fmt.Errorf("max_concurrent_inspections must be at least 1")
+	}
+
+	if config.QueueTimeout < 0 {
+		return fmt.Errorf("queue_timeout must not be negative")
+	}
+
+	// Explicit binary paths must exist; empty paths fall back to the system PATH
+	if config.VirtInspectorPath != "" {
+		if _, err := os.Stat(config.VirtInspectorPath); os.IsNotExist(err) {
+			return fmt.Errorf("virt_inspector_path does not exist: %s", config.VirtInspectorPath)
+		}
+	}
+	if config.VirtV2vInspectorPath != "" {
+		if _, err := os.Stat(config.VirtV2vInspectorPath); os.IsNotExist(err) {
+			return fmt.Errorf("virt_v2v_inspector_path does not exist: %s", config.VirtV2vInspectorPath)
+		}
+	}
+
+	return nil
+}
+
+// bindEnvs registers every configuration key of t with viper so it can be set from the environment
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			if err := bindEnvs(v, field.Type, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("failed to bind '%s': %w", key, err)
+		}
+	}
 	return nil
 }
 
@@ -300,18 +604,47 @@ func (c *ServerConfig) IsTLSEnabled() bool {
 	return c.TLSConfig.Enabled
 }
 
+// VpxSSLVerify returns the TLS option for vpx:// URLs passed to the inspectors
+// Certificates are verified against CACertFile when set, or the system trust store otherwise;
+// verification is only disabled when InsecureSkipVerify is true
+func (c *VMwareConfig) VpxSSLVerify() string {
+	if c.InsecureSkipVerify {
+		return "no_verify=1"
+	}
+	if c.CACertFile != "" {
+		return "cacert=" + c.CACertFile
+	}
+	return ""
+}
+
 // GetDSN returns the database DSN (Data Source Name) for GORM
 func (c *DatabaseConfig) GetDSN() string {
 	switch c.Type {
 	case "sqlite":
 		return c.Name
 	case "postgres":
+		// Values are quoted so passwords with spaces, quotes or backslashes stay intact
 		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+			quotePostgresValue(c.Host), c.Port, quotePostgresValue(c.User), quotePostgresValue(c.Password),
+			quotePostgresValue(c.Name), quotePostgresValue(c.SSLMode))
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			c.User, c.Password, c.Host, c.Port, c.Name)
+		// The driver formats the DSN so special characters in the password (e.g. '@', ':', '/') are handled
+		mysqlConfig := mysql.NewConfig()
+		mysqlConfig.User = c.User
+		mysqlConfig.Passwd = c.Password
+		mysqlConfig.Net = "tcp"
+		mysqlConfig.Addr = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+		mysqlConfig.DBName = c.Name
+		mysqlConfig.ParseTime = true
+		mysqlConfig.Loc = time.Local
+		mysqlConfig.Params = map[string]string{"charset": "utf8mb4"}
+		return mysqlConfig.FormatDSN()
 	default:
 		return ""
 	}
-}
\ No newline at end of file
+}
+
+// quotePostgresValue quotes a value for a PostgreSQL key=value connection string
+func quotePostgresValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dsnPasswords are passwords with the characters DSN formats give a meaning to
+var dsnPasswords = []struct {
+	name     string
+	password string
+}{
+	{name: "plain", password: "secret"},
+	{name: "at sign", password: "p@ss"},
+	{name: "colon and slash", password: "a:b/c"},
+	{name: "single quote", password: "it's"},
+	{name: "backslash", password: `back\slash`},
+	{name: "space", password: "two words"},
+	{name: "everything", password: `@ : / ' \ x=y?z#&`},
+	{name: "empty", password: ""},
+}
+
+func TestGetDSNPostgres(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			db := DatabaseConfig{
+				Type:     "postgres",
+				Host:     "db.example.com",
+				Port:     5432,
+				User:     "vmdi user",
+				Password: tt.password,
+				Name:     "vmdi",
+				SSLMode:  "require",
+			}
+
+			parsed, err := pgconn.ParseConfig(db.GetDSN())
+			if err != nil {
+				t.Fatalf("pgconn.ParseConfig(GetDSN()) error = %v", err)
+			}
+			if parsed.Password != tt.password {
+				t.Errorf("GetDSN() password = %q, want %q", parsed.Password, tt.password)
+			}
+			if parsed.User != db.User || parsed.Host != db.Host || parsed.Port != uint16(db.Port) || parsed.Database != db.Name {
+				t.Errorf("GetDSN() = user %q host %q port %d database %q, want %q %q %d %q",
+					parsed.User, parsed.Host, parsed.Port, parsed.Database, db.User, db.Host, db.Port, db.Name)
+			}
+		})
+	}
+}
+
+func TestGetDSNMySQL(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			db := DatabaseConfig{
+				Type:     "mysql",
+				Host:     "db.example.com",
+				Port:     3306,
+				User:     "vmdi",
+				Password: tt.password,
+				Name:     "vmdi",
+			}
+
+			parsed, err := mysql.ParseDSN(db.GetDSN())
+			if err != nil {
+				t.Fatalf("mysql.ParseDSN(GetDSN()) error = %v", err)
+			}
+			if parsed.Passwd != tt.password {
+				t.Errorf("GetDSN() password = %q, want %q", parsed.Passwd, tt.password)
+			}
+			if parsed.User != db.User || parsed.Addr != "db.example.com:3306" || parsed.DBName != db.Name {
+				t.Errorf("GetDSN() = user %q addr %q database %q, want %q %q %q",
+					parsed.User, parsed.Addr, parsed.DBName, db.User, "db.example.com:3306", db.Name)
+			}
+		})
+	}
+}
+
+func TestGetDSNSQLite(t *testing.T) {
+	db := DatabaseConfig{Type: "sqlite", Name: "/var/lib/vmdi/inspections.db", Password: "ignored"}
+	if got := db.GetDSN(); got != db.Name {
+		t.Errorf("GetDSN() = %q, want %q", got, db.Name)
+	}
+}
+
+func TestLoadEnvironmentOverrides(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `vmware:
+  vcenter_url: "https://file.example.com/sdk"
+  username: "file-user"
+  password: "file-password"
+server:
+  port: 8080
+`
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	// Keys in the file and keys only known from the config struct both read the environment
+	t.Setenv("VMDI_VMWARE_VCENTER_URL", "https://env.example.com/sdk")
+	t.Setenv("VMDI_SERVER_PORT", "9090")
+	t.Setenv("VMDI_INSPECTION_RETRY_ATTEMPTS", "5")
+	t.Setenv("VMDI_INSPECTION_RETRY_DELAY", "3s")
+	t.Setenv("VMDI_DATABASE_NAME", "/tmp/env.db")
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		got  interface{}
+		want interface{}
+	}{
+		{key: "vmware.vcenter_url", got: cfg.VMware.VCenterURL, want: "https://env.example.com/sdk"},
+		{key: "vmware.username", got: cfg.VMware.Username, want: "file-user"},
+		{key: "server.port", got: cfg.Server.Port, want: 9090},
+		{key: "inspection.retry_attempts", got: cfg.Inspection.RetryAttempts, want: 5},
+		{key: "inspection.retry_delay", got: cfg.Inspection.RetryDelay, want: 3 * time.Second},
+		{key: "inspection.timeout", got: cfg.Inspection.Timeout, want: DefaultConfig().Inspection.Timeout},
+		{key: "database.name", got: cfg.Database.Name, want: "/tmp/env.db"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Load() %s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+	}
+}
+
+func TestValidateServerConfigTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{name: "none", proxies: nil},
+		{name: "addresses and ranges", proxies: []string{"10.0.0.1", "192.168.0.0/16", "fd00::/8"}},
+		{name: "hostname", proxies: []string{"proxy.example.com"}, wantErr: true},
+		{name: "invalid range", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := DefaultConfig().Server
+			server.TrustedProxies = tt.proxies
+			if err := validateServerConfig(&server); (err != nil) != tt.wantErr {
+				t.Errorf("validateServerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "vm_deep_inspection"
+
+var (
+	inspectionsStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inspections_started_total",
+		Help:      "Total number of inspections started, by inspector type.",
+	}, []string{"inspector_type"})
+
+	inspectionsSucceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inspections_succeeded_total",
+		Help:      "Total number of inspections that completed successfully, by inspector type.",
+	}, []string{"inspector_type"})
+
+	inspectionsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inspections_failed_total",
+		Help:      "Total number of inspections that failed, by inspector type.",
+	}, []string{"inspector_type"})
+
+	inspectionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "inspection_duration_seconds",
+		Help:      "Duration of inspections in seconds, by inspector type and outcome.",
+		// Inspections take from tens of seconds up to the configured timeout (30m by default)
+		Buckets: []float64{10, 30, 60, 120, 300, 600, 900, 1200, 1800, 3600},
+	}, []string{"inspector_type", "outcome"})
+
+	inspectionsRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inspections_running",
+		Help:      "Number of inspections currently running, by inspector type.",
+	}, []string{"inspector_type"})
+
+	inspectionSlotsInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inspection_slots_in_use",
+		Help:      "Number of inspection slots (inspection.max_concurrent_inspections) currently taken.",
+	})
+
+	inspectionsQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inspections_queued",
+		Help:      "Number of inspections waiting for a free inspection slot.",
+	})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests in seconds, by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Handler returns the HTTP handler that serves metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InspectionStarted records the start of an inspection and returns a function
+// that must be called exactly once with the outcome when the inspection finishes
+func InspectionStarted(inspectorType string) func(succeeded bool) {
+	start := time.Now()
+	inspectionsStarted.WithLabelValues(inspectorType).Inc()
+	inspectionsRunning.WithLabelValues(inspectorType).Inc()
+
+	return func(succeeded bool) {
+		inspectionsRunning.WithLabelValues(inspectorType).Dec()
+
+		outcome := "success"
+		if succeeded {
+			inspectionsSucceeded.WithLabelValues(inspectorType).Inc()
+		} else {
+			outcome = "failure"
+			inspectionsFailed.WithLabelValues(inspectorType).Inc()
+		}
+		inspectionDuration.WithLabelValues(inspectorType, outcome).Observe(time.Since(start).Seconds())
+	}
+}
+
+// InspectionQueued records an inspection waiting for a free slot and returns a function
+// that must be called exactly once when it stops waiting
+func InspectionQueued() func() {
+	inspectionsQueued.Inc()
+	return inspectionsQueued.Dec
+}
+
+// InspectionSlotAcquired records a taken inspection slot and returns a function
+// that must be called exactly once when the slot is freed
+func InspectionSlotAcquired() func() {
+	inspectionSlotsInUse.Inc()
+	return inspectionSlotsInUse.Dec
+}
+
+// ObserveHTTPRequest records the duration of an HTTP request
+// route should be the matched route template (e.g. /api/v1/vms/:name) to keep label cardinality bounded
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
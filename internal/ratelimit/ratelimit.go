@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirarg/vm-deep-inspection-demo/pkg/types"
+)
+
+// sweepInterval is how often buckets of idle clients are dropped
+const sweepInterval = time.Minute
+
+// Limiter is a token bucket rate limiter keyed by client
+// Each client gets a bucket of burst tokens, refilled at rate tokens per second;
+// a request takes one token and is rejected when the bucket is empty
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket holds the tokens left for one client as of last
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter allowing requestsPerSecond per client with bursts of up to burst requests
+func New(requestsPerSecond float64, burst int) *Limiter {
+	return &Limiter{
+		rate:      requestsPerSecond,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for key at now
+// When none is left, ok is false and retryAfter is how long until one is available
+func (l *Limiter) Allow(key string, now time.Time) (ok bool, retryAfter time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+}
+
+// sweep drops the buckets that have refilled completely; they behave like new ones
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware rejects requests over the limiter's rate, keyed by client IP, with
+// 429 Too Many Requests and a Retry-After header in whole seconds
+func Middleware(limiter *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, retryAfter := limiter.Allow(c.ClientIP(), time.Now())
+		if ok {
+			c.Next()
+			return
+		}
+
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, types.ErrorResponse{
+			Error:   "Rate limit exceeded",
+			Code:    "RATE_LIMITED",
+			Details: "Too many requests from this client, retry after " + strconv.Itoa(seconds) + "s",
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	const burst = 3
+	limiter := New(2, burst)
+	now := time.Now()
+
+	for i := 0; i < burst; i++ {
+		if ok, _ := limiter.Allow("10.0.0.1", now); !ok {
+			t.Fatalf("Allow() request %d = false, want true within the burst", i+1)
+		}
+	}
+
+	ok, retryAfter := limiter.Allow("10.0.0.1", now)
+	if ok {
+		t.Fatalf("Allow() request %d = true, want false over the burst", burst+1)
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("Allow() retryAfter = %v, want %v", retryAfter, 500*time.Millisecond)
+	}
+
+	if ok, _ := limiter.Allow("10.0.0.2", now); !ok {
+		t.Errorf("Allow() for another client = false, want true")
+	}
+
+	if ok, _ := limiter.Allow("10.0.0.1", now.Add(retryAfter)); !ok {
+		t.Errorf("Allow() after retryAfter = false, want true")
+	}
+	if ok, _ := limiter.Allow("10.0.0.1", now.Add(retryAfter)); ok {
+		t.Errorf("Allow() twice after one refilled token = true, want false")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	// A bucket refills in 30 seconds
+	limiter := New(1.0/30, 1)
+	now := time.Now()
+
+	limiter.Allow("10.0.0.1", now)
+	limiter.Allow("10.0.0.2", now.Add(sweepInterval-time.Second))
+	limiter.Allow("10.0.0.3", now.Add(sweepInterval))
+
+	// 10.0.0.1 has refilled and is dropped; 10.0.0.2 has not, and 10.0.0.3 was just added
+	if _, ok := limiter.buckets["10.0.0.1"]; ok {
+		t.Errorf("sweep kept the bucket of an idle client")
+	}
+	if len(limiter.buckets) != 2 {
+		t.Errorf("len(buckets) = %d, want 2", len(limiter.buckets))
+	}
+}
+
+// newTestRouter returns a router limited by limiter that trusts the given proxies
+func newTestRouter(t *testing.T, limiter *Limiter, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v", err)
+	}
+	router.GET("/", Middleware(limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	router := newTestRouter(t, New(1, 2), nil)
+
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, status := range want {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != status {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, status)
+		}
+		if status == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "1" {
+			t.Errorf("Retry-After = %q, want %q", w.Header().Get("Retry-After"), "1")
+		}
+	}
+}
+
+func TestMiddlewareClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantLimited    bool
+	}{
+		// Spoofed X-Forwarded-For headers must not give a client a fresh limit
+		{name: "untrusted forwarded header", trustedProxies: nil, wantLimited: true},
+		// Through a trusted proxy, each forwarded client has its own limit
+		{name: "trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, wantLimited: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, New(1, 1), tt.trustedProxies)
+
+			var last int
+			for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2"} {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = "192.0.2.1:1234"
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				last = w.Code
+			}
+
+			if limited := last == http.StatusTooManyRequests; limited != tt.wantLimited {
+				t.Errorf("second client limited = %v, want %v", limited, tt.wantLimited)
+			}
+		})
+	}
+}
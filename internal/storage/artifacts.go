@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrArtifactNotFound is returned when a requested inspection artifact does not exist
+var ErrArtifactNotFound = errors.New("inspection artifact not found")
+
+// artifactRunLayout names run directories; it sorts chronologically
+const artifactRunLayout = "20060102T150405.000000000Z"
+
+// ArtifactFile describes a file stored for an inspection run
+type ArtifactFile struct {
+	Name string
+	Size int64
+}
+
+// ArtifactRun describes the files stored for one inspection run
+type ArtifactRun struct {
+	Run       string
+	CreatedAt time.Time
+	Files     []ArtifactFile
+}
+
+// ArtifactStore keeps per-run inspection artifacts on disk, for post-mortem analysis of
+// inspection failures. Each run is stored in <basePath>/<vm>/<snapshot>/<run>/
+type ArtifactStore struct {
+	basePath  string
+	retention time.Duration
+	logger    *logrus.Logger
+}
+
+// NewArtifactStore creates an artifact store under basePath
+// Runs older than retention are removed by Prune; a zero retention keeps them forever
+func NewArtifactStore(basePath string, retention time.Duration, logger *logrus.Logger) *ArtifactStore {
+	return &ArtifactStore{
+		basePath:  basePath,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Save writes the files of a new inspection run and returns the run name
+func (s *ArtifactStore) Save(ctx context.Context, vmName, snapshotName string, files map[string][]byte) (string, error) {
+	run := time.Now().UTC().Format(artifactRunLayout)
+	dir := filepath.Join(s.snapshotDir(vmName, snapshotName), run)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)), data, 0o640); err != nil {
+			return "", fmt.Errorf("failed to write artifact '%s': %w", name, err)
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"vm_name":       vmName,
+			"snapshot_name": snapshotName,
+			"run":           run,
+			"files":         len(files),
+		}).Debug("Stored inspection artifacts")
+	}
+
+	return run, nil
+}
+
+// List returns the stored runs of a VM snapshot, newest first
+func (s *ArtifactStore) List(vmName, snapshotName string) ([]ArtifactRun, error) {
+	dir := s.snapshotDir(vmName, snapshotName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []ArtifactRun{}, nil
+		}
+		return nil, fmt.Errorf("failed to read artifact directory: %w", err)
+	}
+
+	runs := []ArtifactRun{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		createdAt, ok := runTime(entry)
+		if !ok {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact run '%s': %w", entry.Name(), err)
+		}
+		run := ArtifactRun{Run: entry.Name(), CreatedAt: createdAt, Files: []ArtifactFile{}}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			run.Files = append(run.Files, ArtifactFile{Name: file.Name(), Size: info.Size()})
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Run > runs[j].Run
+	})
+	return runs, nil
+}
+
+// Path returns the location of a stored artifact file
+// Run and file names are validated so the path cannot escape the artifact directory
+func (s *ArtifactStore) Path(vmName, snapshotName, run, file string) (string, error) {
+	if _, err := time.Parse(artifactRunLayout, run); err != nil {
+		return "", fmt.Errorf("%w: invalid run '%s'", ErrArtifactNotFound, run)
+	}
+	if file == "" || file != filepath.Base(file) || strings.HasPrefix(file, ".") {
+		return "", fmt.Errorf("%w: invalid file name '%s'", ErrArtifactNotFound, file)
+	}
+
+	path := filepath.Join(s.snapshotDir(vmName, snapshotName), run, file)
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: '%s' in run '%s'", ErrArtifactNotFound, file, run)
+	}
+	return path, nil
+}
+
+// Prune removes runs older than the retention period, and the directories left empty
+// It returns the number of runs removed
+func (s *ArtifactStore) Prune(ctx context.Context) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.retention)
+	removed := 0
+
+	vmDirs, err := os.ReadDir(s.basePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read artifact directory: %w", err)
+	}
+
+	for _, vmDir := range vmDirs {
+		if !vmDir.IsDir() {
+			continue
+		}
+		vmPath := filepath.Join(s.basePath, vmDir.Name())
+		snapshotDirs, err := os.ReadDir(vmPath)
+		if err != nil {
+			return removed, fmt.Errorf("failed to read artifact directory: %w", err)
+		}
+
+		for _, snapshotDir := range snapshotDirs {
+			if !snapshotDir.IsDir() {
+				continue
+			}
+			snapshotPath := filepath.Join(vmPath, snapshotDir.Name())
+			runs, err := os.ReadDir(snapshotPath)
+			if err != nil {
+				return removed, fmt.Errorf("failed to read artifact directory: %w", err)
+			}
+
+			for _, run := range runs {
+				createdAt, ok := runTime(run)
+				if !ok || !createdAt.Before(cutoff) {
+					continue
+				}
+				if err := os.RemoveAll(filepath.Join(snapshotPath, run.Name())); err != nil {
+					return removed, fmt.Errorf("failed to remove artifact run: %w", err)
+				}
+				removed++
+			}
+
+			// Remove fails on directories that still hold runs, which is intended
+			_ = os.Remove(snapshotPath)
+		}
+		_ = os.Remove(vmPath)
+	}
+
+	if removed > 0 && s.logger != nil {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"removed":   removed,
+			"retention": s.retention.String(),
+		}).Info("Pruned old inspection artifacts")
+	}
+
+	return removed, nil
+}
+
+// snapshotDir returns the directory holding the runs of a VM snapshot
+// Names are escaped so they always form a single path element
+func (s *ArtifactStore) snapshotDir(vmName, snapshotName string) string {
+	return filepath.Join(s.basePath, artifactDirName(vmName), artifactDirName(snapshotName))
+}
+
+// artifactDirName escapes a VM or snapshot name for use as a directory name
+func artifactDirName(name string) string {
+	escaped := url.PathEscape(name)
+	if strings.HasPrefix(escaped, ".") {
+		escaped = "%2E" + escaped[1:]
+	}
+	return escaped
+}
+
+// runTime returns the creation time encoded in a run directory name
+func runTime(entry os.DirEntry) (time.Time, bool) {
+	if !entry.IsDir() {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(artifactRunLayout, entry.Name())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
 	pkgtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
@@ -14,29 +15,33 @@ import (
 // VirtInspectorRecord represents a database record for VirtInspector inspection data
 type VirtInspectorRecord struct {
 	gorm.Model
-	VMName       string `gorm:"index:idx_vm_snapshot,unique"`
-	SnapshotName string `gorm:"index:idx_vm_snapshot,unique"`
-	CacheKey     string `gorm:"uniqueIndex"`
-	DataJSON     string `gorm:"type:longtext"` // MySQL: 4GB, PostgreSQL/SQLite: interpreted as TEXT
+	VMName       string         `gorm:"index:idx_vm_snapshot,unique"`
+	SnapshotName string         `gorm:"index:idx_vm_snapshot,unique"`
+	CacheKey     string         `gorm:"uniqueIndex"`
+	DataJSON     InspectionJSON // PostgreSQL: jsonb, MySQL/SQLite: longtext
 }
 
 // VirtV2VInspectorRecord represents a database record for VirtV2vInspector inspection data
 type VirtV2VInspectorRecord struct {
 	gorm.Model
-	VMName       string `gorm:"index:idx_vm_snapshot_v2v,unique"`
-	SnapshotName string `gorm:"index:idx_vm_snapshot_v2v,unique"`
-	CacheKey     string `gorm:"uniqueIndex"`
-	DataJSON     string `gorm:"type:longtext"` // MySQL: 4GB, PostgreSQL/SQLite: interpreted as TEXT
+	VMName       string         `gorm:"index:idx_vm_snapshot_v2v,unique"`
+	SnapshotName string         `gorm:"index:idx_vm_snapshot_v2v,unique"`
+	CacheKey     string         `gorm:"uniqueIndex"`
+	DataJSON     InspectionJSON // PostgreSQL: jsonb, MySQL/SQLite: longtext
 }
 
 // InspectionDB provides GORM-based persistent storage for inspection results
+// Recently read results are kept in an in-memory LRU cache; cached inspection data is shared
+// between readers and must not be modified
 type InspectionDB struct {
 	db     *gorm.DB
+	lru    *inspectionLRU
 	logger *logrus.Logger
 }
 
 // NewInspectionDB creates a new GORM-based inspection database
-func NewInspectionDB(db *gorm.DB, logger *logrus.Logger) (*InspectionDB, error) {
+// cacheSize is the number of results kept in memory in front of the database (0 disables it)
+func NewInspectionDB(db *gorm.DB, cacheSize int, logger *logrus.Logger) (*InspectionDB, error) {
 	// Auto-migrate the schema
 	if err := db.AutoMigrate(&VirtInspectorRecord{}, &VirtV2VInspectorRecord{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
@@ -44,12 +49,20 @@ func NewInspectionDB(db *gorm.DB, logger *logrus.Logger) (*InspectionDB, error)
 
 	return &InspectionDB{
 		db:     db,
+		lru:    newInspectionLRU(cacheSize),
 		logger: logger,
 	}, nil
 }
 
 // GetVirtInspectorXML retrieves VirtInspector inspection data for a given cache key
 func (db *InspectionDB) GetVirtInspectorXML(ctx context.Context, key persistent.CacheKey) (*pkgtypes.VirtInspectorXML, error) {
+	lruKey := inspectionLRUKey{table: lruTableVirtInspector, form: lruFormData, hash: key.Hash()}
+	if cached, ok := db.lru.get(lruKey); ok {
+		db.logCacheHit(key, "VirtInspector")
+		return cached.(*pkgtypes.VirtInspectorXML), nil
+	}
+	generation := db.lru.currentGeneration()
+
 	var record VirtInspectorRecord
 	result := db.db.WithContext(ctx).Where("cache_key = ?", key.Hash()).First(&record)
 
@@ -74,6 +87,8 @@ func (db *InspectionDB) GetVirtInspectorXML(ctx context.Context, key persistent.
 		}).Debug("Retrieved VirtInspector data from DB")
 	}
 
+	db.lru.add(inspectionLRUEntry{key: lruKey, vmName: key.VMName, snapshotName: key.SnapshotName, value: &data}, generation)
+
 	return &data, nil
 }
 
@@ -89,7 +104,7 @@ func (db *InspectionDB) SetVirtInspectorXML(ctx context.Context, key persistent.
 		VMName:       key.VMName,
 		SnapshotName: key.SnapshotName,
 		CacheKey:     key.Hash(),
-		DataJSON:     string(jsonData),
+		DataJSON:     InspectionJSON(jsonData),
 	}
 
 	// Use Create or update if exists
@@ -97,6 +112,7 @@ func (db *InspectionDB) SetVirtInspectorXML(ctx context.Context, key persistent.
 	if result.Error != nil {
 		return fmt.Errorf("failed to store inspection data: %w", result.Error)
 	}
+	db.lru.invalidate(lruTableVirtInspector, key.Hash(), key.VMName, key.SnapshotName)
 
 	if db.logger != nil {
 		db.logger.WithFields(logrus.Fields{
@@ -110,6 +126,13 @@ func (db *InspectionDB) SetVirtInspectorXML(ctx context.Context, key persistent.
 
 // GetVirtV2VInspectorXML retrieves VirtV2vInspector inspection data for a given cache key
 func (db *InspectionDB) GetVirtV2VInspectorXML(ctx context.Context, key persistent.CacheKey) (*pkgtypes.VirtV2VInspectorXML, error) {
+	lruKey := inspectionLRUKey{table: lruTableVirtV2V, form: lruFormData, hash: key.Hash()}
+	if cached, ok := db.lru.get(lruKey); ok {
+		db.logCacheHit(key, "VirtV2VInspector")
+		return cached.(*pkgtypes.VirtV2VInspectorXML), nil
+	}
+	generation := db.lru.currentGeneration()
+
 	var record VirtV2VInspectorRecord
 	result := db.db.WithContext(ctx).Where("cache_key = ?", key.Hash()).First(&record)
 
@@ -134,6 +157,8 @@ func (db *InspectionDB) GetVirtV2VInspectorXML(ctx context.Context, key persiste
 		}).Debug("Retrieved VirtV2VInspector data from DB")
 	}
 
+	db.lru.add(inspectionLRUEntry{key: lruKey, vmName: key.VMName, snapshotName: key.SnapshotName, value: &data}, generation)
+
 	return &data, nil
 }
 
@@ -149,7 +174,7 @@ func (db *InspectionDB) SetVirtV2VInspectorXML(ctx context.Context, key persiste
 		VMName:       key.VMName,
 		SnapshotName: key.SnapshotName,
 		CacheKey:     key.Hash(),
-		DataJSON:     string(jsonData),
+		DataJSON:     InspectionJSON(jsonData),
 	}
 
 	// Use Create or update if exists
@@ -157,6 +182,7 @@ func (db *InspectionDB) SetVirtV2VInspectorXML(ctx context.Context, key persiste
 	if result.Error != nil {
 		return fmt.Errorf("failed to store inspection data: %w", result.Error)
 	}
+	db.lru.invalidate(lruTableVirtV2V, key.Hash(), key.VMName, key.SnapshotName)
 
 	if db.logger != nil {
 		db.logger.WithFields(logrus.Fields{
@@ -167,3 +193,136 @@ func (db *InspectionDB) SetVirtV2VInspectorXML(ctx context.Context, key persiste
 
 	return nil
 }
+
+// GetVirtInspectorRecord retrieves the raw stored VirtInspector record for a given cache key
+func (db *InspectionDB) GetVirtInspectorRecord(ctx context.Context, key persistent.CacheKey) (*VirtInspectorRecord, error) {
+	lruKey := inspectionLRUKey{table: lruTableVirtInspector, form: lruFormRecord, hash: key.Hash()}
+	if cached, ok := db.lru.get(lruKey); ok {
+		db.logCacheHit(key, "VirtInspector")
+		// Records are small, so callers get their own copy
+		record := *cached.(*VirtInspectorRecord)
+		return &record, nil
+	}
+	generation := db.lru.currentGeneration()
+
+	var record VirtInspectorRecord
+	result := db.db.WithContext(ctx).Where("cache_key = ?", key.Hash()).First(&record)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Not found is not an error, just return nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query inspection data: %w", result.Error)
+	}
+
+	cached := record
+	db.lru.add(inspectionLRUEntry{key: lruKey, vmName: key.VMName, snapshotName: key.SnapshotName, value: &cached}, generation)
+
+	return &record, nil
+}
+
+// GetVirtV2VInspectorRecord retrieves the raw stored VirtV2vInspector record for a given cache key
+func (db *InspectionDB) GetVirtV2VInspectorRecord(ctx context.Context, key persistent.CacheKey) (*VirtV2VInspectorRecord, error) {
+	lruKey := inspectionLRUKey{table: lruTableVirtV2V, form: lruFormRecord, hash: key.Hash()}
+	if cached, ok := db.lru.get(lruKey); ok {
+		db.logCacheHit(key, "VirtV2VInspector")
+		// Records are small, so callers get their own copy
+		record := *cached.(*VirtV2VInspectorRecord)
+		return &record, nil
+	}
+	generation := db.lru.currentGeneration()
+
+	var record VirtV2VInspectorRecord
+	result := db.db.WithContext(ctx).Where("cache_key = ?", key.Hash()).First(&record)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Not found is not an error, just return nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query inspection data: %w", result.Error)
+	}
+
+	cached := record
+	db.lru.add(inspectionLRUEntry{key: lruKey, vmName: key.VMName, snapshotName: key.SnapshotName, value: &cached}, generation)
+
+	return &record, nil
+}
+
+// DeleteVirtInspectorXML permanently deletes VirtInspector inspection data for a given cache key
+func (db *InspectionDB) DeleteVirtInspectorXML(ctx context.Context, key persistent.CacheKey) (int64, error) {
+	result := db.db.WithContext(ctx).Unscoped().
+		Where("cache_key = ?", key.Hash()).
+		Delete(&VirtInspectorRecord{})
+	// The deletion may have partly succeeded, so the cache is invalidated even on error
+	db.lru.invalidate(lruTableVirtInspector, key.Hash(), key.VMName, key.SnapshotName)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete inspection data: %w", result.Error)
+	}
+
+	if db.logger != nil {
+		db.logger.WithFields(logrus.Fields{
+			"key":     key.String(),
+			"deleted": result.RowsAffected,
+		}).Debug("Deleted VirtInspector data from DB")
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteVirtV2VInspectorXML permanently deletes VirtV2vInspector inspection data for a given cache key
+func (db *InspectionDB) DeleteVirtV2VInspectorXML(ctx context.Context, key persistent.CacheKey) (int64, error) {
+	result := db.db.WithContext(ctx).Unscoped().
+		Where("cache_key = ?", key.Hash()).
+		Delete(&VirtV2VInspectorRecord{})
+	// The deletion may have partly succeeded, so the cache is invalidated even on error
+	db.lru.invalidate(lruTableVirtV2V, key.Hash(), key.VMName, key.SnapshotName)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete inspection data: %w", result.Error)
+	}
+
+	if db.logger != nil {
+		db.logger.WithFields(logrus.Fields{
+			"key":     key.String(),
+			"deleted": result.RowsAffected,
+		}).Debug("Deleted VirtV2VInspector data from DB")
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteOlderThan permanently deletes all inspection records created before the given time
+// Returns the total number of deleted records across all inspector types
+func (db *InspectionDB) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	// The deleted records aren't known, so the whole cache is dropped
+	defer db.lru.purge()
+
+	for _, model := range []interface{}{&VirtInspectorRecord{}, &VirtV2VInspectorRecord{}} {
+		result := db.db.WithContext(ctx).Unscoped().Where("created_at < ?", before).Delete(model)
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to delete old inspection data: %w", result.Error)
+		}
+		total += result.RowsAffected
+	}
+
+	if db.logger != nil {
+		db.logger.WithFields(logrus.Fields{
+			"before":  before,
+			"deleted": total,
+		}).Debug("Deleted old inspection data from DB")
+	}
+
+	return total, nil
+}
+
+// logCacheHit logs an inspection result served from the in-memory cache
+func (db *InspectionDB) logCacheHit(key persistent.CacheKey, inspector string) {
+	if db.logger != nil {
+		db.logger.WithFields(logrus.Fields{
+			"key":       key.String(),
+			"cache_key": key.Hash(),
+		}).Debugf("Retrieved %s data from memory cache", inspector)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// InspectionJSON is serialized inspection data
+// It is stored as jsonb on PostgreSQL so the data can be queried in the database,
+// and as longtext (4GB on MySQL, TEXT on SQLite) elsewhere
+type InspectionJSON string
+
+// GormDBDataType returns the column type for the database dialect
+func (InspectionJSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "longtext"
+}
+
+// findBatchSize is the number of records decoded at a time when filtering in Go
+const findBatchSize = 100
+
+// FindByApplication returns the stored VirtInspector records whose data contains appName
+// as a string value, e.g. the name of an installed application
+// PostgreSQL evaluates the match with a JSONB path query; other databases narrow the
+// candidates with a LIKE on the serialized value and match the decoded data in Go
+func (db *InspectionDB) FindByApplication(ctx context.Context, appName string) ([]VirtInspectorRecord, error) {
+	var records []VirtInspectorRecord
+
+	if db.db.Dialector.Name() == "postgres" {
+		result := db.db.WithContext(ctx).
+			Where("jsonb_path_exists(data_json, ?::jsonpath, jsonb_build_object('name', ?::text))", "$.** ? (@ == $name)", appName).
+			Find(&records)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to query inspection data: %w", result.Error)
+		}
+	} else {
+		encoded, err := json.Marshal(appName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode application name: %w", err)
+		}
+
+		var batch []VirtInspectorRecord
+		result := db.db.WithContext(ctx).
+			Where("data_json LIKE ? ESCAPE '!'", "%"+escapeLike(string(encoded))+"%").
+			FindInBatches(&batch, findBatchSize, func(tx *gorm.DB, _ int) error {
+				for _, record := range batch {
+					var data interface{}
+					if err := json.Unmarshal([]byte(record.DataJSON), &data); err != nil {
+						return fmt.Errorf("failed to unmarshal inspection data for '%s': %w", record.VMName, err)
+					}
+					if containsStringValue(data, appName) {
+						records = append(records, record)
+					}
+				}
+				return nil
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to query inspection data: %w", result.Error)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].VMName != records[j].VMName {
+			return records[i].VMName < records[j].VMName
+		}
+		return records[i].SnapshotName < records[j].SnapshotName
+	})
+
+	if db.logger != nil {
+		db.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"application": appName,
+			"matches":     len(records),
+		}).Debug("Searched VirtInspector data by application")
+	}
+
+	return records, nil
+}
+
+// escapeLike escapes LIKE wildcards using '!' as the escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+// containsStringValue reports whether decoded JSON data contains value as a string anywhere
+func containsStringValue(data interface{}, value string) bool {
+	switch v := data.(type) {
+	case string:
+		return v == value
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsStringValue(item, value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsStringValue(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Tables and forms of the inspection data kept in the in-memory cache
+const (
+	lruTableVirtInspector = "virt_inspector"
+	lruTableVirtV2V       = "virt_v2v"
+
+	lruFormRecord = "record" // raw database record, as returned by Get*Record
+	lruFormData   = "data"   // decoded inspection data, as returned by Get*XML
+)
+
+// inspectionLRUKey identifies a cached inspection result
+type inspectionLRUKey struct {
+	table string
+	form  string
+	hash  string
+}
+
+// inspectionLRUEntry is a cached inspection result; the VM and snapshot names allow
+// invalidating all forms of a result when it is deleted by name
+type inspectionLRUEntry struct {
+	key          inspectionLRUKey
+	vmName       string
+	snapshotName string
+	value        interface{}
+}
+
+// inspectionLRU keeps the most recently read inspection results in memory, so repeated
+// reads of a popular result skip the database query and the JSON decoding
+// A nil *inspectionLRU is a disabled cache: lookups miss and other calls do nothing
+type inspectionLRU struct {
+	mu      sync.Mutex
+	size    int
+	entries map[inspectionLRUKey]*list.Element
+	order   *list.List // most recently used first
+
+	// generation changes on every invalidation, so a read that raced with a write doesn't
+	// cache the result it read before the write
+	generation uint64
+}
+
+// newInspectionLRU creates a cache holding up to size results; it returns nil (a disabled
+// cache) when size is 0
+func newInspectionLRU(size int) *inspectionLRU {
+	if size <= 0 {
+		return nil
+	}
+	return &inspectionLRU{
+		size:    size,
+		entries: make(map[inspectionLRUKey]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns a cached result and marks it as recently used
+func (l *inspectionLRU) get(key inspectionLRUKey) (interface{}, bool) {
+	if l == nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*inspectionLRUEntry).value, true
+}
+
+// currentGeneration returns the generation to pass to add for a result about to be read
+// from the database
+func (l *inspectionLRU) currentGeneration() uint64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.generation
+}
+
+// add caches a result read from the database, evicting the least recently used result
+// when the cache is full
+// The result is dropped when the cache was invalidated since generation was taken
+func (l *inspectionLRU) add(entry inspectionLRUEntry, generation uint64) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if generation != l.generation {
+		return
+	}
+	if element, ok := l.entries[entry.key]; ok {
+		element.Value = &entry
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[entry.key] = l.order.PushFront(&entry)
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*inspectionLRUEntry).key)
+	}
+}
+
+// invalidate removes all forms of the results of a table stored under hash or for the
+// given VM snapshot
+func (l *inspectionLRU) invalidate(table, hash, vmName, snapshotName string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	for element := l.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*inspectionLRUEntry)
+		if entry.key.table == table &&
+			(entry.key.hash == hash || (entry.vmName == vmName && entry.snapshotName == snapshotName)) {
+			l.order.Remove(element)
+			delete(l.entries, entry.key)
+		}
+		element = next
+	}
+}
+
+// purge removes every cached result
+func (l *inspectionLRU) purge() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	l.entries = make(map[inspectionLRUKey]*list.Element, l.size)
+	l.order.Init()
+}
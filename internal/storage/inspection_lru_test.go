@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/persistent"
+	pkgtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestInspectionDB returns an inspection database in a temporary SQLite file
+func newTestInspectionDB(tb testing.TB, cacheSize int) *InspectionDB {
+	tb.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(tb.TempDir(), "inspections.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	inspectionDB, err := NewInspectionDB(db, cacheSize, logger)
+	if err != nil {
+		tb.Fatalf("NewInspectionDB() error = %v", err)
+	}
+	return inspectionDB
+}
+
+// testLRUEntry returns a virt-inspector data entry for a VM snapshot
+func testLRUEntry(vmName, snapshotName string) inspectionLRUEntry {
+	key := persistent.CacheKey{VMName: vmName, SnapshotName: snapshotName}
+	return inspectionLRUEntry{
+		key:          inspectionLRUKey{table: lruTableVirtInspector, form: lruFormData, hash: key.Hash()},
+		vmName:       vmName,
+		snapshotName: snapshotName,
+		value:        vmName + "/" + snapshotName,
+	}
+}
+
+// cached reports whether entry's key is in the cache, without marking it as recently used
+func (l *inspectionLRU) cached(entry inspectionLRUEntry) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.entries[entry.key]
+	return ok
+}
+
+func TestInspectionLRUEviction(t *testing.T) {
+	lru := newInspectionLRU(2)
+	a, b, c := testLRUEntry("web01", "a"), testLRUEntry("web01", "b"), testLRUEntry("web01", "c")
+
+	lru.add(a, lru.currentGeneration())
+	lru.add(b, lru.currentGeneration())
+
+	// Reading a makes b the least recently used entry
+	if value, ok := lru.get(a.key); !ok || value != a.value {
+		t.Fatalf("get(a) = %v, %v, want %v, true", value, ok, a.value)
+	}
+	lru.add(c, lru.currentGeneration())
+
+	for _, tt := range []struct {
+		entry inspectionLRUEntry
+		want  bool
+	}{{a, true}, {b, false}, {c, true}} {
+		if got := lru.cached(tt.entry); got != tt.want {
+			t.Errorf("%s cached = %v, want %v", tt.entry.snapshotName, got, tt.want)
+		}
+	}
+	if lru.order.Len() != 2 || len(lru.entries) != 2 {
+		t.Errorf("cache holds %d entries (%d indexed), want 2", lru.order.Len(), len(lru.entries))
+	}
+
+	// Adding a cached key replaces its value without evicting anything
+	updated := a
+	updated.value = "updated"
+	lru.add(updated, lru.currentGeneration())
+	if value, _ := lru.get(a.key); value != "updated" {
+		t.Errorf("get(a) after update = %v, want %v", value, "updated")
+	}
+	if !lru.cached(c) {
+		t.Errorf("updating a cached key evicted another entry")
+	}
+}
+
+func TestInspectionLRUInvalidate(t *testing.T) {
+	web01 := testLRUEntry("web01", "before-upgrade")
+	web01Record := web01
+	web01Record.key.form = lruFormRecord
+	web01V2V := web01
+	web01V2V.key.table = lruTableVirtV2V
+	web02 := testLRUEntry("web02", "before-upgrade")
+	// An entry of the same snapshot stored under another hash, e.g. by an older key format
+	web01OtherHash := web01
+	web01OtherHash.key.hash = "legacy-hash"
+
+	lru := newInspectionLRU(10)
+	for _, entry := range []inspectionLRUEntry{web01, web01Record, web01V2V, web02, web01OtherHash} {
+		lru.add(entry, lru.currentGeneration())
+	}
+
+	lru.invalidate(lruTableVirtInspector, web01.key.hash, web01.vmName, web01.snapshotName)
+
+	tests := []struct {
+		name  string
+		entry inspectionLRUEntry
+		want  bool
+	}{
+		{name: "data form", entry: web01, want: false},
+		{name: "record form", entry: web01Record, want: false},
+		{name: "same snapshot under another hash", entry: web01OtherHash, want: false},
+		{name: "other inspector", entry: web01V2V, want: true},
+		{name: "other VM", entry: web02, want: true},
+	}
+	for _, tt := range tests {
+		if got := lru.cached(tt.entry); got != tt.want {
+			t.Errorf("%s cached = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	lru.purge()
+	if lru.order.Len() != 0 || len(lru.entries) != 0 {
+		t.Errorf("purge() left %d entries", lru.order.Len())
+	}
+}
+
+func TestInspectionLRUGeneration(t *testing.T) {
+	entry := testLRUEntry("web01", "before-upgrade")
+
+	tests := []struct {
+		name       string
+		invalidate func(l *inspectionLRU)
+	}{
+		{name: "invalidate", invalidate: func(l *inspectionLRU) {
+			l.invalidate(lruTableVirtV2V, "other", "web02", "other")
+		}},
+		{name: "purge", invalidate: func(l *inspectionLRU) { l.purge() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lru := newInspectionLRU(10)
+
+			// A read that started before a write must not cache what it read
+			generation := lru.currentGeneration()
+			tt.invalidate(lru)
+			lru.add(entry, generation)
+			if lru.cached(entry) {
+				t.Errorf("add() cached a result read before the cache was invalidated")
+			}
+
+			lru.add(entry, lru.currentGeneration())
+			if !lru.cached(entry) {
+				t.Errorf("add() with the current generation did not cache the result")
+			}
+		})
+	}
+}
+
+func TestInspectionLRUDisabled(t *testing.T) {
+	lru := newInspectionLRU(0)
+	if lru != nil {
+		t.Fatalf("newInspectionLRU(0) = %v, want nil", lru)
+	}
+
+	entry := testLRUEntry("web01", "before-upgrade")
+	lru.add(entry, lru.currentGeneration())
+	if _, ok := lru.get(entry.key); ok {
+		t.Errorf("get() on a disabled cache hit")
+	}
+	lru.invalidate(lruTableVirtInspector, entry.key.hash, entry.vmName, entry.snapshotName)
+	lru.purge()
+}
+
+func TestInspectionDBCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	key := persistent.CacheKey{VMName: "web01", SnapshotName: "before-upgrade"}
+	dataKey := inspectionLRUKey{table: lruTableVirtInspector, form: lruFormData, hash: key.Hash()}
+	recordKey := inspectionLRUKey{table: lruTableVirtInspector, form: lruFormRecord, hash: key.Hash()}
+
+	// read loads both forms of the result into the cache
+	read := func(t *testing.T, db *InspectionDB) {
+		t.Helper()
+		if data, err := db.GetVirtInspectorXML(ctx, key); err != nil || data == nil {
+			t.Fatalf("GetVirtInspectorXML() = %v, %v, want stored data", data, err)
+		}
+		if record, err := db.GetVirtInspectorRecord(ctx, key); err != nil || record == nil {
+			t.Fatalf("GetVirtInspectorRecord() = %v, %v, want a record", record, err)
+		}
+		for _, lruKey := range []inspectionLRUKey{dataKey, recordKey} {
+			if _, ok := db.lru.get(lruKey); !ok {
+				t.Fatalf("%s form not cached after a read", lruKey.form)
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		write      func(db *InspectionDB) error
+		wantStored bool
+	}{
+		{name: "set", wantStored: true, write: func(db *InspectionDB) error {
+			return db.SetVirtInspectorXML(ctx, key, &pkgtypes.VirtInspectorXML{})
+		}},
+		{name: "delete", wantStored: false, write: func(db *InspectionDB) error {
+			_, err := db.DeleteVirtInspectorXML(ctx, key)
+			return err
+		}},
+		{name: "delete older than", wantStored: false, write: func(db *InspectionDB) error {
+			_, err := db.DeleteOlderThan(ctx, time.Now().Add(time.Hour))
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestInspectionDB(t, 10)
+			if err := db.SetVirtInspectorXML(ctx, key, &pkgtypes.VirtInspectorXML{}); err != nil {
+				t.Fatalf("SetVirtInspectorXML() error = %v", err)
+			}
+			read(t, db)
+
+			if err := tt.write(db); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			for _, lruKey := range []inspectionLRUKey{dataKey, recordKey} {
+				if _, ok := db.lru.get(lruKey); ok {
+					t.Errorf("%s form still cached after %s", lruKey.form, tt.name)
+				}
+			}
+
+			record, err := db.GetVirtInspectorRecord(ctx, key)
+			if err != nil {
+				t.Fatalf("GetVirtInspectorRecord() error = %v", err)
+			}
+			if stored := record != nil; stored != tt.wantStored {
+				t.Errorf("GetVirtInspectorRecord() after %s found a record = %v, want %v", tt.name, stored, tt.wantStored)
+			}
+		})
+	}
+}
+
+func BenchmarkGetVirtInspectorXML(b *testing.B) {
+	ctx := context.Background()
+
+	for _, bench := range []struct {
+		name      string
+		cacheSize int
+	}{
+		{name: "database", cacheSize: 0},
+		{name: "memory cache", cacheSize: 100},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			db := newTestInspectionDB(b, bench.cacheSize)
+			keys := make([]persistent.CacheKey, 10)
+			for i := range keys {
+				keys[i] = persistent.CacheKey{VMName: fmt.Sprintf("vm-%d", i), SnapshotName: "before-upgrade"}
+				if err := db.SetVirtInspectorXML(ctx, keys[i], &pkgtypes.VirtInspectorXML{}); err != nil {
+					b.Fatalf("SetVirtInspectorXML() error = %v", err)
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.GetVirtInspectorXML(ctx, keys[i%len(keys)]); err != nil {
+					b.Fatalf("GetVirtInspectorXML() error = %v", err)
+				}
+			}
+		})
+	}
+}
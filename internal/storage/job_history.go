@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InspectionJobRecord represents a database record of a single inspection attempt
+type InspectionJobRecord struct {
+	gorm.Model
+	JobID         string `gorm:"uniqueIndex"`
+	VMName        string `gorm:"index"`
+	SnapshotName  string
+	InspectorType string
+	Datacenter    string
+	Async         bool
+	RequestID     string
+	Status        string `gorm:"index"`
+	StartedAt     *time.Time
+	CompletedAt   *time.Time
+	ErrorCode     string
+	ErrorMessage  string `gorm:"type:text"`
+}
+
+// JobHistoryFilter selects inspection job records
+// Empty fields match all records; a zero Limit returns all matching records
+type JobHistoryFilter struct {
+	VMName string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// JobHistoryDB provides GORM-based persistent storage for the inspection job history
+type JobHistoryDB struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewJobHistoryDB creates a new GORM-based job history database
+func NewJobHistoryDB(db *gorm.DB, logger *logrus.Logger) (*JobHistoryDB, error) {
+	// Auto-migrate the schema
+	if err := db.AutoMigrate(&InspectionJobRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate job history schema: %w", err)
+	}
+
+	return &JobHistoryDB{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// CreateJob stores a new inspection job record
+func (db *JobHistoryDB) CreateJob(ctx context.Context, record *InspectionJobRecord) error {
+	if err := db.db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to store inspection job: %w", err)
+	}
+
+	if db.logger != nil {
+		db.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"job_id": record.JobID,
+			"status": record.Status,
+		}).Debug("Stored inspection job in DB")
+	}
+
+	return nil
+}
+
+// FinishJob records the final status of an inspection job
+// errorCode and errorMessage are empty for successful jobs
+func (db *JobHistoryDB) FinishJob(ctx context.Context, jobID, status, errorCode, errorMessage string) error {
+	now := time.Now()
+	result := db.db.WithContext(ctx).Model(&InspectionJobRecord{}).
+		Where("job_id = ?", jobID).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"completed_at":  &now,
+			"error_code":    errorCode,
+			"error_message": errorMessage,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update inspection job: %w", result.Error)
+	}
+
+	if db.logger != nil {
+		db.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"job_id": jobID,
+			"status": status,
+		}).Debug("Updated inspection job in DB")
+	}
+
+	return nil
+}
+
+// ListJobs returns the inspection job records matching the filter, newest first,
+// along with the total number of matching records before pagination
+func (db *JobHistoryDB) ListJobs(ctx context.Context, filter JobHistoryFilter) ([]InspectionJobRecord, int64, error) {
+	query := db.db.WithContext(ctx).Model(&InspectionJobRecord{})
+	if filter.VMName != "" {
+		query = query.Where("vm_name = ?", filter.VMName)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count inspection jobs: %w", err)
+	}
+
+	query = query.Order("created_at DESC").Order("id DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var records []InspectionJobRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to query inspection jobs: %w", err)
+	}
+
+	return records, total, nil
+}
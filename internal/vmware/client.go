@@ -3,6 +3,7 @@ package vmware
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/url"
 	"sync"
@@ -15,8 +16,12 @@ import (
 	"github.com/vmware/govmomi/session/cache"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/soap"
+	"golang.org/x/sync/singleflight"
 )
 
+// ErrVCenterRequired is returned when a feature that needs vCenter is used against a standalone ESXi host
+var ErrVCenterRequired = errors.New("operation requires vCenter; connected to a standalone ESXi host")
+
 // Client represents a VMware vSphere client with connection management
 type Client struct {
 	config     config.VMwareConfig
@@ -25,6 +30,15 @@ type Client struct {
 	session    *cache.Session
 	mutex      sync.RWMutex
 	isLoggedIn bool
+
+	// Connection diagnostics, guarded by mutex
+	lastConnectTime      time.Time
+	reconnectCount       int
+	lastHealthCheck      time.Time
+	lastHealthCheckError string
+
+	// connectGroup lets concurrent GetClient callers share one in-progress connection attempt
+	connectGroup singleflight.Group
 }
 
 // NewClient creates a new VMware client instance
@@ -65,6 +79,13 @@ func (c *Client) Connect(ctx context.Context) error {
 		}
 	}
 
+	// Verify the vCenter certificate against a custom CA bundle when configured
+	if !c.config.InsecureSkipVerify && c.config.CACertFile != "" {
+		if err := soapClient.SetRootCAs(c.config.CACertFile); err != nil {
+			return fmt.Errorf("failed to load CA certificate file: %w", err)
+		}
+	}
+
 	// Set request timeout
 	soapClient.Timeout = c.config.RequestTimeout
 
@@ -92,6 +113,7 @@ func (c *Client) Connect(ctx context.Context) error {
 	}
 
 	c.isLoggedIn = true
+	c.lastConnectTime = time.Now()
 	c.logger.Info("Successfully connected to vCenter")
 	return nil
 }
@@ -132,6 +154,7 @@ func (c *Client) loginWithRetry(ctx context.Context) error {
 }
 
 // Disconnect closes the connection to vSphere
+// The logout is bounded by the configured disconnect timeout and by ctx, whichever ends first
 func (c *Client) Disconnect(ctx context.Context) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -143,7 +166,7 @@ func (c *Client) Disconnect(ctx context.Context) error {
 	c.logger.Info("Disconnecting from vCenter")
 
 	// Logout with timeout
-	logoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	logoutCtx, cancel := context.WithTimeout(ctx, c.config.DisconnectTimeout)
 	defer cancel()
 
 	if err := c.session.Logout(logoutCtx, c.client.Client); err != nil {
@@ -166,6 +189,7 @@ func (c *Client) IsConnected() bool {
 
 // GetClient returns the underlying govmomi client
 // This method ensures the connection is active before returning
+// Concurrent callers on a cold start share a single connection attempt
 func (c *Client) GetClient(ctx context.Context) (*govmomi.Client, error) {
 	c.mutex.RLock()
 	if c.client != nil && c.isLoggedIn {
@@ -175,20 +199,63 @@ func (c *Client) GetClient(ctx context.Context) (*govmomi.Client, error) {
 	}
 	c.mutex.RUnlock()
 
-	// If not connected, attempt to connect
-	if err := c.Connect(ctx); err != nil {
-		return nil, fmt.Errorf("failed to establish connection: %w", err)
+	// If not connected, attempt to connect (or join the attempt already in progress)
+	// The shared attempt is detached from this caller's cancellation so one aborted
+	// request doesn't fail the others; Connect bounds it with the connection timeout
+	result := c.connectGroup.DoChan("connect", func() (interface{}, error) {
+		if c.IsConnected() {
+			return nil, nil
+		}
+		return nil, c.Connect(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to establish connection: %w", ctx.Err())
+	case res := <-result:
+		if res.Err != nil {
+			return nil, fmt.Errorf("failed to establish connection: %w", res.Err)
+		}
 	}
 
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
+	if c.client == nil {
+		return nil, fmt.Errorf("failed to establish connection: client disconnected")
+	}
 	return c.client, nil
 }
 
+// IsVCenter reports whether the connection is to vCenter rather than a standalone ESXi host
+func (c *Client) IsVCenter(ctx context.Context) (bool, error) {
+	client, err := c.GetClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	return client.IsVC(), nil
+}
+
+// RequireVCenter returns ErrVCenterRequired when connected to a standalone ESXi host
+// Linked clones and vpx:// disk access are only available through vCenter
+func (c *Client) RequireVCenter(ctx context.Context) error {
+	isVC, err := c.IsVCenter(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+	if !isVC {
+		return ErrVCenterRequired
+	}
+	return nil
+}
+
 // Reconnect forces a reconnection to vSphere
 func (c *Client) Reconnect(ctx context.Context) error {
 	c.logger.Info("Forcing reconnection to vCenter")
 
+	c.mutex.Lock()
+	c.reconnectCount++
+	c.mutex.Unlock()
+
 	// Disconnect first (ignore errors)
 	_ = c.Disconnect(ctx)
 
@@ -196,15 +263,47 @@ func (c *Client) Reconnect(ctx context.Context) error {
 	return c.Connect(ctx)
 }
 
+// SessionInfo describes the vCenter user session of the connection
+type SessionInfo struct {
+	Key       string
+	UserName  string
+	LoginTime time.Time
+}
+
+// CurrentSession returns the vCenter user session of the connection
+func (c *Client) CurrentSession(ctx context.Context) (*SessionInfo, error) {
+	client, err := c.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connection not available: %w", err)
+	}
+
+	userSession, err := session.NewManager(client.Client).UserSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user session: %w", err)
+	}
+	if userSession == nil {
+		return nil, fmt.Errorf("no active user session")
+	}
+
+	return &SessionInfo{
+		Key:       userSession.Key,
+		UserName:  userSession.UserName,
+		LoginTime: userSession.LoginTime,
+	}, nil
+}
+
 // HealthCheck verifies the connection is still valid
-func (c *Client) HealthCheck(ctx context.Context) error {
+func (c *Client) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.recordHealthCheck(err) }()
+
 	client, err := c.GetClient(ctx)
 	if err != nil {
 		return fmt.Errorf("connection not available: %w", err)
 	}
 
-	// Create a context with short timeout for health check
-	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	// Bound the session validation separately from the caller's deadline, so a slow
+	// vCenter fails the check quickly and the reconnect still gets the rest of ctx
+	healthCtx, cancel := context.WithTimeout(ctx, c.config.SessionCheckTimeout)
 	defer cancel()
 
 	// Try to get the current session from vCenter as a simple health check
@@ -218,6 +317,44 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// recordHealthCheck records the time and outcome of a health check
+func (c *Client) recordHealthCheck(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lastHealthCheck = time.Now()
+	c.lastHealthCheckError = ""
+	if err != nil {
+		c.lastHealthCheckError = err.Error()
+	}
+}
+
+// ClientStats describes the state and history of the vCenter session
+// Zero times mean the event has not happened yet
+type ClientStats struct {
+	Connected            bool      `json:"connected"`
+	VCenterURL           string    `json:"vcenter_url"`
+	LastConnectTime      time.Time `json:"last_connect_time"`
+	ReconnectCount       int       `json:"reconnect_count"`
+	LastHealthCheck      time.Time `json:"last_health_check"`
+	LastHealthCheckError string    `json:"last_health_check_error,omitempty"`
+}
+
+// Stats returns connection diagnostics, e.g. to tell whether the vCenter session is flapping
+func (c *Client) Stats() ClientStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return ClientStats{
+		Connected:            c.client != nil && c.isLoggedIn,
+		VCenterURL:           c.config.VCenterURL,
+		LastConnectTime:      c.lastConnectTime,
+		ReconnectCount:       c.reconnectCount,
+		LastHealthCheck:      c.lastHealthCheck,
+		LastHealthCheckError: c.lastHealthCheckError,
+	}
+}
+
 // GetConfig returns the VMware configuration
 func (c *Client) GetConfig() config.VMwareConfig {
 	c.mutex.RLock()
@@ -225,6 +362,14 @@ func (c *Client) GetConfig() config.VMwareConfig {
 	return c.config
 }
 
+// SetRetryPolicy changes the vCenter login retry settings used by later connection attempts
+func (c *Client) SetRetryPolicy(attempts int, delay time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.config.RetryAttempts = attempts
+	c.config.RetryDelay = delay
+}
+
 // GetVCenterURL returns the vCenter URL
 func (c *Client) GetVCenterURL() string {
 	c.mutex.RLock()
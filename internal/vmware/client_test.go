@@ -0,0 +1,136 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/simulator"
+)
+
+func TestGetClientSharesConnectionAttempt(t *testing.T) {
+	cfg := startSimulator(t, simulator.VPX())
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	hook := test.NewLocal(logger)
+
+	client := NewClient(cfg, logger)
+	t.Cleanup(func() {
+		_ = client.Disconnect(context.Background())
+	})
+
+	const callers = 20
+	clients := make([]*govmomi.Client, callers)
+	errs := make([]error, callers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			clients[i], errs[i] = client.GetClient(context.Background())
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := range clients {
+		if errs[i] != nil {
+			t.Fatalf("GetClient() caller %d error = %v", i, errs[i])
+		}
+		if clients[i] == nil || clients[i] != clients[0] {
+			t.Errorf("GetClient() caller %d = %p, want the shared client %p", i, clients[i], clients[0])
+		}
+	}
+
+	connects := 0
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Connecting to vCenter" {
+			connects++
+		}
+	}
+	if connects != 1 {
+		t.Errorf("GetClient() from %d callers connected %d times, want 1", callers, connects)
+	}
+}
+
+func TestDisconnectBoundedBySlowLogout(t *testing.T) {
+	cfg, proxy := startStallingProxy(t, startSimulator(t, simulator.VPX()))
+	cfg.DisconnectTimeout = 200 * time.Millisecond
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	client := NewClient(cfg, logger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// The session cache keeps sessions for reuse and skips the Logout call; passthrough
+	// sends it, so the stalled vCenter actually gets to hold it up
+	client.session.Passthrough = true
+	proxy.stall("<Logout")
+	start := time.Now()
+	err := client.Disconnect(context.Background())
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Errorf("Disconnect() error = %v, want nil", err)
+	}
+	if elapsed < cfg.DisconnectTimeout || elapsed > cfg.DisconnectTimeout+time.Second {
+		t.Errorf("Disconnect() took %v, want the %v logout timeout plus a margin", elapsed, cfg.DisconnectTimeout)
+	}
+	if client.IsConnected() {
+		t.Errorf("IsConnected() after Disconnect() = true, want false")
+	}
+}
+
+func TestHealthCheckSessionCheckTimeout(t *testing.T) {
+	cfg, proxy := startStallingProxy(t, startSimulator(t, simulator.VPX()))
+	cfg.SessionCheckTimeout = 200 * time.Millisecond
+	cfg.DisconnectTimeout = 200 * time.Millisecond
+	cfg.ConnectionTimeout = 200 * time.Millisecond
+	cfg.RetryAttempts = 0
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	client := NewClient(cfg, logger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// vCenter stops answering: the session check and the reconnect both time out
+	proxy.stall("<")
+	start := time.Now()
+	err := client.HealthCheck(context.Background())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("HealthCheck() error = nil, want a deadline error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("HealthCheck() error = %v, want a deadline error", err)
+	}
+	if elapsed < cfg.SessionCheckTimeout {
+		t.Errorf("HealthCheck() took %v, want the %v session check to time out first", elapsed, cfg.SessionCheckTimeout)
+	}
+	if bound := cfg.SessionCheckTimeout + cfg.DisconnectTimeout + cfg.ConnectionTimeout + time.Second; elapsed > bound {
+		t.Errorf("HealthCheck() took %v, want at most %v", elapsed, bound)
+	}
+
+	stats := client.Stats()
+	if stats.LastHealthCheck.Before(start) || stats.LastHealthCheckError == "" {
+		t.Errorf("Stats() = last check %v, error %q, want the failed check recorded", stats.LastHealthCheck, stats.LastHealthCheckError)
+	}
+	if stats.ReconnectCount != 1 {
+		t.Errorf("Stats() ReconnectCount = %d, want 1", stats.ReconnectCount)
+	}
+}
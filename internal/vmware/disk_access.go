@@ -0,0 +1,65 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+)
+
+// ErrDiskNotAccessible is returned when a disk file cannot be found on its datastore
+var ErrDiskNotAccessible = errors.New("disk not accessible")
+
+// VerifyDiskFiles checks that each disk file exists on its datastore, so an inspection of
+// a missing or unreachable disk fails with a clear error instead of deep inside nbdkit
+// Disk paths use the datastore path format, e.g. "[datastore1] vm/vm.vmdk"
+// If datacenterName is empty, the datastores are looked up in the default datacenter
+func (s *VMService) VerifyDiskFiles(ctx context.Context, datacenterName string, diskPaths []string) error {
+	s.log(ctx).WithFields(logrus.Fields{
+		"datacenter": datacenterName,
+		"disks":      len(diskPaths),
+	}).Debug("Verifying disk files are accessible")
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	if datacenterName != "" {
+		datacenter, err := finder.Datacenter(ctx, datacenterName)
+		if err != nil {
+			return finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
+		}
+		finder.SetDatacenter(datacenter)
+	} else if _, err := s.getDefaultDatacenter(ctx, finder); err != nil {
+		return err
+	}
+
+	datastores := make(map[string]*object.Datastore)
+	for _, diskPath := range diskPaths {
+		var path object.DatastorePath
+		if !path.FromString(diskPath) {
+			return fmt.Errorf("%w: '%s' is not a datastore path", ErrDiskNotAccessible, diskPath)
+		}
+
+		datastore, ok := datastores[path.Datastore]
+		if !ok {
+			datastore, err = finder.Datastore(ctx, path.Datastore)
+			if err != nil {
+				return fmt.Errorf("%w: '%s': datastore '%s' not found: %w", ErrDiskNotAccessible, diskPath, path.Datastore, err)
+			}
+			datastores[path.Datastore] = datastore
+		}
+
+		if _, err := datastore.Stat(ctx, path.Path); err != nil {
+			return fmt.Errorf("%w: '%s': %w", ErrDiskNotAccessible, diskPath, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+)
+
+func TestVerifyDiskFiles(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	result, err := service.GetVMByName(ctx, "DC0_H0_VM0", AllVMFields())
+	if err != nil {
+		t.Fatalf("GetVMByName() error = %v", err)
+	}
+	if len(result.VM.Disks) == 0 {
+		t.Fatal("GetVMByName() returned no disks")
+	}
+	existing := result.VM.Disks[0].DiskPath
+
+	tests := []struct {
+		name      string
+		diskPaths []string
+		wantErr   error
+	}{
+		{name: "existing disk", diskPaths: []string{existing}},
+		{name: "no disks", diskPaths: nil},
+		{name: "missing file", diskPaths: []string{"[LocalDS_0] nope/nope.vmdk"}, wantErr: ErrDiskNotAccessible},
+		{name: "missing file after an existing disk", diskPaths: []string{existing, "[LocalDS_0] nope/nope.vmdk"}, wantErr: ErrDiskNotAccessible},
+		{name: "unknown datastore", diskPaths: []string{"[nope] nope/nope.vmdk"}, wantErr: ErrDiskNotAccessible},
+		{name: "not a datastore path", diskPaths: []string{"/vmfs/volumes/nope.vmdk"}, wantErr: ErrDiskNotAccessible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.VerifyDiskFiles(ctx, "DC0", tt.diskPaths)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("VerifyDiskFiles() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyDiskFiles() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := service.VerifyDiskFiles(ctx, "nope", []string{existing}); !errors.Is(err, ErrDatacenterNotFound) {
+		t.Errorf("VerifyDiskFiles() in an unknown datacenter error = %v, want %v", err, ErrDatacenterNotFound)
+	}
+}
@@ -0,0 +1,111 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrDiskNotFound is returned when a disk selector matches none of the VM's disks
+var ErrDiskNotFound = errors.New("disk not found")
+
+// DiskSelector picks a single virtual disk of a VM to inspect, either by its device label
+// (e.g. "Hard disk 2") or by its zero-based index among the VM's disks in device order
+// The zero value selects all disks
+type DiskSelector struct {
+	Label string
+	Index *int
+}
+
+// IsZero reports whether the selector selects all disks
+func (d DiskSelector) IsZero() bool {
+	return d.Label == "" && d.Index == nil
+}
+
+// String describes the selected disk, e.g. "Hard disk 2" or "disk 1"
+func (d DiskSelector) String() string {
+	if d.Index != nil {
+		return fmt.Sprintf("disk %d", *d.Index)
+	}
+	return d.Label
+}
+
+// SelectDisk restricts diskInfo to the disk of the VM matched by selector
+// Returns ErrDiskNotFound, listing the VM's disk labels, when no disk matches
+// If datacenterName is empty, the VM is looked up in the default datacenter
+func (s *VMService) SelectDisk(ctx context.Context, vmRef VMRef, datacenterName string, diskInfo *types.SnapshotDiskInfo, selector DiskSelector) (*types.SnapshotDiskInfo, error) {
+	if selector.IsZero() {
+		return diskInfo, nil
+	}
+
+	vm, _, err := s.findVM(ctx, vmRef, datacenterName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	var vmMo mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"config.hardware.device"}, &vmMo); err != nil {
+		return nil, fmt.Errorf("failed to get VM properties: %w", err)
+	}
+	if vmMo.Config == nil {
+		return nil, fmt.Errorf("VM %s configuration is not available", vmRef)
+	}
+
+	// Disks are counted the same way collectDiskPaths builds diskInfo
+	var labels []string
+	index := 0
+	for _, device := range vmMo.Config.Hardware.Device {
+		disk, ok := device.(*vimtypes.VirtualDisk)
+		if !ok {
+			continue
+		}
+		backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			continue
+		}
+
+		label := deviceLabel(disk.GetVirtualDevice())
+		labels = append(labels, label)
+		matched := (selector.Index != nil && *selector.Index == index) ||
+			(selector.Index == nil && strings.EqualFold(label, selector.Label))
+		index++
+		if !matched {
+			continue
+		}
+
+		for i, diskPath := range diskInfo.DiskPaths {
+			if diskPath != backing.FileName {
+				continue
+			}
+			selected := *diskInfo
+			selected.DiskPaths = []string{diskPath}
+			selected.BaseDiskPaths = nil
+			if i < len(diskInfo.BaseDiskPaths) {
+				selected.BaseDiskPaths = []string{diskInfo.BaseDiskPaths[i]}
+			}
+
+			s.log(ctx).WithFields(logrus.Fields{
+				"vm":         vmRef.String(),
+				"disk_label": label,
+				"disk_path":  diskPath,
+			}).Debug("Selected disk for inspection")
+			return &selected, nil
+		}
+		return nil, fmt.Errorf("%w: %s of VM %s is not part of the disks to inspect", ErrDiskNotFound, selector, vmRef)
+	}
+
+	return nil, fmt.Errorf("%w: VM %s has no %s (disks: %s)", ErrDiskNotFound, vmRef, selector, strings.Join(labels, ", "))
+}
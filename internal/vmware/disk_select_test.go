@@ -0,0 +1,136 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/kubev2v/vm-migration-detective/pkg/types"
+	"github.com/vmware/govmomi/simulator"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+func TestSelectDisk(t *testing.T) {
+	model := simulator.VPX()
+	service := newSimulatorService(t, startSimulator(t, model))
+	ctx := context.Background()
+
+	const (
+		vmName    = "DC0_H0_VM0"
+		disk1     = "[LocalDS_0] DC0_H0_VM0/disk1-000001.vmdk"
+		disk2     = "[LocalDS_0] DC0_H0_VM0/disk2-000001.vmdk"
+		baseDisk1 = "[LocalDS_0] DC0_H0_VM0/disk1.vmdk"
+		baseDisk2 = "[LocalDS_0] DC0_H0_VM0/disk2.vmdk"
+	)
+
+	// Give the VM a second disk; vcsim creates VMs with one
+	vm, _, err := service.findVM(ctx, VMRef{Name: vmName}, "")
+	if err != nil {
+		t.Fatalf("findVM() error = %v", err)
+	}
+	simVM := model.Map().Get(vm.Reference()).(*simulator.VirtualMachine)
+	var first *vimtypes.VirtualDisk
+	for _, device := range simVM.Config.Hardware.Device {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			first = disk
+			break
+		}
+	}
+	if first == nil {
+		t.Fatal("simulator VM has no disk")
+	}
+	second := *first
+	second.Key = first.Key + 1
+	unitNumber := int32(1)
+	second.UnitNumber = &unitNumber
+	for _, d := range []struct {
+		disk  *vimtypes.VirtualDisk
+		label string
+		path  string
+	}{{first, "Hard disk 1", disk1}, {&second, "Hard disk 2", disk2}} {
+		d.disk.DeviceInfo = &vimtypes.Description{Label: d.label}
+		d.disk.Backing = &vimtypes.VirtualDiskFlatVer2BackingInfo{
+			VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: d.path},
+		}
+	}
+	simVM.Config.Hardware.Device = append(simVM.Config.Hardware.Device, &second)
+
+	allDisks := &types.SnapshotDiskInfo{
+		VMMoref:       vm.Reference().Value,
+		DiskPaths:     []string{disk1, disk2},
+		BaseDiskPaths: []string{baseDisk1, baseDisk2},
+	}
+	firstDiskOnly := &types.SnapshotDiskInfo{
+		VMMoref:       vm.Reference().Value,
+		DiskPaths:     []string{disk1},
+		BaseDiskPaths: []string{baseDisk1},
+	}
+	index := func(i int) *int { return &i }
+
+	tests := []struct {
+		name          string
+		diskInfo      *types.SnapshotDiskInfo
+		selector      DiskSelector
+		wantPaths     []string
+		wantBasePaths []string
+		wantErr       error
+	}{
+		{name: "all disks", diskInfo: allDisks, wantPaths: []string{disk1, disk2}, wantBasePaths: []string{baseDisk1, baseDisk2}},
+		{name: "label", diskInfo: allDisks, selector: DiskSelector{Label: "Hard disk 2"}, wantPaths: []string{disk2}, wantBasePaths: []string{baseDisk2}},
+		{name: "label in another case", diskInfo: allDisks, selector: DiskSelector{Label: "hard DISK 1"}, wantPaths: []string{disk1}, wantBasePaths: []string{baseDisk1}},
+		{name: "first index", diskInfo: allDisks, selector: DiskSelector{Index: index(0)}, wantPaths: []string{disk1}, wantBasePaths: []string{baseDisk1}},
+		{name: "second index", diskInfo: allDisks, selector: DiskSelector{Index: index(1)}, wantPaths: []string{disk2}, wantBasePaths: []string{baseDisk2}},
+		{name: "unknown label", diskInfo: allDisks, selector: DiskSelector{Label: "Hard disk 3"}, wantErr: ErrDiskNotFound},
+		{name: "index out of range", diskInfo: allDisks, selector: DiskSelector{Index: index(2)}, wantErr: ErrDiskNotFound},
+		{name: "negative index", diskInfo: allDisks, selector: DiskSelector{Index: index(-1)}, wantErr: ErrDiskNotFound},
+		{name: "disk not being inspected", diskInfo: firstDiskOnly, selector: DiskSelector{Label: "Hard disk 2"}, wantErr: ErrDiskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.SelectDisk(ctx, VMRef{Name: vmName}, "", tt.diskInfo, tt.selector)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SelectDisk(%v) error = %v, want %v", tt.selector, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectDisk(%v) error = %v", tt.selector, err)
+			}
+			if !slices.Equal(got.DiskPaths, tt.wantPaths) || !slices.Equal(got.BaseDiskPaths, tt.wantBasePaths) {
+				t.Errorf("SelectDisk(%v) = %v (base %v), want %v (base %v)", tt.selector, got.DiskPaths, got.BaseDiskPaths, tt.wantPaths, tt.wantBasePaths)
+			}
+			if got.VMMoref != tt.diskInfo.VMMoref {
+				t.Errorf("SelectDisk(%v) VMMoref = %q, want %q", tt.selector, got.VMMoref, tt.diskInfo.VMMoref)
+			}
+		})
+	}
+
+	if len(allDisks.DiskPaths) != 2 {
+		t.Errorf("SelectDisk() modified its input, DiskPaths = %v", allDisks.DiskPaths)
+	}
+}
+
+func TestDiskSelectorString(t *testing.T) {
+	index := 1
+	tests := []struct {
+		selector DiskSelector
+		want     string
+		wantZero bool
+	}{
+		{selector: DiskSelector{}, want: "", wantZero: true},
+		{selector: DiskSelector{Label: "Hard disk 2"}, want: "Hard disk 2"},
+		{selector: DiskSelector{Index: &index}, want: "disk 1"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.selector.String(); got != tt.want {
+			t.Errorf("DiskSelector.String() = %q, want %q", got, tt.want)
+		}
+		if got := tt.selector.IsZero(); got != tt.wantZero {
+			t.Errorf("DiskSelector{%q}.IsZero() = %v, want %v", tt.want, got, tt.wantZero)
+		}
+	}
+}
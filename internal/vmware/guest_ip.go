@@ -0,0 +1,121 @@
+package vmware
+
+import (
+	"net/netip"
+
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// IP address families reported for guest addresses
+const (
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
+
+// GuestIPAddress represents a classified IP address reported by VMware Tools
+type GuestIPAddress struct {
+	Address      string `json:"address"`
+	PrefixLength int32  `json:"prefix_length,omitempty"`
+	Family       string `json:"family"`
+	LinkLocal    bool   `json:"link_local"`
+}
+
+// CIDR returns the address in CIDR notation, or the bare address when the prefix length is unknown
+func (ip GuestIPAddress) CIDR() string {
+	if ip.PrefixLength <= 0 {
+		return ip.Address
+	}
+	addr, err := netip.ParseAddr(ip.Address)
+	if err != nil {
+		return ip.Address
+	}
+	return netip.PrefixFrom(addr, int(ip.PrefixLength)).String()
+}
+
+// GuestIPFilter selects guest IP addresses by family and scope
+type GuestIPFilter struct {
+	Family           string // empty matches both families
+	IncludeLinkLocal bool
+}
+
+// Matches reports whether an address passes the filter
+func (f GuestIPFilter) Matches(ip GuestIPAddress) bool {
+	if ip.LinkLocal && !f.IncludeLinkLocal {
+		return false
+	}
+	return f.Family == "" || ip.Family == f.Family
+}
+
+// Apply returns the addresses that pass the filter
+func (f GuestIPFilter) Apply(ips []GuestIPAddress) []GuestIPAddress {
+	var filtered []GuestIPAddress
+	for _, ip := range ips {
+		if f.Matches(ip) {
+			filtered = append(filtered, ip)
+		}
+	}
+	return filtered
+}
+
+// classifyGuestIP parses an address reported by VMware Tools and determines its family and scope
+// Addresses that cannot be parsed are rejected
+func classifyGuestIP(address string, prefixLength int32) (GuestIPAddress, bool) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return GuestIPAddress{}, false
+	}
+
+	ip := GuestIPAddress{
+		Address:      address,
+		PrefixLength: prefixLength,
+		Family:       IPFamilyIPv4,
+		LinkLocal:    addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast(),
+	}
+	if addr.Is6() && !addr.Is4In6() {
+		ip.Family = IPFamilyIPv6
+	}
+	return ip, true
+}
+
+// collectNICIPs returns the classified addresses configured on a guest NIC
+func collectNICIPs(nic vimtypes.GuestNicInfo) []GuestIPAddress {
+	if nic.IpConfig == nil {
+		return nil
+	}
+
+	var ips []GuestIPAddress
+	for _, ipConfig := range nic.IpConfig.IpAddress {
+		if ip, ok := classifyGuestIP(ipConfig.IpAddress, ipConfig.PrefixLength); ok {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// collectGuestIPs returns the deduplicated addresses of all guest NICs, primary address first
+func collectGuestIPs(guest *vimtypes.GuestInfo) []GuestIPAddress {
+	var ips []GuestIPAddress
+	index := make(map[string]int) // address -> position in ips
+
+	add := func(ip GuestIPAddress) {
+		if i, ok := index[ip.Address]; ok {
+			// Keep the prefix length when the address is seen again on a NIC
+			if ips[i].PrefixLength == 0 {
+				ips[i].PrefixLength = ip.PrefixLength
+			}
+			return
+		}
+		index[ip.Address] = len(ips)
+		ips = append(ips, ip)
+	}
+
+	if ip, ok := classifyGuestIP(guest.IpAddress, 0); ok {
+		add(ip)
+	}
+	for _, nic := range guest.Net {
+		for _, ip := range collectNICIPs(nic) {
+			add(ip)
+		}
+	}
+	return ips
+}
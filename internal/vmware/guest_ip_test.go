@@ -0,0 +1,191 @@
+package vmware
+
+import (
+	"reflect"
+	"testing"
+
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+func TestClassifyGuestIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		address      string
+		prefixLength int32
+		want         GuestIPAddress
+		wantOK       bool
+	}{
+		{
+			name:         "IPv4",
+			address:      "10.0.0.5",
+			prefixLength: 24,
+			want:         GuestIPAddress{Address: "10.0.0.5", PrefixLength: 24, Family: IPFamilyIPv4},
+			wantOK:       true,
+		},
+		{
+			name:    "IPv4 link-local",
+			address: "169.254.10.20",
+			want:    GuestIPAddress{Address: "169.254.10.20", Family: IPFamilyIPv4, LinkLocal: true},
+			wantOK:  true,
+		},
+		{
+			name:         "IPv6",
+			address:      "2001:db8::10",
+			prefixLength: 64,
+			want:         GuestIPAddress{Address: "2001:db8::10", PrefixLength: 64, Family: IPFamilyIPv6},
+			wantOK:       true,
+		},
+		{
+			name:         "IPv6 link-local",
+			address:      "fe80::250:56ff:fe01:2",
+			prefixLength: 64,
+			want:         GuestIPAddress{Address: "fe80::250:56ff:fe01:2", PrefixLength: 64, Family: IPFamilyIPv6, LinkLocal: true},
+			wantOK:       true,
+		},
+		{
+			name:    "IPv6 link-local with zone",
+			address: "fe80::1%eth0",
+			want:    GuestIPAddress{Address: "fe80::1%eth0", Family: IPFamilyIPv6, LinkLocal: true},
+			wantOK:  true,
+		},
+		{
+			name:    "IPv4-mapped IPv6",
+			address: "::ffff:192.168.1.5",
+			want:    GuestIPAddress{Address: "::ffff:192.168.1.5", Family: IPFamilyIPv4},
+			wantOK:  true,
+		},
+		{name: "empty", address: "", wantOK: false},
+		{name: "hostname", address: "web01.example.com", wantOK: false},
+		{name: "CIDR notation", address: "10.0.0.5/24", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := classifyGuestIP(tt.address, tt.prefixLength)
+			if ok != tt.wantOK {
+				t.Fatalf("classifyGuestIP(%q) ok = %v, want %v", tt.address, ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("classifyGuestIP(%q) = %+v, want %+v", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+// guestNIC returns a guest NIC configured with addresses
+func guestNIC(addresses ...vimtypes.NetIpConfigInfoIpAddress) vimtypes.GuestNicInfo {
+	return vimtypes.GuestNicInfo{IpConfig: &vimtypes.NetIpConfigInfo{IpAddress: addresses}}
+}
+
+// nicIP returns a NIC address with its prefix length
+func nicIP(address string, prefixLength int32) vimtypes.NetIpConfigInfoIpAddress {
+	return vimtypes.NetIpConfigInfoIpAddress{IpAddress: address, PrefixLength: prefixLength}
+}
+
+func TestCollectGuestIPs(t *testing.T) {
+	nic := guestNIC(
+		nicIP("fe80::250:56ff:fe01:2", 64),
+		nicIP("10.0.0.5", 24),
+		nicIP("2001:db8::10", 64),
+		nicIP("not-an-ip", 0),
+	)
+
+	tests := []struct {
+		name  string
+		guest *vimtypes.GuestInfo
+		want  []GuestIPAddress
+	}{
+		{
+			name:  "no addresses",
+			guest: &vimtypes.GuestInfo{},
+			want:  nil,
+		},
+		{
+			name:  "primary address only",
+			guest: &vimtypes.GuestInfo{IpAddress: "10.0.0.5"},
+			want:  []GuestIPAddress{{Address: "10.0.0.5", Family: IPFamilyIPv4}},
+		},
+		{
+			name:  "primary address first, deduplicated with its NIC prefix length",
+			guest: &vimtypes.GuestInfo{IpAddress: "10.0.0.5", Net: []vimtypes.GuestNicInfo{nic}},
+			want: []GuestIPAddress{
+				{Address: "10.0.0.5", PrefixLength: 24, Family: IPFamilyIPv4},
+				{Address: "fe80::250:56ff:fe01:2", PrefixLength: 64, Family: IPFamilyIPv6, LinkLocal: true},
+				{Address: "2001:db8::10", PrefixLength: 64, Family: IPFamilyIPv6},
+			},
+		},
+		{
+			name: "address on two NICs",
+			guest: &vimtypes.GuestInfo{Net: []vimtypes.GuestNicInfo{
+				guestNIC(nicIP("10.0.0.5", 24)),
+				guestNIC(nicIP("10.0.0.5", 16), nicIP("169.254.1.1", 16)),
+			}},
+			want: []GuestIPAddress{
+				{Address: "10.0.0.5", PrefixLength: 24, Family: IPFamilyIPv4},
+				{Address: "169.254.1.1", PrefixLength: 16, Family: IPFamilyIPv4, LinkLocal: true},
+			},
+		},
+		{
+			name:  "NIC without IP configuration",
+			guest: &vimtypes.GuestInfo{Net: []vimtypes.GuestNicInfo{{MacAddress: "00:50:56:01:02:03"}}},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collectGuestIPs(tt.guest); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectGuestIPs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuestIPFilter(t *testing.T) {
+	ips := []GuestIPAddress{
+		{Address: "10.0.0.5", Family: IPFamilyIPv4},
+		{Address: "169.254.1.1", Family: IPFamilyIPv4, LinkLocal: true},
+		{Address: "2001:db8::10", Family: IPFamilyIPv6},
+		{Address: "fe80::1", Family: IPFamilyIPv6, LinkLocal: true},
+	}
+
+	tests := []struct {
+		name   string
+		filter GuestIPFilter
+		want   []string
+	}{
+		{name: "default", filter: GuestIPFilter{}, want: []string{"10.0.0.5", "2001:db8::10"}},
+		{name: "with link-local", filter: GuestIPFilter{IncludeLinkLocal: true}, want: []string{"10.0.0.5", "169.254.1.1", "2001:db8::10", "fe80::1"}},
+		{name: "IPv4 only", filter: GuestIPFilter{Family: IPFamilyIPv4}, want: []string{"10.0.0.5"}},
+		{name: "IPv6 with link-local", filter: GuestIPFilter{Family: IPFamilyIPv6, IncludeLinkLocal: true}, want: []string{"2001:db8::10", "fe80::1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ip := range tt.filter.Apply(ips) {
+				got = append(got, ip.Address)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GuestIPFilter%+v.Apply() = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuestIPAddressCIDR(t *testing.T) {
+	tests := []struct {
+		ip   GuestIPAddress
+		want string
+	}{
+		{ip: GuestIPAddress{Address: "10.0.0.5", PrefixLength: 24}, want: "10.0.0.5/24"},
+		{ip: GuestIPAddress{Address: "2001:db8::10", PrefixLength: 64}, want: "2001:db8::10/64"},
+		{ip: GuestIPAddress{Address: "10.0.0.5"}, want: "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.ip.CIDR(); got != tt.want {
+			t.Errorf("CIDR(%+v) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+)
+
+// ListDatacenters returns the names of all datacenters, sorted
+func (s *VMService) ListDatacenters(ctx context.Context) ([]string, error) {
+	s.log(ctx).Debug("Listing datacenters")
+
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenters, err := finder.DatacenterList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list datacenters: %w", err)
+	}
+
+	names := make([]string, 0, len(datacenters))
+	for _, datacenter := range datacenters {
+		names = append(names, datacenter.Name())
+	}
+	sort.Strings(names)
+
+	s.log(ctx).WithField("datacenter_count", len(names)).Debug("Listed datacenters")
+	return names, nil
+}
+
+// ListClusters returns the names of all clusters in a datacenter, sorted
+func (s *VMService) ListClusters(ctx context.Context, datacenterName string) ([]string, error) {
+	s.log(ctx).WithField("datacenter", datacenterName).Debug("Listing clusters")
+
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.Datacenter(ctx, datacenterName)
+	if err != nil {
+		return nil, finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
+	}
+	finder.SetDatacenter(datacenter)
+
+	clusters, err := finder.ClusterComputeResourceList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list clusters in datacenter '%s': %w", datacenterName, err)
+	}
+
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name())
+	}
+	sort.Strings(names)
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"datacenter":    datacenterName,
+		"cluster_count": len(names),
+	}).Debug("Listed clusters")
+	return names, nil
+}
@@ -0,0 +1,107 @@
+package vmware
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/simulator"
+)
+
+// startSimulator creates the inventory of model, serves it with vcsim and returns a VMware
+// config pointing at it; the simulator is stopped when the test ends
+func startSimulator(tb testing.TB, model *simulator.Model) config.VMwareConfig {
+	tb.Helper()
+
+	if err := model.Create(); err != nil {
+		tb.Fatalf("failed to create simulator inventory: %v", err)
+	}
+	server := model.Service.NewServer()
+	tb.Cleanup(func() {
+		server.Close()
+		model.Remove()
+	})
+
+	cfg := config.DefaultConfig().VMware
+	u := *server.URL
+	cfg.Username = u.User.Username()
+	cfg.Password, _ = u.User.Password()
+	u.User = nil
+	cfg.VCenterURL = u.String()
+	cfg.InsecureSkipVerify = true
+	return cfg
+}
+
+// newSimulatorService returns a VMService connected with cfg, disconnected when the test ends
+func newSimulatorService(tb testing.TB, cfg config.VMwareConfig) *VMService {
+	tb.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	client := NewClient(cfg, logger)
+	if err := client.Connect(context.Background()); err != nil {
+		tb.Fatalf("failed to connect to the simulator: %v", err)
+	}
+	tb.Cleanup(func() {
+		_ = client.Disconnect(context.Background())
+	})
+	return NewVMService(client, logger)
+}
+
+// stallingProxy forwards SOAP requests to a simulator; once stalled, requests whose body
+// contains the stall pattern hang until the client gives up
+type stallingProxy struct {
+	pattern atomic.Value // string; empty stalls nothing
+}
+
+// stall makes requests containing pattern hang; "<" stalls every request
+func (p *stallingProxy) stall(pattern string) {
+	p.pattern.Store(pattern)
+}
+
+// startStallingProxy serves a proxy to the simulator of cfg and returns a config pointing at it
+func startStallingProxy(tb testing.TB, cfg config.VMwareConfig) (config.VMwareConfig, *stallingProxy) {
+	tb.Helper()
+
+	target, err := url.Parse(cfg.VCenterURL)
+	if err != nil {
+		tb.Fatalf("invalid simulator URL: %v", err)
+	}
+	forward := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: target.Scheme, Host: target.Host})
+	forward.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+
+	proxy := &stallingProxy{}
+	proxy.pattern.Store("")
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if pattern := proxy.pattern.Load().(string); pattern != "" && strings.Contains(string(body), pattern) {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		forward.ServeHTTP(w, r)
+	}))
+	tb.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+
+	proxied := cfg
+	proxied.VCenterURL = server.URL + target.Path
+	return proxied, proxy
+}
@@ -0,0 +1,48 @@
+package vmware
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// FetchThumbprint returns the SHA-1 thumbprint of the vCenter TLS certificate, as used by
+// VDDK to verify the host it reads disks from
+// The certificate is read without verification, so this works with self-signed certificates
+func (c *Client) FetchThumbprint(ctx context.Context) (string, error) {
+	cfg := c.GetConfig()
+
+	vcenterURL, err := url.Parse(cfg.VCenterURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid vCenter URL: %w", err)
+	}
+
+	address := vcenterURL.Host
+	if vcenterURL.Port() == "" {
+		address = net.JoinHostPort(vcenterURL.Hostname(), "443")
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: cfg.ConnectionTimeout},
+		Config: &tls.Config{
+			InsecureSkipVerify: true, // Only the certificate is read; nothing is sent
+			ServerName:         vcenterURL.Hostname(),
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to '%s': %w", address, err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return "", fmt.Errorf("'%s' presented no TLS certificate", address)
+	}
+
+	return soap.ThumbprintSHA1(certs[0]), nil
+}
@@ -0,0 +1,60 @@
+package vmware
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nirarg/vm-deep-inspection-demo/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestFetchThumbprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// VDDK expects the SHA-1 digest of the certificate as colon-separated hex bytes
+	digest := sha1.Sum(server.Certificate().Raw)
+	hexBytes := make([]string, len(digest))
+	for i, b := range digest {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+	thumbprint := strings.Join(hexBytes, ":")
+
+	// A closed server leaves an address nothing listens on
+	closed := httptest.NewTLSServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "explicit port", url: server.URL + "/sdk", want: thumbprint},
+		{name: "nothing listening", url: closed.URL + "/sdk", wantErr: true},
+		{name: "invalid URL", url: "https://vcenter:port/sdk", wantErr: true},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(config.VMwareConfig{VCenterURL: tt.url, ConnectionTimeout: 5 * time.Second}, logger)
+
+			got, err := client.FetchThumbprint(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchThumbprint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FetchThumbprint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrInvalidAnnotation is returned when an annotation cannot be stored on a VM
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// MaxAnnotationLength is the maximum size in bytes of a VM annotation accepted by vSphere
+const MaxAnnotationLength = 65535
+
+// VMAnnotation represents the annotation (notes) of a VM
+type VMAnnotation struct {
+	Name       string `json:"name"`
+	Annotation string `json:"annotation"`
+}
+
+// SetAnnotation replaces the annotation (notes) of a VM and returns the stored annotation
+func (s *VMService) SetAnnotation(ctx context.Context, vmName string, text string) (*VMAnnotation, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name": vmName,
+		"length":  len(text),
+	}).Info("Setting VM annotation")
+
+	if err := validateAnnotation(text); err != nil {
+		return nil, err
+	}
+
+	// Find VM by name
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := vm.Reconfigure(ctx, vimtypes.VirtualMachineConfigSpec{
+		Annotation: text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconfigure task: %w", err)
+	}
+
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Reconfigure task created, waiting for completion")
+
+	if err := task.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("VM annotation update failed: %w", err)
+	}
+
+	// Get govmomi client for property collector
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	// Read back the stored annotation
+	var updated mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"name", "config.annotation"}, &updated); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	result := &VMAnnotation{Name: updated.Name}
+	if updated.Config != nil {
+		result.Annotation = updated.Config.Annotation
+	}
+
+	s.log(ctx).WithField("vm_name", result.Name).Info("VM annotation updated successfully")
+
+	return result, nil
+}
+
+// validateAnnotation checks an annotation against the vSphere limits
+func validateAnnotation(text string) error {
+	// An empty annotation is omitted from the config spec, so it would silently change nothing
+	if text == "" {
+		return fmt.Errorf("%w: annotation must not be empty", ErrInvalidAnnotation)
+	}
+	if len(text) > MaxAnnotationLength {
+		return fmt.Errorf("%w: annotation is %d bytes, the maximum is %d", ErrInvalidAnnotation, len(text), MaxAnnotationLength)
+	}
+	if !utf8.ValidString(text) {
+		return fmt.Errorf("%w: annotation must be valid UTF-8", ErrInvalidAnnotation)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package vmware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+func TestChangeTrackingSpec(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		spec := changeTrackingSpec(enabled)
+		if spec.ChangeTrackingEnabled == nil || *spec.ChangeTrackingEnabled != enabled {
+			t.Errorf("changeTrackingSpec(%v).ChangeTrackingEnabled = %v, want %v", enabled, spec.ChangeTrackingEnabled, enabled)
+		}
+
+		// Only the CBT setting may change; a non-empty field would reconfigure more of the VM
+		spec.ChangeTrackingEnabled = nil
+		if spec.DeviceChange != nil || spec.ExtraConfig != nil || spec.Name != "" || spec.NumCPUs != 0 || spec.MemoryMB != 0 {
+			t.Errorf("changeTrackingSpec(%v) = %+v, want only ChangeTrackingEnabled set", enabled, spec)
+		}
+	}
+}
+
+func TestSetChangeTracking(t *testing.T) {
+	tests := []struct {
+		name              string
+		poweredOff        bool
+		enabled           bool
+		diskChangeID      string
+		wantSnapshotCycle bool
+	}{
+		{name: "enable on a powered-on VM", enabled: true, wantSnapshotCycle: true},
+		{name: "enable on a powered-off VM", poweredOff: true, enabled: true, wantSnapshotCycle: false},
+		{name: "enable with disks already tracking", enabled: true, diskChangeID: "52 aa bb/1", wantSnapshotCycle: false},
+		{name: "disable with disks still tracking", enabled: false, diskChangeID: "52 aa bb/1", wantSnapshotCycle: true},
+		{name: "disable on a powered-on VM", enabled: false, wantSnapshotCycle: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := simulator.VPX()
+			service := newSimulatorService(t, startSimulator(t, model))
+			ctx := context.Background()
+
+			const vmName = "DC0_H0_VM0"
+			if tt.poweredOff {
+				powerOffVM(t, service, vmName)
+			}
+			if tt.diskChangeID != "" {
+				vm, _, err := service.findVMByName(ctx, vmName)
+				if err != nil {
+					t.Fatalf("findVMByName() error = %v", err)
+				}
+				simVM := model.Map().Get(vm.Reference()).(*simulator.VirtualMachine)
+				for _, device := range simVM.Config.Hardware.Device {
+					if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+						disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo).ChangeId = tt.diskChangeID
+					}
+				}
+			}
+
+			result, err := service.SetChangeTracking(ctx, vmName, tt.enabled)
+			if err != nil {
+				t.Fatalf("SetChangeTracking(%v) error = %v", tt.enabled, err)
+			}
+			if result.Name != vmName || result.Enabled != tt.enabled {
+				t.Errorf("SetChangeTracking(%v) = %s enabled %v, want %s enabled %v", tt.enabled, result.Name, result.Enabled, vmName, tt.enabled)
+			}
+			if result.RequiresSnapshotCycle != tt.wantSnapshotCycle {
+				t.Errorf("SetChangeTracking(%v) RequiresSnapshotCycle = %v, want %v", tt.enabled, result.RequiresSnapshotCycle, tt.wantSnapshotCycle)
+			}
+
+			current, err := service.GetChangeTracking(ctx, vmName)
+			if err != nil {
+				t.Fatalf("GetChangeTracking() error = %v", err)
+			}
+			if *current != *result {
+				t.Errorf("GetChangeTracking() = %+v, want %+v", current, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrInvalidClonePattern is returned when a clone name pattern cannot be parsed
+var ErrInvalidClonePattern = errors.New("invalid clone name pattern")
+
+// cloneMarkerKey is the extraConfig key set on every clone created by this service
+// Only VMs carrying it, or named with a generated clone name, are ever considered for cleanup
+const cloneMarkerKey = "vmdi.clone"
+
+// cloneTimestampSuffix matches the timestamp appended to generated clone names,
+// either a Unix timestamp (inspection clones) or YYYYMMDDHHMMSS (API clones)
+// Clones created before cloneMarkerKey was set are recognized by it
+var cloneTimestampSuffix = regexp.MustCompile(`-clone-(\d{10}|\d{14})$`)
+
+// CloneCleanupFailure describes a stale clone that could not be deleted
+type CloneCleanupFailure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// CloneCleanupResult summarizes a stale clone cleanup run
+type CloneCleanupResult struct {
+	Deleted []string              `json:"deleted"`
+	Failed  []CloneCleanupFailure `json:"failed"`
+	Skipped int                   `json:"skipped"`
+}
+
+// CleanupStaleClones deletes leaked clones in the default datacenter that are older than olderThan
+// Only powered-off clones created by this service (matching namePattern, if set) are deleted
+// Clones whose disks back other linked clones are reported as failed and kept
+// VMs whose age cannot be determined are skipped
+func (s *VMService) CleanupStaleClones(ctx context.Context, olderThan time.Duration, namePattern string) (*CloneCleanupResult, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"older_than":   olderThan.String(),
+		"name_pattern": namePattern,
+	}).Info("Cleaning up stale clones")
+
+	if namePattern != "" {
+		if _, err := path.Match(namePattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: '%s': %w", ErrInvalidClonePattern, namePattern, err)
+		}
+	}
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := s.getDefaultDatacenter(ctx, finder)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CloneCleanupResult{
+		Deleted: []string{},
+		Failed:  []CloneCleanupFailure{},
+	}
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	var vmRefs []vimtypes.ManagedObjectReference
+	for _, vm := range vms {
+		vmRefs = append(vmRefs, vm.Reference())
+	}
+
+	pc := property.DefaultCollector(client.Client)
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.createDate",
+		"config.extraConfig",
+		"config.template",
+		"runtime.powerState",
+		"runtime.bootTime",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	for _, vmProp := range vmProperties {
+		if !isServiceClone(vmProp, namePattern) {
+			continue
+		}
+
+		logger := s.log(ctx).WithFields(logrus.Fields{
+			"clone_name":  vmProp.Name,
+			"clone_moref": vmProp.Self.Value,
+		})
+
+		if vmProp.Config != nil && vmProp.Config.Template {
+			logger.Debug("Skipping template created as a clone")
+			result.Skipped++
+			continue
+		}
+		if vmProp.Runtime.PowerState != vimtypes.VirtualMachinePowerStatePoweredOff {
+			logger.Debug("Skipping clone that is not powered off")
+			result.Skipped++
+			continue
+		}
+
+		created, ok := cloneCreationTime(vmProp)
+		if !ok {
+			logger.Warn("Skipping clone with unknown creation time")
+			result.Skipped++
+			continue
+		}
+		if created.After(cutoff) {
+			result.Skipped++
+			continue
+		}
+
+		// A clone that was itself cloned backs the disks of the newer clone
+		vm := object.NewVirtualMachine(client.Client, vmProp.Self)
+		dependents, err := s.findDependentClones(ctx, vm, datacenter)
+		if err != nil {
+			logger.WithError(err).Error("Failed to check stale clone for dependent clones")
+			result.Failed = append(result.Failed, CloneCleanupFailure{
+				Name:  vmProp.Name,
+				Error: fmt.Sprintf("failed to check for dependent clones: %v", err),
+			})
+			continue
+		}
+		if len(dependents) > 0 {
+			logger.WithField("dependents", dependents).Warn("Not deleting stale clone that backs linked clones")
+			result.Failed = append(result.Failed, CloneCleanupFailure{
+				Name:  vmProp.Name,
+				Error: fmt.Sprintf("%v: backs %s", ErrVMHasDependentClones, strings.Join(dependents, ", ")),
+			})
+			continue
+		}
+
+		logger.WithField("created", created.UTC().Format(time.RFC3339)).Info("Deleting stale clone")
+		if err := s.destroyVM(ctx, vm); err != nil {
+			logger.WithError(err).Error("Failed to delete stale clone")
+			result.Failed = append(result.Failed, CloneCleanupFailure{Name: vmProp.Name, Error: err.Error()})
+			continue
+		}
+		result.Deleted = append(result.Deleted, vmProp.Name)
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"deleted": len(result.Deleted),
+		"failed":  len(result.Failed),
+		"skipped": result.Skipped,
+	}).Info("Stale clone cleanup completed")
+
+	return result, nil
+}
+
+// destroyVM destroys a VM and waits for the task to complete
+func (s *VMService) destroyVM(ctx context.Context, vm *object.VirtualMachine) error {
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create delete task: %w", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("VM deletion failed: %w", err)
+	}
+	return nil
+}
+
+// isServiceClone reports whether vm is a clone created by this service and its name
+// matches namePattern
+// A clone carries cloneMarkerKey; clones created before the marker was set are
+// recognized by the timestamp suffix of their generated name
+func isServiceClone(vm mo.VirtualMachine, namePattern string) bool {
+	if namePattern != "" {
+		if matched, _ := path.Match(namePattern, vm.Name); !matched {
+			return false
+		}
+	}
+	if vm.Config != nil {
+		for _, option := range vm.Config.ExtraConfig {
+			if value := option.GetOptionValue(); value.Key == cloneMarkerKey && value.Value == "true" {
+				return true
+			}
+		}
+	}
+	return cloneTimestampSuffix.MatchString(vm.Name)
+}
+
+// cloneCreationTime determines when a clone was created
+// It prefers config.createDate (vSphere 6.7+), then the timestamp embedded in
+// generated clone names, and finally the last boot time
+func cloneCreationTime(vm mo.VirtualMachine) (time.Time, bool) {
+	if vm.Config != nil && vm.Config.CreateDate != nil {
+		return *vm.Config.CreateDate, true
+	}
+
+	if match := cloneTimestampSuffix.FindStringSubmatch(vm.Name); match != nil {
+		if len(match[1]) == 10 {
+			if seconds, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				return time.Unix(seconds, 0), true
+			}
+		} else if created, err := time.ParseInLocation("20060102150405", match[1], time.Local); err == nil {
+			return created, true
+		}
+	}
+
+	if vm.Runtime.BootTime != nil {
+		return *vm.Runtime.BootTime, true
+	}
+
+	return time.Time{}, false
+}
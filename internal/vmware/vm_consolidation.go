@@ -0,0 +1,135 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrConsolidationNotNeeded is returned when consolidating the disks of a VM that doesn't need it
+var ErrConsolidationNotNeeded = errors.New("VM disks do not need consolidation")
+
+// ListVMsNeedingConsolidation returns the VMs whose disks need consolidation
+// Leftover delta disks from failed snapshot removals are a common cause of inspection failures
+// If datacenterName is empty, the default datacenter is searched
+func (s *VMService) ListVMsNeedingConsolidation(ctx context.Context, datacenterName string) (*VMListResult, error) {
+	s.log(ctx).WithField("datacenter", datacenterName).Info("Listing VMs needing disk consolidation")
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+
+	var datacenter *object.Datacenter
+	if datacenterName != "" {
+		datacenter, err = finder.Datacenter(ctx, datacenterName)
+		if err != nil {
+			return nil, finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
+		}
+		finder.SetDatacenter(datacenter)
+	} else {
+		datacenter, err = s.getDefaultDatacenter(ctx, finder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &VMListResult{
+		Datacenter: datacenter.Name(),
+		VMs:        []VMInfo{},
+	}
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	var vmRefs []vimtypes.ManagedObjectReference
+	for _, vm := range vms {
+		vmRefs = append(vmRefs, vm.Reference())
+	}
+
+	pc := property.DefaultCollector(client.Client)
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.uuid",
+		"runtime.powerState",
+		"runtime.connectionState",
+		"runtime.consolidationNeeded",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	for _, vmProp := range vmProperties {
+		if vmProp.Runtime.ConsolidationNeeded != nil && *vmProp.Runtime.ConsolidationNeeded {
+			result.VMs = append(result.VMs, *s.convertToVMInfo(vmProp))
+		}
+	}
+	result.Total = len(result.VMs)
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"scanned":             len(vmProperties),
+		"needs_consolidation": result.Total,
+	}).Info("Disk consolidation scan completed")
+
+	return result, nil
+}
+
+// ConsolidateDisks consolidates the disks of a VM that needs consolidation and waits for the task
+func (s *VMService) ConsolidateDisks(ctx context.Context, vmName string) error {
+	s.log(ctx).WithField("vm_name", vmName).Info("Consolidating VM disks")
+
+	// Find VM by name
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	var vmProp mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"runtime.consolidationNeeded"}, &vmProp); err != nil {
+		return fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+	if vmProp.Runtime.ConsolidationNeeded == nil || !*vmProp.Runtime.ConsolidationNeeded {
+		return fmt.Errorf("%w: '%s'", ErrConsolidationNotNeeded, vmName)
+	}
+
+	res, err := methods.ConsolidateVMDisks_Task(ctx, client.Client, &vimtypes.ConsolidateVMDisks_Task{
+		This: vm.Reference(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consolidate task: %w", err)
+	}
+
+	task := object.NewTask(client.Client, res.Returnval)
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Consolidate task created, waiting for completion")
+
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("VM disk consolidation failed: %w", err)
+	}
+
+	s.log(ctx).WithField("vm_name", vmName).Info("VM disks consolidated successfully")
+	return nil
+}
@@ -0,0 +1,62 @@
+package vmware
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// isDegraded reports whether a VM is orphaned, inaccessible, invalid or disconnected
+// vSphere only reports a subset of the properties of such VMs, so only their name, moref,
+// power state and connection state are returned
+func isDegraded(vm mo.VirtualMachine) bool {
+	return vm.Runtime.ConnectionState != "" &&
+		vm.Runtime.ConnectionState != vimtypes.VirtualMachineConnectionStateConnected
+}
+
+// retrieveVMs retrieves the given properties for a list of VMs, one VM at a time from a single
+// property retrieval
+// vSphere reports a fault instead of the value of some properties of orphaned or inaccessible
+// VMs; such a VM is loaded with the properties that were reported instead of failing the
+// retrieval of all VMs
+func (s *VMService) retrieveVMs(ctx context.Context, pc *property.Collector, vmRefs []vimtypes.ManagedObjectReference, props []string) ([]mo.VirtualMachine, error) {
+	var contents []vimtypes.ObjectContent
+	if err := pc.Retrieve(ctx, vmRefs, props, &contents); err != nil {
+		return nil, err
+	}
+
+	vms := make([]mo.VirtualMachine, 0, len(contents))
+	for _, content := range contents {
+		value, err := mo.ObjectContentToType(content)
+		if err != nil {
+			var missing []string
+			for _, missingProperty := range content.MissingSet {
+				// An expired session is reported per object, but affects every VM
+				if _, ok := missingProperty.Fault.Fault.(*vimtypes.NotAuthenticated); ok {
+					return nil, err
+				}
+				missing = append(missing, missingProperty.Path)
+			}
+			s.log(ctx).WithError(err).WithFields(logrus.Fields{
+				"moref":              content.Obj.Value,
+				"missing_properties": missing,
+			}).Warn("Some VM properties are unavailable, loading the reported ones")
+
+			content.MissingSet = nil
+			if value, err = mo.ObjectContentToType(content); err != nil {
+				return nil, err
+			}
+		}
+
+		vm, ok := value.(mo.VirtualMachine)
+		if !ok {
+			continue
+		}
+		vms = append(vms, vm)
+	}
+
+	return vms, nil
+}
@@ -0,0 +1,144 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrVMHasDependentClones is returned when deleting a VM whose disks back linked clones
+var ErrVMHasDependentClones = errors.New("VM has dependent linked clones")
+
+// FindDependentClones returns the names of the VMs whose disk backing chains reference a
+// disk file of vmName, i.e. the linked clones that would break if vmName were destroyed
+// Only VMs in the same datacenter as vmName are scanned
+func (s *VMService) FindDependentClones(ctx context.Context, vmName string) ([]string, error) {
+	vm, datacenter, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+	return s.findDependentClones(ctx, vm, datacenter)
+}
+
+// findDependentClones returns the names of the VMs in datacenter whose disks have a parent
+// disk file belonging to vm
+func (s *VMService) findDependentClones(ctx context.Context, vm *object.VirtualMachine, datacenter *object.Datacenter) ([]string, error) {
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	// The VM's disk files include those of every snapshot, which linked clones are created from
+	var vmProps mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"config.hardware.device", "layoutEx.file"}, &vmProps); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM disk layout: %w", err)
+	}
+
+	diskFiles := make(map[string]bool)
+	if vmProps.LayoutEx != nil {
+		for _, file := range vmProps.LayoutEx.File {
+			if file.Type == string(vimtypes.VirtualMachineFileLayoutExFileTypeDiskDescriptor) {
+				diskFiles[file.Name] = true
+			}
+		}
+	}
+	if vmProps.Config != nil {
+		for _, fileName := range diskBackingChain(vmProps.Config.Hardware.Device) {
+			diskFiles[fileName] = true
+		}
+	}
+	if len(diskFiles) == 0 {
+		return nil, nil
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	finder.SetDatacenter(datacenter)
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	var vmRefs []vimtypes.ManagedObjectReference
+	for _, other := range vms {
+		if other.Reference() != vm.Reference() {
+			vmRefs = append(vmRefs, other.Reference())
+		}
+	}
+	if len(vmRefs) == 0 {
+		return nil, nil
+	}
+
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.hardware.device",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	var dependents []string
+	for _, other := range vmProperties {
+		if other.Config == nil {
+			continue
+		}
+		// A VM's own files head its backing chain; only parent disks can belong to vm
+		for _, fileName := range diskParentChain(other.Config.Hardware.Device) {
+			if diskFiles[fileName] {
+				dependents = append(dependents, other.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_moref":   vm.Reference().Value,
+		"scanned":    len(vmProperties),
+		"dependents": len(dependents),
+	}).Debug("Dependent clone scan completed")
+
+	return dependents, nil
+}
+
+// diskBackingChain returns the file names of every disk backing of devices, parents included
+func diskBackingChain(devices []vimtypes.BaseVirtualDevice) []string {
+	var fileNames []string
+	for _, device := range devices {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok {
+				for ; backing != nil; backing = backing.Parent {
+					fileNames = append(fileNames, backing.FileName)
+				}
+			}
+		}
+	}
+	return fileNames
+}
+
+// diskParentChain returns the file names of the parent disk backings of devices
+func diskParentChain(devices []vimtypes.BaseVirtualDevice) []string {
+	var fileNames []string
+	for _, device := range devices {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok {
+				for parent := backing.Parent; parent != nil; parent = parent.Parent {
+					fileNames = append(fileNames, parent.FileName)
+				}
+			}
+		}
+	}
+	return fileNames
+}
@@ -0,0 +1,167 @@
+package vmware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidVMField is returned when an unknown VM detail section is requested
+var ErrInvalidVMField = errors.New("invalid VM field")
+
+// VMField selects a section of the VM details to retrieve
+type VMField string
+
+// Supported VM detail sections
+const (
+	VMFieldBasic    VMField = "basic"
+	VMFieldHardware VMField = "hardware"
+	VMFieldNetwork  VMField = "network"
+	VMFieldStorage  VMField = "storage"
+	VMFieldAll      VMField = "all"
+)
+
+// vmFieldOrder lists the detail sections in the order their properties are requested
+var vmFieldOrder = []VMField{VMFieldBasic, VMFieldHardware, VMFieldNetwork, VMFieldStorage}
+
+// vmFieldProperties maps each detail section to the vSphere properties it needs
+var vmFieldProperties = map[VMField][]string{
+	VMFieldBasic: {
+		"name",
+		"config.uuid",
+		"config.instanceUuid",
+		"config.guestFullName",
+		"config.guestId",
+		"config.annotation",
+		"config.template",
+		"config.changeTrackingEnabled",
+		"runtime.powerState",
+		"runtime.host",
+		"runtime.connectionState",
+		"runtime.bootTime",
+		"runtime.maxCpuUsage",
+		"runtime.maxMemoryUsage",
+		"runtime.consolidationNeeded",
+		"runtime.faultToleranceState",
+		"guest.toolsStatus",
+		"guest.toolsVersion",
+		"guest.toolsRunningStatus",
+		"guest.toolsVersionStatus2",
+		"guest.hostName",
+		"guest.guestState",
+		"guestHeartbeatStatus",
+		"resourcePool",
+		"parent",
+	},
+	VMFieldHardware: {
+		"config.hardware.numCPU",
+		"config.hardware.numCoresPerSocket",
+		"config.hardware.memoryMB",
+		"config.version",
+		"config.firmware",
+		"config.cpuHotAddEnabled",
+		"config.cpuHotRemoveEnabled",
+		"config.memoryHotAddEnabled",
+		"config.cpuAllocation",
+		"config.memoryAllocation",
+	},
+	VMFieldNetwork: {
+		"config.hardware.device",
+		"guest.ipAddress",
+		"guest.net",
+		"network",
+	},
+	VMFieldStorage: {
+		"config.hardware.device",
+		"config.files.vmPathName",
+		"datastore",
+		"summary.storage",
+		"layoutEx.file",
+		"snapshot",
+	},
+}
+
+// VMFields is a set of VM detail sections
+// Basic information is always included
+type VMFields map[VMField]bool
+
+// AllVMFields returns the set of all VM detail sections
+func AllVMFields() VMFields {
+	fields := VMFields{}
+	for _, field := range vmFieldOrder {
+		fields[field] = true
+	}
+	return fields
+}
+
+// ParseVMFields parses requested detail sections, given as repeated and/or comma-separated values
+// No values, or "all", selects every section
+func ParseVMFields(values []string) (VMFields, error) {
+	fields := VMFields{VMFieldBasic: true}
+	requested := false
+
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			field := VMField(strings.ToLower(strings.TrimSpace(name)))
+			if field == "" {
+				continue
+			}
+			requested = true
+			if field == VMFieldAll {
+				return AllVMFields(), nil
+			}
+			if _, ok := vmFieldProperties[field]; !ok {
+				return nil, fmt.Errorf("%w: '%s' (supported: %s)", ErrInvalidVMField, name, strings.Join(SupportedVMFields(), ", "))
+			}
+			fields[field] = true
+		}
+	}
+
+	if !requested {
+		return AllVMFields(), nil
+	}
+	return fields, nil
+}
+
+// SupportedVMFields returns the names of the VM detail sections
+func SupportedVMFields() []string {
+	supported := make([]string, 0, len(vmFieldOrder)+1)
+	for _, field := range vmFieldOrder {
+		supported = append(supported, string(field))
+	}
+	return append(supported, string(VMFieldAll))
+}
+
+// Has reports whether a section is included
+func (f VMFields) Has(field VMField) bool {
+	return f[field]
+}
+
+// Names returns the included sections in a stable order
+func (f VMFields) Names() []string {
+	var names []string
+	for _, field := range vmFieldOrder {
+		if f[field] {
+			names = append(names, string(field))
+		}
+	}
+	return names
+}
+
+// properties returns the deduplicated vSphere properties needed for the included sections
+func (f VMFields) properties() []string {
+	var props []string
+	seen := make(map[string]bool)
+	for _, field := range vmFieldOrder {
+		if !f[field] {
+			continue
+		}
+		for _, prop := range vmFieldProperties[field] {
+			if !seen[prop] {
+				seen[prop] = true
+				props = append(props, prop)
+			}
+		}
+	}
+	return props
+}
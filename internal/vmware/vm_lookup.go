@@ -0,0 +1,156 @@
+package vmware
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// VMRef identifies a VM by name, managed object reference (e.g. "vm-42") or BIOS UUID
+// Only one field is expected to be set; a moref takes precedence over a UUID, and a UUID
+// over a name. Names are only unique within a folder, so a moref or UUID is the
+// unambiguous way to target a VM
+type VMRef struct {
+	Name  string
+	Moref string
+	UUID  string
+}
+
+// String describes the VM reference for log and error messages
+func (r VMRef) String() string {
+	switch {
+	case r.Moref != "":
+		return fmt.Sprintf("moref '%s'", r.Moref)
+	case r.UUID != "":
+		return fmt.Sprintf("UUID '%s'", r.UUID)
+	}
+	return fmt.Sprintf("'%s'", r.Name)
+}
+
+// ResolvedVM identifies the VM a VMRef points to
+type ResolvedVM struct {
+	Name       string
+	Moref      string
+	Datacenter string
+}
+
+// ResolveVM looks up the VM a reference points to, so callers can pin later operations to
+// its moref instead of looking it up by name again
+// If datacenterName is empty, VMs looked up by name are searched in the default datacenter,
+// while morefs and UUIDs are resolved in whichever datacenter holds the VM
+func (s *VMService) ResolveVM(ctx context.Context, ref VMRef, datacenterName string) (*ResolvedVM, error) {
+	vm, datacenter, err := s.findVM(ctx, ref, datacenterName)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := vm.ObjectName(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get name of VM %s: %w", ref, err)
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm":         ref.String(),
+		"vm_name":    name,
+		"vm_moref":   vm.Reference().Value,
+		"datacenter": datacenter.Name(),
+	}).Debug("Resolved VM")
+
+	return &ResolvedVM{
+		Name:       name,
+		Moref:      vm.Reference().Value,
+		Datacenter: datacenter.Name(),
+	}, nil
+}
+
+// findVM finds the VM a reference points to, along with its datacenter
+func (s *VMService) findVM(ctx context.Context, ref VMRef, datacenterName string) (*object.VirtualMachine, *object.Datacenter, error) {
+	switch {
+	case ref.Moref != "":
+		return s.findVMByMoref(ctx, ref.Moref, datacenterName)
+	case ref.UUID != "":
+		return s.findVMByUUID(ctx, ref.UUID, datacenterName)
+	}
+	return s.findVMInDatacenter(ctx, ref.Name, datacenterName)
+}
+
+// findVMByUUID is a helper to find a VM by its BIOS UUID
+// If datacenterName is empty, all datacenters are searched
+func (s *VMService) findVMByUUID(ctx context.Context, uuid string, datacenterName string) (*object.VirtualMachine, *object.Datacenter, error) {
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	var datacenter *object.Datacenter
+	if datacenterName != "" {
+		finder := find.NewFinder(client.Client, true)
+		datacenter, err = finder.Datacenter(ctx, datacenterName)
+		if err != nil {
+			return nil, nil, finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
+		}
+	}
+
+	searchIndex := object.NewSearchIndex(client.Client)
+	vmRef, err := searchIndex.FindByUuid(ctx, datacenter, uuid, true, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search for VM with UUID '%s': %w", uuid, err)
+	}
+	if vmRef == nil {
+		if datacenterName != "" {
+			return nil, nil, fmt.Errorf("%w: VM with UUID '%s' in datacenter '%s'", ErrVMNotInDatacenter, uuid, datacenterName)
+		}
+		return nil, nil, fmt.Errorf("VM with UUID '%s' not found", uuid)
+	}
+
+	return s.findVMByMoref(ctx, vmRef.Reference().Value, datacenterName)
+}
+
+// findVMByMoref is a helper to find a VM by its managed object reference
+// The VM's datacenter is looked up from its inventory path; if datacenterName is set,
+// the VM must be in that datacenter
+func (s *VMService) findVMByMoref(ctx context.Context, moref string, datacenterName string) (*object.VirtualMachine, *object.Datacenter, error) {
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	ref := vimtypes.ManagedObjectReference{Type: "VirtualMachine", Value: moref}
+	entities, err := mo.Ancestors(ctx, client.Client, client.ServiceContent.PropertyCollector, ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("VM with moref '%s' not found: %w", moref, err)
+	}
+
+	if len(entities) == 0 {
+		return nil, nil, fmt.Errorf("VM with moref '%s' not found", moref)
+	}
+
+	// Ancestors run from the root folder down to the VM itself
+	var datacenter *object.Datacenter
+	inventoryPath := "/"
+	for _, entity := range entities[1:] {
+		inventoryPath = path.Join(inventoryPath, entity.Name)
+		if entity.Self.Type == "Datacenter" {
+			datacenter = object.NewDatacenter(client.Client, entity.Self)
+			datacenter.InventoryPath = inventoryPath
+		}
+	}
+	if datacenter == nil {
+		return nil, nil, fmt.Errorf("failed to find datacenter of VM with moref '%s'", moref)
+	}
+	if datacenterName != "" && datacenterName != datacenter.Name() && datacenterName != datacenter.InventoryPath {
+		return nil, nil, fmt.Errorf("%w: VM with moref '%s' in datacenter '%s'", ErrVMNotInDatacenter, moref, datacenterName)
+	}
+
+	vm := object.NewVirtualMachine(client.Client, ref)
+	vm.InventoryPath = inventoryPath
+	return vm, datacenter, nil
+}
@@ -0,0 +1,85 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+func TestResolveVMDuplicateNames(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	client, err := service.client.GetClient(ctx)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.Datacenter(ctx, "DC0")
+	if err != nil {
+		t.Fatalf("Datacenter() error = %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+	folders, err := datacenter.Folders(ctx)
+	if err != nil {
+		t.Fatalf("Folders() error = %v", err)
+	}
+
+	// Move a second VM into another folder under the name of the first
+	const vmName = "DC0_H0_VM0"
+	original, err := finder.VirtualMachine(ctx, vmName)
+	if err != nil {
+		t.Fatalf("VirtualMachine(%q) error = %v", vmName, err)
+	}
+	duplicate, err := finder.VirtualMachine(ctx, "DC0_H0_VM1")
+	if err != nil {
+		t.Fatalf("VirtualMachine(%q) error = %v", "DC0_H0_VM1", err)
+	}
+	folder, err := folders.VmFolder.CreateFolder(ctx, "duplicates")
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	task, err := folder.MoveInto(ctx, []vimtypes.ManagedObjectReference{duplicate.Reference()})
+	if err == nil {
+		err = task.Wait(ctx)
+	}
+	if err != nil {
+		t.Fatalf("MoveInto() error = %v", err)
+	}
+	task, err = duplicate.Rename(ctx, vmName)
+	if err == nil {
+		err = task.Wait(ctx)
+	}
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	var multiple *find.MultipleFoundError
+	if _, err := service.ResolveVM(ctx, VMRef{Name: vmName}, ""); !errors.As(err, &multiple) {
+		t.Errorf("ResolveVM() by name error = %v, want a *find.MultipleFoundError", err)
+	}
+
+	for _, vm := range []*object.VirtualMachine{original, duplicate} {
+		var props mo.VirtualMachine
+		if err := vm.Properties(ctx, vm.Reference(), []string{"config.uuid"}, &props); err != nil {
+			t.Fatalf("Properties() error = %v", err)
+		}
+		want := vm.Reference().Value
+
+		for _, ref := range []VMRef{{Moref: want}, {UUID: props.Config.Uuid}} {
+			got, err := service.ResolveVM(ctx, ref, "")
+			if err != nil {
+				t.Fatalf("ResolveVM(%s) error = %v", ref, err)
+			}
+			if got.Moref != want || got.Name != vmName || got.Datacenter != "DC0" {
+				t.Errorf("ResolveVM(%s) = %s/%s (%s), want DC0/%s (%s)", ref, got.Datacenter, got.Name, got.Moref, vmName, want)
+			}
+		}
+	}
+}
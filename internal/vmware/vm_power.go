@@ -0,0 +1,75 @@
+package vmware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// GetPowerState returns the power state of a VM
+func (s *VMService) GetPowerState(ctx context.Context, vmName string) (vimtypes.VirtualMachinePowerState, error) {
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get VM power state: %w", err)
+	}
+	return state, nil
+}
+
+// SuspendVM suspends a powered-on VM and waits for the task to complete
+// The guest memory is written to the VM's suspend file, so its disks are no longer in use
+func (s *VMService) SuspendVM(ctx context.Context, vmName string) error {
+	s.log(ctx).WithField("vm_name", vmName).Info("Suspending VM")
+
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.Suspend(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create suspend task: %w", err)
+	}
+
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Suspend task created, waiting for completion")
+
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("VM suspend failed: %w", err)
+	}
+
+	s.log(ctx).Info("VM suspended successfully")
+	return nil
+}
+
+// PowerOnVM powers on (or resumes) a VM and waits for the task to complete
+func (s *VMService) PowerOnVM(ctx context.Context, vmName string) error {
+	s.log(ctx).WithField("vm_name", vmName).Info("Powering on VM")
+
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.PowerOn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create power-on task: %w", err)
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name": vmName,
+		"task_id": task.Reference().Value,
+	}).Info("Power-on task created, waiting for completion")
+
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("VM power-on failed: %w", err)
+	}
+
+	s.log(ctx).Info("VM powered on successfully")
+	return nil
+}
@@ -0,0 +1,197 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// Errors returned when a reconfiguration request cannot be applied
+var (
+	ErrInvalidReconfigureSpec = errors.New("invalid reconfigure spec")
+	ErrReconfigureNotAllowed  = errors.New("reconfigure not allowed")
+)
+
+// VMReconfigureSpec describes the hardware changes to apply to a VM
+// Nil fields are left unchanged
+type VMReconfigureSpec struct {
+	NumCPU            *int32 `json:"num_cpu,omitempty"`
+	NumCoresPerSocket *int32 `json:"num_cores_per_socket,omitempty"`
+	MemoryMB          *int64 `json:"memory_mb,omitempty"`
+}
+
+// VMHardwareConfig represents the CPU and memory configuration of a VM
+type VMHardwareConfig struct {
+	Name              string `json:"name"`
+	PowerState        string `json:"power_state"`
+	NumCPU            int32  `json:"num_cpu"`
+	NumCoresPerSocket int32  `json:"num_cores_per_socket"`
+	MemoryMB          int32  `json:"memory_mb"`
+}
+
+// Reconfigure changes the CPU and memory configuration of a VM and returns the new configuration
+// Powered-on VMs can only grow CPU or memory when the corresponding hot-add option is enabled
+func (s *VMService) Reconfigure(ctx context.Context, vmName string, spec VMReconfigureSpec) (*VMHardwareConfig, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name":              vmName,
+		"num_cpu":              spec.NumCPU,
+		"num_cores_per_socket": spec.NumCoresPerSocket,
+		"memory_mb":            spec.MemoryMB,
+	}).Info("Reconfiguring VM")
+
+	// Find VM by name
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get govmomi client for property collector
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	pc := property.DefaultCollector(client.Client)
+	reconfigureProps := []string{
+		"name",
+		"runtime.powerState",
+		"config.hardware.numCPU",
+		"config.hardware.numCoresPerSocket",
+		"config.hardware.memoryMB",
+		"config.cpuHotAddEnabled",
+		"config.cpuHotRemoveEnabled",
+		"config.memoryHotAddEnabled",
+	}
+
+	var vmProps mo.VirtualMachine
+	if err := pc.RetrieveOne(ctx, vm.Reference(), reconfigureProps, &vmProps); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+	if vmProps.Config == nil {
+		return nil, fmt.Errorf("VM '%s' configuration is not available", vmName)
+	}
+
+	current := vmProps.Config.Hardware
+	if err := validateReconfigureSpec(spec, current); err != nil {
+		return nil, err
+	}
+
+	if vmProps.Runtime.PowerState != vimtypes.VirtualMachinePowerStatePoweredOff {
+		if err := checkHotReconfigure(spec, vmProps.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	configSpec := vimtypes.VirtualMachineConfigSpec{}
+	if spec.NumCPU != nil {
+		configSpec.NumCPUs = *spec.NumCPU
+	}
+	if spec.NumCoresPerSocket != nil {
+		configSpec.NumCoresPerSocket = *spec.NumCoresPerSocket
+	}
+	if spec.MemoryMB != nil {
+		configSpec.MemoryMB = *spec.MemoryMB
+	}
+
+	task, err := vm.Reconfigure(ctx, configSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconfigure task: %w", err)
+	}
+
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Reconfigure task created, waiting for completion")
+
+	if err := task.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("VM reconfiguration failed: %w", err)
+	}
+
+	// Read back the applied configuration
+	var updated mo.VirtualMachine
+	if err := pc.RetrieveOne(ctx, vm.Reference(), reconfigureProps, &updated); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	result := &VMHardwareConfig{
+		Name:       updated.Name,
+		PowerState: string(updated.Runtime.PowerState),
+	}
+	if updated.Config != nil {
+		result.NumCPU = updated.Config.Hardware.NumCPU
+		result.NumCoresPerSocket = updated.Config.Hardware.NumCoresPerSocket
+		result.MemoryMB = updated.Config.Hardware.MemoryMB
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name":              result.Name,
+		"num_cpu":              result.NumCPU,
+		"num_cores_per_socket": result.NumCoresPerSocket,
+		"memory_mb":            result.MemoryMB,
+	}).Info("VM reconfigured successfully")
+
+	return result, nil
+}
+
+// validateReconfigureSpec checks the requested values against each other and the current hardware
+func validateReconfigureSpec(spec VMReconfigureSpec, current vimtypes.VirtualHardware) error {
+	if spec.NumCPU == nil && spec.NumCoresPerSocket == nil && spec.MemoryMB == nil {
+		return fmt.Errorf("%w: at least one of num_cpu, num_cores_per_socket or memory_mb must be set", ErrInvalidReconfigureSpec)
+	}
+
+	numCPU := current.NumCPU
+	if spec.NumCPU != nil {
+		if *spec.NumCPU < 1 {
+			return fmt.Errorf("%w: num_cpu must be at least 1", ErrInvalidReconfigureSpec)
+		}
+		numCPU = *spec.NumCPU
+	}
+
+	if spec.NumCoresPerSocket != nil {
+		cores := *spec.NumCoresPerSocket
+		if cores < 1 {
+			return fmt.Errorf("%w: num_cores_per_socket must be at least 1", ErrInvalidReconfigureSpec)
+		}
+		if numCPU%cores != 0 {
+			return fmt.Errorf("%w: num_cpu (%d) must be a multiple of num_cores_per_socket (%d)", ErrInvalidReconfigureSpec, numCPU, cores)
+		}
+	}
+
+	// vSphere requires memory to be a multiple of 4 MB
+	if spec.MemoryMB != nil && (*spec.MemoryMB < 4 || *spec.MemoryMB%4 != 0) {
+		return fmt.Errorf("%w: memory_mb must be a positive multiple of 4", ErrInvalidReconfigureSpec)
+	}
+
+	return nil
+}
+
+// checkHotReconfigure verifies that the requested changes can be applied while the VM is running
+func checkHotReconfigure(spec VMReconfigureSpec, config *vimtypes.VirtualMachineConfigInfo) error {
+	current := config.Hardware
+
+	if spec.NumCPU != nil && *spec.NumCPU != current.NumCPU {
+		if *spec.NumCPU > current.NumCPU && (config.CpuHotAddEnabled == nil || !*config.CpuHotAddEnabled) {
+			return fmt.Errorf("%w: VM must be powered off to add CPUs (CPU hot-add is disabled)", ErrReconfigureNotAllowed)
+		}
+		if *spec.NumCPU < current.NumCPU && (config.CpuHotRemoveEnabled == nil || !*config.CpuHotRemoveEnabled) {
+			return fmt.Errorf("%w: VM must be powered off to remove CPUs (CPU hot-remove is disabled)", ErrReconfigureNotAllowed)
+		}
+	}
+
+	if spec.NumCoresPerSocket != nil && *spec.NumCoresPerSocket != current.NumCoresPerSocket {
+		return fmt.Errorf("%w: VM must be powered off to change cores per socket", ErrReconfigureNotAllowed)
+	}
+
+	if spec.MemoryMB != nil && *spec.MemoryMB != int64(current.MemoryMB) {
+		if *spec.MemoryMB < int64(current.MemoryMB) {
+			return fmt.Errorf("%w: VM must be powered off to reduce memory", ErrReconfigureNotAllowed)
+		}
+		if config.MemoryHotAddEnabled == nil || !*config.MemoryHotAddEnabled {
+			return fmt.Errorf("%w: VM must be powered off to add memory (memory hot-add is disabled)", ErrReconfigureNotAllowed)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrInvalidIPAddress is returned when a search IP address cannot be parsed
+var ErrInvalidIPAddress = errors.New("invalid IP address")
+
+// VMIPMatch represents a VM reporting a searched IP address
+type VMIPMatch struct {
+	VM         VMInfo           `json:"vm"`
+	MatchedIPs []GuestIPAddress `json:"matched_ips"`
+}
+
+// VMIPSearchResult represents the VMs reporting a searched IP address
+type VMIPSearchResult struct {
+	Datacenter string      `json:"datacenter"`
+	IP         string      `json:"ip"`
+	Matches    []VMIPMatch `json:"matches"`
+	Total      int         `json:"total"`
+}
+
+// FindVMsByIP returns all VMs whose guest reports the given IP address
+// If datacenterName is empty, the default datacenter is searched
+// Several VMs may match, e.g. clones or disconnected guests still reporting a stale address
+func (s *VMService) FindVMsByIP(ctx context.Context, ip string, datacenterName string) (*VMIPSearchResult, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"ip":         ip,
+		"datacenter": datacenterName,
+	}).Info("Searching VMs by IP address")
+
+	target, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s': %w", ErrInvalidIPAddress, ip, err)
+	}
+	target = target.Unmap().WithZone("")
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+
+	var datacenter *object.Datacenter
+	if datacenterName != "" {
+		datacenter, err = finder.Datacenter(ctx, datacenterName)
+		if err != nil {
+			return nil, finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
+		}
+		finder.SetDatacenter(datacenter)
+	} else {
+		datacenter, err = s.getDefaultDatacenter(ctx, finder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &VMIPSearchResult{
+		Datacenter: datacenter.Name(),
+		IP:         target.String(),
+		Matches:    []VMIPMatch{},
+	}
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	var vmRefs []vimtypes.ManagedObjectReference
+	for _, vm := range vms {
+		vmRefs = append(vmRefs, vm.Reference())
+	}
+
+	// Only the identity and guest network properties are needed to match
+	pc := property.DefaultCollector(client.Client)
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.uuid",
+		"runtime.powerState",
+		"runtime.connectionState",
+		"guest.ipAddress",
+		"guest.net",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	for _, vmProp := range vmProperties {
+		if vmProp.Guest == nil {
+			continue
+		}
+
+		var matched []GuestIPAddress
+		for _, guestIP := range collectGuestIPs(vmProp.Guest) {
+			if addr, err := netip.ParseAddr(guestIP.Address); err == nil && addr.Unmap().WithZone("") == target {
+				matched = append(matched, guestIP)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		result.Matches = append(result.Matches, VMIPMatch{
+			VM:         *s.convertToVMInfo(vmProp),
+			MatchedIPs: matched,
+		})
+	}
+	result.Total = len(result.Matches)
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"ip":      result.IP,
+		"scanned": len(vmProperties),
+		"matches": result.Total,
+	}).Info("VM IP search completed")
+
+	return result, nil
+}
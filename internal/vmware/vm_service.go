@@ -2,8 +2,13 @@ package vmware
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kubev2v/vm-migration-detective/pkg/types"
@@ -11,10 +16,48 @@ import (
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/mo"
 	vimtypes "github.com/vmware/govmomi/vim25/types"
 )
 
+// Errors returned when a requested inventory scope cannot be resolved
+var (
+	ErrDatacenterNotFound = errors.New("datacenter not found")
+	ErrClusterNotFound    = errors.New("cluster not found")
+	ErrVMNotInDatacenter  = errors.New("VM not found in datacenter")
+	ErrVMPoweredOn        = errors.New("VM is powered on")
+	ErrVMEncrypted        = errors.New("VM is encrypted")
+	ErrFolderNotFound     = errors.New("folder not found")
+	ErrDatastoreNotFound  = errors.New("datastore not found")
+	ErrSnapshotAmbiguous  = errors.New("snapshot name is ambiguous")
+)
+
+// finderError wraps the error of a finder lookup with notFound when the object doesn't
+// exist, so handlers answer 404 or 400; other failures, e.g. timeouts or expired sessions,
+// are returned without it so they surface as 500 or 503
+func finderError(notFound error, kind, name string, err error) error {
+	var findErr *find.NotFoundError
+	if errors.As(err, &findErr) {
+		return fmt.Errorf("%w: '%s': %w", notFound, name, err)
+	}
+	return fmt.Errorf("failed to look up %s '%s': %w", kind, name, err)
+}
+
+// CloneInfo identifies a newly created linked clone
+type CloneInfo struct {
+	Name  string `json:"name"`
+	Moref string `json:"moref"`
+	UUID  string `json:"uuid"`
+}
+
+// CloneTarget describes where a linked clone is placed
+// Empty fields keep the defaults: the datacenter's root VM folder and the source VM's datastore
+type CloneTarget struct {
+	Folder    string `json:"folder,omitempty"`
+	Datastore string `json:"datastore,omitempty"`
+}
+
 // VMService provides VM discovery and management functionality
 type VMService struct {
 	client *Client
@@ -32,11 +75,25 @@ type VMFilter struct {
 	Offset      int    `json:"offset,omitempty"`
 }
 
+// page returns the bounds of the page selected by Offset and Limit in a list of count
+// name-sorted VMs; Total reports count, so clients can page through the rest
+func (f VMFilter) page(count int) (start, end int) {
+	start = min(max(f.Offset, 0), count)
+	end = count
+	if f.Limit > 0 {
+		end = min(start+f.Limit, count)
+	}
+	return start, end
+}
+
 // VMInfo represents basic information about a virtual machine
 type VMInfo struct {
-	UUID       string `json:"uuid"`
-	Name       string `json:"name"`
-	PowerState string `json:"power_state"`
+	UUID            string `json:"uuid"`
+	Moref           string `json:"moref"`
+	Name            string `json:"name"`
+	PowerState      string `json:"power_state"`
+	ConnectionState string `json:"connection_state"`
+	Degraded        bool   `json:"degraded"`
 }
 
 // VMDiskInfo represents virtual disk information
@@ -48,6 +105,10 @@ type VMDiskInfo struct {
 	ThinProvisioned  bool   `json:"thin_provisioned"`
 	DiskMode         string `json:"disk_mode"`
 	ControllerKey    int32  `json:"controller_key"`
+	ControllerBus    string `json:"controller_bus"`
+	ControllerType   string `json:"controller_type"`
+	BusNumber        int32  `json:"bus_number"`
+	UnitNumber       *int32 `json:"unit_number,omitempty"`
 }
 
 // VMNetworkAdapterInfo represents network adapter information
@@ -55,9 +116,14 @@ type VMNetworkAdapterInfo struct {
 	Label          string   `json:"label"`
 	NetworkName    string   `json:"network_name"`
 	MacAddress     string   `json:"mac_address"`
-	IPAddresses    []string `json:"ip_addresses"`
+	IPAddresses    []GuestIPAddress `json:"ip_addresses"`
 	Connected      bool     `json:"connected"`
 	AdapterType    string   `json:"adapter_type"`
+	// ToolsAvailable is false when VMware Tools isn't running, so guest IPs could not be reported
+	ToolsAvailable bool     `json:"tools_available"`
+	// Distributed switch backing; NetworkName is the portgroup name when it could be resolved
+	PortgroupKey   string   `json:"portgroup_key,omitempty"`
+	SwitchUUID     string   `json:"switch_uuid,omitempty"`
 }
 
 // VMSnapshotInfo represents snapshot information
@@ -68,6 +134,12 @@ type VMSnapshotInfo struct {
 	State       string    `json:"state"`
 	Quiesced    bool      `json:"quiesced"`
 	ID          int32     `json:"id"`
+	ParentID    int32     `json:"parent_id,omitempty"`
+	Depth       int       `json:"depth"`
+	IsCurrent   bool      `json:"is_current"`
+
+	// Children is only set in snapshot trees; flat lists leave it empty
+	Children []VMSnapshotInfo `json:"children,omitempty"`
 }
 
 // VMResourceAllocation represents resource allocation settings
@@ -86,6 +158,7 @@ type VMResourceAllocation struct {
 type VMDetailedInfo struct {
 	// Basic Info
 	UUID              string   `json:"uuid"`
+	Moref             string   `json:"moref"`
 	Name              string   `json:"name"`
 	PowerState        string   `json:"power_state"`
 	GuestFullName     string   `json:"guest_full_name"`
@@ -108,13 +181,15 @@ type VMDetailedInfo struct {
 	ToolsStatus        string   `json:"tools_status"`
 	ToolsVersion       string   `json:"tools_version"`
 	ToolsRunningStatus string   `json:"tools_running_status"`
-	IPAddresses        []string `json:"ip_addresses"`
+	ToolsVersionStatus string   `json:"tools_version_status"`
+	IPAddresses        []GuestIPAddress `json:"ip_addresses"`
 	Hostname           string   `json:"hostname"`
 	GuestState         string   `json:"guest_state"`
 
 	// Runtime Info
 	Host              string    `json:"host"`
 	ConnectionState   string    `json:"connection_state"`
+	Degraded          bool      `json:"degraded"`
 	BootTime          time.Time `json:"boot_time,omitempty"`
 	UptimeSeconds     int64     `json:"uptime_seconds"`
 	MaxCPUUsage       int32     `json:"max_cpu_usage_mhz"`
@@ -126,6 +201,7 @@ type VMDetailedInfo struct {
 	Datastores        []string     `json:"datastores"`
 	CommittedStorage  int64        `json:"committed_storage_bytes"`
 	UncommittedStorage int64       `json:"uncommitted_storage_bytes"`
+	StorageSource     string       `json:"storage_source"`
 
 	// Network
 	NetworkAdapters   []VMNetworkAdapterInfo `json:"network_adapters"`
@@ -140,6 +216,8 @@ type VMDetailedInfo struct {
 	// Snapshots
 	Snapshots         []VMSnapshotInfo `json:"snapshots"`
 	CurrentSnapshot   string           `json:"current_snapshot"`
+	CurrentSnapshotName        string  `json:"current_snapshot_name"`
+	CurrentSnapshotDescription string  `json:"current_snapshot_description"`
 
 	// Files
 	VMPathName        string   `json:"vm_path_name"`
@@ -165,6 +243,27 @@ type VMDetailedResult struct {
 	VM         VMDetailedInfo `json:"vm"`
 }
 
+// VMDiskListResult represents the virtual disks of a single VM
+type VMDiskListResult struct {
+	Datacenter string       `json:"datacenter"`
+	VMMoref    string       `json:"vm_moref"`
+	Disks      []VMDiskInfo `json:"disks"`
+}
+
+// VMSnapshotListResult represents the snapshots of a single VM
+type VMSnapshotListResult struct {
+	Datacenter      string           `json:"datacenter"`
+	VMMoref         string           `json:"vm_moref"`
+	Snapshots       []VMSnapshotInfo `json:"snapshots"`
+	CurrentSnapshot string           `json:"current_snapshot"`
+
+	// Tree holds the same snapshots as Snapshots, nested under their parents
+	Tree []VMSnapshotInfo `json:"tree"`
+
+	CurrentSnapshotName        string `json:"current_snapshot_name"`
+	CurrentSnapshotDescription string `json:"current_snapshot_description"`
+}
+
 // VMListResult represents the result of VM listing
 type VMListResult struct {
 	Datacenter string   `json:"datacenter"`
@@ -180,8 +279,31 @@ func NewVMService(client *Client, logger *logrus.Logger) *VMService {
 	}
 }
 
+// log returns a logger entry that carries the request ID from ctx, if any
+func (s *VMService) log(ctx context.Context) *logrus.Entry {
+	return s.logger.WithContext(ctx)
+}
+
+// standaloneDatacenter is the single datacenter exposed by a standalone ESXi host
+const standaloneDatacenter = "ha-datacenter"
+
 // getDefaultDatacenter is a helper to get the default datacenter
+// When connected to a standalone ESXi host, its implicit ha-datacenter is used
 func (s *VMService) getDefaultDatacenter(ctx context.Context, finder *find.Finder) (*object.Datacenter, error) {
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	if !client.IsVC() {
+		datacenter, err := finder.Datacenter(ctx, standaloneDatacenter)
+		if err != nil {
+			return nil, finderError(ErrDatacenterNotFound, "datacenter", standaloneDatacenter, err)
+		}
+		finder.SetDatacenter(datacenter)
+		return datacenter, nil
+	}
+
 	datacenter, err := finder.DefaultDatacenter(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("no default datacenter found: %w", err)
@@ -191,16 +313,23 @@ func (s *VMService) getDefaultDatacenter(ctx context.Context, finder *find.Finde
 }
 
 // GetDatacenterName returns the datacenter name for a given VM
-func (s *VMService) GetDatacenterName(ctx context.Context, vmName string) (string, error) {
-	_, datacenter, err := s.findVMByName(ctx, vmName)
+// If datacenterName is empty, the VM is looked up in the default datacenter
+func (s *VMService) GetDatacenterName(ctx context.Context, vmName string, datacenterName string) (string, error) {
+	_, datacenter, err := s.findVMInDatacenter(ctx, vmName, datacenterName)
 	if err != nil {
 		return "", err
 	}
 	return datacenter.Name(), nil
 }
 
-// findVMByName is a helper to find a VM by name
+// findVMByName is a helper to find a VM by name in the default datacenter
 func (s *VMService) findVMByName(ctx context.Context, name string) (*object.VirtualMachine, *object.Datacenter, error) {
+	return s.findVMInDatacenter(ctx, name, "")
+}
+
+// findVMInDatacenter is a helper to find a VM by name in a specific datacenter
+// If datacenterName is empty, the default datacenter is used
+func (s *VMService) findVMInDatacenter(ctx context.Context, name string, datacenterName string) (*object.VirtualMachine, *object.Datacenter, error) {
 	// Get govmomi client
 	client, err := s.client.GetClient(ctx)
 	if err != nil {
@@ -210,24 +339,45 @@ func (s *VMService) findVMByName(ctx context.Context, name string) (*object.Virt
 	// Create finder
 	finder := find.NewFinder(client.Client, true)
 
-	// Get default datacenter
-	datacenter, err := s.getDefaultDatacenter(ctx, finder)
+	if datacenterName == "" {
+		// Get default datacenter
+		datacenter, err := s.getDefaultDatacenter(ctx, finder)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Find VM by name
+		vm, err := finder.VirtualMachine(ctx, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("VM with name '%s' not found: %w", name, err)
+		}
+
+		return vm, datacenter, nil
+	}
+
+	// Scope the finder to the requested datacenter
+	datacenter, err := finder.Datacenter(ctx, datacenterName)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, finderError(ErrDatacenterNotFound, "datacenter", datacenterName, err)
 	}
+	finder.SetDatacenter(datacenter)
 
 	// Find VM by name
 	vm, err := finder.VirtualMachine(ctx, name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("VM with name '%s' not found: %w", name, err)
+		return nil, nil, fmt.Errorf("%w: VM '%s' in datacenter '%s': %w", ErrVMNotInDatacenter, name, datacenterName, err)
 	}
 
 	return vm, datacenter, nil
 }
 
-// GetVMByName retrieves a single VM by its name with full details
-func (s *VMService) GetVMByName(ctx context.Context, name string) (*VMDetailedResult, error) {
-	s.logger.WithField("name", name).Info("Getting VM by name")
+// GetVMByName retrieves a single VM by its name
+// Only the properties of the requested detail sections are retrieved and populated
+func (s *VMService) GetVMByName(ctx context.Context, name string, fields VMFields) (*VMDetailedResult, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"name":   name,
+		"fields": fields.Names(),
+	}).Info("Getting VM by name")
 
 	// Find VM by name
 	vm, datacenter, err := s.findVMByName(ctx, name)
@@ -241,80 +391,41 @@ func (s *VMService) GetVMByName(ctx context.Context, name string) (*VMDetailedRe
 		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
 	}
 
-	// Retrieve VM properties with comprehensive details
-	var vmProp mo.VirtualMachine
+	// Retrieve the VM properties of the requested sections
 	pc := property.DefaultCollector(client.Client)
-	err = pc.RetrieveOne(ctx, vm.Reference(), []string{
-		// Basic
-		"name",
-		"config.uuid",
-		"config.instanceUuid",
-		"config.guestFullName",
-		"config.guestId",
-		"config.annotation",
-		"config.template",
-
-		// Hardware
-		"config.hardware.numCPU",
-		"config.hardware.numCoresPerSocket",
-		"config.hardware.memoryMB",
-		"config.hardware.device",
-		"config.version",
-		"config.firmware",
-		"config.cpuHotAddEnabled",
-		"config.cpuHotRemoveEnabled",
-		"config.memoryHotAddEnabled",
-		"config.changeTrackingEnabled",
-
-		// Runtime
-		"runtime.powerState",
-		"runtime.host",
-		"runtime.connectionState",
-		"runtime.bootTime",
-		"runtime.maxCpuUsage",
-		"runtime.maxMemoryUsage",
-		"runtime.consolidationNeeded",
-		"runtime.faultToleranceState",
-
-		// Guest
-		"guest.toolsStatus",
-		"guest.toolsVersion",
-		"guest.toolsRunningStatus",
-		"guest.ipAddress",
-		"guest.hostName",
-		"guest.net",
-		"guest.guestState",
-		"guestHeartbeatStatus",
-
-		// Storage
-		"datastore",
-		"summary.storage",
-		"layoutEx.file",
-		"config.files.vmPathName",
-
-		// Network
-		"network",
-
-		// Resource allocation
-		"config.cpuAllocation",
-		"config.memoryAllocation",
-		"resourcePool",
-
-		// Snapshots
-		"snapshot",
-
-		// Location
-		"parent",
-	}, &vmProp)
-
+	vmProps, err := s.retrieveVMs(ctx, pc, []vimtypes.ManagedObjectReference{vm.Reference()}, fields.properties())
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
 	}
+	if len(vmProps) == 0 {
+		return nil, fmt.Errorf("VM '%s' not found", name)
+	}
+	vmProp := vmProps[0]
+
+	// Orphaned and inaccessible VMs only report a subset of their properties
+	if isDegraded(vmProp) {
+		s.log(ctx).WithField("connection_state", vmProp.Runtime.ConnectionState).Warn("VM is not connected, returning its basic properties only")
+
+		return &VMDetailedResult{
+			Datacenter: datacenter.Name(),
+			VM: VMDetailedInfo{
+				UUID:            s.vmIdentifier(vmProp),
+				Moref:           vmProp.Self.Value,
+				Name:            vmProp.Name,
+				PowerState:      string(vmProp.Runtime.PowerState),
+				ConnectionState: string(vmProp.Runtime.ConnectionState),
+				Degraded:        true,
+			},
+		}, nil
+	}
 
 	// Convert to VMDetailedInfo
-	vmInfo := s.convertToVMDetailedInfo(vmProp)
+	vmInfo := s.convertToVMDetailedInfo(vmProp, fields)
+	if fields.Has(VMFieldNetwork) {
+		s.resolvePortgroupNames(ctx, pc, vmProp.Network, vmInfo.NetworkAdapters)
+	}
 
-	s.logger.Info("VM retrieval completed")
+	s.log(ctx).Info("VM retrieval completed")
 
 	return &VMDetailedResult{
 		Datacenter: datacenter.Name(),
@@ -324,7 +435,7 @@ func (s *VMService) GetVMByName(ctx context.Context, name string) (*VMDetailedRe
 
 // GetVMByUUID retrieves a single VM by its UUID
 func (s *VMService) GetVMByUUID(ctx context.Context, uuid string) (*VMResult, error) {
-	s.logger.WithField("uuid", uuid).Info("Getting VM by UUID")
+	s.log(ctx).WithField("uuid", uuid).Info("Getting VM by UUID")
 
 	// Get govmomi client
 	client, err := s.client.GetClient(ctx)
@@ -336,11 +447,10 @@ func (s *VMService) GetVMByUUID(ctx context.Context, uuid string) (*VMResult, er
 	finder := find.NewFinder(client.Client, true)
 
 	// Get default datacenter
-	datacenter, err := finder.DefaultDatacenter(ctx)
+	datacenter, err := s.getDefaultDatacenter(ctx, finder)
 	if err != nil {
-		return nil, fmt.Errorf("no default datacenter found: %w", err)
+		return nil, err
 	}
-	finder.SetDatacenter(datacenter)
 
 	// Use SearchIndex to find VM by UUID (fastest method)
 	searchIndex := object.NewSearchIndex(client.Client)
@@ -353,22 +463,24 @@ func (s *VMService) GetVMByUUID(ctx context.Context, uuid string) (*VMResult, er
 	}
 
 	// Retrieve VM properties
-	var vmProp mo.VirtualMachine
 	pc := property.DefaultCollector(client.Client)
-	err = pc.RetrieveOne(ctx, vmRef.Reference(), []string{
+	vmProps, err := s.retrieveVMs(ctx, pc, []vimtypes.ManagedObjectReference{vmRef.Reference()}, []string{
 		"name",
 		"config.uuid",
 		"runtime.powerState",
-	}, &vmProp)
-
+		"runtime.connectionState",
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
 	}
+	if len(vmProps) == 0 {
+		return nil, fmt.Errorf("VM with UUID '%s' not found", uuid)
+	}
 
 	// Convert to VMInfo
-	vmInfo := s.convertToVMInfo(vmProp)
+	vmInfo := s.convertToVMInfo(vmProps[0])
 
-	s.logger.Info("VM retrieval completed")
+	s.log(ctx).Info("VM retrieval completed")
 
 	return &VMResult{
 		Datacenter: datacenter.Name(),
@@ -378,7 +490,7 @@ func (s *VMService) GetVMByUUID(ctx context.Context, uuid string) (*VMResult, er
 
 // ListVMs retrieves all virtual machines with optional filtering
 func (s *VMService) ListVMs(ctx context.Context, filter VMFilter) (*VMListResult, error) {
-	s.logger.WithFields(logrus.Fields{
+	s.log(ctx).WithFields(logrus.Fields{
 		"filter": filter,
 	}).Info("Starting VM discovery")
 
@@ -388,24 +500,90 @@ func (s *VMService) ListVMs(ctx context.Context, filter VMFilter) (*VMListResult
 		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
 	}
 
+	datacenter, vmRefs, err := s.findFilteredVMs(ctx, client.Client, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vmRefs) == 0 {
+		return &VMListResult{
+			Datacenter: datacenter.Name(),
+			VMs:        []VMInfo{},
+			Total:      0,
+		}, nil
+	}
+
+	// Define properties to retrieve for all VMs
+	pc := property.DefaultCollector(client.Client)
+
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.uuid",
+		"runtime.powerState",
+		"runtime.connectionState",
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	// Convert all VMs and apply filters
+	var vmInfos []VMInfo
+	for _, vmProp := range vmProperties {
+		vmInfo := s.convertToVMInfo(vmProp)
+
+		// Apply name filter (contains)
+		if filter.Name != "" && !strings.Contains(strings.ToLower(vmInfo.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+
+		// Apply power state filter
+		if filter.PowerState != "" && vmInfo.PowerState != filter.PowerState {
+			continue
+		}
+
+		vmInfos = append(vmInfos, *vmInfo)
+	}
+
+	// Sort by name for deterministic ordering before pagination
+	sort.Slice(vmInfos, func(i, j int) bool {
+		return vmInfos[i].Name < vmInfos[j].Name
+	})
+	total := len(vmInfos)
+
+	start, end := filter.page(total)
+	vmInfos = vmInfos[start:end]
+
+	s.log(ctx).WithField("total_vms", total).Info("VM discovery completed")
+
+	return &VMListResult{
+		Datacenter: datacenter.Name(),
+		VMs:        vmInfos,
+		Total:      total,
+	}, nil
+}
+
+// findFilteredVMs finds the VMs in the datacenter and cluster of a filter
+// If filter.Datacenter is empty, the default datacenter is used
+func (s *VMService) findFilteredVMs(ctx context.Context, c *vim25.Client, filter VMFilter) (*object.Datacenter, []vimtypes.ManagedObjectReference, error) {
 	// Create finder for object discovery
-	finder := find.NewFinder(client.Client, true)
+	finder := find.NewFinder(c, true)
 
 	// Set datacenter if specified in filter
 	var datacenter *object.Datacenter
+	var err error
 	if filter.Datacenter != "" {
 		datacenter, err = finder.Datacenter(ctx, filter.Datacenter)
 		if err != nil {
-			return nil, fmt.Errorf("datacenter '%s' not found: %w", filter.Datacenter, err)
+			return nil, nil, finderError(ErrDatacenterNotFound, "datacenter", filter.Datacenter, err)
 		}
 		finder.SetDatacenter(datacenter)
 	} else {
-		// If no datacenter specified, use default (first one found)
-		datacenter, err = finder.DefaultDatacenter(ctx)
+		// If no datacenter specified, use default (first one found, or ha-datacenter on ESXi)
+		datacenter, err = s.getDefaultDatacenter(ctx, finder)
 		if err != nil {
-			return nil, fmt.Errorf("no default datacenter found: %w", err)
+			return nil, nil, err
 		}
-		finder.SetDatacenter(datacenter)
 	}
 
 	// Find all VMs or filter by cluster
@@ -414,22 +592,22 @@ func (s *VMService) ListVMs(ctx context.Context, filter VMFilter) (*VMListResult
 		// Find VMs in specific cluster
 		cluster, err := finder.ClusterComputeResource(ctx, filter.Cluster)
 		if err != nil {
-			return nil, fmt.Errorf("cluster '%s' not found: %w", filter.Cluster, err)
+			return nil, nil, finderError(ErrClusterNotFound, "cluster", filter.Cluster, err)
 		}
 
 		vms, err = finder.VirtualMachineList(ctx, cluster.InventoryPath+"/*")
 		if err != nil {
-			return nil, fmt.Errorf("failed to list VMs in cluster '%s': %w", filter.Cluster, err)
+			return nil, nil, fmt.Errorf("failed to list VMs in cluster '%s': %w", filter.Cluster, err)
 		}
 	} else {
 		// Find all VMs in datacenter
 		vms, err = finder.VirtualMachineList(ctx, "*")
 		if err != nil {
-			return nil, fmt.Errorf("failed to list VMs: %w", err)
+			return nil, nil, fmt.Errorf("failed to list VMs: %w", err)
 		}
 	}
 
-	s.logger.WithField("vm_count", len(vms)).Info("Found VMs in vSphere")
+	s.log(ctx).WithField("vm_count", len(vms)).Info("Found VMs in vSphere")
 
 	// Collect VM managed object references
 	var vmRefs []vimtypes.ManagedObjectReference
@@ -437,68 +615,104 @@ func (s *VMService) ListVMs(ctx context.Context, filter VMFilter) (*VMListResult
 		vmRefs = append(vmRefs, vm.Reference())
 	}
 
-	if len(vmRefs) == 0 {
-		return &VMListResult{
-			Datacenter: datacenter.Name(),
-			VMs:        []VMInfo{},
-			Total:      0,
-		}, nil
-	}
+	return datacenter, vmRefs, nil
+}
 
-	// Define properties to retrieve for all VMs
-	var vmProperties []mo.VirtualMachine
-	pc := property.DefaultCollector(client.Client)
+// retrieveVMProperties retrieves the given properties for a list of VMs
+// When a property batch size is configured and the inventory is larger than one batch,
+// the references are split into batches fetched concurrently by a bounded worker pool
+func (s *VMService) retrieveVMProperties(ctx context.Context, pc *property.Collector, vmRefs []vimtypes.ManagedObjectReference, props []string) ([]mo.VirtualMachine, error) {
+	cfg := s.client.GetConfig()
+	batchSize := cfg.PropertyBatchSize
 
-	err = pc.Retrieve(ctx, vmRefs, []string{
-		"name",
-		"config.uuid",
-		"runtime.powerState",
-	}, &vmProperties)
+	// Single retrieve when batching is disabled or not needed
+	if batchSize <= 0 || len(vmRefs) <= batchSize {
+		return s.retrieveVMs(ctx, pc, vmRefs, props)
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	workers := cfg.PropertyBatchWorkers
+	if workers <= 0 {
+		workers = 1
 	}
 
-	// Convert all VMs and apply filters
-	var vmInfos []VMInfo
-	for _, vmProp := range vmProperties {
-		vmInfo := s.convertToVMInfo(vmProp)
+	// Split references into batches
+	var batches [][]vimtypes.ManagedObjectReference
+	for start := 0; start < len(vmRefs); start += batchSize {
+		end := min(start+batchSize, len(vmRefs))
+		batches = append(batches, vmRefs[start:end])
+	}
 
-		// Apply name filter (contains)
-		if filter.Name != "" && !strings.Contains(strings.ToLower(vmInfo.Name), strings.ToLower(filter.Name)) {
-			continue
-		}
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_count":   len(vmRefs),
+		"batch_size": batchSize,
+		"batches":    len(batches),
+		"workers":    workers,
+	}).Debug("Retrieving VM properties in batches")
 
-		// Apply power state filter
-		if filter.PowerState != "" && vmInfo.PowerState != filter.PowerState {
-			continue
-		}
+	// Fetch batches concurrently with a bounded number of workers
+	results := make([][]mo.VirtualMachine, len(batches))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
 
-		vmInfos = append(vmInfos, *vmInfo)
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch []vimtypes.ManagedObjectReference) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = s.retrieveVMs(ctx, pc, batch, props)
+		}(i, batch)
 	}
+	wg.Wait()
 
-	s.logger.WithField("total_vms", len(vmInfos)).Info("VM discovery completed")
+	// Merge results, failing on the first batch error
+	vmProperties := make([]mo.VirtualMachine, 0, len(vmRefs))
+	for i := range batches {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("batch %d of %d failed: %w", i+1, len(batches), errs[i])
+		}
+		vmProperties = append(vmProperties, results[i]...)
+	}
 
-	return &VMListResult{
-		Datacenter: datacenter.Name(),
-		VMs:        vmInfos,
-		Total:      len(vmInfos),
-	}, nil
+	return vmProperties, nil
 }
 
 // convertToVMInfo converts a vSphere VM managed object to VMInfo
 func (s *VMService) convertToVMInfo(vm mo.VirtualMachine) *VMInfo {
 	return &VMInfo{
-		UUID:       vm.Config.Uuid,
-		Name:       vm.Name,
-		PowerState: string(vm.Runtime.PowerState),
+		UUID:            s.vmIdentifier(vm),
+		Moref:           vm.Self.Value,
+		Name:            vm.Name,
+		PowerState:      string(vm.Runtime.PowerState),
+		ConnectionState: string(vm.Runtime.ConnectionState),
+		Degraded:        isDegraded(vm),
+	}
+}
+
+// vmIdentifier returns the VM BIOS UUID, falling back to the managed object reference
+// when the config is unavailable (e.g. disconnected, inaccessible or orphaned VMs)
+func (s *VMService) vmIdentifier(vm mo.VirtualMachine) string {
+	if vm.Config != nil && vm.Config.Uuid != "" {
+		return vm.Config.Uuid
 	}
+
+	s.logger.WithFields(logrus.Fields{
+		"vm_name":          vm.Name,
+		"moref":            vm.Self.Value,
+		"connection_state": vm.Runtime.ConnectionState,
+	}).Debug("VM config unavailable, using managed object reference as identifier")
+
+	return vm.Self.Value
 }
 
 // convertToVMDetailedInfo converts a vSphere VM managed object to VMDetailedInfo
-func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInfo {
+// Disks and network adapters share the device list, so they are only extracted for their own sections
+func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine, fields VMFields) *VMDetailedInfo {
 	info := &VMDetailedInfo{
-		UUID:       vm.Config.Uuid,
+		UUID:       s.vmIdentifier(vm),
+		Moref:      vm.Self.Value,
 		Name:       vm.Name,
 		PowerState: string(vm.Runtime.PowerState),
 	}
@@ -536,7 +750,9 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 
 		// Resource allocation
 		if vm.Config.CpuAllocation != nil {
-			info.ResourceAllocation.CPUReservation = *vm.Config.CpuAllocation.Reservation
+			if vm.Config.CpuAllocation.Reservation != nil {
+				info.ResourceAllocation.CPUReservation = *vm.Config.CpuAllocation.Reservation
+			}
 			if vm.Config.CpuAllocation.Limit != nil && *vm.Config.CpuAllocation.Limit != -1 {
 				info.ResourceAllocation.CPULimit = *vm.Config.CpuAllocation.Limit
 			}
@@ -546,7 +762,9 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 			}
 		}
 		if vm.Config.MemoryAllocation != nil {
-			info.ResourceAllocation.MemoryReservation = *vm.Config.MemoryAllocation.Reservation
+			if vm.Config.MemoryAllocation.Reservation != nil {
+				info.ResourceAllocation.MemoryReservation = *vm.Config.MemoryAllocation.Reservation
+			}
 			if vm.Config.MemoryAllocation.Limit != nil && *vm.Config.MemoryAllocation.Limit != -1 {
 				info.ResourceAllocation.MemoryLimit = *vm.Config.MemoryAllocation.Limit
 			}
@@ -557,10 +775,14 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 		}
 
 		// Extract disk information
-		info.Disks = s.extractDiskInfo(vm.Config.Hardware.Device)
+		if fields.Has(VMFieldStorage) {
+			info.Disks = s.extractDiskInfo(vm.Config.Hardware.Device)
+		}
 
 		// Extract network adapter information
-		info.NetworkAdapters = s.extractNetworkAdapters(vm.Config.Hardware.Device, vm.Guest)
+		if fields.Has(VMFieldNetwork) {
+			info.NetworkAdapters = s.extractNetworkAdapters(vm.Config.Hardware.Device, vm.Guest)
+		}
 	}
 
 	// Runtime properties
@@ -588,44 +810,22 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 		info.ToolsStatus = string(vm.Guest.ToolsStatus)
 		info.ToolsVersion = vm.Guest.ToolsVersion
 		info.ToolsRunningStatus = vm.Guest.ToolsRunningStatus
+		info.ToolsVersionStatus = vm.Guest.ToolsVersionStatus2
 		info.Hostname = vm.Guest.HostName
 		info.GuestState = vm.Guest.GuestState
 
-		// Collect all IP addresses from guest NICs
-		var ipAddresses []string
-		if vm.Guest.IpAddress != "" {
-			ipAddresses = append(ipAddresses, vm.Guest.IpAddress)
-		}
-		for _, nic := range vm.Guest.Net {
-			if nic.IpConfig != nil {
-				for _, ipConfig := range nic.IpConfig.IpAddress {
-					ip := ipConfig.IpAddress
-					// Skip if already in list
-					found := false
-					for _, existing := range ipAddresses {
-						if existing == ip {
-							found = true
-							break
-						}
-					}
-					if !found && ip != "" {
-						ipAddresses = append(ipAddresses, ip)
-					}
-				}
-			}
+		// Collect and classify all IP addresses from guest NICs
+		if fields.Has(VMFieldNetwork) {
+			info.IPAddresses = collectGuestIPs(vm.Guest)
 		}
-		info.IPAddresses = ipAddresses
 	}
 
 	// Guest heartbeat status
 	info.GuestHeartbeatStatus = string(vm.GuestHeartbeatStatus)
 
-	// Storage information from summary
-	if vm.Summary.Storage.Committed > 0 {
-		info.CommittedStorage = vm.Summary.Storage.Committed
-	}
-	if vm.Summary.Storage.Uncommitted > 0 {
-		info.UncommittedStorage = vm.Summary.Storage.Uncommitted
+	// Storage usage
+	if fields.Has(VMFieldStorage) {
+		info.CommittedStorage, info.UncommittedStorage, info.StorageSource = storageUsage(vm)
 	}
 
 	// Datastores
@@ -637,9 +837,10 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 
 	// Snapshot information
 	if vm.Snapshot != nil {
-		info.Snapshots = s.extractSnapshotInfo(vm.Snapshot.RootSnapshotList)
+		info.Snapshots = s.extractSnapshotInfo(vm.Snapshot.RootSnapshotList, vm.Snapshot.CurrentSnapshot)
 		if vm.Snapshot.CurrentSnapshot != nil {
 			info.CurrentSnapshot = vm.Snapshot.CurrentSnapshot.Value
+			info.CurrentSnapshotName, info.CurrentSnapshotDescription, _ = resolveSnapshotName(vm.Snapshot.RootSnapshotList, *vm.Snapshot.CurrentSnapshot)
 		}
 	}
 
@@ -671,16 +872,60 @@ func (s *VMService) convertToVMDetailedInfo(vm mo.VirtualMachine) *VMDetailedInf
 	return info
 }
 
+// Sources of the committed and uncommitted storage of a VM
+const (
+	StorageSourceSummary     = "summary"
+	StorageSourceLayout      = "layout"
+	StorageSourceUnavailable = "unavailable"
+)
+
+// storageUsage returns the committed and uncommitted storage of a VM and where it came from
+// summary.storage is not reported for every VM state (e.g. inaccessible or orphaned VMs); the
+// committed storage is then the total size of the VM files in layoutEx, and the uncommitted
+// storage is the provisioned disk capacity not yet backed by disk files
+func storageUsage(vm mo.VirtualMachine) (int64, int64, string) {
+	if vm.Summary.Storage != nil {
+		return vm.Summary.Storage.Committed, vm.Summary.Storage.Uncommitted, StorageSourceSummary
+	}
+	if vm.LayoutEx == nil || len(vm.LayoutEx.File) == 0 {
+		return 0, 0, StorageSourceUnavailable
+	}
+
+	var committed, diskFiles int64
+	for _, file := range vm.LayoutEx.File {
+		committed += file.Size
+		if file.Type == string(vimtypes.VirtualMachineFileLayoutExFileTypeDiskExtent) ||
+			file.Type == string(vimtypes.VirtualMachineFileLayoutExFileTypeDiskDescriptor) {
+			diskFiles += file.Size
+		}
+	}
+
+	var provisioned int64
+	if vm.Config != nil {
+		for _, device := range vm.Config.Hardware.Device {
+			if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+				provisioned += disk.CapacityInBytes
+			}
+		}
+	}
+
+	return committed, max(provisioned-diskFiles, 0), StorageSourceLayout
+}
+
 // GetSnapshotDiskInfo gets the VM moref, snapshot moref and disk path for a VM snapshot
 // This is used by the inspection system to access snapshot disks via VDDK
-func (s *VMService) GetSnapshotDiskInfo(ctx context.Context, vmName string, snapshotName string) (*types.SnapshotDiskInfo, error) {
-	s.logger.WithFields(logrus.Fields{
-		"vm_name":       vmName,
+// A non-zero snapshotID selects the snapshot by ID, disambiguating snapshots that share a name
+// If datacenterName is empty, the VM is looked up in the default datacenter
+func (s *VMService) GetSnapshotDiskInfo(ctx context.Context, vmRef VMRef, snapshotName string, snapshotID int32, datacenterName string) (*types.SnapshotDiskInfo, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm":            vmRef.String(),
 		"snapshot_name": snapshotName,
+		"snapshot_id":   snapshotID,
+		"datacenter":    datacenterName,
 	}).Debug("Getting snapshot disk info for inspection")
 
-	// Find VM by name
-	vm, _, err := s.findVMByName(ctx, vmName)
+	// Find the VM
+	vm, _, err := s.findVM(ctx, vmRef, datacenterName)
 	if err != nil {
 		return nil, err
 	}
@@ -696,18 +941,23 @@ func (s *VMService) GetSnapshotDiskInfo(ctx context.Context, vmName string, snap
 
 	var vmMo mo.VirtualMachine
 	pc := property.DefaultCollector(client.Client)
-	err = pc.RetrieveOne(ctx, vm.Reference(), []string{"snapshot", "config.hardware.device", "runtime.host"}, &vmMo)
+	err = pc.RetrieveOne(ctx, vm.Reference(), []string{"snapshot", "config.hardware.device", "config.keyId", "runtime.host"}, &vmMo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VM properties: %w", err)
 	}
 
+	// VDDK cannot read encrypted disks without the encryption key
+	if isEncrypted(vmMo.Config) {
+		return nil, fmt.Errorf("%w: VM %s uses vSphere VM Encryption and its disks cannot be read by the inspector", ErrVMEncrypted, vmRef)
+	}
+
 	// Check if VM has snapshots
 	if vmMo.Snapshot == nil {
-		return nil, fmt.Errorf("VM '%s' has no snapshots", vmName)
+		return nil, fmt.Errorf("VM %s has no snapshots", vmRef)
 	}
 
-	// Find the snapshot by name
-	snapshotRef, err := s.findSnapshotInTree(vmMo.Snapshot.RootSnapshotList, snapshotName)
+	// Find the snapshot by ID, or by name
+	snapshotRef, err := s.findSnapshot(ctx, vmMo.Snapshot.RootSnapshotList, snapshotName, snapshotID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find snapshot '%s': %w", snapshotName, err)
 	}
@@ -716,84 +966,23 @@ func (s *VMService) GetSnapshotDiskInfo(ctx context.Context, vmName string, snap
 	snapshotMoref := snapshotRef.Snapshot.Value
 
 	// Get disk paths from ALL virtual disks (not just the first one)
-	// Use ParentFile (backing.Parent.FileName) if available
-	// This is the base/parent disk file that the snapshot was created from
-	var diskPaths []string
-	var baseDiskPaths []string
-
-	for _, device := range vmMo.Config.Hardware.Device {
-		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
-			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok {
-				diskPath := backing.FileName
-				diskPaths = append(diskPaths, diskPath)
-
-				// Check if backing has a Parent
-				// Parent points to the base disk file that the snapshot was created from
-				var baseDiskPath string
-				if backing.Parent != nil && backing.Parent.FileName != "" {
-					baseDiskPath = backing.Parent.FileName
-					s.logger.WithFields(logrus.Fields{
-						"disk_path":   diskPath,
-						"parent_file": baseDiskPath,
-					}).Debug("Found parent file from disk backing")
-				} else {
-					// Fallback: calculate base disk path (remove delta disk suffix like -000002)
-					baseDiskPath = s.getBaseDiskPath(diskPath)
-					s.logger.WithFields(logrus.Fields{
-						"disk_path":      diskPath,
-						"calculated_base": baseDiskPath,
-					}).Debug("Calculated base disk path (no parent in backing)")
-				}
-				baseDiskPaths = append(baseDiskPaths, baseDiskPath)
-			}
-		}
-	}
+	diskPaths, baseDiskPaths := s.collectDiskPaths(vmMo.Config.Hardware.Device)
 
 	if len(diskPaths) == 0 {
-		return nil, fmt.Errorf("no disks found for VM '%s'", vmName)
+		return nil, fmt.Errorf("no disks found for VM %s", vmRef)
 	}
 
 	if len(baseDiskPaths) == 0 {
-		return nil, fmt.Errorf("no base disk paths found for VM '%s'", vmName)
+		return nil, fmt.Errorf("no base disk paths found for VM %s", vmRef)
 	}
 
 	// Get compute resource path (host/cluster) for vpx:// URL
-	var computeResourcePath string
-	if vmMo.Runtime.Host != nil {
-		finder := find.NewFinder(client.Client, true)
-		host, err := finder.ObjectReference(ctx, *vmMo.Runtime.Host)
-		if err == nil {
-			if hostObj, ok := host.(*object.HostSystem); ok {
-				// Get the host's inventory path
-				computeResourcePath = hostObj.InventoryPath
-				s.logger.WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from host")
-			}
-		}
-		// If we couldn't get the host path, try to get it from the host's parent (cluster)
-		if computeResourcePath == "" && vmMo.Runtime.Host != nil {
-			// Try to get cluster path by finding the host's parent
-			var hostMo mo.HostSystem
-			err = pc.RetrieveOne(ctx, *vmMo.Runtime.Host, []string{"parent"}, &hostMo)
-			if err == nil && hostMo.Parent != nil {
-				parentObj, err := finder.ObjectReference(ctx, *hostMo.Parent)
-				if err == nil {
-					if clusterObj, ok := parentObj.(*object.ClusterComputeResource); ok {
-						computeResourcePath = clusterObj.InventoryPath
-						s.logger.WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from cluster")
-					} else if computeResourceObj, ok := parentObj.(*object.ComputeResource); ok {
-						computeResourcePath = computeResourceObj.InventoryPath
-						s.logger.WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from compute resource")
-					}
-				}
-			}
-		}
-	}
-
+	computeResourcePath := s.getComputeResourcePath(ctx, client.Client, pc, vmMo.Runtime.Host)
 	if computeResourcePath == "" {
-		return nil, fmt.Errorf("failed to get compute resource path for VM '%s'", vmName)
+		return nil, fmt.Errorf("failed to get compute resource path for VM %s", vmRef)
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.log(ctx).WithFields(logrus.Fields{
 		"vm_moref":             vmMoref,
 		"snapshot_moref":       snapshotMoref,
 		"disk_count":           len(diskPaths),
@@ -811,71 +1000,277 @@ func (s *VMService) GetSnapshotDiskInfo(ctx context.Context, vmName string, snap
 	}, nil
 }
 
-// findSnapshotInTree recursively searches for a snapshot by name in the snapshot tree
-func (s *VMService) findSnapshotInTree(snapshots []vimtypes.VirtualMachineSnapshotTree, name string) (*vimtypes.VirtualMachineSnapshotTree, error) {
-	for idx := range snapshots {
-		if snapshots[idx].Name == name {
-			return &snapshots[idx], nil
-		}
-		// Search in child snapshots
-		if len(snapshots[idx].ChildSnapshotList) > 0 {
-			result, err := s.findSnapshotInTree(snapshots[idx].ChildSnapshotList, name)
-			if err == nil {
-				return result, nil
-			}
-		}
+// GetCurrentDiskInfo gets the VM moref and current disk paths of a powered-off VM
+// This is used to inspect a VM's current disks via VDDK without a snapshot; the returned
+// SnapshotDiskInfo has an empty SnapshotMoref, and BaseDiskPaths are the current backing
+// files rather than their snapshot parents. Powered-on VMs return ErrVMPoweredOn since
+// their disks are in use and can only be read consistently through a snapshot.
+// Suspended VMs are accepted only when allowSuspended is set, e.g. by a live inspection
+// that suspended the VM itself.
+// If datacenterName is empty, the VM is looked up in the default datacenter
+func (s *VMService) GetCurrentDiskInfo(ctx context.Context, vmRef VMRef, datacenterName string, allowSuspended bool) (*types.SnapshotDiskInfo, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm":         vmRef.String(),
+		"datacenter": datacenterName,
+	}).Debug("Getting current disk info for inspection")
+
+	// Find the VM
+	vm, _, err := s.findVM(ctx, vmRef, datacenterName)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("snapshot '%s' not found", name)
-}
 
-// getBaseDiskPath removes the -XXXXXX delta disk suffix to get the base VMDK path
-// Example: "[datastore] vm/vm-000002.vmdk" -> "[datastore] vm/vm.vmdk"
-func (s *VMService) getBaseDiskPath(diskPath string) string {
-	// Find the last occurrence of .vmdk
-	vmdkIndex := len(diskPath) - len(".vmdk")
-	if vmdkIndex < 0 || diskPath[vmdkIndex:] != ".vmdk" {
-		// Not a .vmdk file, return as-is
-		return diskPath
-	}
-
-	// Find the part before .vmdk
-	prefix := diskPath[:vmdkIndex]
-
-	// Look for -XXXXXX pattern (6 digits) before .vmdk
-	// Example: "vm-000002" -> "vm"
-	if len(prefix) >= 7 && prefix[len(prefix)-7] == '-' {
-		// Check if last 6 characters are digits
-		isAllDigits := true
-		for i := len(prefix) - 6; i < len(prefix); i++ {
-			if prefix[i] < '0' || prefix[i] > '9' {
-				isAllDigits = false
-				break
-			}
-		}
-		if isAllDigits {
-			// Remove -XXXXXX suffix
-			return prefix[:len(prefix)-7] + ".vmdk"
-		}
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
 	}
 
-	// No delta disk suffix found, return original path
-	return diskPath
-}
+	var vmMo mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	err = pc.RetrieveOne(ctx, vm.Reference(), []string{"config.hardware.device", "config.keyId", "runtime.host", "runtime.powerState"}, &vmMo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM properties: %w", err)
+	}
 
-// extractDiskInfo extracts disk information from hardware devices
+	switch vmMo.Runtime.PowerState {
+	case vimtypes.VirtualMachinePowerStatePoweredOff:
+	case vimtypes.VirtualMachinePowerStateSuspended:
+		if !allowSuspended {
+			return nil, fmt.Errorf("%w: VM %s is %s, create a snapshot and inspect it instead", ErrVMPoweredOn, vmRef, vmMo.Runtime.PowerState)
+		}
+	default:
+		return nil, fmt.Errorf("%w: VM %s is %s, create a snapshot and inspect it instead", ErrVMPoweredOn, vmRef, vmMo.Runtime.PowerState)
+	}
+
+	if vmMo.Config == nil {
+		return nil, fmt.Errorf("VM %s configuration is not available", vmRef)
+	}
+
+	if isEncrypted(vmMo.Config) {
+		return nil, fmt.Errorf("%w: VM %s uses vSphere VM Encryption and its disks cannot be read by the inspector", ErrVMEncrypted, vmRef)
+	}
+
+	// The current state is in the current backing files; their parents, when the VM has
+	// snapshots, hold the disk contents as of the snapshot and would be stale
+	diskPaths, _ := s.collectDiskPaths(vmMo.Config.Hardware.Device)
+	if len(diskPaths) == 0 {
+		return nil, fmt.Errorf("no disks found for VM %s", vmRef)
+	}
+
+	computeResourcePath := s.getComputeResourcePath(ctx, client.Client, pc, vmMo.Runtime.Host)
+	if computeResourcePath == "" {
+		return nil, fmt.Errorf("failed to get compute resource path for VM %s", vmRef)
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_moref":              vm.Reference().Value,
+		"disk_count":            len(diskPaths),
+		"disk_paths":            diskPaths,
+		"compute_resource_path": computeResourcePath,
+	}).Debug("Got current disk info")
+
+	return &types.SnapshotDiskInfo{
+		VMMoref:             vm.Reference().Value,
+		DiskPaths:           diskPaths,
+		BaseDiskPaths:       diskPaths,
+		ComputeResourcePath: computeResourcePath,
+	}, nil
+}
+
+// isEncrypted reports whether the VM home or any of its virtual disks is encrypted
+func isEncrypted(config *vimtypes.VirtualMachineConfigInfo) bool {
+	if config == nil {
+		return false
+	}
+	if config.KeyId != nil {
+		return true
+	}
+	for _, device := range config.Hardware.Device {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok && backing.KeyId != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectDiskPaths returns the backing file and base disk file of every virtual disk
+// The base disk is taken from backing.Parent when available, otherwise it is derived
+// from the delta disk file name
+func (s *VMService) collectDiskPaths(devices []vimtypes.BaseVirtualDevice) (diskPaths []string, baseDiskPaths []string) {
+	for _, device := range devices {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok {
+				diskPath := backing.FileName
+				diskPaths = append(diskPaths, diskPath)
+
+				// Check if backing has a Parent
+				// Parent points to the base disk file that the snapshot was created from
+				var baseDiskPath string
+				if backing.Parent != nil && backing.Parent.FileName != "" {
+					baseDiskPath = backing.Parent.FileName
+					s.logger.WithFields(logrus.Fields{
+						"disk_path":   diskPath,
+						"parent_file": baseDiskPath,
+					}).Debug("Found parent file from disk backing")
+				} else {
+					// Fallback: calculate base disk path (remove delta disk suffix like -000002)
+					baseDiskPath = getBaseDiskPath(diskPath)
+					s.logger.WithFields(logrus.Fields{
+						"disk_path":       diskPath,
+						"calculated_base": baseDiskPath,
+					}).Debug("Calculated base disk path (no parent in backing)")
+				}
+				baseDiskPaths = append(baseDiskPaths, baseDiskPath)
+			}
+		}
+	}
+
+	return diskPaths, baseDiskPaths
+}
+
+// getComputeResourcePath returns the inventory path of the VM's host, falling back to
+// the host's parent cluster or compute resource. Returns an empty string if it cannot be resolved
+func (s *VMService) getComputeResourcePath(ctx context.Context, client *vim25.Client, pc *property.Collector, hostRef *vimtypes.ManagedObjectReference) string {
+	if hostRef == nil {
+		return ""
+	}
+
+	var computeResourcePath string
+	finder := find.NewFinder(client, true)
+	host, err := finder.ObjectReference(ctx, *hostRef)
+	if err == nil {
+		if hostObj, ok := host.(*object.HostSystem); ok {
+			// Get the host's inventory path
+			computeResourcePath = hostObj.InventoryPath
+			s.log(ctx).WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from host")
+		}
+	}
+
+	// If we couldn't get the host path, try to get it from the host's parent (cluster)
+	if computeResourcePath == "" {
+		var hostMo mo.HostSystem
+		err = pc.RetrieveOne(ctx, *hostRef, []string{"parent"}, &hostMo)
+		if err == nil && hostMo.Parent != nil {
+			parentObj, err := finder.ObjectReference(ctx, *hostMo.Parent)
+			if err == nil {
+				if clusterObj, ok := parentObj.(*object.ClusterComputeResource); ok {
+					computeResourcePath = clusterObj.InventoryPath
+					s.log(ctx).WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from cluster")
+				} else if computeResourceObj, ok := parentObj.(*object.ComputeResource); ok {
+					computeResourcePath = computeResourceObj.InventoryPath
+					s.log(ctx).WithField("compute_resource_path", computeResourcePath).Debug("Got compute resource path from compute resource")
+				}
+			}
+		}
+	}
+
+	return computeResourcePath
+}
+
+// findSnapshot finds a snapshot in the snapshot tree
+// A non-zero id selects the snapshot with that ID, whose name must also match when name is
+// set; otherwise the snapshot named name is returned. Snapshot names are not unique, so a
+// name shared by several snapshots fails with ErrSnapshotAmbiguous rather than guessing one
+func (s *VMService) findSnapshot(ctx context.Context, snapshots []vimtypes.VirtualMachineSnapshotTree, name string, id int32) (*vimtypes.VirtualMachineSnapshotTree, error) {
+	if id != 0 {
+		snapshot := findSnapshotInTree(snapshots, func(node *vimtypes.VirtualMachineSnapshotTree) bool {
+			return node.Id == id
+		})
+		if snapshot == nil {
+			return nil, fmt.Errorf("snapshot with ID %d not found", id)
+		}
+		if name != "" && snapshot.Name != name {
+			return nil, fmt.Errorf("snapshot with ID %d is named '%s', not '%s'", id, snapshot.Name, name)
+		}
+		return snapshot, nil
+	}
+
+	snapshot := findSnapshotInTree(snapshots, func(node *vimtypes.VirtualMachineSnapshotTree) bool {
+		return node.Name == name
+	})
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot '%s' not found", name)
+	}
+
+	if count := countSnapshotsNamed(snapshots, name); count > 1 {
+		s.log(ctx).WithFields(logrus.Fields{
+			"snapshot_name": name,
+			"matches":       count,
+		}).Warn("Several snapshots share this name; the snapshot ID is needed to select one")
+		return nil, fmt.Errorf("%w: %d snapshots are named '%s'; pass the snapshot ID to select one", ErrSnapshotAmbiguous, count, name)
+	}
+
+	return snapshot, nil
+}
+
+// findSnapshotInTree returns the first snapshot in the tree (depth-first) matching match, or nil
+func findSnapshotInTree(snapshots []vimtypes.VirtualMachineSnapshotTree, match func(*vimtypes.VirtualMachineSnapshotTree) bool) *vimtypes.VirtualMachineSnapshotTree {
+	for idx := range snapshots {
+		if match(&snapshots[idx]) {
+			return &snapshots[idx]
+		}
+		// Search in child snapshots
+		if result := findSnapshotInTree(snapshots[idx].ChildSnapshotList, match); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// countSnapshotsNamed counts the snapshots in the tree with the given name
+func countSnapshotsNamed(snapshots []vimtypes.VirtualMachineSnapshotTree, name string) int {
+	count := 0
+	for _, node := range snapshots {
+		if node.Name == name {
+			count++
+		}
+		count += countSnapshotsNamed(node.ChildSnapshotList, name)
+	}
+	return count
+}
+
+// deltaDiskSuffix matches the delta disk suffixes before ".vmdk", including chained deltas
+// (e.g. "-000001-000002"). VMware numbers deltas with at least six digits; shorter numbers
+// are left alone so base disks of VMs named like "web-01" keep their name
+var deltaDiskSuffix = regexp.MustCompile(`(-[0-9]{6,})+\.vmdk$`)
+
+// getBaseDiskPath removes the delta disk suffixes to get the base VMDK path
+// Example: "[datastore] vm/vm-000002.vmdk" -> "[datastore] vm/vm.vmdk"
+// Paths without a delta suffix, or that are not .vmdk files, are returned as-is
+func getBaseDiskPath(diskPath string) string {
+	return deltaDiskSuffix.ReplaceAllString(diskPath, ".vmdk")
+}
+
+// extractDiskInfo extracts disk information from hardware devices
 func (s *VMService) extractDiskInfo(devices []vimtypes.BaseVirtualDevice) []VMDiskInfo {
+	// Disks reference their controller by device key
+	controllers := make(map[int32]diskController)
+	for _, device := range devices {
+		if controller, ok := newDiskController(device); ok {
+			controllers[device.GetVirtualDevice().Key] = controller
+		}
+	}
+
 	var disks []VMDiskInfo
 	for _, device := range devices {
 		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			controller := controllers[disk.ControllerKey]
 			diskInfo := VMDiskInfo{
-				Label:         disk.DeviceInfo.GetDescription().Label,
-				CapacityKB:    disk.CapacityInKB,
-				ControllerKey: disk.ControllerKey,
+				Label:          deviceLabel(disk.GetVirtualDevice()),
+				CapacityKB:     disk.CapacityInKB,
+				ControllerKey:  disk.ControllerKey,
+				ControllerBus:  controller.bus,
+				ControllerType: controller.controllerType,
+				BusNumber:      controller.busNumber,
+				UnitNumber:     disk.UnitNumber,
 			}
 
 			if backing, ok := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo); ok {
 				diskInfo.DiskPath = backing.FileName
-				diskInfo.ThinProvisioned = *backing.ThinProvisioned
+				// ThinProvisioned is optional and may be unset (e.g. older backing info)
+				diskInfo.ThinProvisioned = backing.ThinProvisioned != nil && *backing.ThinProvisioned
 				diskInfo.DiskMode = backing.DiskMode
 				// Extract datastore from path [datastore] path/to/disk.vmdk
 				if idx := strings.Index(backing.FileName, "]"); idx > 0 {
@@ -889,66 +1284,105 @@ func (s *VMService) extractDiskInfo(devices []vimtypes.BaseVirtualDevice) []VMDi
 	return disks
 }
 
+// Disk controller buses
+const (
+	ControllerBusIDE  = "ide"
+	ControllerBusSCSI = "scsi"
+	ControllerBusSATA = "sata"
+	ControllerBusNVMe = "nvme"
+)
+
+// diskController describes the controller a disk is attached to
+type diskController struct {
+	bus            string
+	controllerType string
+	busNumber      int32
+}
+
+// newDiskController describes a disk controller device; ok is false for other devices
+// The controller type names the emulated adapter, e.g. PVSCSI or LSI Logic SAS, which
+// decides the drivers a migrated guest needs
+func newDiskController(device vimtypes.BaseVirtualDevice) (controller diskController, ok bool) {
+	switch device.(type) {
+	case *vimtypes.VirtualIDEController:
+		controller = diskController{bus: ControllerBusIDE, controllerType: "ide"}
+	case *vimtypes.ParaVirtualSCSIController:
+		controller = diskController{bus: ControllerBusSCSI, controllerType: "pvscsi"}
+	case *vimtypes.VirtualLsiLogicSASController:
+		controller = diskController{bus: ControllerBusSCSI, controllerType: "lsilogic-sas"}
+	case *vimtypes.VirtualLsiLogicController:
+		controller = diskController{bus: ControllerBusSCSI, controllerType: "lsilogic"}
+	case *vimtypes.VirtualBusLogicController:
+		controller = diskController{bus: ControllerBusSCSI, controllerType: "buslogic"}
+	case vimtypes.BaseVirtualSCSIController:
+		controller = diskController{bus: ControllerBusSCSI, controllerType: "scsi"}
+	case *vimtypes.VirtualAHCIController:
+		controller = diskController{bus: ControllerBusSATA, controllerType: "ahci"}
+	case vimtypes.BaseVirtualSATAController:
+		controller = diskController{bus: ControllerBusSATA, controllerType: "sata"}
+	case *vimtypes.VirtualNVMEController:
+		controller = diskController{bus: ControllerBusNVMe, controllerType: "nvme"}
+	default:
+		return diskController{}, false
+	}
+
+	if base, isController := device.(vimtypes.BaseVirtualController); isController {
+		controller.busNumber = base.GetVirtualController().BusNumber
+	}
+	return controller, true
+}
+
 // extractNetworkAdapters extracts network adapter information from hardware devices
 func (s *VMService) extractNetworkAdapters(devices []vimtypes.BaseVirtualDevice, guest *vimtypes.GuestInfo) []VMNetworkAdapterInfo {
 	var adapters []VMNetworkAdapterInfo
 
 	// Create a map of MAC to IPs from guest info
-	macToIPs := make(map[string][]string)
+	// Without running VMware Tools there is no guest info, but the adapters are still
+	// reported from the device list with their MAC and backing network
+	toolsAvailable := guestToolsRunning(guest)
+	macToIPs := make(map[string][]GuestIPAddress)
 	if guest != nil {
 		for _, nic := range guest.Net {
 			if nic.MacAddress != "" && nic.IpConfig != nil {
-				var ips []string
-				for _, ipConfig := range nic.IpConfig.IpAddress {
-					if ipConfig.IpAddress != "" {
-						ips = append(ips, ipConfig.IpAddress)
-					}
-				}
-				macToIPs[nic.MacAddress] = ips
+				macToIPs[strings.ToLower(nic.MacAddress)] = collectNICIPs(nic)
 			}
 		}
 	}
 
 	for _, device := range devices {
-		var label, mac, network, adapterType string
-		var connected bool
-
-		switch nic := device.(type) {
-		case *vimtypes.VirtualE1000:
-			label = nic.DeviceInfo.GetDescription().Label
-			mac = nic.MacAddress
-			connected = nic.Connectable != nil && nic.Connectable.Connected
-			adapterType = "E1000"
-			if backing, ok := nic.Backing.(*vimtypes.VirtualEthernetCardNetworkBackingInfo); ok {
-				network = backing.DeviceName
-			}
-		case *vimtypes.VirtualE1000e:
-			label = nic.DeviceInfo.GetDescription().Label
-			mac = nic.MacAddress
-			connected = nic.Connectable != nil && nic.Connectable.Connected
-			adapterType = "E1000e"
-			if backing, ok := nic.Backing.(*vimtypes.VirtualEthernetCardNetworkBackingInfo); ok {
-				network = backing.DeviceName
-			}
-		case *vimtypes.VirtualVmxnet3:
-			label = nic.DeviceInfo.GetDescription().Label
-			mac = nic.MacAddress
-			connected = nic.Connectable != nil && nic.Connectable.Connected
-			adapterType = "VMXNET3"
-			if backing, ok := nic.Backing.(*vimtypes.VirtualEthernetCardNetworkBackingInfo); ok {
-				network = backing.DeviceName
-			}
-		default:
+		// All adapter types embed the base ethernet card
+		baseCard, ok := device.(vimtypes.BaseVirtualEthernetCard)
+		if !ok {
 			continue
 		}
+		card := baseCard.GetVirtualEthernetCard()
+
+		label := deviceLabel(card.GetVirtualDevice())
+		mac := card.MacAddress
+		connected := card.Connectable != nil && card.Connectable.Connected
+		adapterType := ethernetCardType(device)
+
+		var network, portgroupKey, switchUUID string
+		switch backing := card.Backing.(type) {
+		case *vimtypes.VirtualEthernetCardNetworkBackingInfo:
+			// Standard switch portgroup
+			network = backing.DeviceName
+		case *vimtypes.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+			// Distributed switch portgroup; the name is resolved by the caller
+			portgroupKey = backing.Port.PortgroupKey
+			switchUUID = backing.Port.SwitchUuid
+		}
 
 		adapter := VMNetworkAdapterInfo{
-			Label:       label,
-			NetworkName: network,
-			MacAddress:  mac,
-			Connected:   connected,
-			AdapterType: adapterType,
-			IPAddresses: macToIPs[mac],
+			Label:          label,
+			NetworkName:    network,
+			MacAddress:     mac,
+			Connected:      connected,
+			AdapterType:    adapterType,
+			IPAddresses:    macToIPs[strings.ToLower(mac)],
+			ToolsAvailable: toolsAvailable,
+			PortgroupKey:   portgroupKey,
+			SwitchUUID:     switchUUID,
 		}
 		adapters = append(adapters, adapter)
 	}
@@ -956,33 +1390,234 @@ func (s *VMService) extractNetworkAdapters(devices []vimtypes.BaseVirtualDevice,
 	return adapters
 }
 
-// extractSnapshotInfo recursively extracts snapshot information
-func (s *VMService) extractSnapshotInfo(snapshots []vimtypes.VirtualMachineSnapshotTree) []VMSnapshotInfo {
-	var result []VMSnapshotInfo
+// ethernetCardType returns the display name of a virtual ethernet card type
+// Card types without a known display name are reported by their type name (e.g. "EthernetCard")
+func ethernetCardType(device vimtypes.BaseVirtualDevice) string {
+	switch device.(type) {
+	case *vimtypes.VirtualVmxnet3Vrdma:
+		return "PVRDMA"
+	case *vimtypes.VirtualSriovEthernetCard:
+		return "SR-IOV"
+	case *vimtypes.VirtualE1000:
+		return "E1000"
+	case *vimtypes.VirtualE1000e:
+		return "E1000e"
+	case *vimtypes.VirtualVmxnet3:
+		return "VMXNET3"
+	case *vimtypes.VirtualVmxnet2:
+		return "VMXNET2"
+	case *vimtypes.VirtualVmxnet:
+		return "VMXNET"
+	case *vimtypes.VirtualPCNet32:
+		return "PCNet32"
+	default:
+		return strings.TrimPrefix(reflect.TypeOf(device).Elem().Name(), "Virtual")
+	}
+}
+
+// resolvePortgroupNames fills in the network name of adapters on distributed switch portgroups
+// networks are the VM's network references; portgroups that can't be resolved keep only their key
+func (s *VMService) resolvePortgroupNames(ctx context.Context, pc *property.Collector, networks []vimtypes.ManagedObjectReference, adapters []VMNetworkAdapterInfo) {
+	var portgroupRefs []vimtypes.ManagedObjectReference
+	for _, ref := range networks {
+		if ref.Type == "DistributedVirtualPortgroup" {
+			portgroupRefs = append(portgroupRefs, ref)
+		}
+	}
+	if len(portgroupRefs) == 0 {
+		return
+	}
+
+	var portgroups []mo.DistributedVirtualPortgroup
+	if err := pc.Retrieve(ctx, portgroupRefs, []string{"name", "key"}, &portgroups); err != nil {
+		s.log(ctx).WithError(err).Warn("Failed to resolve distributed portgroup names")
+		return
+	}
+
+	names := make(map[string]string, len(portgroups))
+	for _, portgroup := range portgroups {
+		names[portgroup.Key] = portgroup.Name
+	}
+
+	for i := range adapters {
+		if adapters[i].PortgroupKey != "" && adapters[i].NetworkName == "" {
+			adapters[i].NetworkName = names[adapters[i].PortgroupKey]
+		}
+	}
+}
+
+// guestToolsRunning reports whether VMware Tools is running in the guest, i.e. whether
+// guest network information can be reported
+func guestToolsRunning(guest *vimtypes.GuestInfo) bool {
+	return guest != nil && guest.ToolsRunningStatus == string(vimtypes.VirtualMachineToolsRunningStatusGuestToolsRunning)
+}
+
+// deviceLabel returns the label of a virtual device, or an empty string when
+// the device carries no description
+func deviceLabel(device *vimtypes.VirtualDevice) string {
+	if device == nil || device.DeviceInfo == nil {
+		return ""
+	}
+	if description := device.DeviceInfo.GetDescription(); description != nil {
+		return description.Label
+	}
+	return ""
+}
+
+// extractSnapshotInfo flattens the snapshot tree depth-first, each snapshot followed by its
+// children; current is the moref of the snapshot the VM runs from (nil when there is none)
+func (s *VMService) extractSnapshotInfo(snapshots []vimtypes.VirtualMachineSnapshotTree, current *vimtypes.ManagedObjectReference) []VMSnapshotInfo {
+	return flattenSnapshotTree(buildSnapshotTree(snapshots, 0, 0, current))
+}
+
+// buildSnapshotTree converts the snapshot tree reported by vSphere, with siblings ordered by
+// create time (oldest first)
+// parentID is the ID of the parent snapshot and depth the depth of the given snapshots (both 0
+// for root snapshots)
+func buildSnapshotTree(snapshots []vimtypes.VirtualMachineSnapshotTree, parentID int32, depth int, current *vimtypes.ManagedObjectReference) []VMSnapshotInfo {
+	result := make([]VMSnapshotInfo, 0, len(snapshots))
 	for _, snap := range snapshots {
-		info := VMSnapshotInfo{
+		result = append(result, VMSnapshotInfo{
 			Name:        snap.Name,
 			Description: snap.Description,
 			CreateTime:  snap.CreateTime,
 			State:       string(snap.State),
 			Quiesced:    snap.Quiesced,
 			ID:          snap.Id,
+			ParentID:    parentID,
+			Depth:       depth,
+			IsCurrent:   current != nil && snap.Snapshot == *current,
+			Children:    buildSnapshotTree(snap.ChildSnapshotList, snap.Id, depth+1, current),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreateTime.Before(result[j].CreateTime)
+	})
+	return result
+}
+
+// flattenSnapshotTree lists the snapshots of a tree depth-first, without their children
+func flattenSnapshotTree(tree []VMSnapshotInfo) []VMSnapshotInfo {
+	var result []VMSnapshotInfo
+	for _, snap := range tree {
+		children := snap.Children
+		snap.Children = nil
+		result = append(result, snap)
+		result = append(result, flattenSnapshotTree(children)...)
+	}
+	return result
+}
+
+// resolveSnapshotName walks the snapshot tree to find the snapshot with the given moref
+// and returns its name and description; ok is false when it is not in the tree
+func resolveSnapshotName(tree []vimtypes.VirtualMachineSnapshotTree, moref vimtypes.ManagedObjectReference) (name string, description string, ok bool) {
+	for _, snap := range tree {
+		if snap.Snapshot == moref {
+			return snap.Name, snap.Description, true
+		}
+		if name, description, ok := resolveSnapshotName(snap.ChildSnapshotList, moref); ok {
+			return name, description, true
 		}
-		result = append(result, info)
+	}
+	return "", "", false
+}
+
+// ListSnapshots retrieves the snapshots of a VM without fetching the full VM details
+func (s *VMService) ListSnapshots(ctx context.Context, vmName string) (*VMSnapshotListResult, error) {
+	s.log(ctx).WithField("vm_name", vmName).Info("Listing VM snapshots")
+
+	// Find VM by name
+	vm, datacenter, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
 
-		// Recursively add child snapshots
-		if len(snap.ChildSnapshotList) > 0 {
-			result = append(result, s.extractSnapshotInfo(snap.ChildSnapshotList)...)
+	// Get govmomi client for property collector
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	// Retrieve only the snapshot property
+	var vmProps mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	err = pc.RetrieveOne(ctx, vm.Reference(), []string{"snapshot"}, &vmProps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM snapshots: %w", err)
+	}
+
+	result := &VMSnapshotListResult{
+		Datacenter: datacenter.Name(),
+		VMMoref:    vm.Reference().Value,
+		Snapshots:  []VMSnapshotInfo{},
+		Tree:       []VMSnapshotInfo{},
+	}
+
+	if vmProps.Snapshot != nil {
+		// The flat list is built from the tree, so both come from the same retrieval
+		result.Tree = buildSnapshotTree(vmProps.Snapshot.RootSnapshotList, 0, 0, vmProps.Snapshot.CurrentSnapshot)
+		if snapshots := flattenSnapshotTree(result.Tree); snapshots != nil {
+			result.Snapshots = snapshots
+		}
+		if vmProps.Snapshot.CurrentSnapshot != nil {
+			result.CurrentSnapshot = vmProps.Snapshot.CurrentSnapshot.Value
+			result.CurrentSnapshotName, result.CurrentSnapshotDescription, _ = resolveSnapshotName(vmProps.Snapshot.RootSnapshotList, *vmProps.Snapshot.CurrentSnapshot)
 		}
 	}
-	return result
+
+	s.log(ctx).WithField("snapshot_count", len(result.Snapshots)).Info("Snapshot listing completed")
+
+	return result, nil
+}
+
+// ListDisks retrieves the virtual disks of a VM without fetching the full VM details
+func (s *VMService) ListDisks(ctx context.Context, vmName string) (*VMDiskListResult, error) {
+	s.log(ctx).WithField("vm_name", vmName).Info("Listing VM disks")
+
+	// Find VM by name
+	vm, datacenter, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get govmomi client for property collector
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	// Retrieve only the device list; controllers are needed to resolve the disk buses
+	var vmProps mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	err = pc.RetrieveOne(ctx, vm.Reference(), []string{"config.hardware.device"}, &vmProps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM devices: %w", err)
+	}
+
+	result := &VMDiskListResult{
+		Datacenter: datacenter.Name(),
+		VMMoref:    vm.Reference().Value,
+		Disks:      []VMDiskInfo{},
+	}
+	if vmProps.Config != nil {
+		if disks := s.extractDiskInfo(vmProps.Config.Hardware.Device); disks != nil {
+			result.Disks = disks
+		}
+	}
+
+	s.log(ctx).WithField("disk_count", len(result.Disks)).Info("Disk listing completed")
+
+	return result, nil
 }
 
 // FindSnapshotByName finds a snapshot by name on a VM
-func (s *VMService) FindSnapshotByName(ctx context.Context, vmName string, snapshotName string) (*vimtypes.ManagedObjectReference, error) {
-	s.logger.WithFields(logrus.Fields{
+// A non-zero snapshotID selects the snapshot by ID, disambiguating snapshots that share a name
+func (s *VMService) FindSnapshotByName(ctx context.Context, vmName string, snapshotName string, snapshotID int32) (*vimtypes.ManagedObjectReference, error) {
+	s.log(ctx).WithFields(logrus.Fields{
 		"vm_name":       vmName,
 		"snapshot_name": snapshotName,
+		"snapshot_id":   snapshotID,
 	}).Info("Finding snapshot by name")
 
 	// Find VM by name
@@ -1008,104 +1643,227 @@ func (s *VMService) FindSnapshotByName(ctx context.Context, vmName string, snaps
 		return nil, fmt.Errorf("VM '%s' has no snapshots", vmName)
 	}
 
-	// Search for snapshot by name
-	var findSnapshot func(tree []vimtypes.VirtualMachineSnapshotTree) *vimtypes.ManagedObjectReference
-	findSnapshot = func(tree []vimtypes.VirtualMachineSnapshotTree) *vimtypes.ManagedObjectReference {
-		for _, node := range tree {
-			if node.Name == snapshotName {
-				return &node.Snapshot
-			}
-			if len(node.ChildSnapshotList) > 0 {
-				if ref := findSnapshot(node.ChildSnapshotList); ref != nil {
-					return ref
-				}
-			}
-		}
-		return nil
-	}
-
-	snapshotRef := findSnapshot(vmProps.Snapshot.RootSnapshotList)
-	if snapshotRef == nil {
-		return nil, fmt.Errorf("snapshot '%s' not found on VM '%s'", snapshotName, vmName)
+	// Search for snapshot by ID, or by name
+	snapshot, err := s.findSnapshot(ctx, vmProps.Snapshot.RootSnapshotList, snapshotName, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("%w on VM '%s'", err, vmName)
 	}
 
-	s.logger.Info("Snapshot found successfully")
-	return snapshotRef, nil
+	s.log(ctx).Info("Snapshot found successfully")
+	return &snapshot.Snapshot, nil
 }
 
 // CreateLinkedClone creates a linked clone from a snapshot
-func (s *VMService) CreateLinkedClone(ctx context.Context, vmName string, snapshotRef *vimtypes.ManagedObjectReference, cloneName string) error {
-	s.logger.WithFields(logrus.Fields{
-		"vm_name":    vmName,
-		"clone_name": cloneName,
+func (s *VMService) CreateLinkedClone(ctx context.Context, vmName string, snapshotRef *vimtypes.ManagedObjectReference, cloneName string, target CloneTarget) (*CloneInfo, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name":          vmName,
+		"clone_name":       cloneName,
+		"target_folder":    target.Folder,
+		"target_datastore": target.Datastore,
 	}).Info("Creating linked clone from snapshot")
 
+	// Linked clones are a vCenter feature
+	if err := s.client.RequireVCenter(ctx); err != nil {
+		return nil, err
+	}
+
 	// Find source VM
 	vm, datacenter, err := s.findVMByName(ctx, vmName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get govmomi client
 	client, err := s.client.GetClient(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get vSphere client: %w", err)
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
 	}
 
 	// Get VM folder
 	finder := find.NewFinder(client.Client, true)
 	finder.SetDatacenter(datacenter)
 
-	vmFolder, err := finder.FolderOrDefault(ctx, "vm")
+	var vmFolder *object.Folder
+	if target.Folder != "" {
+		vmFolder, err = finder.Folder(ctx, target.Folder)
+		if err != nil {
+			return nil, finderError(ErrFolderNotFound, "folder", target.Folder, err)
+		}
+	} else {
+		vmFolder, err = finder.FolderOrDefault(ctx, "vm")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find VM folder: %w", err)
+		}
+	}
+
+	cloneSpec := linkedCloneSpec(snapshotRef)
+
+	// Place the clone's files and child disks on the target datastore (e.g. scratch storage)
+	if target.Datastore != "" {
+		datastore, err := finder.Datastore(ctx, target.Datastore)
+		if err != nil {
+			return nil, finderError(ErrDatastoreNotFound, "datastore", target.Datastore, err)
+		}
+		datastoreRef := datastore.Reference()
+		cloneSpec.Location.Datastore = &datastoreRef
+	}
+
+	// Create clone task
+	task, err := vm.Clone(ctx, vmFolder, cloneName, cloneSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clone task: %w", err)
+	}
+
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Clone task created, waiting for completion")
+
+	// Wait for task to complete; the task result is the new VM's reference
+	taskInfo, err := task.WaitForResult(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to find VM folder: %w", err)
+		return nil, fmt.Errorf("clone creation failed: %w", err)
 	}
 
-	// Create linked clone spec
-	cloneSpec := vimtypes.VirtualMachineCloneSpec{
+	cloneRef, ok := taskInfo.Result.(vimtypes.ManagedObjectReference)
+	if !ok {
+		return nil, fmt.Errorf("clone task returned unexpected result type %T", taskInfo.Result)
+	}
+
+	info := &CloneInfo{
+		Name:  cloneName,
+		Moref: cloneRef.Value,
+	}
+
+	// Retrieve the clone's UUID so callers get a stable handle
+	var cloneProps mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, cloneRef, []string{"config.uuid"}, &cloneProps); err != nil {
+		s.log(ctx).WithError(err).WithField("clone_moref", info.Moref).Warn("Failed to retrieve clone UUID")
+	} else if cloneProps.Config != nil {
+		info.UUID = cloneProps.Config.Uuid
+	}
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"clone_moref": info.Moref,
+		"clone_uuid":  info.UUID,
+	}).Info("Linked clone created successfully")
+	return info, nil
+}
+
+// linkedCloneSpec returns the spec of a powered-off linked clone of a snapshot
+// The clone is marked with cloneMarkerKey so stale clone cleanup never mistakes another
+// VM for one
+func linkedCloneSpec(snapshotRef *vimtypes.ManagedObjectReference) vimtypes.VirtualMachineCloneSpec {
+	return vimtypes.VirtualMachineCloneSpec{
 		Location: vimtypes.VirtualMachineRelocateSpec{
 			DiskMoveType: string(vimtypes.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking),
 		},
+		Config: &vimtypes.VirtualMachineConfigSpec{
+			ExtraConfig: []vimtypes.BaseOptionValue{
+				&vimtypes.OptionValue{Key: cloneMarkerKey, Value: "true"},
+			},
+		},
 		Snapshot: snapshotRef,
 		PowerOn:  false,
 		Template: false,
 	}
+}
 
-	// Create clone task
-	task, err := vm.Clone(ctx, vmFolder, cloneName, cloneSpec)
+// CreateLinkedCloneFromCurrent creates a linked clone of a VM's current state
+// Linked clones need a snapshot, so a temporary snapshot is created for the clone and
+// removed afterwards, whether or not the clone succeeded
+func (s *VMService) CreateLinkedCloneFromCurrent(ctx context.Context, vmName string, cloneName string, target CloneTarget) (*CloneInfo, error) {
+	snapshotName := fmt.Sprintf("%s-source-%d", cloneName, time.Now().Unix())
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name":       vmName,
+		"clone_name":    cloneName,
+		"snapshot_name": snapshotName,
+	}).Info("Creating linked clone from current state")
+
+	// Fail before creating a snapshot that would only be removed again
+	if err := s.client.RequireVCenter(ctx); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.CreateSnapshot(ctx, vmName, snapshotName, "Temporary snapshot for linked clone "+cloneName, false, false)
 	if err != nil {
-		return fmt.Errorf("failed to create clone task: %w", err)
+		return nil, fmt.Errorf("failed to create temporary snapshot: %w", err)
 	}
 
-	s.logger.WithField("task_id", task.Reference().Value).Info("Clone task created, waiting for completion")
+	// Remove the snapshot even when the clone failed or the request was cancelled
+	defer func() {
+		removeCtx := context.WithoutCancel(ctx)
+		if err := s.RemoveSnapshot(removeCtx, vmName, snapshot.SnapshotMoref, snapshotName); err != nil {
+			s.log(ctx).WithError(err).WithField("snapshot_name", snapshotName).Error("Failed to remove temporary clone snapshot")
+		}
+	}()
 
-	// Wait for task to complete
-	err = task.Wait(ctx)
+	snapshotRef := &vimtypes.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: snapshot.SnapshotMoref}
+	if snapshot.SnapshotMoref == "" {
+		if snapshotRef, err = s.FindSnapshotByName(ctx, vmName, snapshotName, 0); err != nil {
+			return nil, fmt.Errorf("failed to find temporary snapshot: %w", err)
+		}
+	}
+
+	return s.CreateLinkedClone(ctx, vmName, snapshotRef, cloneName, target)
+}
+
+// RemoveSnapshot removes a single VM snapshot, identified by moref or, when it is empty, by name
+// The snapshot's disks are consolidated into the VM's disk chain
+func (s *VMService) RemoveSnapshot(ctx context.Context, vmName string, snapshotMoref string, snapshotName string) error {
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	snapshot := snapshotMoref
+	if snapshot == "" {
+		snapshot = snapshotName
+	}
+	consolidate := true
+	task, err := vm.RemoveSnapshot(ctx, snapshot, false, &consolidate)
 	if err != nil {
-		return fmt.Errorf("clone creation failed: %w", err)
+		return fmt.Errorf("failed to create snapshot removal task: %w", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("snapshot removal failed: %w", err)
 	}
 
-	s.logger.Info("Linked clone created successfully")
+	s.log(ctx).WithField("snapshot", snapshot).Info("Snapshot removed successfully")
 	return nil
 }
 
 // DeleteVM deletes a VM
-func (s *VMService) DeleteVM(ctx context.Context, vmName string) error {
-	s.logger.WithField("vm_name", vmName).Info("Deleting VM")
+// Unless force is set, a VM whose disks back linked clones is not deleted and
+// ErrVMHasDependentClones is returned, naming the dependent clones
+func (s *VMService) DeleteVM(ctx context.Context, vmName string, force bool) error {
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name": vmName,
+		"force":   force,
+	}).Info("Deleting VM")
 
 	// Find VM
-	vm, _, err := s.findVMByName(ctx, vmName)
+	vm, datacenter, err := s.findVMByName(ctx, vmName)
 	if err != nil {
 		return err
 	}
 
+	// Destroying the parent of linked clones removes the disks their delta disks are based on
+	if !force {
+		dependents, err := s.findDependentClones(ctx, vm, datacenter)
+		if err != nil {
+			return fmt.Errorf("failed to check for dependent clones: %w", err)
+		}
+		if len(dependents) > 0 {
+			return fmt.Errorf("%w: '%s' backs %s", ErrVMHasDependentClones, vmName, strings.Join(dependents, ", "))
+		}
+	}
+
 	// Destroy VM task
 	task, err := vm.Destroy(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create delete task: %w", err)
 	}
 
-	s.logger.WithField("task_id", task.Reference().Value).Info("Delete task created, waiting for completion")
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Delete task created, waiting for completion")
 
 	// Wait for task to complete
 	err = task.Wait(ctx)
@@ -1113,13 +1871,25 @@ func (s *VMService) DeleteVM(ctx context.Context, vmName string) error {
 		return fmt.Errorf("VM deletion failed: %w", err)
 	}
 
-	s.logger.Info("VM deleted successfully")
+	s.log(ctx).Info("VM deleted successfully")
 	return nil
 }
 
+// SnapshotCreateResult describes a created snapshot
+type SnapshotCreateResult struct {
+	TaskID        string
+	SnapshotMoref string
+	// Quiesced reports whether vSphere quiesced the guest file systems; it is only
+	// checked when quiescing was requested and QuiesceVerified is true
+	Quiesced        bool
+	QuiesceVerified bool
+}
+
 // CreateSnapshot creates a snapshot for a VM
-func (s *VMService) CreateSnapshot(ctx context.Context, vmName string, snapshotName string, description string, memory bool, quiesce bool) (string, error) {
-	s.logger.WithFields(logrus.Fields{
+// When quiesce is requested, the created snapshot is read back to verify it was quiesced:
+// vSphere may silently create a crash-consistent snapshot, e.g. when VMware Tools is not running
+func (s *VMService) CreateSnapshot(ctx context.Context, vmName string, snapshotName string, description string, memory bool, quiesce bool) (*SnapshotCreateResult, error) {
+	s.log(ctx).WithFields(logrus.Fields{
 		"vm_name":       vmName,
 		"snapshot_name": snapshotName,
 		"memory":        memory,
@@ -1129,27 +1899,93 @@ func (s *VMService) CreateSnapshot(ctx context.Context, vmName string, snapshotN
 	// Find VM by name using the helper function
 	vm, _, err := s.findVMByName(ctx, vmName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Create snapshot task
 	task, err := vm.CreateSnapshot(ctx, snapshotName, description, memory, quiesce)
 	if err != nil {
-		return "", fmt.Errorf("failed to create snapshot task: %w", err)
+		return nil, fmt.Errorf("failed to create snapshot task: %w", err)
 	}
 
-	s.logger.WithField("task_id", task.Reference().Value).Info("Snapshot task created, waiting for completion")
+	s.log(ctx).WithField("task_id", task.Reference().Value).Info("Snapshot task created, waiting for completion")
 
 	// Wait for task to complete
-	err = task.Wait(ctx)
+	info, err := task.WaitForResult(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("snapshot creation failed: %w", err)
+		return nil, fmt.Errorf("snapshot creation failed: %w", err)
 	}
 
-	s.logger.Info("Snapshot created successfully")
+	s.log(ctx).Info("Snapshot created successfully")
 
-	// Return the task reference as snapshot ID
-	return task.Reference().Value, nil
+	// The task reference is returned as the snapshot ID
+	result := &SnapshotCreateResult{TaskID: task.Reference().Value}
+	snapshotRef, ok := info.Result.(vimtypes.ManagedObjectReference)
+	if !ok {
+		s.log(ctx).Warn("Snapshot task returned no snapshot reference")
+		return result, nil
+	}
+	result.SnapshotMoref = snapshotRef.Value
+
+	if quiesce {
+		quiesced, err := s.snapshotQuiesced(ctx, vm, snapshotRef)
+		if err != nil {
+			s.log(ctx).WithError(err).Warn("Failed to verify that the snapshot was quiesced")
+		} else {
+			result.Quiesced = quiesced
+			result.QuiesceVerified = true
+			if !quiesced {
+				s.log(ctx).WithField("snapshot_moref", snapshotRef.Value).Warn("Quiesced snapshot was requested but vSphere created a crash-consistent snapshot")
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// snapshotQuiesced reads the quiesced flag of a VM snapshot from the VM's snapshot tree
+func (s *VMService) snapshotQuiesced(ctx context.Context, vm *object.VirtualMachine, snapshotRef vimtypes.ManagedObjectReference) (bool, error) {
+	snapshot, err := s.snapshotByMoref(ctx, vm, snapshotRef.Value)
+	if err != nil {
+		return false, err
+	}
+	return snapshot.Quiesced, nil
+}
+
+// FindSnapshotIDByMoref returns the ID of a VM snapshot within the VM's snapshot tree
+// Snapshot names need not be unique, so a caller that created a snapshot resolves it by
+// the moref CreateSnapshot returned rather than by its name
+func (s *VMService) FindSnapshotIDByMoref(ctx context.Context, vmName string, snapshotMoref string) (int32, error) {
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshot, err := s.snapshotByMoref(ctx, vm, snapshotMoref)
+	if err != nil {
+		return 0, err
+	}
+	return snapshot.Id, nil
+}
+
+// snapshotByMoref returns the node of a VM's snapshot tree with the given snapshot moref
+func (s *VMService) snapshotByMoref(ctx context.Context, vm *object.VirtualMachine, snapshotMoref string) (*vimtypes.VirtualMachineSnapshotTree, error) {
+	var vmProps mo.VirtualMachine
+	pc := property.DefaultCollector(vm.Client())
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"snapshot"}, &vmProps); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM snapshots: %w", err)
+	}
+	if vmProps.Snapshot == nil {
+		return nil, fmt.Errorf("VM has no snapshots")
+	}
+
+	snapshot := findSnapshotInTree(vmProps.Snapshot.RootSnapshotList, func(node *vimtypes.VirtualMachineSnapshotTree) bool {
+		return node.Snapshot.Value == snapshotMoref
+	})
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot '%s' not found", snapshotMoref)
+	}
+	return snapshot, nil
 }
 
 // InspectVMFromSnapshot inspects a VM by creating a temporary clone from a snapshot
@@ -1157,37 +1993,38 @@ func (s *VMService) InspectVMFromSnapshot(ctx context.Context, vmName string, sn
 	// Generate unique clone name
 	cloneName := fmt.Sprintf("%s-inspect-clone-%d", vmName, time.Now().Unix())
 
-	s.logger.WithFields(logrus.Fields{
+	s.log(ctx).WithFields(logrus.Fields{
 		"vm_name":       vmName,
 		"snapshot_name": snapshotName,
 		"clone_name":    cloneName,
 	}).Info("Starting VM inspection from snapshot")
 
 	// Find snapshot
-	snapshotRef, err := s.FindSnapshotByName(ctx, vmName, snapshotName)
+	snapshotRef, err := s.FindSnapshotByName(ctx, vmName, snapshotName, 0)
 	if err != nil {
 		return fmt.Errorf("failed to find snapshot: %w", err)
 	}
 
 	// Create linked clone
-	err = s.CreateLinkedClone(ctx, vmName, snapshotRef, cloneName)
+	_, err = s.CreateLinkedClone(ctx, vmName, snapshotRef, cloneName, CloneTarget{})
 	if err != nil {
 		return fmt.Errorf("failed to create linked clone: %w", err)
 	}
 
 	// Ensure cleanup of clone
 	defer func() {
-		s.logger.Info("Cleaning up inspection clone")
-		cleanupErr := s.DeleteVM(ctx, cloneName)
+		s.log(ctx).Info("Cleaning up inspection clone")
+		// Nothing can depend on a clone made for this inspection, so skip the dependency scan
+		cleanupErr := s.DeleteVM(ctx, cloneName, true)
 		if cleanupErr != nil {
-			s.logger.WithError(cleanupErr).Error("Failed to delete inspection clone")
+			s.log(ctx).WithError(cleanupErr).Error("Failed to delete inspection clone")
 		}
 	}()
 
 	// Note: The actual virt-inspector execution will be handled by the API handler
 	// This method just manages the clone lifecycle
 
-	s.logger.Info("Inspection clone ready for inspection")
+	s.log(ctx).Info("Inspection clone ready for inspection")
 	return nil
 }
 
@@ -0,0 +1,1410 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// newTestService returns a VMService without a vSphere connection, for conversion helpers
+func newTestService() *VMService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &VMService{logger: logger}
+}
+
+func TestVMFilterPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    VMFilter
+		count     int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "no pagination", filter: VMFilter{}, count: 10, wantStart: 0, wantEnd: 10},
+		{name: "limit only", filter: VMFilter{Limit: 3}, count: 10, wantStart: 0, wantEnd: 3},
+		{name: "offset and limit", filter: VMFilter{Offset: 4, Limit: 3}, count: 10, wantStart: 4, wantEnd: 7},
+		{name: "last partial page", filter: VMFilter{Offset: 8, Limit: 5}, count: 10, wantStart: 8, wantEnd: 10},
+		{name: "offset past the end", filter: VMFilter{Offset: 12, Limit: 5}, count: 10, wantStart: 10, wantEnd: 10},
+		{name: "negative offset", filter: VMFilter{Offset: -1}, count: 10, wantStart: 0, wantEnd: 10},
+		{name: "empty list", filter: VMFilter{Offset: 2, Limit: 5}, count: 0, wantStart: 0, wantEnd: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.filter.page(tt.count)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("page(%d) = [%d:%d], want [%d:%d]", tt.count, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// BenchmarkRetrieveVMProperties compares a single property retrieval with batched concurrent
+// retrievals over a simulated 5000-VM inventory
+func BenchmarkRetrieveVMProperties(b *testing.B) {
+	const inventorySize = 5000
+
+	model := simulator.VPX()
+	model.Host = 0
+	model.ClusterHost = 1
+	model.Machine = 1
+	cfg := startSimulator(b, model)
+
+	// vcsim takes minutes to create thousands of VMs, so the inventory is filled with
+	// copies of the properties of a simulated VM, which are all the benchmark reads
+	template := model.Map().Any("VirtualMachine").(*simulator.VirtualMachine)
+	vmRefs := make([]vimtypes.ManagedObjectReference, 0, inventorySize)
+	for i := 0; i < inventorySize; i++ {
+		vm := &mo.VirtualMachine{
+			ManagedEntity: mo.ManagedEntity{Name: fmt.Sprintf("bench-vm-%04d", i)},
+			Config:        template.Config,
+			Runtime:       template.Runtime,
+		}
+		vmRefs = append(vmRefs, model.Map().Put(vm).Reference())
+	}
+	props := []string{"name", "config.uuid", "runtime.powerState", "runtime.connectionState"}
+
+	benchmarks := []struct {
+		name      string
+		batchSize int
+		workers   int
+	}{
+		{name: "single", batchSize: 0, workers: 1},
+		{name: "batch=500/workers=4", batchSize: 500, workers: 4},
+		{name: "batch=250/workers=8", batchSize: 250, workers: 8},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := cfg
+			cfg.PropertyBatchSize = bm.batchSize
+			cfg.PropertyBatchWorkers = bm.workers
+			service := newSimulatorService(b, cfg)
+
+			ctx := context.Background()
+			client, err := service.client.GetClient(ctx)
+			if err != nil {
+				b.Fatalf("GetClient() error = %v", err)
+			}
+			pc := property.DefaultCollector(client.Client)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vms, err := service.retrieveVMProperties(ctx, pc, vmRefs, props)
+				if err != nil {
+					b.Fatalf("retrieveVMProperties() error = %v", err)
+				}
+				if len(vms) != inventorySize {
+					b.Fatalf("retrieveVMProperties() returned %d VMs, want %d", len(vms), inventorySize)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractDiskInfo(t *testing.T) {
+	unit := int32(1)
+	controller := &vimtypes.ParaVirtualSCSIController{
+		VirtualSCSIController: vimtypes.VirtualSCSIController{
+			VirtualController: vimtypes.VirtualController{
+				VirtualDevice: vimtypes.VirtualDevice{Key: 1000},
+				BusNumber:     0,
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		backing vimtypes.BaseVirtualDeviceBackingInfo
+		info    vimtypes.BaseDescription
+		want    VMDiskInfo
+	}{
+		{
+			name: "thin provisioned flat disk",
+			backing: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: "[datastore1] vm/vm.vmdk"},
+				DiskMode:                     "persistent",
+				ThinProvisioned:              vimtypes.NewBool(true),
+			},
+			info: &vimtypes.Description{Label: "Hard disk 1"},
+			want: VMDiskInfo{
+				Label:           "Hard disk 1",
+				DiskPath:        "[datastore1] vm/vm.vmdk",
+				Datastore:       "datastore1",
+				ThinProvisioned: true,
+				DiskMode:        "persistent",
+			},
+		},
+		{
+			name: "nil ThinProvisioned",
+			backing: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: "[datastore1] vm/vm.vmdk"},
+				DiskMode:                     "persistent",
+			},
+			info: &vimtypes.Description{Label: "Hard disk 1"},
+			want: VMDiskInfo{
+				Label:     "Hard disk 1",
+				DiskPath:  "[datastore1] vm/vm.vmdk",
+				Datastore: "datastore1",
+				DiskMode:  "persistent",
+			},
+		},
+		{
+			name: "nil DeviceInfo",
+			backing: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: "[datastore1] vm/vm.vmdk"},
+			},
+			want: VMDiskInfo{
+				DiskPath:  "[datastore1] vm/vm.vmdk",
+				Datastore: "datastore1",
+			},
+		},
+		{
+			name:    "raw device mapping backing",
+			backing: &vimtypes.VirtualDiskRawDiskMappingVer1BackingInfo{},
+			info:    &vimtypes.Description{Label: "Hard disk 2"},
+			want:    VMDiskInfo{Label: "Hard disk 2"},
+		},
+		{
+			name: "nil backing",
+			want: VMDiskInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := &vimtypes.VirtualDisk{
+				VirtualDevice: vimtypes.VirtualDevice{
+					Key:           2000,
+					DeviceInfo:    tt.info,
+					Backing:       tt.backing,
+					ControllerKey: 1000,
+					UnitNumber:    &unit,
+				},
+				CapacityInKB: 1024,
+			}
+
+			disks := (&VMService{}).extractDiskInfo([]vimtypes.BaseVirtualDevice{controller, disk})
+			if len(disks) != 1 {
+				t.Fatalf("extractDiskInfo() returned %d disks, want 1", len(disks))
+			}
+
+			want := tt.want
+			want.CapacityKB = 1024
+			want.ControllerKey = 1000
+			want.ControllerBus = ControllerBusSCSI
+			want.ControllerType = "pvscsi"
+			want.UnitNumber = &unit
+			if disks[0] != want {
+				t.Errorf("extractDiskInfo() = %+v, want %+v", disks[0], want)
+			}
+		})
+	}
+
+	// Disks on a SATA controller report the SATA bus and the controller's bus number
+	sataUnit := int32(3)
+	sataController := &vimtypes.VirtualAHCIController{
+		VirtualSATAController: vimtypes.VirtualSATAController{
+			VirtualController: vimtypes.VirtualController{
+				VirtualDevice: vimtypes.VirtualDevice{Key: 15000},
+				BusNumber:     1,
+			},
+		},
+	}
+	sataDisk := &vimtypes.VirtualDisk{
+		VirtualDevice: vimtypes.VirtualDevice{
+			Key:           16000,
+			DeviceInfo:    &vimtypes.Description{Label: "Hard disk 3"},
+			ControllerKey: 15000,
+			UnitNumber:    &sataUnit,
+		},
+		CapacityInKB: 2048,
+	}
+
+	disks := (&VMService{}).extractDiskInfo([]vimtypes.BaseVirtualDevice{controller, sataController, sataDisk})
+	if len(disks) != 1 {
+		t.Fatalf("extractDiskInfo() returned %d disks, want 1", len(disks))
+	}
+	want := VMDiskInfo{
+		Label:          "Hard disk 3",
+		CapacityKB:     2048,
+		ControllerKey:  15000,
+		ControllerBus:  ControllerBusSATA,
+		ControllerType: "ahci",
+		BusNumber:      1,
+		UnitNumber:     &sataUnit,
+	}
+	if disks[0] != want {
+		t.Errorf("extractDiskInfo() = %+v, want %+v", disks[0], want)
+	}
+}
+
+func TestDeviceLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		device *vimtypes.VirtualDevice
+		want   string
+	}{
+		{name: "nil device", device: nil, want: ""},
+		{name: "nil DeviceInfo", device: &vimtypes.VirtualDevice{}, want: ""},
+		{name: "description", device: &vimtypes.VirtualDevice{DeviceInfo: &vimtypes.Description{Label: "Network adapter 1"}}, want: "Network adapter 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deviceLabel(tt.device); got != tt.want {
+				t.Errorf("deviceLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinderError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantNotFound bool
+	}{
+		{name: "not found", err: &find.NotFoundError{}, wantNotFound: true},
+		{name: "wrapped not found", err: fmt.Errorf("lookup: %w", &find.NotFoundError{}), wantNotFound: true},
+		{name: "timeout", err: context.DeadlineExceeded, wantNotFound: false},
+		{name: "expired session", err: errors.New("NotAuthenticated"), wantNotFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := finderError(ErrDatacenterNotFound, "datacenter", "DC1", tt.err)
+			if got := errors.Is(err, ErrDatacenterNotFound); got != tt.wantNotFound {
+				t.Errorf("errors.Is(%v, ErrDatacenterNotFound) = %v, want %v", err, got, tt.wantNotFound)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("finderError() = %v, want it to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestListVMsUnknownScope(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+
+	tests := []struct {
+		name   string
+		filter VMFilter
+		want   error
+	}{
+		{name: "unknown datacenter", filter: VMFilter{Datacenter: "missing"}, want: ErrDatacenterNotFound},
+		{name: "unknown cluster", filter: VMFilter{Datacenter: "DC0", Cluster: "missing"}, want: ErrClusterNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ListVMs(context.Background(), tt.filter); !errors.Is(err, tt.want) {
+				t.Errorf("ListVMs() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateLinkedCloneUnknownTarget(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	if _, err := service.CreateSnapshot(ctx, vmName, "base", "", false, false); err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	snapshotRef, err := service.FindSnapshotByName(ctx, vmName, "base", 0)
+	if err != nil {
+		t.Fatalf("FindSnapshotByName() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		target CloneTarget
+		want   error
+	}{
+		{name: "unknown folder", target: CloneTarget{Folder: "missing"}, want: ErrFolderNotFound},
+		{name: "unknown datastore", target: CloneTarget{Datastore: "missing"}, want: ErrDatastoreNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateLinkedClone(ctx, vmName, snapshotRef, "clone", tt.target); !errors.Is(err, tt.want) {
+				t.Errorf("CreateLinkedClone() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSnapshotIDByMoref(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	// Both snapshots share a name; each must resolve to the one its moref refers to
+	const vmName, snapshotName = "DC0_H0_VM0", "live"
+	var morefs []string
+	for i := 0; i < 2; i++ {
+		result, err := service.CreateSnapshot(ctx, vmName, snapshotName, "", false, false)
+		if err != nil {
+			t.Fatalf("CreateSnapshot() error = %v", err)
+		}
+		morefs = append(morefs, result.SnapshotMoref)
+	}
+
+	ids := make(map[int32]bool)
+	for _, moref := range morefs {
+		id, err := service.FindSnapshotIDByMoref(ctx, vmName, moref)
+		if err != nil {
+			t.Fatalf("FindSnapshotIDByMoref(%q) error = %v", moref, err)
+		}
+		ids[id] = true
+
+		snapshotRef, err := service.FindSnapshotByName(ctx, vmName, snapshotName, id)
+		if err != nil {
+			t.Fatalf("FindSnapshotByName(%q, %d) error = %v", snapshotName, id, err)
+		}
+		if snapshotRef.Value != moref {
+			t.Errorf("FindSnapshotByName(%q, %d) = %v, want %v", snapshotName, id, snapshotRef.Value, moref)
+		}
+	}
+	if len(ids) != len(morefs) {
+		t.Errorf("FindSnapshotIDByMoref() returned %d distinct IDs, want %d", len(ids), len(morefs))
+	}
+	if _, err := service.FindSnapshotByName(ctx, vmName, snapshotName, 0); !errors.Is(err, ErrSnapshotAmbiguous) {
+		t.Errorf("FindSnapshotByName(%q) without an ID error = %v, want %v", snapshotName, err, ErrSnapshotAmbiguous)
+	}
+
+	if _, err := service.FindSnapshotIDByMoref(ctx, vmName, "snapshot-missing"); err == nil {
+		t.Error("FindSnapshotIDByMoref() for an unknown moref error = nil, want an error")
+	}
+}
+
+func TestVMIdentifier(t *testing.T) {
+	self := vimtypes.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-42"}
+
+	tests := []struct {
+		name           string
+		vm             mo.VirtualMachine
+		wantUUID       string
+		wantDegraded   bool
+		wantPowerState string
+	}{
+		{
+			name: "config available",
+			vm: mo.VirtualMachine{
+				ManagedEntity: mo.ManagedEntity{ExtensibleManagedObject: mo.ExtensibleManagedObject{Self: self}, Name: "web-01"},
+				Config:        &vimtypes.VirtualMachineConfigInfo{Uuid: "4201c5a3-1111-2222-3333-444455556666"},
+				Runtime: vimtypes.VirtualMachineRuntimeInfo{
+					ConnectionState: vimtypes.VirtualMachineConnectionStateConnected,
+					PowerState:      vimtypes.VirtualMachinePowerStatePoweredOn,
+				},
+			},
+			wantUUID:       "4201c5a3-1111-2222-3333-444455556666",
+			wantPowerState: "poweredOn",
+		},
+		{
+			name: "nil config on an orphaned VM",
+			vm: mo.VirtualMachine{
+				ManagedEntity: mo.ManagedEntity{ExtensibleManagedObject: mo.ExtensibleManagedObject{Self: self}, Name: "web-01"},
+				Runtime: vimtypes.VirtualMachineRuntimeInfo{
+					ConnectionState: vimtypes.VirtualMachineConnectionStateOrphaned,
+					PowerState:      vimtypes.VirtualMachinePowerStatePoweredOff,
+				},
+			},
+			wantUUID:       "vm-42",
+			wantDegraded:   true,
+			wantPowerState: "poweredOff",
+		},
+		{
+			name: "empty config UUID",
+			vm: mo.VirtualMachine{
+				ManagedEntity: mo.ManagedEntity{ExtensibleManagedObject: mo.ExtensibleManagedObject{Self: self}, Name: "web-01"},
+				Config:        &vimtypes.VirtualMachineConfigInfo{},
+			},
+			wantUUID: "vm-42",
+		},
+	}
+
+	service := newTestService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := service.convertToVMInfo(tt.vm)
+			if info.UUID != tt.wantUUID || info.Degraded != tt.wantDegraded || info.PowerState != tt.wantPowerState {
+				t.Errorf("convertToVMInfo() = %+v, want UUID %q, Degraded %v, PowerState %q", info, tt.wantUUID, tt.wantDegraded, tt.wantPowerState)
+			}
+
+			detailed := service.convertToVMDetailedInfo(tt.vm, AllVMFields())
+			if detailed.UUID != tt.wantUUID {
+				t.Errorf("convertToVMDetailedInfo() UUID = %q, want %q", detailed.UUID, tt.wantUUID)
+			}
+			if detailed.Moref != self.Value {
+				t.Errorf("convertToVMDetailedInfo() Moref = %q, want %q", detailed.Moref, self.Value)
+			}
+		})
+	}
+}
+
+func TestGetCurrentDiskInfoDeltaDisk(t *testing.T) {
+	model := simulator.VPX()
+	service := newSimulatorService(t, startSimulator(t, model))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	vm, _, err := service.findVM(ctx, VMRef{Name: vmName}, "")
+	if err != nil {
+		t.Fatalf("findVM() error = %v", err)
+	}
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatalf("PowerOff() error = %v", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("PowerOff() task error = %v", err)
+	}
+
+	// vcsim keeps VMs on their flat disks across snapshots, so put the disk on a delta
+	// file as vSphere does once the VM has a snapshot
+	const (
+		basePath  = "[LocalDS_0] DC0_H0_VM0/disk1.vmdk"
+		deltaPath = "[LocalDS_0] DC0_H0_VM0/disk1-000001.vmdk"
+	)
+	simVM := model.Map().Get(vm.Reference()).(*simulator.VirtualMachine)
+	for _, device := range simVM.Config.Hardware.Device {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			disk.Backing = &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: deltaPath},
+				Parent: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+					VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: basePath},
+				},
+			}
+		}
+	}
+
+	info, err := service.GetCurrentDiskInfo(ctx, VMRef{Name: vmName}, "", false)
+	if err != nil {
+		t.Fatalf("GetCurrentDiskInfo() error = %v", err)
+	}
+	if info.SnapshotMoref != "" {
+		t.Errorf("GetCurrentDiskInfo() SnapshotMoref = %q, want empty", info.SnapshotMoref)
+	}
+	want := []string{deltaPath}
+	if fmt.Sprint(info.DiskPaths) != fmt.Sprint(want) {
+		t.Errorf("GetCurrentDiskInfo() DiskPaths = %v, want %v", info.DiskPaths, want)
+	}
+	if fmt.Sprint(info.BaseDiskPaths) != fmt.Sprint(want) {
+		t.Errorf("GetCurrentDiskInfo() BaseDiskPaths = %v, want the current disks %v", info.BaseDiskPaths, want)
+	}
+}
+
+func TestIsServiceClone(t *testing.T) {
+	marked := &vimtypes.VirtualMachineConfigInfo{
+		ExtraConfig: []vimtypes.BaseOptionValue{&vimtypes.OptionValue{Key: cloneMarkerKey, Value: "true"}},
+	}
+	unmarked := &vimtypes.VirtualMachineConfigInfo{
+		ExtraConfig: []vimtypes.BaseOptionValue{&vimtypes.OptionValue{Key: "guestinfo.role", Value: "db"}},
+	}
+
+	tests := []struct {
+		name        string
+		vmName      string
+		config      *vimtypes.VirtualMachineConfigInfo
+		namePattern string
+		want        bool
+	}{
+		{name: "marked clone with a custom name", vmName: "web01-debug", config: marked, want: true},
+		{name: "marked clone not matching the pattern", vmName: "web01-debug", config: marked, namePattern: "db*", want: false},
+		{name: "generated inspection clone name", vmName: "web01-inspect-clone-1700000000", config: unmarked, want: true},
+		{name: "generated API clone name", vmName: "web01-clone-20240102030405", want: true},
+		{name: "generated name matching the pattern", vmName: "web01-clone-20240102030405", namePattern: "web01-*", want: true},
+		{name: "generated name not matching the pattern", vmName: "web01-clone-20240102030405", namePattern: "db*", want: false},
+		{name: "user VM with clone in its name", vmName: "db-clone-primary", config: unmarked, want: false},
+		{name: "user VM with a short numeric suffix", vmName: "app-clone-2", config: unmarked, want: false},
+		{name: "timestamp not at the end", vmName: "web01-clone-20240102030405-keep", want: false},
+		{name: "marker explicitly unset", vmName: "web01-debug", config: &vimtypes.VirtualMachineConfigInfo{
+			ExtraConfig: []vimtypes.BaseOptionValue{&vimtypes.OptionValue{Key: cloneMarkerKey, Value: "false"}},
+		}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vm mo.VirtualMachine
+			vm.Name = tt.vmName
+			vm.Config = tt.config
+			if got := isServiceClone(vm, tt.namePattern); got != tt.want {
+				t.Errorf("isServiceClone(%q, %q) = %v, want %v", tt.vmName, tt.namePattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkedCloneSpecMarksClone(t *testing.T) {
+	snapshotRef := &vimtypes.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-1"}
+	spec := linkedCloneSpec(snapshotRef)
+
+	var vm mo.VirtualMachine
+	vm.Name = "web01-debug"
+	vm.Config = &vimtypes.VirtualMachineConfigInfo{ExtraConfig: spec.Config.ExtraConfig}
+	if !isServiceClone(vm, "") {
+		t.Errorf("linkedCloneSpec() extraConfig = %v, want the %s marker", spec.Config.ExtraConfig, cloneMarkerKey)
+	}
+	if spec.Snapshot != snapshotRef || spec.PowerOn {
+		t.Errorf("linkedCloneSpec() = %+v, want a powered-off clone of %v", spec, snapshotRef)
+	}
+}
+
+// powerOffVM powers off a simulator VM
+func powerOffVM(t *testing.T, service *VMService, name string) {
+	t.Helper()
+	ctx := context.Background()
+
+	vm, _, err := service.findVM(ctx, VMRef{Name: name}, "")
+	if err != nil {
+		t.Fatalf("findVM(%q) error = %v", name, err)
+	}
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatalf("PowerOff(%q) error = %v", name, err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("PowerOff(%q) task error = %v", name, err)
+	}
+}
+
+// markClone marks a simulator VM the way CreateLinkedClone marks its clones, since vcsim
+// ignores the extraConfig of clone specs
+func markClone(t *testing.T, service *VMService, name string) {
+	t.Helper()
+	ctx := context.Background()
+
+	vm, _, err := service.findVM(ctx, VMRef{Name: name}, "")
+	if err != nil {
+		t.Fatalf("findVM(%q) error = %v", name, err)
+	}
+	task, err := vm.Reconfigure(ctx, *linkedCloneSpec(nil).Config)
+	if err != nil {
+		t.Fatalf("Reconfigure(%q) error = %v", name, err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("Reconfigure(%q) task error = %v", name, err)
+	}
+}
+
+func TestCleanupStaleClonesMarkedClone(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	// The marked VM's name gives no hint that it is a clone
+	const cloneName, keptName = "DC0_H0_VM0", "DC0_H0_VM1"
+	powerOffVM(t, service, cloneName)
+	powerOffVM(t, service, keptName)
+	markClone(t, service, cloneName)
+
+	result, err := service.CleanupStaleClones(ctx, time.Nanosecond, "")
+	if err != nil {
+		t.Fatalf("CleanupStaleClones() error = %v", err)
+	}
+	if fmt.Sprint(result.Deleted) != fmt.Sprint([]string{cloneName}) {
+		t.Errorf("CleanupStaleClones() deleted %v, want [%s]", result.Deleted, cloneName)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("CleanupStaleClones() failed = %v, want none", result.Failed)
+	}
+	if _, _, err := service.findVM(ctx, VMRef{Name: keptName}, ""); err != nil {
+		t.Errorf("findVM(%q) after cleanup error = %v, want the unmarked VM kept", keptName, err)
+	}
+}
+
+func TestCleanupStaleClonesDependentClone(t *testing.T) {
+	model := simulator.VPX()
+	service := newSimulatorService(t, startSimulator(t, model))
+	ctx := context.Background()
+
+	const cloneName, dependentName = "DC0_H0_VM0", "DC0_H0_VM1"
+	powerOffVM(t, service, cloneName)
+	markClone(t, service, cloneName)
+
+	// vcsim clones get flat disks, so put the dependent VM on a delta disk of the clone's disk
+	clone, _, err := service.findVM(ctx, VMRef{Name: cloneName}, "")
+	if err != nil {
+		t.Fatalf("findVM(%q) error = %v", cloneName, err)
+	}
+	dependent, _, err := service.findVM(ctx, VMRef{Name: dependentName}, "")
+	if err != nil {
+		t.Fatalf("findVM(%q) error = %v", dependentName, err)
+	}
+	var cloneDisk string
+	for _, device := range model.Map().Get(clone.Reference()).(*simulator.VirtualMachine).Config.Hardware.Device {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			cloneDisk = disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo).FileName
+		}
+	}
+	for _, device := range model.Map().Get(dependent.Reference()).(*simulator.VirtualMachine).Config.Hardware.Device {
+		if disk, ok := device.(*vimtypes.VirtualDisk); ok {
+			disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo).Parent = &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: cloneDisk},
+			}
+		}
+	}
+
+	result, err := service.CleanupStaleClones(ctx, time.Nanosecond, "")
+	if err != nil {
+		t.Fatalf("CleanupStaleClones() error = %v", err)
+	}
+	if len(result.Deleted) != 0 {
+		t.Errorf("CleanupStaleClones() deleted %v, want none", result.Deleted)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Name != cloneName || !strings.Contains(result.Failed[0].Error, dependentName) {
+		t.Errorf("CleanupStaleClones() failed = %v, want %s reported as backing %s", result.Failed, cloneName, dependentName)
+	}
+	if _, _, err := service.findVM(ctx, VMRef{Name: cloneName}, ""); err != nil {
+		t.Errorf("findVM(%q) after cleanup error = %v, want the clone kept", cloneName, err)
+	}
+}
+
+func TestGetBaseDiskPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		diskPath string
+		want     string
+	}{
+		{name: "base disk", diskPath: "[datastore1] web01/web01.vmdk", want: "[datastore1] web01/web01.vmdk"},
+		{name: "single delta", diskPath: "[datastore1] web01/web01-000002.vmdk", want: "[datastore1] web01/web01.vmdk"},
+		{name: "wide delta number", diskPath: "[datastore1] web01/web01-0000012.vmdk", want: "[datastore1] web01/web01.vmdk"},
+		{name: "chained deltas", diskPath: "[datastore1] web01/web01-000001-000002.vmdk", want: "[datastore1] web01/web01.vmdk"},
+		{name: "second disk delta", diskPath: "[datastore1] web01/web01_1-000003.vmdk", want: "[datastore1] web01/web01_1.vmdk"},
+		{name: "VM name ending in a number", diskPath: "[datastore1] web-01/web-01.vmdk", want: "[datastore1] web-01/web-01.vmdk"},
+		{name: "short number is not a delta", diskPath: "[datastore1] app/app-20240.vmdk", want: "[datastore1] app/app-20240.vmdk"},
+		{name: "delta of a VM name ending in a number", diskPath: "[datastore1] web-01/web-01-000001.vmdk", want: "[datastore1] web-01/web-01.vmdk"},
+		{name: "suffix not at the end", diskPath: "[datastore1] web01-000001/web01.vmdk", want: "[datastore1] web01-000001/web01.vmdk"},
+		{name: "not a vmdk", diskPath: "[datastore1] web01/web01-000001.vmsn", want: "[datastore1] web01/web01-000001.vmsn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBaseDiskPath(tt.diskPath); got != tt.want {
+				t.Errorf("getBaseDiskPath(%q) = %q, want %q", tt.diskPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectDiskPaths(t *testing.T) {
+	flatDisk := func(fileName string, parent *vimtypes.VirtualDiskFlatVer2BackingInfo) *vimtypes.VirtualDisk {
+		return &vimtypes.VirtualDisk{VirtualDevice: vimtypes.VirtualDevice{
+			Backing: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: fileName},
+				Parent:                       parent,
+			},
+		}}
+	}
+	parent := &vimtypes.VirtualDiskFlatVer2BackingInfo{
+		VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: "[datastore1] template/template.vmdk"},
+	}
+
+	devices := []vimtypes.BaseVirtualDevice{
+		&vimtypes.ParaVirtualSCSIController{},
+		// A linked clone's delta disk, whose parent lives in another VM's folder
+		flatDisk("[datastore1] clone/clone-000001.vmdk", parent),
+		// A delta disk without parent information falls back to the file name
+		flatDisk("[datastore1] web01/web01_1-000002.vmdk", nil),
+		flatDisk("[datastore1] web01/web01_2.vmdk", nil),
+		&vimtypes.VirtualDisk{VirtualDevice: vimtypes.VirtualDevice{
+			Backing: &vimtypes.VirtualDiskRawDiskMappingVer1BackingInfo{},
+		}},
+	}
+
+	diskPaths, baseDiskPaths := newTestService().collectDiskPaths(devices)
+
+	wantDiskPaths := []string{
+		"[datastore1] clone/clone-000001.vmdk",
+		"[datastore1] web01/web01_1-000002.vmdk",
+		"[datastore1] web01/web01_2.vmdk",
+	}
+	wantBaseDiskPaths := []string{
+		"[datastore1] template/template.vmdk",
+		"[datastore1] web01/web01_1.vmdk",
+		"[datastore1] web01/web01_2.vmdk",
+	}
+	if fmt.Sprint(diskPaths) != fmt.Sprint(wantDiskPaths) {
+		t.Errorf("collectDiskPaths() diskPaths = %v, want %v", diskPaths, wantDiskPaths)
+	}
+	if fmt.Sprint(baseDiskPaths) != fmt.Sprint(wantBaseDiskPaths) {
+		t.Errorf("collectDiskPaths() baseDiskPaths = %v, want %v", baseDiskPaths, wantBaseDiskPaths)
+	}
+}
+
+func TestListVMs(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		filter    VMFilter
+		wantNames []string
+		wantTotal int
+		wantErr   error
+	}{
+		{
+			name:      "all VMs",
+			wantNames: []string{"DC0_C0_RP0_VM0", "DC0_C0_RP0_VM1", "DC0_H0_VM0", "DC0_H0_VM1"},
+			wantTotal: 4,
+		},
+		{
+			name:      "name substring",
+			filter:    VMFilter{Name: "h0_vm"},
+			wantNames: []string{"DC0_H0_VM0", "DC0_H0_VM1"},
+			wantTotal: 2,
+		},
+		{
+			name:      "page",
+			filter:    VMFilter{Limit: 2, Offset: 1},
+			wantNames: []string{"DC0_C0_RP0_VM1", "DC0_H0_VM0"},
+			wantTotal: 4,
+		},
+		{
+			name:      "powered off",
+			filter:    VMFilter{PowerState: "poweredOff"},
+			wantNames: []string{},
+			wantTotal: 0,
+		},
+		{name: "unknown datacenter", filter: VMFilter{Datacenter: "missing"}, wantErr: ErrDatacenterNotFound},
+		{name: "unknown cluster", filter: VMFilter{Cluster: "missing"}, wantErr: ErrClusterNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.ListVMs(ctx, tt.filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ListVMs() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListVMs() error = %v", err)
+			}
+
+			names := make([]string, 0, len(result.VMs))
+			for _, vm := range result.VMs {
+				names = append(names, vm.Name)
+				if vm.Moref == "" || vm.UUID == "" {
+					t.Errorf("ListVMs() VM %s has moref %q and UUID %q, want both set", vm.Name, vm.Moref, vm.UUID)
+				}
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("ListVMs() names = %v, want %v", names, tt.wantNames)
+			}
+			if result.Total != tt.wantTotal {
+				t.Errorf("ListVMs() total = %d, want %d", result.Total, tt.wantTotal)
+			}
+			if result.Datacenter != "DC0" {
+				t.Errorf("ListVMs() datacenter = %q, want %q", result.Datacenter, "DC0")
+			}
+		})
+	}
+}
+
+func TestGetVMByName(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	result, err := service.GetVMByName(ctx, "DC0_H0_VM0", AllVMFields())
+	if err != nil {
+		t.Fatalf("GetVMByName() error = %v", err)
+	}
+	if result.Datacenter != "DC0" || result.VM.Name != "DC0_H0_VM0" {
+		t.Errorf("GetVMByName() = %s/%s, want DC0/DC0_H0_VM0", result.Datacenter, result.VM.Name)
+	}
+	if result.VM.Moref == "" || result.VM.UUID == "" {
+		t.Errorf("GetVMByName() moref = %q, UUID = %q, want both set", result.VM.Moref, result.VM.UUID)
+	}
+	if result.VM.PowerState != string(vimtypes.VirtualMachinePowerStatePoweredOn) {
+		t.Errorf("GetVMByName() power state = %q, want %q", result.VM.PowerState, vimtypes.VirtualMachinePowerStatePoweredOn)
+	}
+	if len(result.VM.Disks) == 0 {
+		t.Errorf("GetVMByName() returned no disks")
+	}
+
+	var notFound *find.NotFoundError
+	if _, err := service.GetVMByName(ctx, "missing", AllVMFields()); !errors.As(err, &notFound) {
+		t.Errorf("GetVMByName() for a missing VM error = %v, want a *find.NotFoundError", err)
+	}
+}
+
+func TestCreateSnapshotAndFindByName(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	created := make(map[string]string)
+	for _, name := range []string{"first", "second"} {
+		result, err := service.CreateSnapshot(ctx, vmName, name, "test snapshot", false, false)
+		if err != nil {
+			t.Fatalf("CreateSnapshot(%q) error = %v", name, err)
+		}
+		if result.SnapshotMoref == "" {
+			t.Fatalf("CreateSnapshot(%q) returned no snapshot moref", name)
+		}
+		if result.QuiesceVerified {
+			t.Errorf("CreateSnapshot(%q) verified quiescing, which was not requested", name)
+		}
+		created[name] = result.SnapshotMoref
+	}
+	secondID, err := service.FindSnapshotIDByMoref(ctx, vmName, created["second"])
+	if err != nil {
+		t.Fatalf("FindSnapshotIDByMoref() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		vmName       string
+		snapshotName string
+		snapshotID   int32
+		want         string
+		wantErr      bool
+	}{
+		{name: "by name", vmName: vmName, snapshotName: "first", want: created["first"]},
+		{name: "nested snapshot by name", vmName: vmName, snapshotName: "second", want: created["second"]},
+		{name: "by ID", vmName: vmName, snapshotID: secondID, want: created["second"]},
+		{name: "by ID and matching name", vmName: vmName, snapshotName: "second", snapshotID: secondID, want: created["second"]},
+		{name: "ID with another name", vmName: vmName, snapshotName: "first", snapshotID: secondID, wantErr: true},
+		{name: "unknown name", vmName: vmName, snapshotName: "missing", wantErr: true},
+		{name: "VM without snapshots", vmName: "DC0_H0_VM1", snapshotName: "first", wantErr: true},
+		{name: "unknown VM", vmName: "missing", snapshotName: "first", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.FindSnapshotByName(ctx, tt.vmName, tt.snapshotName, tt.snapshotID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSnapshotByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Value != tt.want {
+				t.Errorf("FindSnapshotByName() = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateLinkedClone(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	if _, err := service.CreateSnapshot(ctx, vmName, "base", "", false, false); err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	snapshotRef, err := service.FindSnapshotByName(ctx, vmName, "base", 0)
+	if err != nil {
+		t.Fatalf("FindSnapshotByName() error = %v", err)
+	}
+
+	clone, err := service.CreateLinkedClone(ctx, vmName, snapshotRef, "web01-clone", CloneTarget{})
+	if err != nil {
+		t.Fatalf("CreateLinkedClone() error = %v", err)
+	}
+	if clone.Name != "web01-clone" || clone.Moref == "" {
+		t.Errorf("CreateLinkedClone() = %+v, want web01-clone with a moref", clone)
+	}
+
+	result, err := service.GetVMByName(ctx, "web01-clone", AllVMFields())
+	if err != nil {
+		t.Fatalf("GetVMByName() for the clone error = %v", err)
+	}
+	if result.VM.Moref != clone.Moref {
+		t.Errorf("clone moref = %q, want %q", result.VM.Moref, clone.Moref)
+	}
+	if result.VM.PowerState != string(vimtypes.VirtualMachinePowerStatePoweredOff) {
+		t.Errorf("clone power state = %q, want %q", result.VM.PowerState, vimtypes.VirtualMachinePowerStatePoweredOff)
+	}
+
+	if _, err := service.CreateLinkedClone(ctx, "missing", snapshotRef, "orphan-clone", CloneTarget{}); err == nil {
+		t.Errorf("CreateLinkedClone() from a missing VM error = nil, want an error")
+	}
+}
+
+func TestConvertToVMDetailedInfoTools(t *testing.T) {
+	tests := []struct {
+		name              string
+		guest             *vimtypes.GuestInfo
+		wantVersionStatus string
+		wantStatus        string
+		wantToolsRunning  bool
+	}{
+		{name: "no guest info", guest: nil},
+		{
+			name: "current tools",
+			guest: &vimtypes.GuestInfo{
+				ToolsStatus:         vimtypes.VirtualMachineToolsStatusToolsOk,
+				ToolsVersion:        "12352",
+				ToolsRunningStatus:  string(vimtypes.VirtualMachineToolsRunningStatusGuestToolsRunning),
+				ToolsVersionStatus2: string(vimtypes.VirtualMachineToolsVersionStatusGuestToolsCurrent),
+			},
+			wantVersionStatus: "guestToolsCurrent",
+			wantStatus:        "toolsOk",
+			wantToolsRunning:  true,
+		},
+		{
+			name: "outdated tools",
+			guest: &vimtypes.GuestInfo{
+				ToolsStatus:         vimtypes.VirtualMachineToolsStatusToolsOld,
+				ToolsVersionStatus2: string(vimtypes.VirtualMachineToolsVersionStatusGuestToolsSupportedOld),
+			},
+			wantVersionStatus: "guestToolsSupportedOld",
+			wantStatus:        "toolsOld",
+		},
+		{
+			name: "tools not installed",
+			guest: &vimtypes.GuestInfo{
+				ToolsStatus:         vimtypes.VirtualMachineToolsStatusToolsNotInstalled,
+				ToolsVersionStatus2: string(vimtypes.VirtualMachineToolsVersionStatusGuestToolsNotInstalled),
+			},
+			wantVersionStatus: "guestToolsNotInstalled",
+			wantStatus:        "toolsNotInstalled",
+		},
+	}
+
+	service := newTestService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vm mo.VirtualMachine
+			vm.Name = "web-01"
+			vm.Guest = tt.guest
+			vm.Config = &vimtypes.VirtualMachineConfigInfo{
+				Hardware: vimtypes.VirtualHardware{
+					Device: []vimtypes.BaseVirtualDevice{
+						&vimtypes.VirtualVmxnet3{VirtualVmxnet: vimtypes.VirtualVmxnet{VirtualEthernetCard: vimtypes.VirtualEthernetCard{
+							VirtualDevice: vimtypes.VirtualDevice{DeviceInfo: &vimtypes.Description{Label: "Network adapter 1"}},
+							MacAddress:    "00:50:56:00:00:01",
+						}}},
+					},
+				},
+			}
+
+			info := service.convertToVMDetailedInfo(vm, AllVMFields())
+			if info.ToolsVersionStatus != tt.wantVersionStatus {
+				t.Errorf("convertToVMDetailedInfo() ToolsVersionStatus = %q, want %q", info.ToolsVersionStatus, tt.wantVersionStatus)
+			}
+			if info.ToolsStatus != tt.wantStatus {
+				t.Errorf("convertToVMDetailedInfo() ToolsStatus = %q, want %q", info.ToolsStatus, tt.wantStatus)
+			}
+			// Adapters are reported from the device list even without guest info
+			if len(info.NetworkAdapters) != 1 {
+				t.Fatalf("convertToVMDetailedInfo() returned %d network adapters, want 1", len(info.NetworkAdapters))
+			}
+			if adapter := info.NetworkAdapters[0]; adapter.ToolsAvailable != tt.wantToolsRunning || adapter.MacAddress != "00:50:56:00:00:01" {
+				t.Errorf("convertToVMDetailedInfo() adapter = %+v, want MAC 00:50:56:00:00:01 and tools_available %v", adapter, tt.wantToolsRunning)
+			}
+		})
+	}
+}
+
+func TestGetVMByNameToolsVersionStatus(t *testing.T) {
+	model := simulator.VPX()
+	service := newSimulatorService(t, startSimulator(t, model))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	vm, _, err := service.findVMByName(ctx, vmName)
+	if err != nil {
+		t.Fatalf("findVMByName() error = %v", err)
+	}
+	simVM := model.Map().Get(vm.Reference()).(*simulator.VirtualMachine)
+	simVM.Guest.ToolsVersionStatus2 = string(vimtypes.VirtualMachineToolsVersionStatusGuestToolsNeedUpgrade)
+
+	// The version status is part of the basic section, retrieved whatever else is requested
+	fields, err := ParseVMFields([]string{"hardware"})
+	if err != nil {
+		t.Fatalf("ParseVMFields() error = %v", err)
+	}
+	result, err := service.GetVMByName(ctx, vmName, fields)
+	if err != nil {
+		t.Fatalf("GetVMByName() error = %v", err)
+	}
+	if got, want := result.VM.ToolsVersionStatus, "guestToolsNeedUpgrade"; got != want {
+		t.Errorf("GetVMByName() ToolsVersionStatus = %q, want %q", got, want)
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	key := &vimtypes.CryptoKeyId{KeyId: "key-1", ProviderId: &vimtypes.KeyProviderId{Id: "kms"}}
+	disk := func(keyID *vimtypes.CryptoKeyId) *vimtypes.VirtualDisk {
+		return &vimtypes.VirtualDisk{
+			VirtualDevice: vimtypes.VirtualDevice{
+				Backing: &vimtypes.VirtualDiskFlatVer2BackingInfo{
+					VirtualDeviceFileBackingInfo: vimtypes.VirtualDeviceFileBackingInfo{FileName: "[ds] web01/web01.vmdk"},
+					KeyId:                        keyID,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config *vimtypes.VirtualMachineConfigInfo
+		want   bool
+	}{
+		{name: "no config", config: nil, want: false},
+		{name: "plain VM", config: &vimtypes.VirtualMachineConfigInfo{
+			Hardware: vimtypes.VirtualHardware{Device: []vimtypes.BaseVirtualDevice{disk(nil)}},
+		}, want: false},
+		{name: "encrypted VM home", config: &vimtypes.VirtualMachineConfigInfo{
+			KeyId:    key,
+			Hardware: vimtypes.VirtualHardware{Device: []vimtypes.BaseVirtualDevice{disk(nil)}},
+		}, want: true},
+		{name: "encrypted disk", config: &vimtypes.VirtualMachineConfigInfo{
+			Hardware: vimtypes.VirtualHardware{Device: []vimtypes.BaseVirtualDevice{disk(nil), disk(key)}},
+		}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEncrypted(tt.config); got != tt.want {
+				t.Errorf("isEncrypted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotDiskInfoEncrypted(t *testing.T) {
+	model := simulator.VPX()
+	service := newSimulatorService(t, startSimulator(t, model))
+	ctx := context.Background()
+
+	const vmName = "DC0_H0_VM0"
+	if _, err := service.CreateSnapshot(ctx, vmName, "base", "", false, false); err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	vm, _, err := service.findVMByName(ctx, vmName)
+	if err != nil {
+		t.Fatalf("findVMByName() error = %v", err)
+	}
+	simVM := model.Map().Get(vm.Reference()).(*simulator.VirtualMachine)
+	simVM.Config.KeyId = &vimtypes.CryptoKeyId{KeyId: "key-1", ProviderId: &vimtypes.KeyProviderId{Id: "kms"}}
+
+	_, err = service.GetSnapshotDiskInfo(ctx, VMRef{Name: vmName}, "base", 0, "")
+	if !errors.Is(err, ErrVMEncrypted) {
+		t.Errorf("GetSnapshotDiskInfo() error = %v, want %v", err, ErrVMEncrypted)
+	}
+}
+
+func TestFindSnapshotDuplicateNames(t *testing.T) {
+	// base -> {nightly (2) -> pre-upgrade (3), branch (4) -> nightly (5)}
+	snapshotRef := func(moref string) vimtypes.ManagedObjectReference {
+		return vimtypes.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: moref}
+	}
+	tree := []vimtypes.VirtualMachineSnapshotTree{{
+		Name: "base", Id: 1, Snapshot: snapshotRef("snapshot-1"),
+		ChildSnapshotList: []vimtypes.VirtualMachineSnapshotTree{
+			{
+				Name: "nightly", Id: 2, Snapshot: snapshotRef("snapshot-2"),
+				ChildSnapshotList: []vimtypes.VirtualMachineSnapshotTree{
+					{Name: "pre-upgrade", Id: 3, Snapshot: snapshotRef("snapshot-3")},
+				},
+			},
+			{
+				Name: "branch", Id: 4, Snapshot: snapshotRef("snapshot-4"),
+				ChildSnapshotList: []vimtypes.VirtualMachineSnapshotTree{
+					{Name: "nightly", Id: 5, Snapshot: snapshotRef("snapshot-5")},
+				},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name         string
+		snapshotName string
+		snapshotID   int32
+		want         string
+		wantErr      error
+	}{
+		{name: "unique name", snapshotName: "pre-upgrade", want: "snapshot-3"},
+		{name: "duplicate name", snapshotName: "nightly", wantErr: ErrSnapshotAmbiguous},
+		{name: "duplicate name in the first branch by ID", snapshotName: "nightly", snapshotID: 2, want: "snapshot-2"},
+		{name: "duplicate name in the second branch by ID", snapshotName: "nightly", snapshotID: 5, want: "snapshot-5"},
+		{name: "ID only", snapshotID: 5, want: "snapshot-5"},
+	}
+
+	service := newTestService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.findSnapshot(context.Background(), tree, tt.snapshotName, tt.snapshotID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("findSnapshot() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findSnapshot() error = %v", err)
+			}
+			if got.Snapshot.Value != tt.want {
+				t.Errorf("findSnapshot() = %s, want %s", got.Snapshot.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSnapshotName(t *testing.T) {
+	snapshotRef := func(moref string) vimtypes.ManagedObjectReference {
+		return vimtypes.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: moref}
+	}
+	// base -> {before-upgrade -> after-upgrade, sibling}
+	tree := []vimtypes.VirtualMachineSnapshotTree{{
+		Name: "base", Description: "fresh install", Snapshot: snapshotRef("snapshot-1"),
+		ChildSnapshotList: []vimtypes.VirtualMachineSnapshotTree{
+			{
+				Name: "before-upgrade", Description: "before the upgrade", Snapshot: snapshotRef("snapshot-2"),
+				ChildSnapshotList: []vimtypes.VirtualMachineSnapshotTree{
+					{Name: "after-upgrade", Description: "upgrade done", Snapshot: snapshotRef("snapshot-3")},
+				},
+			},
+			{Name: "sibling", Snapshot: snapshotRef("snapshot-4")},
+		},
+	}}
+
+	tests := []struct {
+		name            string
+		moref           string
+		wantName        string
+		wantDescription string
+		wantOK          bool
+	}{
+		{name: "root", moref: "snapshot-1", wantName: "base", wantDescription: "fresh install", wantOK: true},
+		{name: "child", moref: "snapshot-2", wantName: "before-upgrade", wantDescription: "before the upgrade", wantOK: true},
+		{name: "grandchild", moref: "snapshot-3", wantName: "after-upgrade", wantDescription: "upgrade done", wantOK: true},
+		{name: "child after a branch", moref: "snapshot-4", wantName: "sibling", wantOK: true},
+		{name: "not in the tree", moref: "snapshot-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, description, ok := resolveSnapshotName(tree, snapshotRef(tt.moref))
+			if name != tt.wantName || description != tt.wantDescription || ok != tt.wantOK {
+				t.Errorf("resolveSnapshotName(%s) = %q, %q, %v, want %q, %q, %v", tt.moref, name, description, ok, tt.wantName, tt.wantDescription, tt.wantOK)
+			}
+		})
+	}
+
+	// The VM details report the name of the current snapshot, here the grandchild
+	var vm mo.VirtualMachine
+	vm.Name = "web-01"
+	current := snapshotRef("snapshot-3")
+	vm.Snapshot = &vimtypes.VirtualMachineSnapshotInfo{RootSnapshotList: tree, CurrentSnapshot: &current}
+
+	info := newTestService().convertToVMDetailedInfo(vm, AllVMFields())
+	if info.CurrentSnapshot != "snapshot-3" || info.CurrentSnapshotName != "after-upgrade" || info.CurrentSnapshotDescription != "upgrade done" {
+		t.Errorf("convertToVMDetailedInfo() current snapshot = %s %q %q, want snapshot-3 \"after-upgrade\" \"upgrade done\"", info.CurrentSnapshot, info.CurrentSnapshotName, info.CurrentSnapshotDescription)
+	}
+}
+
+func TestStorageUsage(t *testing.T) {
+	layout := &vimtypes.VirtualMachineFileLayoutEx{
+		File: []vimtypes.VirtualMachineFileLayoutExFileInfo{
+			{Name: "[LocalDS_0] web-01/web-01.vmx", Type: string(vimtypes.VirtualMachineFileLayoutExFileTypeConfig), Size: 4096},
+			{Name: "[LocalDS_0] web-01/web-01.vmdk", Type: string(vimtypes.VirtualMachineFileLayoutExFileTypeDiskDescriptor), Size: 1024},
+			{Name: "[LocalDS_0] web-01/web-01-flat.vmdk", Type: string(vimtypes.VirtualMachineFileLayoutExFileTypeDiskExtent), Size: 3 << 30},
+			{Name: "[LocalDS_0] web-01/vmware.log", Type: string(vimtypes.VirtualMachineFileLayoutExFileTypeLog), Size: 2048},
+		},
+	}
+	config := &vimtypes.VirtualMachineConfigInfo{
+		Hardware: vimtypes.VirtualHardware{
+			Device: []vimtypes.BaseVirtualDevice{
+				&vimtypes.VirtualDisk{CapacityInBytes: 10 << 30},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		storage         *vimtypes.VirtualMachineStorageSummary
+		layout          *vimtypes.VirtualMachineFileLayoutEx
+		config          *vimtypes.VirtualMachineConfigInfo
+		wantCommitted   int64
+		wantUncommitted int64
+		wantSource      string
+	}{
+		{
+			name:            "summary storage",
+			storage:         &vimtypes.VirtualMachineStorageSummary{Committed: 5 << 30, Uncommitted: 7 << 30},
+			layout:          layout,
+			config:          config,
+			wantCommitted:   5 << 30,
+			wantUncommitted: 7 << 30,
+			wantSource:      StorageSourceSummary,
+		},
+		{
+			name:            "no summary storage falls back to the file layout",
+			layout:          layout,
+			config:          config,
+			wantCommitted:   3<<30 + 4096 + 1024 + 2048,
+			wantUncommitted: 10<<30 - (3<<30 + 1024),
+			wantSource:      StorageSourceLayout,
+		},
+		{
+			name:          "file layout without config",
+			layout:        layout,
+			wantCommitted: 3<<30 + 4096 + 1024 + 2048,
+			wantSource:    StorageSourceLayout,
+		},
+		{
+			name:       "no summary storage or file layout",
+			config:     config,
+			wantSource: StorageSourceUnavailable,
+		},
+		{
+			name:       "empty file layout",
+			layout:     &vimtypes.VirtualMachineFileLayoutEx{},
+			config:     config,
+			wantSource: StorageSourceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vm mo.VirtualMachine
+			vm.Summary.Storage = tt.storage
+			vm.LayoutEx = tt.layout
+			vm.Config = tt.config
+
+			committed, uncommitted, source := storageUsage(vm)
+			if committed != tt.wantCommitted || uncommitted != tt.wantUncommitted || source != tt.wantSource {
+				t.Errorf("storageUsage() = %d, %d, %q, want %d, %d, %q", committed, uncommitted, source, tt.wantCommitted, tt.wantUncommitted, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestExtractNetworkAdapters(t *testing.T) {
+	card := func(label string) vimtypes.VirtualEthernetCard {
+		return vimtypes.VirtualEthernetCard{
+			VirtualDevice: vimtypes.VirtualDevice{
+				DeviceInfo:  &vimtypes.Description{Label: label},
+				Backing:     &vimtypes.VirtualEthernetCardNetworkBackingInfo{VirtualDeviceDeviceBackingInfo: vimtypes.VirtualDeviceDeviceBackingInfo{DeviceName: "VM Network"}},
+				Connectable: &vimtypes.VirtualDeviceConnectInfo{Connected: true},
+			},
+			MacAddress: "00:50:56:00:00:01",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		device   vimtypes.BaseVirtualDevice
+		wantType string
+	}{
+		{name: "VMXNET3", device: &vimtypes.VirtualVmxnet3{VirtualVmxnet: vimtypes.VirtualVmxnet{VirtualEthernetCard: card("Network adapter 1")}}, wantType: "VMXNET3"},
+		{name: "E1000e", device: &vimtypes.VirtualE1000e{VirtualEthernetCard: card("Network adapter 1")}, wantType: "E1000e"},
+		{name: "SR-IOV", device: &vimtypes.VirtualSriovEthernetCard{VirtualEthernetCard: card("SR-IOV network adapter 1")}, wantType: "SR-IOV"},
+		{name: "PVRDMA", device: &vimtypes.VirtualVmxnet3Vrdma{VirtualVmxnet3: vimtypes.VirtualVmxnet3{VirtualVmxnet: vimtypes.VirtualVmxnet{VirtualEthernetCard: card("Network adapter 1")}}}, wantType: "PVRDMA"},
+		{name: "VMXNET2", device: &vimtypes.VirtualVmxnet2{VirtualVmxnet: vimtypes.VirtualVmxnet{VirtualEthernetCard: card("Network adapter 1")}}, wantType: "VMXNET2"},
+	}
+
+	service := newTestService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices := []vimtypes.BaseVirtualDevice{
+				&vimtypes.VirtualDisk{VirtualDevice: vimtypes.VirtualDevice{DeviceInfo: &vimtypes.Description{Label: "Hard disk 1"}}},
+				tt.device,
+			}
+
+			adapters := service.extractNetworkAdapters(devices, nil)
+			if len(adapters) != 1 {
+				t.Fatalf("extractNetworkAdapters() returned %d adapters, want 1", len(adapters))
+			}
+			adapter := adapters[0]
+			if adapter.AdapterType != tt.wantType {
+				t.Errorf("extractNetworkAdapters() AdapterType = %q, want %q", adapter.AdapterType, tt.wantType)
+			}
+			if adapter.NetworkName != "VM Network" || adapter.MacAddress != "00:50:56:00:00:01" || !adapter.Connected {
+				t.Errorf("extractNetworkAdapters() = %+v, want a connected adapter on VM Network with MAC 00:50:56:00:00:01", adapter)
+			}
+		})
+	}
+}
+
+func TestExtractNetworkAdaptersDistributedPortgroup(t *testing.T) {
+	devices := []vimtypes.BaseVirtualDevice{
+		&vimtypes.VirtualVmxnet3{VirtualVmxnet: vimtypes.VirtualVmxnet{VirtualEthernetCard: vimtypes.VirtualEthernetCard{
+			VirtualDevice: vimtypes.VirtualDevice{
+				DeviceInfo: &vimtypes.Description{Label: "Network adapter 1"},
+				Backing: &vimtypes.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+					Port: vimtypes.DistributedVirtualSwitchPortConnection{
+						SwitchUuid:   "50 2a 9f 3c 1d 7e 4b 21-8c 6f 0a 9e 3b 5d 7c 11",
+						PortgroupKey: "dvportgroup-42",
+						PortKey:      "17",
+					},
+				},
+			},
+			MacAddress: "00:50:56:00:00:01",
+		}}},
+	}
+
+	adapters := newTestService().extractNetworkAdapters(devices, nil)
+	if len(adapters) != 1 {
+		t.Fatalf("extractNetworkAdapters() returned %d adapters, want 1", len(adapters))
+	}
+	adapter := adapters[0]
+	if adapter.PortgroupKey != "dvportgroup-42" || adapter.SwitchUUID != "50 2a 9f 3c 1d 7e 4b 21-8c 6f 0a 9e 3b 5d 7c 11" {
+		t.Errorf("extractNetworkAdapters() portgroup = %q, switch = %q, want the distributed port backing", adapter.PortgroupKey, adapter.SwitchUUID)
+	}
+	// The portgroup name is only known once the caller resolves it
+	if adapter.NetworkName != "" {
+		t.Errorf("extractNetworkAdapters() NetworkName = %q, want empty", adapter.NetworkName)
+	}
+}
+
+func TestGetVMByNameDistributedPortgroup(t *testing.T) {
+	service := newSimulatorService(t, startSimulator(t, simulator.VPX()))
+	ctx := context.Background()
+
+	// Simulated VMs are connected to a distributed switch portgroup
+	client, err := service.client.GetClient(ctx)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.Datacenter(ctx, "DC0")
+	if err != nil {
+		t.Fatalf("Datacenter() error = %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+	network, err := finder.Network(ctx, "DC0_DVPG0")
+	if err != nil {
+		t.Fatalf("Network() error = %v", err)
+	}
+	var portgroup mo.DistributedVirtualPortgroup
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, network.Reference(), []string{"key", "config.distributedVirtualSwitch"}, &portgroup); err != nil {
+		t.Fatalf("RetrieveOne(portgroup) error = %v", err)
+	}
+	var dvs mo.DistributedVirtualSwitch
+	if err := pc.RetrieveOne(ctx, *portgroup.Config.DistributedVirtualSwitch, []string{"uuid"}, &dvs); err != nil {
+		t.Fatalf("RetrieveOne(switch) error = %v", err)
+	}
+
+	result, err := service.GetVMByName(ctx, "DC0_H0_VM0", AllVMFields())
+	if err != nil {
+		t.Fatalf("GetVMByName() error = %v", err)
+	}
+	if len(result.VM.NetworkAdapters) == 0 {
+		t.Fatal("GetVMByName() returned no network adapters")
+	}
+	adapter := result.VM.NetworkAdapters[0]
+	if adapter.NetworkName != "DC0_DVPG0" || adapter.PortgroupKey != portgroup.Key || adapter.SwitchUUID != dvs.Uuid {
+		t.Errorf("GetVMByName() adapter network = %q (%s) on switch %q, want %q (%s) on switch %q",
+			adapter.NetworkName, adapter.PortgroupKey, adapter.SwitchUUID, "DC0_DVPG0", portgroup.Key, dvs.Uuid)
+	}
+}
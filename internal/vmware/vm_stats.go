@@ -0,0 +1,199 @@
+package vmware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/performance"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// realtimeInterval is the vSphere real-time performance sampling interval in seconds
+const realtimeInterval = 20
+
+// vmStatsCounters lists the real-time performance counters queried for VM statistics
+var vmStatsCounters = []string{
+	"cpu.usage.average",
+	"cpu.usagemhz.average",
+	"cpu.ready.summation",
+	"mem.usage.average",
+	"mem.consumed.average",
+	"mem.active.average",
+	"mem.vmmemctl.average",
+	"mem.swapped.average",
+	"disk.read.average",
+	"disk.write.average",
+	"disk.numberReadAveraged.average",
+	"disk.numberWriteAveraged.average",
+	"disk.maxTotalLatency.latest",
+	"net.received.average",
+	"net.transmitted.average",
+	"net.packetsRx.summation",
+	"net.packetsTx.summation",
+}
+
+// VMStatsInfo represents real-time performance statistics of a virtual machine
+type VMStatsInfo struct {
+	UUID          string    `json:"uuid"`
+	Name          string    `json:"name"`
+	PowerState    string    `json:"power_state"`
+	Available     bool      `json:"available"`
+	Note          string    `json:"note,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+
+	// CPU
+	CPUUsagePercent float64 `json:"cpu_usage_percent"`
+	CPUUsageMHz     int32   `json:"cpu_usage_mhz"`
+	CPUReadyTimeMS  int64   `json:"cpu_ready_time_ms"`
+
+	// Memory
+	MemoryUsagePercent float64 `json:"memory_usage_percent"`
+	MemoryUsageMB      int32   `json:"memory_usage_mb"`
+	MemoryActiveMB     int32   `json:"memory_active_mb"`
+	MemoryBalloonedMB  int32   `json:"memory_ballooned_mb"`
+	MemorySwappedMB    int32   `json:"memory_swapped_mb"`
+
+	// Disk
+	DiskReadIOPS  int64   `json:"disk_read_iops"`
+	DiskWriteIOPS int64   `json:"disk_write_iops"`
+	DiskReadMBps  float64 `json:"disk_read_mbps"`
+	DiskWriteMBps float64 `json:"disk_write_mbps"`
+	DiskLatencyMS float64 `json:"disk_latency_ms"`
+
+	// Network
+	NetworkReceiveMBps  float64 `json:"network_receive_mbps"`
+	NetworkTransmitMBps float64 `json:"network_transmit_mbps"`
+	NetworkReceivePPS   int64   `json:"network_receive_pps"`
+	NetworkTransmitPPS  int64   `json:"network_transmit_pps"`
+}
+
+// GetVMStats retrieves real-time performance statistics for a VM using the PerformanceManager
+// Powered-off VMs have no real-time counters, so zero values are returned with Available set to false
+func (s *VMService) GetVMStats(ctx context.Context, vmName string) (*VMStatsInfo, error) {
+	s.log(ctx).WithField("vm_name", vmName).Info("Getting VM performance statistics")
+
+	// Find VM by name
+	vm, _, err := s.findVMByName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get govmomi client for property collector and performance manager
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	var vmProps mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	err = pc.RetrieveOne(ctx, vm.Reference(), []string{
+		"name",
+		"config.uuid",
+		"runtime.powerState",
+		"runtime.bootTime",
+	}, &vmProps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	stats := &VMStatsInfo{
+		Name:       vmProps.Name,
+		PowerState: string(vmProps.Runtime.PowerState),
+		Timestamp:  time.Now(),
+	}
+	if vmProps.Config != nil {
+		stats.UUID = vmProps.Config.Uuid
+	}
+
+	if vmProps.Runtime.PowerState != vimtypes.VirtualMachinePowerStatePoweredOn {
+		stats.Note = fmt.Sprintf("real-time statistics are only available for powered-on VMs (power state: %s)", stats.PowerState)
+		s.log(ctx).WithField("power_state", stats.PowerState).Info("VM is not powered on, returning empty statistics")
+		return stats, nil
+	}
+
+	if vmProps.Runtime.BootTime != nil {
+		stats.UptimeSeconds = int64(time.Since(*vmProps.Runtime.BootTime).Seconds())
+	}
+
+	// Query the latest real-time sample of the aggregate ("" instance) counters
+	perfManager := performance.NewManager(client.Client)
+	spec := vimtypes.PerfQuerySpec{
+		MaxSample:  1,
+		IntervalId: realtimeInterval,
+		MetricId:   []vimtypes.PerfMetricId{{Instance: ""}},
+	}
+
+	sample, err := perfManager.SampleByName(ctx, spec, vmStatsCounters, []vimtypes.ManagedObjectReference{vm.Reference()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query performance counters: %w", err)
+	}
+
+	series, err := perfManager.ToMetricSeries(ctx, sample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert performance counters: %w", err)
+	}
+
+	if len(series) == 0 || len(series[0].Value) == 0 {
+		stats.Note = "no real-time statistics reported by vCenter yet"
+		return stats, nil
+	}
+
+	metric := series[0]
+	if len(metric.SampleInfo) > 0 {
+		stats.Timestamp = metric.SampleInfo[len(metric.SampleInfo)-1].Timestamp
+	}
+
+	values := make(map[string]int64)
+	for _, v := range metric.Value {
+		if v.Instance != "" || len(v.Value) == 0 {
+			continue
+		}
+		// vSphere reports -1 when no value is available for the sample
+		if latest := v.Value[len(v.Value)-1]; latest >= 0 {
+			values[v.Name] = latest
+		}
+	}
+
+	stats.Available = true
+	s.applyStatsCounters(stats, values)
+
+	s.log(ctx).WithFields(logrus.Fields{
+		"vm_name":  stats.Name,
+		"counters": len(values),
+	}).Info("VM performance statistics retrieved")
+
+	return stats, nil
+}
+
+// applyStatsCounters maps raw performance counter values onto VMStatsInfo, converting units
+func (s *VMService) applyStatsCounters(stats *VMStatsInfo, values map[string]int64) {
+	// Percentages are reported in hundredths of a percent
+	stats.CPUUsagePercent = float64(values["cpu.usage.average"]) / 100
+	stats.CPUUsageMHz = int32(values["cpu.usagemhz.average"])
+	stats.CPUReadyTimeMS = values["cpu.ready.summation"]
+
+	// Memory counters are reported in KB
+	stats.MemoryUsagePercent = float64(values["mem.usage.average"]) / 100
+	stats.MemoryUsageMB = int32(values["mem.consumed.average"] / 1024)
+	stats.MemoryActiveMB = int32(values["mem.active.average"] / 1024)
+	stats.MemoryBalloonedMB = int32(values["mem.vmmemctl.average"] / 1024)
+	stats.MemorySwappedMB = int32(values["mem.swapped.average"] / 1024)
+
+	// Throughput counters are reported in KBps
+	stats.DiskReadIOPS = values["disk.numberReadAveraged.average"]
+	stats.DiskWriteIOPS = values["disk.numberWriteAveraged.average"]
+	stats.DiskReadMBps = float64(values["disk.read.average"]) / 1024
+	stats.DiskWriteMBps = float64(values["disk.write.average"]) / 1024
+	stats.DiskLatencyMS = float64(values["disk.maxTotalLatency.latest"])
+
+	// Packet counters are summed over the sampling interval
+	stats.NetworkReceiveMBps = float64(values["net.received.average"]) / 1024
+	stats.NetworkTransmitMBps = float64(values["net.transmitted.average"]) / 1024
+	stats.NetworkReceivePPS = values["net.packetsRx.summation"] / realtimeInterval
+	stats.NetworkTransmitPPS = values["net.packetsTx.summation"] / realtimeInterval
+}
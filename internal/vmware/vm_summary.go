@@ -0,0 +1,183 @@
+package vmware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+)
+
+// VMSummaryInfo represents a VM with its sizing and guest OS, for list views
+type VMSummaryInfo struct {
+	UUID       string `json:"uuid"`
+	Name       string `json:"name"`
+	PowerState string `json:"power_state"`
+	CPUCount   int32  `json:"cpu_count"`
+	MemoryMB   int32  `json:"memory_mb"`
+	GuestOS    string `json:"guest_os"`
+	Datacenter string `json:"datacenter"`
+	Cluster    string `json:"cluster"`
+}
+
+// VMSummaryListResult represents the result of listing VM summaries
+type VMSummaryListResult struct {
+	Datacenter string          `json:"datacenter"`
+	VMs        []VMSummaryInfo `json:"vms"`
+	Total      int             `json:"total"`
+}
+
+// ListVMSummaries lists VMs with their CPU count, memory and guest OS
+// The summaries are read from summary.config in the same property retrieval as the VM names,
+// so the cost stays close to ListVMs instead of fetching the details of each VM
+// Name, datacenter and cluster filters and pagination behave like ListVMs
+func (s *VMService) ListVMSummaries(ctx context.Context, filter VMFilter) (*VMSummaryListResult, error) {
+	s.log(ctx).WithFields(logrus.Fields{
+		"filter": filter,
+	}).Info("Listing VM summaries")
+
+	// Get govmomi client
+	client, err := s.client.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vSphere client: %w", err)
+	}
+
+	datacenter, vmRefs, err := s.findFilteredVMs(ctx, client.Client, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vmRefs) == 0 {
+		return &VMSummaryListResult{
+			Datacenter: datacenter.Name(),
+			VMs:        []VMSummaryInfo{},
+			Total:      0,
+		}, nil
+	}
+
+	pc := property.DefaultCollector(client.Client)
+	vmProperties, err := s.retrieveVMProperties(ctx, pc, vmRefs, []string{
+		"name",
+		"config.uuid",
+		"runtime.powerState",
+		"runtime.connectionState",
+		"runtime.host",
+		"summary.config",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	var matched []mo.VirtualMachine
+	for _, vmProp := range vmProperties {
+		// Apply name filter (contains)
+		if filter.Name != "" && !strings.Contains(strings.ToLower(vmProp.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		matched = append(matched, vmProp)
+	}
+
+	// Sort by name for deterministic ordering before pagination
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Name < matched[j].Name
+	})
+	total := len(matched)
+
+	start, end := filter.page(total)
+	matched = matched[start:end]
+
+	// Clusters are only looked up for the returned page
+	clusters := map[string]string{}
+	if filter.Cluster == "" {
+		clusters, err = s.hostClusterNames(ctx, pc, matched)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summaries := make([]VMSummaryInfo, 0, len(matched))
+	for _, vmProp := range matched {
+		summary := VMSummaryInfo{
+			UUID:       s.vmIdentifier(vmProp),
+			Name:       vmProp.Name,
+			PowerState: string(vmProp.Runtime.PowerState),
+			CPUCount:   vmProp.Summary.Config.NumCpu,
+			MemoryMB:   vmProp.Summary.Config.MemorySizeMB,
+			GuestOS:    vmProp.Summary.Config.GuestFullName,
+			Datacenter: datacenter.Name(),
+			Cluster:    filter.Cluster,
+		}
+		if filter.Cluster == "" && vmProp.Runtime.Host != nil {
+			summary.Cluster = clusters[vmProp.Runtime.Host.Value]
+		}
+		summaries = append(summaries, summary)
+	}
+
+	s.log(ctx).WithField("total_vms", total).Info("VM summary listing completed")
+
+	return &VMSummaryListResult{
+		Datacenter: datacenter.Name(),
+		VMs:        summaries,
+		Total:      total,
+	}, nil
+}
+
+// hostClusterNames maps the hosts running the given VMs to the names of their clusters
+// Standalone hosts are not part of a cluster and are left out
+func (s *VMService) hostClusterNames(ctx context.Context, pc *property.Collector, vms []mo.VirtualMachine) (map[string]string, error) {
+	clusters := map[string]string{}
+
+	seen := map[string]bool{}
+	var hostRefs []vimtypes.ManagedObjectReference
+	for _, vm := range vms {
+		if vm.Runtime.Host == nil || seen[vm.Runtime.Host.Value] {
+			continue
+		}
+		seen[vm.Runtime.Host.Value] = true
+		hostRefs = append(hostRefs, *vm.Runtime.Host)
+	}
+	if len(hostRefs) == 0 {
+		return clusters, nil
+	}
+
+	var hosts []mo.HostSystem
+	if err := pc.Retrieve(ctx, hostRefs, []string{"parent"}, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to retrieve host properties: %w", err)
+	}
+
+	seen = map[string]bool{}
+	var clusterRefs []vimtypes.ManagedObjectReference
+	for _, host := range hosts {
+		if host.Parent == nil || host.Parent.Type != "ClusterComputeResource" || seen[host.Parent.Value] {
+			continue
+		}
+		seen[host.Parent.Value] = true
+		clusterRefs = append(clusterRefs, *host.Parent)
+	}
+	if len(clusterRefs) == 0 {
+		return clusters, nil
+	}
+
+	var clusterProperties []mo.ClusterComputeResource
+	if err := pc.Retrieve(ctx, clusterRefs, []string{"name"}, &clusterProperties); err != nil {
+		return nil, fmt.Errorf("failed to retrieve cluster properties: %w", err)
+	}
+
+	clusterNames := make(map[string]string, len(clusterProperties))
+	for _, cluster := range clusterProperties {
+		clusterNames[cluster.Self.Value] = cluster.Name
+	}
+	for _, host := range hosts {
+		if host.Parent != nil {
+			if name, ok := clusterNames[host.Parent.Value]; ok {
+				clusters[host.Self.Value] = name
+			}
+		}
+	}
+
+	return clusters, nil
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	validationtypes "github.com/kubev2v/vm-migration-detective/pkg/types"
 )
 
@@ -10,20 +12,43 @@ type VMInspectionRequest struct {
 }
 
 // CloneRequest represents a request to create a clone from snapshot
+// An empty SnapshotName clones the VM's current state through a temporary snapshot
 type CloneRequest struct {
-	SnapshotName string `json:"snapshot_name" binding:"required" example:"backup-snapshot"`
-	CloneName    string `json:"clone_name,omitempty" example:"my-clone"`
+	SnapshotName    string `json:"snapshot_name,omitempty" example:"backup-snapshot"`
+	SnapshotID      int32  `json:"snapshot_id,omitempty" binding:"omitempty,min=1" example:"3"`
+	CloneName       string `json:"clone_name,omitempty" example:"my-clone"`
+	TargetFolder    string `json:"target_folder,omitempty" example:"inspection-clones"`
+	TargetDatastore string `json:"target_datastore,omitempty" example:"scratch-datastore"`
 }
 
 // CloneResponse represents the response from clone creation
 type CloneResponse struct {
 	CloneName    string `json:"clone_name" example:"vm-clone-123"`
+	CloneUUID    string `json:"clone_uuid,omitempty" example:"4201a2b3-c4d5-e6f7-8901-234567890abc"`
+	CloneMoref   string `json:"clone_moref" example:"vm-1234"`
 	VMName       string `json:"vm_name" example:"web-server-01"`
 	SnapshotName string `json:"snapshot_name" example:"backup-snapshot"`
 	Status       string `json:"status" example:"completed"`
 	Message      string `json:"message" example:"Clone created successfully"`
 }
 
+// CloneCleanupFailure describes a stale clone that could not be deleted
+type CloneCleanupFailure struct {
+	Name  string `json:"name" example:"web-server-01-inspect-clone-1705329000"`
+	Error string `json:"error" example:"VM deletion failed: permission denied"`
+}
+
+// CloneCleanupResponse represents the result of a stale clone cleanup
+type CloneCleanupResponse struct {
+	OlderThan string                `json:"older_than" example:"1h0m0s"`
+	Pattern   string                `json:"pattern,omitempty" example:"web-server-01-*"`
+	Deleted   []string              `json:"deleted"`
+	Failed    []CloneCleanupFailure `json:"failed"`
+	Skipped   int                   `json:"skipped" example:"2"`
+	Status    string                `json:"status" example:"completed"`
+	Message   string                `json:"message" example:"Deleted 3 stale clone(s)"`
+}
+
 // VMInspectionResponse represents the response from VM inspection
 type VMInspectionResponse struct {
 	VMName        string      `json:"vm_name" example:"web-server-01"`
@@ -33,6 +58,22 @@ type VMInspectionResponse struct {
 	InspectorType string      `json:"inspector_type" example:"virt-inspector"`
 	VirtInspector interface{} `json:"virt_inspector,omitempty"`
 	VirtV2V       interface{} `json:"virt_v2v,omitempty"`
+
+	// MigrationRelevantPackages lists installed packages and drivers that matter for migration
+	MigrationRelevantPackages []MigrationPackage `json:"migration_relevant_packages,omitempty"`
+
+	// Cached is true when the result was loaded from the stored results instead of running the inspector
+	Cached bool `json:"cached" example:"false"`
+	// InspectedAt is when the inspector produced the result
+	InspectedAt time.Time `json:"inspected_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// MigrationPackage represents an installed package or driver that matters for migration,
+// categorized as 'vmware-tools', 'virtio' or 'cloud-init'
+type MigrationPackage struct {
+	Name     string `json:"name" example:"open-vm-tools"`
+	Version  string `json:"version,omitempty" example:"12.1.5"`
+	Category string `json:"category" example:"vmware-tools"`
 }
 
 // NewVirtInspectorResponse creates a response with virt-inspector data
@@ -59,6 +100,187 @@ func NewVirtV2VInspectorResponse(vmName, snapshotName, message string, data *val
 	}
 }
 
+// BatchInspectionItem identifies one snapshot to inspect in a batch inspection request
+type BatchInspectionItem struct {
+	VM         string `json:"vm" example:"web-server-01"`
+	Snapshot   string `json:"snapshot" example:"inspection-snapshot"`
+	SnapshotID int32  `json:"snapshot_id,omitempty" example:"3"`
+	Inspector  string `json:"inspector,omitempty" example:"virt-inspector"`
+	Datacenter string `json:"datacenter,omitempty" example:"Datacenter1"`
+}
+
+// BatchInspectionResult represents the outcome of one item of a batch inspection
+type BatchInspectionResult struct {
+	Index         int                   `json:"index" example:"0"`
+	VMName        string                `json:"vm_name" example:"web-server-01"`
+	SnapshotName  string                `json:"snapshot_name" example:"inspection-snapshot"`
+	InspectorType string                `json:"inspector_type" example:"virt-inspector"`
+	Status        string                `json:"status" example:"completed"`
+	Cached        bool                  `json:"cached" example:"false"`
+	Result        *VMInspectionResponse `json:"result,omitempty"`
+	Error         *ErrorResponse        `json:"error,omitempty"`
+}
+
+// BatchInspectionResponse represents the results of a batch inspection, in request order
+type BatchInspectionResponse struct {
+	Results   []BatchInspectionResult `json:"results"`
+	Total     int                     `json:"total" example:"3"`
+	Succeeded int                     `json:"succeeded" example:"2"`
+	Failed    int                     `json:"failed" example:"1"`
+}
+
+// InspectDiskRequest represents a request to inspect disks at known locations, without
+// looking the VM or snapshot up by name
+type InspectDiskRequest struct {
+	VMMoref             string `json:"vm_moref" binding:"required" example:"vm-1234"`
+	SnapshotMoref       string `json:"snapshot_moref" binding:"required" example:"snapshot-5678"`
+	BaseDiskPath        string `json:"base_disk_path" binding:"required" example:"[datastore1] web-server-01/web-server-01.vmdk"`
+	DiskPath            string `json:"disk_path,omitempty" example:"[datastore1] web-server-01/web-server-01-000001.vmdk"`
+	ComputeResourcePath string `json:"compute_resource_path" binding:"required" example:"/Datacenter1/host/Cluster1/esxi-01.example.com"`
+	Datacenter          string `json:"datacenter,omitempty" example:"Datacenter1"`
+	VMName              string `json:"vm_name,omitempty" example:"web-server-01"`
+	SnapshotName        string `json:"snapshot_name,omitempty" example:"backup-snapshot"`
+	Inspector           string `json:"inspector,omitempty" example:"virt-inspector"`
+}
+
+// InspectionProgressEvent is sent as a "progress" Server-Sent Event while a streamed inspection runs
+type InspectionProgressEvent struct {
+	Phase     string    `json:"phase" example:"running inspector"`
+	Timestamp time.Time `json:"timestamp" example:"2024-01-15T14:30:05Z"`
+}
+
+// JobCreateResponse represents the response from starting an asynchronous inspection job
+type JobCreateResponse struct {
+	JobID   string `json:"job_id" example:"0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"`
+	Status  string `json:"status" example:"pending"`
+	Message string `json:"message" example:"Inspection job accepted"`
+}
+
+// JobStatusResponse represents the status of an asynchronous inspection job
+type JobStatusResponse struct {
+	JobID         string                `json:"job_id" example:"0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"`
+	Status        string                `json:"status" example:"running"`
+	VMName        string                `json:"vm_name" example:"web-server-01"`
+	SnapshotName  string                `json:"snapshot_name" example:"backup-snapshot"`
+	InspectorType string                `json:"inspector_type" example:"virt-inspector"`
+	CreatedAt     time.Time             `json:"created_at" example:"2024-01-15T14:30:00Z"`
+	StartedAt     *time.Time            `json:"started_at,omitempty" example:"2024-01-15T14:30:01Z"`
+	CompletedAt   *time.Time            `json:"completed_at,omitempty" example:"2024-01-15T14:45:00Z"`
+	Result        *VMInspectionResponse `json:"result,omitempty"`
+	Error         *ErrorResponse        `json:"error,omitempty"`
+}
+
+// JobListRequest represents the query parameters for listing the inspection job history
+type JobListRequest struct {
+	VM     string `form:"vm" json:"vm,omitempty" example:"web-server-01"`
+	Status string `form:"status" json:"status,omitempty" binding:"omitempty,oneof=running completed failed" example:"failed"`
+	Limit  int    `form:"limit" json:"limit,omitempty" binding:"omitempty,min=1,max=500" example:"50"`
+	Offset int    `form:"offset" json:"offset,omitempty" binding:"omitempty,min=0" example:"0"`
+}
+
+// JobHistoryEntry represents a recorded inspection attempt
+type JobHistoryEntry struct {
+	JobID         string     `json:"job_id" example:"0b7e6c1a-3f2d-4c59-9a57-1c2e5f8d9a10"`
+	VMName        string     `json:"vm_name" example:"web-server-01"`
+	SnapshotName  string     `json:"snapshot_name" example:"backup-snapshot"`
+	InspectorType string     `json:"inspector_type" example:"virt-inspector"`
+	Datacenter    string     `json:"datacenter,omitempty" example:"Datacenter1"`
+	Async         bool       `json:"async" example:"true"`
+	RequestID     string     `json:"request_id,omitempty" example:"4f6c2d1e-8a9b-4c3d-9e2f-1a2b3c4d5e6f"`
+	Status        string     `json:"status" example:"failed"`
+	CreatedAt     time.Time  `json:"created_at" example:"2024-01-15T14:30:00Z"`
+	StartedAt     *time.Time `json:"started_at,omitempty" example:"2024-01-15T14:30:01Z"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" example:"2024-01-15T14:45:00Z"`
+	ErrorCode     string     `json:"error_code,omitempty" example:"INSPECTION_FAILED"`
+	ErrorMessage  string     `json:"error_message,omitempty" example:"inspection failed after 3 attempt(s): connection reset by peer"`
+}
+
+// JobListResponse represents a page of the inspection job history
+type JobListResponse struct {
+	Jobs   []JobHistoryEntry `json:"jobs"`
+	Total  int64             `json:"total" example:"42"`
+	Limit  int               `json:"limit" example:"50"`
+	Offset int               `json:"offset" example:"0"`
+}
+
+// ApplicationMatch represents an installed application found in stored inspection results
+type ApplicationMatch struct {
+	VMName       string    `json:"vm_name" example:"web-server-01"`
+	SnapshotName string    `json:"snapshot_name" example:"backup-snapshot"`
+	InspectedAt  time.Time `json:"inspected_at" example:"2024-01-15T14:45:00Z"`
+	Name         string    `json:"name" example:"openssl"`
+	Version      string    `json:"version" example:"1.0.2k"`
+	Arch         string    `json:"arch,omitempty" example:"x86_64"`
+}
+
+// ApplicationSearchResponse represents the result of searching inspections by application
+type ApplicationSearchResponse struct {
+	Application string             `json:"application" example:"openssl"`
+	Version     string             `json:"version,omitempty" example:"1.0"`
+	Matches     []ApplicationMatch `json:"matches"`
+	Total       int                `json:"total" example:"3"`
+}
+
+// InspectionArtifactFile represents a file stored for an inspection run
+type InspectionArtifactFile struct {
+	Name string `json:"name" example:"result.json"`
+	Size int64  `json:"size" example:"48213"`
+}
+
+// InspectionArtifactRun represents the artifacts stored for one inspection run
+type InspectionArtifactRun struct {
+	Run       string                   `json:"run" example:"20240115T143000.123456789Z"`
+	CreatedAt time.Time                `json:"created_at" example:"2024-01-15T14:30:00Z"`
+	Files     []InspectionArtifactFile `json:"files"`
+}
+
+// InspectionArtifactListResponse represents the stored inspection runs of a VM snapshot
+type InspectionArtifactListResponse struct {
+	VMName       string                  `json:"vm_name" example:"web-server-01"`
+	SnapshotName string                  `json:"snapshot_name" example:"backup-snapshot"`
+	Runs         []InspectionArtifactRun `json:"runs"`
+}
+
+// InspectionSummaryResponse represents a compact summary of stored inspection results
+type InspectionSummaryResponse struct {
+	VMName             string `json:"vm_name" example:"web-server-01"`
+	SnapshotName       string `json:"snapshot_name" example:"backup-snapshot"`
+	InspectorType      string `json:"inspector_type" example:"virt-inspector"`
+	OSFamily           string `json:"os_family" example:"linux"`
+	OSName             string `json:"os_name,omitempty" example:"linux"`
+	Distro             string `json:"distro,omitempty" example:"rhel"`
+	ProductName        string `json:"product_name,omitempty" example:"Red Hat Enterprise Linux 9.2 (Plow)"`
+	PackageFormat      string `json:"package_format,omitempty" example:"rpm"`
+	ApplicationCount   int    `json:"application_count" example:"512"`
+	BootFilesystemType string `json:"boot_filesystem_type,omitempty" example:"xfs"`
+
+	// AdditionalOperatingSystems lists the operating systems found after the one
+	// summarized above, e.g. on multi-boot disks
+	AdditionalOperatingSystems []InspectionOperatingSystem `json:"additional_operating_systems,omitempty"`
+}
+
+// InspectionOperatingSystem represents an operating system found in inspection results
+type InspectionOperatingSystem struct {
+	Root          string `json:"root,omitempty" example:"/dev/sda1"`
+	OSFamily      string `json:"os_family" example:"windows"`
+	OSName        string `json:"os_name,omitempty" example:"windows"`
+	Distro        string `json:"distro,omitempty" example:"windows"`
+	ProductName   string `json:"product_name,omitempty" example:"Windows 10 Pro"`
+	PackageFormat string `json:"package_format,omitempty" example:"rpm"`
+}
+
+// InspectionDeleteResponse represents the response from deleting stored inspection records
+type InspectionDeleteResponse struct {
+	Deleted int64  `json:"deleted" example:"2"`
+	Message string `json:"message" example:"Inspection records deleted successfully"`
+}
+
+// CheckRequest represents a request to run validation checks
+// An empty list runs all available checks
+type CheckRequest struct {
+	Checks []string `json:"checks,omitempty" example:"fstab,disk-access"`
+}
+
 // CheckResult represents the result of a single validation check
 type CheckResult struct {
 	CheckType string  `json:"check_type" example:"fstab"`
@@ -74,3 +296,19 @@ type CheckResponse struct {
 	Results      []CheckResult `json:"results"`
 	AllValid     bool          `json:"all_valid" example:"true"`
 }
+
+// PreflightCheck represents a single inspection prerequisite
+type PreflightCheck struct {
+	Name    string `json:"name" example:"nbdkit"`
+	Passed  bool   `json:"passed" example:"true"`
+	Message string `json:"message" example:"Found at /usr/bin/nbdkit"`
+}
+
+// PreflightResponse represents the result of checking inspection prerequisites for a snapshot
+type PreflightResponse struct {
+	VMName        string           `json:"vm_name" example:"web-server-01"`
+	SnapshotName  string           `json:"snapshot_name" example:"backup-snapshot"`
+	InspectorType string           `json:"inspector_type" example:"virt-inspector"`
+	Checks        []PreflightCheck `json:"checks"`
+	Ready         bool             `json:"ready" example:"true"`
+}
@@ -42,10 +42,18 @@ type Snapshot struct {
 }
 
 // SnapshotListResponse represents a list of snapshots for a VM
+// In the tree format, Snapshots holds the root snapshots with their children nested and
+// Total still counts every snapshot
 type SnapshotListResponse struct {
-	VMID      string     `json:"vm_id" example:"vm-123"`
-	Snapshots []Snapshot `json:"snapshots"`
-	Total     int        `json:"total" example:"5"`
+	VMID            string       `json:"vm_id" example:"vm-123"`
+	VMName          string       `json:"vm_name" example:"web-server-01"`
+	Format          string       `json:"format" example:"flat"`
+	Snapshots       []VMSnapshot `json:"snapshots"`
+	CurrentSnapshot string       `json:"current_snapshot,omitempty" example:"snapshot-1"`
+	Total           int          `json:"total" example:"5"`
+
+	CurrentSnapshotName        string `json:"current_snapshot_name,omitempty" example:"before-upgrade"`
+	CurrentSnapshotDescription string `json:"current_snapshot_description,omitempty" example:"Snapshot before OS upgrade"`
 }
 
 // HealthResponse represents the health check response
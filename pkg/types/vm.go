@@ -14,10 +14,15 @@ type VMListRequest struct {
 }
 
 // VM represents a virtual machine with minimal information
+// Degraded is set for VMs that are not connected (e.g. orphaned or inaccessible), for which
+// vSphere only reports the name, moref, power state and connection state
 type VM struct {
-	UUID       string `json:"uuid" example:"502e7c6e-b5c3-4d0e-9a5a-8b9c1d2e3f4g"`
-	Name       string `json:"name" example:"web-server-01"`
-	PowerState string `json:"power_state" example:"poweredOn"`
+	UUID            string `json:"uuid" example:"502e7c6e-b5c3-4d0e-9a5a-8b9c1d2e3f4g"`
+	Moref           string `json:"moref,omitempty" example:"vm-42"`
+	Name            string `json:"name" example:"web-server-01"`
+	PowerState      string `json:"power_state" example:"poweredOn"`
+	ConnectionState string `json:"connection_state,omitempty" example:"connected"`
+	Degraded        bool   `json:"degraded,omitempty" example:"false"`
 }
 
 // VMToolsInfo represents VMware Tools information
@@ -63,13 +68,41 @@ type VMListResponse struct {
 	Total      int    `json:"total" example:"150"`
 }
 
+// VMIPSearchMatch represents a VM whose guest reports the searched IP address
+type VMIPSearchMatch struct {
+	VM         VM            `json:"vm"`
+	MatchedIPs []VMIPAddress `json:"matched_ips"`
+}
+
+// VMIPSearchResponse represents the VMs reporting a searched IP address
+type VMIPSearchResponse struct {
+	IP         string            `json:"ip" example:"192.168.1.100"`
+	Datacenter string            `json:"datacenter" example:"Datacenter1"`
+	Matches    []VMIPSearchMatch `json:"matches"`
+	Total      int               `json:"total" example:"1"`
+}
+
+// DatacenterListResponse represents the datacenters available for the datacenter filter
+type DatacenterListResponse struct {
+	Datacenters []string `json:"datacenters" example:"Datacenter1,Datacenter2"`
+	Total       int      `json:"total" example:"2"`
+}
+
+// ClusterListResponse represents the clusters of a datacenter available for the cluster filter
+type ClusterListResponse struct {
+	Datacenter string   `json:"datacenter" example:"Datacenter1"`
+	Clusters   []string `json:"clusters" example:"Cluster1,Cluster2"`
+	Total      int      `json:"total" example:"2"`
+}
+
 // VMGuestInfo represents guest OS information
 type VMGuestInfo struct {
-	Hostname             string   `json:"hostname,omitempty" example:"web-server-01"`
-	IPAddresses          []string `json:"ip_addresses,omitempty" example:"192.168.1.100,10.0.0.5"`
-	GuestID              string   `json:"guest_id,omitempty" example:"rhel9_64Guest"`
-	GuestState           string   `json:"guest_state,omitempty" example:"running"`
-	GuestHeartbeatStatus string   `json:"guest_heartbeat_status,omitempty" example:"green"`
+	Hostname             string        `json:"hostname,omitempty" example:"web-server-01"`
+	IPAddresses          []string      `json:"ip_addresses,omitempty" example:"192.168.1.100,10.0.0.5"`
+	Addresses            []VMIPAddress `json:"addresses,omitempty"`
+	GuestID              string        `json:"guest_id,omitempty" example:"rhel9_64Guest"`
+	GuestState           string        `json:"guest_state,omitempty" example:"running"`
+	GuestHeartbeatStatus string        `json:"guest_heartbeat_status,omitempty" example:"green"`
 }
 
 // VMMetadata represents VM metadata
@@ -101,16 +134,41 @@ type VMDisk struct {
 	Datastore       string `json:"datastore" example:"datastore1"`
 	ThinProvisioned bool   `json:"thin_provisioned" example:"true"`
 	DiskMode        string `json:"disk_mode" example:"persistent"`
+	ControllerBus   string `json:"controller_bus,omitempty" example:"scsi"`
+	ControllerType  string `json:"controller_type,omitempty" example:"pvscsi"`
+	BusNumber       int32  `json:"bus_number" example:"0"`
+	UnitNumber      *int32 `json:"unit_number,omitempty" example:"1"`
+}
+
+// DiskListResponse represents the virtual disks of a VM
+type DiskListResponse struct {
+	VMID   string   `json:"vm_id" example:"vm-123"`
+	VMName string   `json:"vm_name" example:"web-server-01"`
+	Disks  []VMDisk `json:"disks"`
+	Total  int      `json:"total" example:"2"`
+}
+
+// VMIPAddress represents a guest IP address with its prefix length and classification
+type VMIPAddress struct {
+	Address      string `json:"address" example:"192.168.1.100"`
+	PrefixLength int32  `json:"prefix_length,omitempty" example:"24"`
+	CIDR         string `json:"cidr" example:"192.168.1.100/24"`
+	Family       string `json:"family" example:"ipv4"`
+	LinkLocal    bool   `json:"link_local" example:"false"`
 }
 
 // VMNetworkAdapter represents network adapter information
 type VMNetworkAdapter struct {
-	Label       string   `json:"label" example:"Network adapter 1"`
-	NetworkName string   `json:"network_name" example:"VM Network"`
-	MacAddress  string   `json:"mac_address" example:"00:50:56:9a:12:34"`
-	IPAddresses []string `json:"ip_addresses,omitempty" example:"192.168.1.100"`
-	Connected   bool     `json:"connected" example:"true"`
-	AdapterType string   `json:"adapter_type" example:"VMXNET3"`
+	Label          string        `json:"label" example:"Network adapter 1"`
+	NetworkName    string        `json:"network_name" example:"VM Network"`
+	MacAddress     string        `json:"mac_address" example:"00:50:56:9a:12:34"`
+	IPAddresses    []string      `json:"ip_addresses,omitempty" example:"192.168.1.100"`
+	Addresses      []VMIPAddress `json:"addresses,omitempty"`
+	Connected      bool          `json:"connected" example:"true"`
+	AdapterType    string        `json:"adapter_type" example:"VMXNET3"`
+	ToolsAvailable bool          `json:"tools_available" example:"true"`
+	PortgroupKey   string        `json:"portgroup_key,omitempty" example:"dvportgroup-1021"`
+	SwitchUUID     string        `json:"switch_uuid,omitempty" example:"50 2e 7c 6e b5 c3 4d 0e-9a 5a 8b 9c 1d 2e 3f 40"`
 }
 
 // VMSnapshot represents snapshot information
@@ -121,6 +179,12 @@ type VMSnapshot struct {
 	State       string    `json:"state" example:"poweredOff"`
 	Quiesced    bool      `json:"quiesced" example:"true"`
 	ID          int32     `json:"id" example:"1"`
+	ParentID    int32     `json:"parent_id,omitempty" example:"0"`
+	Depth       int       `json:"depth" example:"0"`
+	IsCurrent   bool      `json:"is_current" example:"false"`
+
+	// Children is only set when snapshots are listed as a tree
+	Children []VMSnapshot `json:"children,omitempty"`
 }
 
 // VMResourceInfo represents resource allocation information
@@ -142,6 +206,10 @@ type VMStorageSummary struct {
 	UncommittedBytes int64    `json:"uncommitted_bytes" example:"47244640256"`
 	UncommittedGB    int64    `json:"uncommitted_gb" example:"45"`
 	Datastores       []string `json:"datastores" example:"datastore1,datastore2"`
+
+	// StorageSource is 'summary' (vSphere storage summary), 'layout' (computed from the VM
+	// files when the summary is unavailable) or 'unavailable'
+	StorageSource string `json:"storage_source,omitempty" example:"summary"`
 }
 
 // VMFileInfo represents VM file information
@@ -168,6 +236,7 @@ type VMAdvancedInfo struct {
 // VMDetailsResponse represents detailed information about a single VM
 type VMDetailsResponse struct {
 	VM              VM                 `json:"vm"`
+	Fields          []string           `json:"fields" example:"basic,hardware,network,storage"`
 	Hardware        VMHardwareInfo     `json:"hardware"`
 	Tools           VMToolsInfo        `json:"tools"`
 	GuestInfo       VMGuestInfo        `json:"guest_info"`
@@ -177,6 +246,8 @@ type VMDetailsResponse struct {
 	NetworkAdapters []VMNetworkAdapter `json:"network_adapters,omitempty"`
 	Snapshots       []VMSnapshot       `json:"snapshots,omitempty"`
 	CurrentSnapshot string             `json:"current_snapshot,omitempty" example:"snapshot-1"`
+	CurrentSnapshotName        string  `json:"current_snapshot_name,omitempty" example:"before-upgrade"`
+	CurrentSnapshotDescription string  `json:"current_snapshot_description,omitempty" example:"Snapshot before OS upgrade"`
 	Resources       VMResourceInfo     `json:"resources"`
 	Storage         VMStorageSummary   `json:"storage"`
 	Files           VMFileInfo         `json:"files"`
@@ -224,6 +295,24 @@ type VMSummary struct {
 	Cluster    string `json:"cluster" example:"Cluster1"`
 }
 
+// VMSummaryListRequest represents the query parameters for listing VM summaries
+type VMSummaryListRequest struct {
+	NameContains string `form:"name_contains" json:"name_contains,omitempty" example:"web"`
+	Datacenter   string `form:"datacenter" json:"datacenter,omitempty" example:"Datacenter1"`
+	Cluster      string `form:"cluster" json:"cluster,omitempty" example:"Cluster1"`
+	Limit        int    `form:"limit" json:"limit,omitempty" binding:"omitempty,min=1,max=500" example:"50"`
+	Offset       int    `form:"offset" json:"offset,omitempty" binding:"omitempty,min=0" example:"0"`
+}
+
+// VMSummaryListResponse represents a page of VM summaries
+type VMSummaryListResponse struct {
+	Datacenter string      `json:"datacenter" example:"Datacenter1"`
+	VMs        []VMSummary `json:"vms"`
+	Total      int         `json:"total" example:"150"`
+	Limit      int         `json:"limit,omitempty" example:"50"`
+	Offset     int         `json:"offset" example:"0"`
+}
+
 // VMStatsResponse represents VM performance statistics
 type VMStatsResponse struct {
 	UUID        string            `json:"uuid" example:"502e7c6e-b5c3-4d0e-9a5a-8b9c1d2e3f4g"`
@@ -234,6 +323,9 @@ type VMStatsResponse struct {
 	DiskUsage   VMDiskStats       `json:"disk_usage"`
 	NetworkUsage VMNetworkStats   `json:"network_usage"`
 	Uptime      int64             `json:"uptime_seconds" example:"86400"`
+	PowerState  string            `json:"power_state" example:"poweredOn"`
+	Available   bool              `json:"available" example:"true"`
+	Note        string            `json:"note,omitempty" example:"real-time statistics are only available for powered-on VMs"`
 }
 
 // VMCPUStats represents CPU usage statistics
@@ -284,6 +376,54 @@ type VMOperationResponse struct {
 	Message   string `json:"message" example:"VM start operation initiated"`
 }
 
+// VMReconfigureRequest represents a request to change the CPU and memory configuration of a VM
+// Omitted fields are left unchanged
+type VMReconfigureRequest struct {
+	NumCPU            *int32 `json:"num_cpu,omitempty" binding:"omitempty,min=1" example:"4"`
+	NumCoresPerSocket *int32 `json:"num_cores_per_socket,omitempty" binding:"omitempty,min=1" example:"2"`
+	MemoryMB          *int64 `json:"memory_mb,omitempty" binding:"omitempty,min=4" example:"8192"`
+}
+
+// VMReconfigureResponse represents the VM configuration after a reconfiguration
+type VMReconfigureResponse struct {
+	Name              string `json:"name" example:"web-server-01-clone"`
+	PowerState        string `json:"power_state" example:"poweredOff"`
+	NumCPU            int32  `json:"num_cpu" example:"4"`
+	NumCoresPerSocket int32  `json:"num_cores_per_socket" example:"2"`
+	MemoryMB          int32  `json:"memory_mb" example:"8192"`
+	Status            string `json:"status" example:"completed"`
+	Message           string `json:"message" example:"VM reconfigured successfully"`
+}
+
+// VMAnnotationRequest represents a request to replace the annotation (notes) of a VM
+type VMAnnotationRequest struct {
+	Annotation string `json:"annotation" binding:"required" example:"migration: inspected 2024-01-15, ready"`
+}
+
+// VMAnnotationResponse represents the VM annotation after an update
+type VMAnnotationResponse struct {
+	Name       string `json:"name" example:"web-server-01"`
+	Annotation string `json:"annotation" example:"migration: inspected 2024-01-15, ready"`
+	Status     string `json:"status" example:"completed"`
+	Message    string `json:"message" example:"VM annotation updated successfully"`
+}
+
+// VMChangeTrackingResponse represents the change block tracking (CBT) state of a VM
+type VMChangeTrackingResponse struct {
+	Name                  string `json:"name" example:"web-server-01"`
+	Enabled               bool   `json:"enabled" example:"true"`
+	PowerState            string `json:"power_state" example:"poweredOn"`
+	RequiresSnapshotCycle bool   `json:"requires_snapshot_cycle" example:"true"`
+	Message               string `json:"message" example:"Change block tracking enabled; create and delete a snapshot to activate it on the running VM"`
+}
+
+// VMConsolidateResponse represents the result of a VM disk consolidation
+type VMConsolidateResponse struct {
+	Name    string `json:"name" example:"web-server-01"`
+	Status  string `json:"status" example:"completed"`
+	Message string `json:"message" example:"VM disks consolidated successfully"`
+}
+
 // SnapshotCreateRequest represents a request to create a VM snapshot
 type SnapshotCreateRequest struct {
 	Name        string `json:"name" binding:"required" example:"backup-snapshot"`
@@ -294,11 +434,32 @@ type SnapshotCreateRequest struct {
 
 // SnapshotCreateResponse represents the response for snapshot creation
 type SnapshotCreateResponse struct {
-	SnapshotID  string `json:"snapshot_id" example:"snapshot-123"`
-	Name        string `json:"name" example:"backup-snapshot"`
-	VMID        string `json:"vm_id" example:"vm-456"`
-	VMName      string `json:"vm_name" example:"web-server-01"`
-	Status      string `json:"status" example:"completed"`
-	Message     string `json:"message" example:"Snapshot created successfully"`
-	CreatedTime string `json:"created_time,omitempty" example:"2024-01-15T14:30:00Z"`
-}
\ No newline at end of file
+	SnapshotID  string   `json:"snapshot_id" example:"snapshot-123"`
+	Name        string   `json:"name" example:"backup-snapshot"`
+	VMID        string   `json:"vm_id" example:"vm-456"`
+	VMName      string   `json:"vm_name" example:"web-server-01"`
+	Status      string   `json:"status" example:"completed"`
+	Message     string   `json:"message" example:"Snapshot created successfully"`
+	CreatedTime string   `json:"created_time,omitempty" example:"2024-01-15T14:30:00Z"`
+	Warnings    []string `json:"warnings,omitempty" example:"Quiesce was requested but the snapshot is not quiesced; it is only crash-consistent"`
+}
+// VSphereStatusResponse represents the state of the service's vCenter session
+type VSphereStatusResponse struct {
+	Connected            bool       `json:"connected" example:"true"`
+	VCenterURL           string     `json:"vcenter_url" example:"https://vcenter.example.com/sdk"`
+	LastConnectTime      *time.Time `json:"last_connect_time,omitempty" example:"2024-01-15T14:30:00Z"`
+	ConnectedFor         string     `json:"connected_for,omitempty" example:"2h15m0s"`
+	ReconnectCount       int        `json:"reconnect_count" example:"0"`
+	LastHealthCheck      *time.Time `json:"last_health_check,omitempty" example:"2024-01-15T16:45:00Z"`
+	LastHealthCheckError string     `json:"last_health_check_error,omitempty" example:"connection not available: connection refused"`
+}
+
+// VSphereReconnectResponse represents the vCenter session established by a forced reconnect
+type VSphereReconnectResponse struct {
+	Connected      bool       `json:"connected" example:"true"`
+	VCenterURL     string     `json:"vcenter_url" example:"https://vcenter.example.com/sdk"`
+	ReconnectCount int        `json:"reconnect_count" example:"1"`
+	SessionKey     string     `json:"session_key,omitempty" example:"52b2ffd5-6f2d-ec3d-bb6b-9d3e4b1fd2a4"`
+	UserName       string     `json:"user_name,omitempty" example:"VSPHERE.LOCAL\\inspector"`
+	LoginTime      *time.Time `json:"login_time,omitempty" example:"2024-01-15T16:50:00Z"`
+}